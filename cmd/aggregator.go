@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc"
+)
+
+var backends []string
+var aggregatorPkg string
+
+// aggregatorCmd generates aggregator client code for BFF
+var aggregatorCmd = &cobra.Command{
+	Use:   "aggregator",
+	Short: "generate aggregator client which calls several backend services concurrently",
+	Long: `generate aggregator client which resolves each backend service through service registry and calls them concurrently,
+errors are collected per backend so that partial results are still available. Go http client code of each backend service
+must be generated by go-doudou svc http -c first, and backend modules must be required in go.mod of current project.
+
+example: go-doudou svc http aggregator -b ../usersvc -b order-svc=../ordersvc`,
+	Run: func(cmd *cobra.Command, args []string) {
+		s := svc.Svc{
+			Backends:      backends,
+			AggregatorPkg: aggregatorPkg,
+		}
+		s.GenAggregator()
+	},
+}
+
+func init() {
+	httpCmd.AddCommand(aggregatorCmd)
+
+	aggregatorCmd.Flags().StringArrayVarP(&backends, "backend", "b", nil, `backend service in the form of [serviceName=]dir, dir is project root path of the backend service, serviceName defaults to lowercase service interface name`)
+	aggregatorCmd.Flags().StringVarP(&aggregatorPkg, "pkg", "p", "aggregator", `aggregator client package name`)
+	aggregatorCmd.MarkFlagRequired("backend")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc"
+	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc/codegen"
 
 	"github.com/spf13/cobra"
 )
@@ -12,6 +13,9 @@ var doc bool
 var jsonattrcase string
 var routePatternStrategy int
 var allowGetWithReqBody bool
+var check bool
+var batch bool
+var iclientMode string
 
 // httpCmd generates scaffold code of restful service
 var httpCmd = &cobra.Command{
@@ -19,6 +23,8 @@ var httpCmd = &cobra.Command{
 	Short: "generate http routes and handlers",
 	Long:  ``,
 	Run: func(cmd *cobra.Command, args []string) {
+		mode, err := codegen.ParseOverwriteMode(iclientMode)
+		cobra.CheckErr(err)
 		s := svc.Svc{
 			Handler:              handler,
 			Client:               client,
@@ -28,6 +34,12 @@ var httpCmd = &cobra.Command{
 			Env:                  baseURLEnv,
 			RoutePatternStrategy: routePatternStrategy,
 			AllowGetWithReqBody:  allowGetWithReqBody,
+			Batch:                batch,
+			IClientMode:          mode,
+		}
+		if check {
+			cobra.CheckErr(s.Check())
+			return
 		}
 		s.Http()
 	},
@@ -44,4 +56,7 @@ func init() {
 	httpCmd.Flags().StringVarP(&baseURLEnv, "env", "e", "", `base url environment variable name`)
 	httpCmd.Flags().IntVarP(&routePatternStrategy, "routePattern", "r", 0, "route pattern generate strategy. 0 means splitting each methods of service interface by slash / after converting to snake case. 1 means no splitting, only lowercase. recommend default value.")
 	httpCmd.Flags().BoolVarP(&allowGetWithReqBody, "allowGetWithReqBody", "", false, "Whether allow get http request with request body.")
+	httpCmd.Flags().BoolVarP(&batch, "batch", "", false, "Whether generate batch endpoint bundling multiple calls into one request, and batch client code if --client is also set.")
+	httpCmd.Flags().StringVarP(&iclientMode, "iclientMode", "", "overwrite", "How to treat existing client/iclient.go when generating client code. optional values: overwrite, skip (leave it untouched), diff (write to iclient.go.new and print unified diff).")
+	httpCmd.Flags().BoolVarP(&check, "check", "", false, "Check whether generated code is up to date with svc.go without changing any file. Exit non-zero with a diff if stale. Useful in CI.")
 }
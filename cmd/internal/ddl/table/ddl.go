@@ -546,8 +546,10 @@ func updateIndexFromStruct(ctx context.Context, tx *sqlx.Tx, t Table, fks []Fore
 	}
 }
 
-func Setup() (func(), *sqlx.DB, error) {
-	var terminateContainer func() // variable to store function to terminate container
+// Setup starts a MySQL container and connects to it. It returns error rather than panicking if docker is not available,
+// so that callers can skip tests. The returned function terminates the container.
+func Setup() (func() error, *sqlx.DB, error) {
+	var terminateContainer func() error // variable to store function to terminate container
 	var host string
 	var port int
 	var err error
@@ -577,6 +579,7 @@ func Setup() (func(), *sqlx.DB, error) {
 	var db *sqlx.DB
 	db, err = sqlx.Connect("mysql", conn)
 	if err != nil {
+		_ = terminateContainer()
 		return nil, nil, errors.Wrap(err, caller.NewCaller().String())
 	}
 	db.MapperFunc(strcase.ToSnake)
@@ -584,7 +587,7 @@ func Setup() (func(), *sqlx.DB, error) {
 	return terminateContainer, db, nil
 }
 
-func setupMySQLContainer(logger zerolog.Logger, initdb string, dbname string) (func(), string, int, error) {
+func setupMySQLContainer(logger zerolog.Logger, initdb string, dbname string) (func() error, string, int, error) {
 	logger.Info().Msg("setup MySQL Container")
 	ctx := context.Background()
 	if stringutils.IsEmpty(dbname) {
@@ -609,21 +612,24 @@ func setupMySQLContainer(logger zerolog.Logger, initdb string, dbname string) (f
 	})
 
 	if err != nil {
-		logger.Error().Msgf("error starting mysql container: %s", err)
-		panic(fmt.Sprintf("%v", err))
+		return nil, "", 0, errors.Wrap(err, "error starting mysql container")
 	}
 
-	closeContainer := func() {
+	closeContainer := func() error {
 		logger.Info().Msg("terminating container")
-		err := mysqlC.Terminate(ctx)
-		if err != nil {
-			logger.Error().Msgf("error terminating mysql container: %s", err)
-			panic(fmt.Sprintf("%v", err))
-		}
+		return errors.Wrap(mysqlC.Terminate(ctx), "error terminating mysql container")
 	}
 
-	host, _ := mysqlC.Host(ctx)
-	p, _ := mysqlC.MappedPort(ctx, "3306/tcp")
+	host, err := mysqlC.Host(ctx)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting mysql container host")
+	}
+	p, err := mysqlC.MappedPort(ctx, "3306/tcp")
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting mysql container port")
+	}
 	port := p.Int()
 
 	return closeContainer, host, port, nil
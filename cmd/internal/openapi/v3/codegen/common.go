@@ -860,6 +860,8 @@ func New{{.Meta.Name}}(opts ...restclient.RestClientOption) *{{.Meta.Name}}Clien
 
 	svcClient.client.OnBeforeRequest(func(_ *resty.Client, request *resty.Request) error {
 		request.URL = svcClient.provider.SelectServer() + svcClient.rootPath + request.URL
+		restclient.PropagateTenant(request)
+		restclient.PropagateCorrelation(request)
 		return nil
 	})
 
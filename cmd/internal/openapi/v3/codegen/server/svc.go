@@ -1,14 +1,13 @@
 package server
 
 import (
-	"bufio"
 	"github.com/iancoleman/strcase"
 	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/openapi/v3/codegen"
+	svccodegen "github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc/codegen"
 	v3 "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
 var dtoTmpl = `/**
@@ -55,14 +54,10 @@ func GenSvcGo(dir string, docPath string) {
 		panic(err)
 	}
 	defer f.Close()
-	modfile := filepath.Join(dir, "go.mod")
-	var modf *os.File
-	if modf, err = os.Open(modfile); err != nil {
+	modName, err := svccodegen.ReadModulePath(dir)
+	if err != nil {
 		panic(err)
 	}
-	reader := bufio.NewReader(modf)
-	firstLine, _ := reader.ReadString('\n')
-	modName := strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
 	api := v3.LoadAPI(docPath)
 	generator := &codegen.OpenAPICodeGenerator{
 		Schemas:       api.Components.Schemas,
@@ -0,0 +1,156 @@
+package svc
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sirupsen/logrus"
+	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc/codegen"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrStale is returned by Check when committed generated code doesn't match svc.go
+var ErrStale = errors.New("generated code is stale, please run go-doudou svc http again")
+
+// docVersionRegex matches the timestamp based version in generated OpenAPI 3.0 json document,
+// which changes on every generation, so it should be ignored when comparing
+var docVersionRegex = regexp.MustCompile(`"version":"v\d+"`)
+
+// generatedArtifacts returns paths relative to project root of all files which will be
+// regenerated by Http command
+func generatedArtifacts(svcname string) []string {
+	return []string{
+		filepath.Join("client", "iclient.go"),
+		filepath.Join("client", "options.go"),
+		filepath.Join("client", "client.go"),
+		filepath.Join("client", "clientproxy.go"),
+		filepath.Join("client", "batch.go"),
+		filepath.Join("client", "mock", "mock_iclient.go"),
+		filepath.Join("transport", "httpsrv", "handler.go"),
+		filepath.Join("transport", "httpsrv", "handlerimpl.go"),
+		filepath.Join("transport", "httpsrv", "batch.go"),
+		"svcimpl.go",
+		svcname + "_openapi3.json",
+		svcname + "_openapi3.go",
+	}
+}
+
+// Check regenerates code into a temporary copy of the project, then compares generated artifacts
+// with the committed ones. It returns ErrStale and prints unified diff for each stale file if any differs.
+// It is designed for CI pipeline to catch changes to service interface without regenerating code.
+func (receiver *Svc) Check() error {
+	dir := receiver.dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	ic := astutils.BuildInterfaceCollector(filepath.Join(dir, "svc.go"), astutils.ExprString)
+	if len(ic.Interfaces) == 0 {
+		return errors.Errorf("no service interface found in %s", filepath.Join(dir, "svc.go"))
+	}
+	svcname := strings.ToLower(ic.Interfaces[0].Name)
+	tmpDir, err := ioutil.TempDir("", "go-doudou-check-")
+	if err != nil {
+		return errors.Wrap(err, "fail to create temp directory")
+	}
+	defer os.RemoveAll(tmpDir)
+	if err = copyProject(dir, tmpDir); err != nil {
+		return errors.Wrap(err, "fail to copy project to temp directory")
+	}
+	shadow := *receiver
+	shadow.dir = tmpDir
+	// keep iclient.go untouched in skip mode to catch mismatch between it and regenerated client.go,
+	// and don't write .new files in diff mode
+	if shadow.IClientMode == codegen.WriteNewAndDiff {
+		shadow.IClientMode = codegen.SkipIfExists
+	}
+	if err = generate(&shadow); err != nil {
+		return err
+	}
+
+	var stale []string
+	for _, rel := range generatedArtifacts(svcname) {
+		generated, err := ioutil.ReadFile(filepath.Join(tmpDir, rel))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.WithStack(err)
+		}
+		committed, err := ioutil.ReadFile(filepath.Join(dir, rel))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+		a := docVersionRegex.ReplaceAllString(string(committed), "")
+		b := docVersionRegex.ReplaceAllString(string(generated), "")
+		if a == b {
+			continue
+		}
+		stale = append(stale, rel)
+		diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(a),
+			B:        difflib.SplitLines(b),
+			FromFile: "committed/" + filepath.ToSlash(rel),
+			ToFile:   "generated/" + filepath.ToSlash(rel),
+			Context:  3,
+		})
+		fmt.Println(diff)
+	}
+	if len(stale) > 0 {
+		logrus.Errorf("stale generated files: %s", strings.Join(stale, ", "))
+		return ErrStale
+	}
+	logrus.Infoln("generated code is up to date")
+	return nil
+}
+
+// generate runs Http, returning panics like client generation errors as error
+func generate(receiver *Svc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("fail to generate code: %v", r)
+		}
+	}()
+	receiver.Http()
+	return nil
+}
+
+// copyProject copies all files in src to dst except hidden directories and vendor directory
+func copyProject(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			if rel != "." && (strings.HasPrefix(info.Name(), ".") || info.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		_, err = io.Copy(out, in)
+		return err
+	})
+}
@@ -0,0 +1,180 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"github.com/unionj-cloud/go-doudou/v2/version"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var aggregatorTmpl = `/**
+* Generated by go-doudou {{.Version}}.
+* Don't edit!
+*/
+package {{.PkgName}}
+
+import (
+	"context"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry"
+	"github.com/unionj-cloud/go-doudou/v2/framework/restclient"
+{{- range $b := .Backends }}
+	{{$b.Alias}} "{{$b.ClientPackage}}"
+{{- end }}
+)
+
+const (
+{{- range $b := .Backends }}
+	{{$b.Name}}ServiceName = "{{$b.ServiceName}}"
+{{- end }}
+)
+
+// ProviderFactory creates a service provider backed by service registry for serviceName,
+// e.g. wraps memberlist.NewRRServiceProvider or nacos.NewRRServiceProvider
+type ProviderFactory func(serviceName string) registry.IServiceProvider
+
+// Aggregator aggregates clients of backend services
+type Aggregator struct {
+{{- range $b := .Backends }}
+	{{$b.Name}} {{$b.Alias}}.I{{$b.Name}}Client
+{{- end }}
+}
+
+// NewAggregator creates Aggregator whose backends are resolved through service registry by providers created from factory
+func NewAggregator(factory ProviderFactory, opts ...restclient.RestClientOption) *Aggregator {
+	return &Aggregator{
+{{- range $b := .Backends }}
+		{{$b.Name}}: {{$b.Alias}}.New{{$b.Name}}ClientFromRegistry({{$b.Name}}ServiceName, factory({{$b.Name}}ServiceName), opts...),
+{{- end }}
+	}
+}
+
+// FanOut calls several backends concurrently. Assign results to variables captured by closures passed to
+// backend methods, they are safe to read after Wait returned.
+type FanOut struct {
+	*restclient.FanOut
+	aggregator *Aggregator
+}
+
+// FanOut creates a FanOut. If failFast is true, context passed to calls will be cancelled once any call failed,
+// otherwise all calls run to completion and errors are collected per backend in *restclient.FanOutError returned by Wait.
+func (receiver *Aggregator) FanOut(ctx context.Context, failFast bool) *FanOut {
+	return &FanOut{
+		FanOut:     restclient.NewFanOut(ctx, failFast),
+		aggregator: receiver,
+	}
+}
+{{- range $b := .Backends }}
+
+// {{$b.Name}} calls fn with {{$b.Name}} client in a new goroutine
+func (receiver *FanOut) {{$b.Name}}(fn func(ctx context.Context, client {{$b.Alias}}.I{{$b.Name}}Client) error) {
+	receiver.Go({{$b.Name}}ServiceName, func(ctx context.Context) error {
+		return fn(ctx, receiver.aggregator.{{$b.Name}})
+	})
+}
+{{- end }}
+`
+
+// AggregatorBackend describes a backend service of generated aggregator client
+type AggregatorBackend struct {
+	// ServiceName is the name of the service registered in service registry, defaults to lowercase interface name
+	ServiceName string
+	// Dir is project root path of the backend service
+	Dir string
+}
+
+type aggregatorBackendMeta struct {
+	Name          string
+	Alias         string
+	ServiceName   string
+	ClientPackage string
+}
+
+// ParseAggregatorBackend parses backend from string in the form of [serviceName=]dir
+func ParseAggregatorBackend(s string) AggregatorBackend {
+	if i := strings.Index(s, "="); i > 0 {
+		return AggregatorBackend{
+			ServiceName: strings.TrimSpace(s[:i]),
+			Dir:         strings.TrimSpace(s[i+1:]),
+		}
+	}
+	return AggregatorBackend{
+		Dir: strings.TrimSpace(s),
+	}
+}
+
+// GenAggregator generates aggregator client code into pkg directory under dir, which resolves each of backends
+// through service registry and calls them concurrently. Go http client code of each backend must be generated
+// by go-doudou svc http -c first.
+func GenAggregator(dir string, pkg string, backends []AggregatorBackend) {
+	var (
+		err     error
+		f       *os.File
+		tpl     *template.Template
+		buf     bytes.Buffer
+		metas   []aggregatorBackendMeta
+		aggfile string
+	)
+	if len(backends) == 0 {
+		panic("no backend service specified")
+	}
+	aliases := make(map[string]struct{})
+	for _, item := range backends {
+		ic := astutils.BuildInterfaceCollector(filepath.Join(item.Dir, "svc.go"), astutils.ExprString)
+		if len(ic.Interfaces) == 0 {
+			panic(fmt.Sprintf("no service interface found in %s", filepath.Join(item.Dir, "svc.go")))
+		}
+		name := ic.Interfaces[0].Name
+		if _, err = os.Stat(filepath.Join(item.Dir, "client", "client.go")); err != nil {
+			logrus.Warnf("client code of %s not found, please run go-doudou svc http -c in %s", name, item.Dir)
+		}
+		alias := strings.ToLower(name) + "client"
+		if _, exists := aliases[alias]; exists {
+			panic(fmt.Sprintf("duplicate backend service %s", name))
+		}
+		aliases[alias] = struct{}{}
+		serviceName := item.ServiceName
+		if serviceName == "" {
+			serviceName = strings.ToLower(name)
+		}
+		metas = append(metas, aggregatorBackendMeta{
+			Name:          name,
+			Alias:         alias,
+			ServiceName:   serviceName,
+			ClientPackage: readModName(item.Dir) + "/client",
+		})
+	}
+
+	aggDir := filepath.Join(dir, pkg)
+	if err = os.MkdirAll(aggDir, os.ModePerm); err != nil {
+		panic(err)
+	}
+	aggfile = filepath.Join(aggDir, "aggregator.go")
+	if _, err = os.Stat(aggfile); err == nil {
+		logrus.Warningln("file aggregator.go will be overwritten")
+	}
+	if f, err = os.Create(aggfile); err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	if tpl, err = template.New("aggregator.go.tmpl").Parse(aggregatorTmpl); err != nil {
+		panic(err)
+	}
+	if err = tpl.Execute(&buf, struct {
+		PkgName  string
+		Backends []aggregatorBackendMeta
+		Version  string
+	}{
+		PkgName:  filepath.Base(pkg),
+		Backends: metas,
+		Version:  version.Release,
+	}); err != nil {
+		panic(err)
+	}
+	astutils.FixImport([]byte(strings.TrimSpace(buf.String())), aggfile)
+}
@@ -0,0 +1,37 @@
+package codegen
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAggregatorBackend(t *testing.T) {
+	require.Equal(t, AggregatorBackend{Dir: "../usersvc"}, ParseAggregatorBackend("../usersvc"))
+	require.Equal(t, AggregatorBackend{ServiceName: "user-svc", Dir: "../usersvc"}, ParseAggregatorBackend("user-svc=../usersvc"))
+}
+
+func TestGenAggregator(t *testing.T) {
+	dir := t.TempDir()
+	GenAggregator(dir, "aggregator", []AggregatorBackend{
+		{
+			ServiceName: "user-svc",
+			Dir:         testDir,
+		},
+	})
+	source, err := ioutil.ReadFile(filepath.Join(dir, "aggregator", "aggregator.go"))
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `usersvcclient "testdata/client"`)
+	require.Contains(t, code, `UsersvcServiceName = "user-svc"`)
+	require.Contains(t, code, `Usersvc usersvcclient.IUsersvcClient`)
+	require.Contains(t, code, `usersvcclient.NewUsersvcClientFromRegistry(UsersvcServiceName, factory(UsersvcServiceName), opts...)`)
+	require.Contains(t, code, `func (receiver *FanOut) Usersvc(fn func(ctx context.Context, client usersvcclient.IUsersvcClient) error)`)
+}
+
+func TestGenAggregator_NoBackend(t *testing.T) {
+	require.Panics(t, func() {
+		GenAggregator(t.TempDir(), "aggregator", nil)
+	})
+}
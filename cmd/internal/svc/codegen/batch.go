@@ -0,0 +1,299 @@
+package codegen
+
+import (
+	"bytes"
+	"github.com/iancoleman/strcase"
+	"github.com/sirupsen/logrus"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	v3helper "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
+	"github.com/unionj-cloud/go-doudou/v2/version"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var httpBatchTmpl = `/**
+* Generated by go-doudou {{.Version}}.
+* Don't edit!
+*/
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	{{.ServiceAlias}} "{{.ServicePackage}}"
+	"{{.VoPackage}}"
+	"{{.DtoPackage}}"
+)
+
+// BatchRoutes returns route of batch endpoint, which executes calls to {{.Meta.Name}} bundled in one request.
+// Add it to server by srv.AddRoute(httpsrv.BatchRoutes(svc)...)
+func BatchRoutes(_svc {{.ServiceAlias}}.{{.Meta.Name}}) []rest.Route {
+	return []rest.Route{
+		{
+			Name:        "Batch",
+			Method:      "POST",
+			Pattern:     "{{.BatchPath}}",
+			HandlerFunc: rest.BatchHandler(BatchMethods(_svc)),
+		},
+	}
+}
+
+// BatchMethods returns methods of {{.Meta.Name}} callable from batch endpoint keyed by method name.
+// Methods uploading or downloading files are not included.
+func BatchMethods(_svc {{.ServiceAlias}}.{{.Meta.Name}}) map[string]rest.BatchMethod {
+	return map[string]rest.BatchMethod{
+		{{- range $m := .Methods }}
+		"{{$m.Name}}": func(_ctx context.Context, _params json.RawMessage) (interface{}, error) {
+			var _p struct {
+				{{- range $p := $m.Params }}
+				{{- if ne $p.Type "context.Context" }}
+				{{ $p.Name | toCamel }} {{ $p.Type | fieldType }} ` + "`" + `json:"{{ $p.Name }}"` + "`" + `
+				{{- end }}
+				{{- end }}
+			}
+			if _err := rest.DecodeBatchParams(_params, &_p); _err != nil {
+				return nil, _err
+			}
+			{{- range $p := $m.Params }}
+			{{- if ne $p.Type "context.Context" }}
+			{{- if and (isStruct $p) (not (isOptional $p.Type)) }}
+			if _err := rest.ValidateStruct(_p.{{ $p.Name | toCamel }}); _err != nil {
+				return nil, rest.NewBizError(_err, rest.WithStatusCode(http.StatusBadRequest))
+			}
+			{{- else if $p.ValidateTag }}
+			if _err := rest.ValidateVar(_p.{{ $p.Name | toCamel }}, "{{$p.ValidateTag}}", "{{$p.Name}}"); _err != nil {
+				return nil, rest.NewBizError(_err, rest.WithStatusCode(http.StatusBadRequest))
+			}
+			{{- end }}
+			{{- end }}
+			{{- end }}
+			{{ if $m.Results }}{{ range $i, $r := $m.Results }}{{- if $i}}, {{ end }}{{- $r.Name }}{{- end }} := {{ end }}_svc.{{$m.Name}}(
+				{{- range $p := $m.Params }}
+				{{- if eq $p.Type "context.Context" }}
+				_ctx,
+				{{- else if isVarargs $p.Type }}
+				_p.{{ $p.Name | toCamel }}...,
+				{{- else }}
+				_p.{{ $p.Name | toCamel }},
+				{{- end }}
+				{{- end }}
+			)
+			{{- range $r := $m.Results }}
+			{{- if eq $r.Type "error" }}
+			if {{ $r.Name }} != nil {
+				return nil, {{ $r.Name }}
+			}
+			{{- end }}
+			{{- end }}
+			return struct {
+				{{- range $r := $m.Results }}
+				{{- if ne $r.Type "error" }}
+				{{ $r.Name | toCamel }} {{ $r.Type }} ` + "`" + `json:"{{ $r.Name | convertCase }}{{if $.Omitempty}},omitempty{{end}}"` + "`" + `
+				{{- end }}
+				{{- end }}
+			}{
+				{{- range $r := $m.Results }}
+				{{- if ne $r.Type "error" }}
+				{{ $r.Name | toCamel }}: {{ $r.Name }},
+				{{- end }}
+				{{- end }}
+			}, nil
+		},
+		{{- end }}
+	}
+}
+`
+
+var clientBatchTmpl = `/**
+* Generated by go-doudou {{.Version}}.
+* Don't edit!
+*/
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/unionj-cloud/go-doudou/v2/framework/restclient"
+	"{{.VoPackage}}"
+	"{{.DtoPackage}}"
+)
+
+// {{.Meta.Name}}Batch bundles calls to {{.Meta.Name}} into one request to its batch endpoint
+type {{.Meta.Name}}Batch struct {
+	batch  restclient.Batch
+	client *{{.Meta.Name}}Client
+}
+
+// NewBatch creates an empty {{.Meta.Name}}Batch
+func (receiver *{{.Meta.Name}}Client) NewBatch() *{{.Meta.Name}}Batch {
+	return &{{.Meta.Name}}Batch{
+		client: receiver,
+	}
+}
+
+// Do sends all calls in the batch by one request. It returns error only if the batch request failed as a whole,
+// errors of calls are returned by Err of each restclient.BatchCall.
+func (receiver *{{.Meta.Name}}Batch) Do(ctx context.Context, _headers map[string]string) error {
+	_req := receiver.client.client.R()
+	if len(_headers) > 0 {
+		_req.SetHeaders(_headers)
+	}
+	_req.SetContext(ctx)
+	return receiver.batch.Do(_req, "{{.BatchPath}}")
+}
+{{- range $m := .Methods }}
+
+// {{$m.Name}} adds a call of {{$m.Name}} to the batch. Results are assigned to non-nil pointers after Do returned
+// if the call succeeded.
+func (receiver *{{$.Meta.Name}}Batch) {{$m.Name}}(
+	{{- range $p := $m.Params }}
+	{{- if ne $p.Type "context.Context" }}
+	{{ $p.Name }} {{ $p.Type | fieldType }},
+	{{- end }}
+	{{- end }}
+	{{- range $r := $m.Results }}
+	{{- if ne $r.Type "error" }}
+	{{ $r.Name }} *{{ $r.Type }},
+	{{- end }}
+	{{- end }}
+) *restclient.BatchCall {
+	return receiver.batch.Add("{{$m.Name}}", struct {
+		{{- range $p := $m.Params }}
+		{{- if ne $p.Type "context.Context" }}
+		{{ $p.Name | toCamel }} {{ $p.Type | fieldType }} ` + "`" + `json:"{{ $p.Name }}"` + "`" + `
+		{{- end }}
+		{{- end }}
+	}{
+		{{- range $p := $m.Params }}
+		{{- if ne $p.Type "context.Context" }}
+		{{ $p.Name | toCamel }}: {{ $p.Name }},
+		{{- end }}
+		{{- end }}
+	}, func(_result json.RawMessage) error {
+		_r := struct {
+			{{- range $r := $m.Results }}
+			{{- if ne $r.Type "error" }}
+			{{ $r.Name | toCamel }} *{{ $r.Type }} ` + "`" + `json:"{{ $r.Name | convertCase }}"` + "`" + `
+			{{- end }}
+			{{- end }}
+		}{
+			{{- range $r := $m.Results }}
+			{{- if ne $r.Type "error" }}
+			{{ $r.Name | toCamel }}: {{ $r.Name }},
+			{{- end }}
+			{{- end }}
+		}
+		return json.Unmarshal(_result, &_r)
+	})
+}
+{{- end }}
+`
+
+// GenHttpBatchConfig configures batch endpoint and batch client code generation
+type GenHttpBatchConfig struct {
+	RoutePatternStrategy int
+	Omitempty            bool
+	CaseConvertor        func(string) string
+}
+
+// batchable reports whether method can be called from batch endpoint. Methods uploading or downloading files
+// are excluded because they can't be bundled into a json request.
+func batchable(method astutils.MethodMeta) bool {
+	for _, p := range method.Params {
+		if strings.Contains(p.Type, "FileModel") || strings.Contains(p.Type, "multipart.FileHeader") {
+			return false
+		}
+	}
+	for _, r := range method.Results {
+		if r.Type == "*os.File" {
+			return false
+		}
+	}
+	return true
+}
+
+func batchPath(meta astutils.InterfaceMeta, routePatternStrategy int) string {
+	if routePatternStrategy == 1 {
+		return "/" + strings.ToLower(meta.Name) + "/batch"
+	}
+	return "/batch"
+}
+
+func genBatch(file, name, tmpl string, dir string, ic astutils.InterfaceCollector, config GenHttpBatchConfig) {
+	var (
+		err     error
+		tpl     *template.Template
+		buf     bytes.Buffer
+		methods []astutils.MethodMeta
+	)
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		panic(err)
+	}
+	if _, err = os.Stat(file); err == nil {
+		logrus.Warningf("file %s will be overwritten", filepath.Base(file))
+	}
+	meta := ic.Interfaces[0]
+	for _, item := range meta.Methods {
+		if batchable(item) {
+			methods = append(methods, item)
+		} else {
+			logrus.Warningf("method %s is not callable from batch endpoint as it uploads or downloads files", item.Name)
+		}
+	}
+	modName := readModName(dir)
+	funcMap := make(map[string]interface{})
+	funcMap["toCamel"] = strcase.ToCamel
+	funcMap["isStruct"] = v3helper.IsStruct
+	funcMap["isOptional"] = v3helper.IsOptional
+	funcMap["isVarargs"] = v3helper.IsVarargs
+	funcMap["convertCase"] = config.CaseConvertor
+	funcMap["fieldType"] = func(t string) string {
+		if v3helper.IsVarargs(t) {
+			return v3helper.ToSlice(t)
+		}
+		return t
+	}
+	if tpl, err = template.New(name).Funcs(funcMap).Parse(tmpl); err != nil {
+		panic(err)
+	}
+	if err = tpl.Execute(&buf, struct {
+		ServicePackage string
+		ServiceAlias   string
+		VoPackage      string
+		DtoPackage     string
+		Meta           astutils.InterfaceMeta
+		Methods        []astutils.MethodMeta
+		BatchPath      string
+		Omitempty      bool
+		Version        string
+	}{
+		ServicePackage: modName,
+		ServiceAlias:   ic.Package.Name,
+		VoPackage:      modName + "/vo",
+		DtoPackage:     modName + "/dto",
+		Meta:           meta,
+		Methods:        methods,
+		BatchPath:      batchPath(meta, config.RoutePatternStrategy),
+		Omitempty:      config.Omitempty,
+		Version:        version.Release,
+	}); err != nil {
+		panic(err)
+	}
+	astutils.FixImport([]byte(strings.TrimSpace(buf.String())), file)
+}
+
+// GenHttpBatch generates batch endpoint into transport/httpsrv/batch.go, which executes calls to service methods
+// bundled in one request and returns an array of results with per call errors
+func GenHttpBatch(dir string, ic astutils.InterfaceCollector, config GenHttpBatchConfig) {
+	genBatch(filepath.Join(dir, "transport", "httpsrv", "batch.go"), "httpbatch.go.tmpl", httpBatchTmpl, dir, ic, config)
+}
+
+// GenGoClientBatch generates batch client into client/batch.go, which bundles calls to service methods into one request
+// to batch endpoint generated by GenHttpBatch
+func GenGoClientBatch(dir string, ic astutils.InterfaceCollector, config GenHttpBatchConfig) {
+	genBatch(filepath.Join(dir, "client", "batch.go"), "clientbatch.go.tmpl", clientBatchTmpl, dir, ic, config)
+}
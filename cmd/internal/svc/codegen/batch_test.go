@@ -0,0 +1,48 @@
+package codegen
+
+import (
+	"github.com/iancoleman/strcase"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenBatch(t *testing.T) {
+	dir := t.TempDir()
+	mod, err := ioutil.ReadFile(filepath.Join(testDir, "go.mod"))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), mod, 0644))
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), astutils.ExprString)
+	config := GenHttpBatchConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	}
+	GenHttpBatch(dir, ic, config)
+	GenGoClientBatch(dir, ic, config)
+
+	serverFile := filepath.Join(dir, "transport", "httpsrv", "batch.go")
+	source, err := ioutil.ReadFile(serverFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), serverFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `Pattern:     "/usersvc/batch"`)
+	require.Contains(t, code, `"SignUp": func(_ctx context.Context, _params json.RawMessage) (interface{}, error) {`)
+	require.Contains(t, code, `code, data, msg := _svc.SignUp(`)
+	require.NotContains(t, code, `"UploadAvatar"`)
+	require.NotContains(t, code, `"DownloadAvatar"`)
+
+	clientFile := filepath.Join(dir, "client", "batch.go")
+	source, err = ioutil.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code = string(source)
+	require.Contains(t, code, `func (receiver *UsersvcClient) NewBatch() *UsersvcBatch`)
+	require.Contains(t, code, `return receiver.batch.Do(_req, "/usersvc/batch")`)
+	require.Contains(t, code, `func (receiver *UsersvcBatch) SignUp(`)
+}
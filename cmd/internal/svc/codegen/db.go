@@ -1,12 +1,10 @@
 package codegen
 
 import (
-	"bufio"
 	"github.com/sirupsen/logrus"
 	"github.com/unionj-cloud/go-doudou/v2/version"
 	"os"
 	"path/filepath"
-	"strings"
 	"text/template"
 )
 
@@ -52,14 +50,12 @@ var Stat = os.Stat
 // GenDb generates db connection code
 func GenDb(dir string) {
 	var (
-		err       error
-		dbfile    string
-		f         *os.File
-		tpl       *template.Template
-		dbDir     string
-		modfile   string
-		modName   string
-		firstLine string
+		err     error
+		dbfile  string
+		f       *os.File
+		tpl     *template.Template
+		dbDir   string
+		modName string
 	)
 	dbDir = filepath.Join(dir, "db")
 	if err = MkdirAll(dbDir, os.ModePerm); err != nil {
@@ -68,13 +64,7 @@ func GenDb(dir string) {
 
 	dbfile = filepath.Join(dbDir, "db.go")
 	if _, err = Stat(dbfile); os.IsNotExist(err) {
-		modfile = filepath.Join(dir, "go.mod")
-		if f, err = Open(modfile); err != nil {
-			panic(err)
-		}
-		reader := bufio.NewReader(f)
-		firstLine, _ = reader.ReadString('\n')
-		modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+		modName = readModName(dir)
 
 		if f, err = Create(dbfile); err != nil {
 			panic(err)
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/iancoleman/strcase"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/constants"
 	v3 "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/sliceutils"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"go/ast"
 	"go/parser"
@@ -133,6 +135,16 @@ func operationOf(method astutils.MethodMeta, httpMethod string, config GenDocCon
 						Required:    required,
 					}
 					params = append(params, param)
+				} else if v3.IsBuiltin(item) && sliceutils.StringContains(queryParams(method), item.Name) {
+					pschema := v3.CopySchema(item)
+					v3.RefAddDoc(&pschema, strings.Join(item.Comments, "\n"))
+					params = append(params, v3.Parameter{
+						Name:        strcase.ToLowerCamel(item.Name),
+						In:          v3.InQuery,
+						Schema:      &pschema,
+						Description: pschema.Description,
+						Required:    !v3.IsOptional(item.Type),
+					})
 				}
 			}
 		} else {
@@ -239,11 +251,12 @@ func uploadFile(method astutils.MethodMeta) *v3.RequestBody {
 		Title:      title,
 		Properties: make(map[string]*v3.Schema),
 	}
+	queries := queryParams(method)
 	for _, item := range method.Params {
 		if item.Type == "context.Context" {
 			continue
 		}
-		if item.IsPathVariable {
+		if item.IsPathVariable || sliceutils.StringContains(queries, item.Name) {
 			continue
 		}
 		pschemaType := v3.SchemaOf(item)
@@ -284,6 +297,7 @@ func postFormUrl(reqBody *v3.RequestBody, params *[]v3.Parameter, method astutil
 		Title:      title,
 		Properties: make(map[string]*v3.Schema),
 	}
+	queries := queryParams(method)
 	for _, item := range method.Params {
 		if item.Type == "context.Context" {
 			continue
@@ -302,6 +316,16 @@ func postFormUrl(reqBody *v3.RequestBody, params *[]v3.Parameter, method astutil
 			*params = append(*params, param)
 			continue
 		}
+		if sliceutils.StringContains(queries, item.Name) {
+			*params = append(*params, v3.Parameter{
+				Name:        strcase.ToLowerCamel(item.Name),
+				In:          v3.InQuery,
+				Schema:      &pschema,
+				Description: pschema.Description,
+				Required:    required,
+			})
+			continue
+		}
 		prop := strcase.ToLowerCamel(item.Name)
 		reqSchema.Properties[prop] = &pschema
 		if required {
@@ -370,6 +394,41 @@ func init() {
 }
 `
 
+// apiOf builds OpenAPI 3.0 description of the first interface in ic
+func apiOf(ic astutils.InterfaceCollector, config GenDocConfig) v3.API {
+	return v3.API{
+		Openapi: "3.0.2",
+		Info: &v3.Info{
+			Title:       ic.Interfaces[0].Name,
+			Description: strings.Join(ic.Interfaces[0].Comments, "\n"),
+			Version:     fmt.Sprintf("v%s", time.Now().Local().Format(constants.FORMAT10)),
+		},
+		Servers: []v3.Server{
+			{
+				URL: fmt.Sprintf("http://localhost:%d", 6060),
+			},
+		},
+		Paths: pathsOf(ic, config),
+		Components: &v3.Components{
+			Schemas: v3.Schemas,
+		},
+	}
+}
+
+// GenOpenAPISpec generates openapi.json file into dir from the service interface in ic, which is the same
+// description as GenDoc generates with default GenDocConfig. Http method and route of each operation are derived
+// from method name the same way as generated routes. Different from GenDoc, it returns error rather than panicking.
+func GenOpenAPISpec(dir string, ic astutils.InterfaceCollector) error {
+	if len(ic.Interfaces) == 0 {
+		return errors.New("no service interface found")
+	}
+	data, err := json.Marshal(apiOf(ic, GenDocConfig{}))
+	if err != nil {
+		return errors.Wrap(err, "marshal openapi spec")
+	}
+	return errors.Wrap(ioutil.WriteFile(filepath.Join(dir, "openapi.json"), data, 0644), "write openapi.json")
+}
+
 type GenDocConfig struct {
 	RoutePatternStrategy int
 	AllowGetWithReqBody  bool
@@ -386,7 +445,6 @@ func GenDoc(dir string, ic astutils.InterfaceCollector, config GenDocConfig) {
 		fi      os.FileInfo
 		api     v3.API
 		data    []byte
-		paths   map[string]v3.Path
 		tpl     *template.Template
 		sqlBuf  bytes.Buffer
 		source  string
@@ -408,26 +466,11 @@ func GenDoc(dir string, ic astutils.InterfaceCollector, config GenDocConfig) {
 	if fi != nil {
 		logrus.Warningln("file " + gofile + " will be overwritten")
 	}
-	paths = pathsOf(ic, config)
-	api = v3.API{
-		Openapi: "3.0.2",
-		Info: &v3.Info{
-			Title:       svcname,
-			Description: strings.Join(ic.Interfaces[0].Comments, "\n"),
-			Version:     fmt.Sprintf("v%s", time.Now().Local().Format(constants.FORMAT10)),
-		},
-		Servers: []v3.Server{
-			{
-				URL: fmt.Sprintf("http://localhost:%d", 6060),
-			},
-		},
-		Paths: paths,
-		Components: &v3.Components{
-			Schemas: v3.Schemas,
-		},
+	api = apiOf(ic, config)
+	if data, err = json.Marshal(api); err != nil {
+		panic(err)
 	}
-	data, err = json.Marshal(api)
-	err = ioutil.WriteFile(docfile, data, os.ModePerm)
+	err = ioutil.WriteFile(docfile, data, 0644)
 	if err != nil {
 		panic(err)
 	}
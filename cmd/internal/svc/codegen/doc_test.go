@@ -1,6 +1,7 @@
 package codegen
 
 import (
+	"encoding/json"
 	"fmt"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
@@ -8,6 +9,7 @@ import (
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/pathutils"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -163,3 +165,131 @@ func Test_pathsOf(t *testing.T) {
 		})
 	}
 }
+
+func Test_operationOf_query(t *testing.T) {
+	Convey("Parameters listed in @query annotation should be query parameters", t, func() {
+		method := astutils.MethodMeta{
+			Name: "PostUsers",
+			Params: []astutils.FieldMeta{
+				{Name: "ctx", Type: "context.Context"},
+				{Name: "ids", Type: "[]int"},
+				{Name: "name", Type: "string"},
+			},
+			Annotations: []astutils.Annotation{
+				{Name: "@query", Params: []string{"ids"}},
+			},
+		}
+		op := operationOf(method, "POST", GenDocConfig{})
+		So(op.Parameters, ShouldHaveLength, 1)
+		So(op.Parameters[0].Name, ShouldEqual, "ids")
+		So(op.Parameters[0].In, ShouldEqual, v3helper.InQuery)
+		So(op.Parameters[0].Required, ShouldBeTrue)
+		So(v3helper.Schemas["PostUsersReq"].Properties, ShouldContainKey, "name")
+		So(v3helper.Schemas["PostUsersReq"].Properties, ShouldNotContainKey, "ids")
+	})
+}
+
+func TestGenDoc_Paths(t *testing.T) {
+	dir := testDir + "openapispec"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	ic := astutils.BuildInterfaceCollector(filepath.Join(dir, "svc.go"), ExprStringP)
+	ic.Interfaces[0].Methods = append(ic.Interfaces[0].Methods, astutils.MethodMeta{
+		Name: "GetUser",
+		Params: []astutils.FieldMeta{
+			{Name: "ctx", Type: "context.Context"},
+			{Name: "id", Type: "int"},
+		},
+		Results: []astutils.FieldMeta{
+			{Name: "name", Type: "string"},
+			{Name: "err", Type: "error"},
+		},
+	})
+	GenDoc(dir, ic, GenDocConfig{})
+	docfile := filepath.Join(dir, strings.ToLower(ic.Interfaces[0].Name)+"_openapi3.json")
+	fi, err := os.Stat(docfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm()&0133 != 0 {
+		t.Fatalf("unexpected file mode %s", fi.Mode())
+	}
+	data, err := os.ReadFile(docfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api v3helper.API
+	if err = json.Unmarshal(data, &api); err != nil {
+		t.Fatal(err)
+	}
+	path, ok := api.Paths["/user"]
+	if !ok {
+		t.Fatalf("path /user not found in %v", api.Paths)
+	}
+	if path.Get == nil {
+		t.Fatal("expected get operation for GetUser")
+	}
+	if len(path.Get.Parameters) != 1 || path.Get.Parameters[0].Name != "id" {
+		t.Fatalf("unexpected parameters %+v", path.Get.Parameters)
+	}
+}
+
+func TestGenOpenAPISpec(t *testing.T) {
+	dir := testDir + "openapispec2"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	ic := astutils.BuildInterfaceCollector(filepath.Join(dir, "svc.go"), ExprStringP)
+	ic.Interfaces[0].Methods = append(ic.Interfaces[0].Methods, astutils.MethodMeta{
+		Name: "PostUser",
+		Params: []astutils.FieldMeta{
+			{Name: "ctx", Type: "context.Context"},
+			{Name: "name", Type: "string"},
+		},
+		Results: []astutils.FieldMeta{
+			{Name: "id", Type: "int"},
+			{Name: "err", Type: "error"},
+		},
+	})
+	if err := GenOpenAPISpec(dir, ic); err != nil {
+		t.Fatal(err)
+	}
+	specfile := filepath.Join(dir, "openapi.json")
+	fi, err := os.Stat(specfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm()&0133 != 0 {
+		t.Fatalf("unexpected file mode %s", fi.Mode())
+	}
+	data, err := os.ReadFile(specfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var api v3helper.API
+	if err = json.Unmarshal(data, &api); err != nil {
+		t.Fatal(err)
+	}
+	if api.Info == nil || api.Info.Title != ic.Interfaces[0].Name {
+		t.Fatalf("unexpected info %+v", api.Info)
+	}
+	path, ok := api.Paths["/user"]
+	if !ok {
+		t.Fatalf("path /user not found in %v", api.Paths)
+	}
+	if path.Post == nil || path.Post.RequestBody == nil {
+		t.Fatal("expected post operation with request body for PostUser")
+	}
+	if path.Post.Responses == nil {
+		t.Fatal("expected responses for PostUser")
+	}
+}
+
+func TestGenOpenAPISpec_Error(t *testing.T) {
+	if err := GenOpenAPISpec(t.TempDir(), astutils.InterfaceCollector{}); err == nil {
+		t.Fatal("expected error")
+	}
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), ExprStringP)
+	if err := GenOpenAPISpec(filepath.Join(t.TempDir(), "notexist"), ic); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -1,11 +1,10 @@
 package codegen
 
 import (
-	"bufio"
 	"bytes"
 	"github.com/sirupsen/logrus"
-	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	v3 "github.com/unionj-cloud/go-doudou/v2/cmd/internal/protobuf/v3"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	"github.com/unionj-cloud/go-doudou/v2/version"
 	"os"
 	"path/filepath"
@@ -65,18 +64,16 @@ func main() {
 // GenMainGrpc generates main function for grpc service
 func GenMainGrpc(dir string, ic astutils.InterfaceCollector, grpcSvc v3.Service) {
 	var (
-		err       error
-		modfile   string
-		modName   string
-		mainfile  string
-		firstLine string
-		f         *os.File
-		tpl       *template.Template
-		cmdDir    string
-		svcName   string
-		alias     string
-		sqlBuf    bytes.Buffer
-		source    string
+		err      error
+		modName  string
+		mainfile string
+		f        *os.File
+		tpl      *template.Template
+		cmdDir   string
+		svcName  string
+		alias    string
+		sqlBuf   bytes.Buffer
+		source   string
 	)
 	cmdDir = filepath.Join(dir, "cmd")
 	if err = MkdirAll(cmdDir, os.ModePerm); err != nil {
@@ -87,13 +84,7 @@ func GenMainGrpc(dir string, ic astutils.InterfaceCollector, grpcSvc v3.Service)
 	alias = ic.Package.Name
 	mainfile = filepath.Join(cmdDir, "main.go")
 	if _, err = Stat(mainfile); os.IsNotExist(err) {
-		modfile = filepath.Join(dir, "go.mod")
-		if f, err = Open(modfile); err != nil {
-			panic(err)
-		}
-		reader := bufio.NewReader(f)
-		firstLine, _ = reader.ReadString('\n')
-		modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+		modName = readModName(dir)
 
 		if f, err = Create(mainfile); err != nil {
 			panic(err)
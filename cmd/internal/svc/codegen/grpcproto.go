@@ -1,7 +1,6 @@
 package codegen
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	v3 "github.com/unionj-cloud/go-doudou/v2/cmd/internal/protobuf/v3"
@@ -83,16 +82,13 @@ func toComment(comments []string) string {
 
 func GenGrpcProto(dir string, ic astutils.InterfaceCollector, p v3.ProtoGenerator) (service v3.Service, protoFile string) {
 	var (
-		err       error
-		svcname   string
-		fi        os.FileInfo
-		tpl       *template.Template
-		f         *os.File
-		modfile   string
-		modf      *os.File
-		modName   string
-		firstLine string
-		grpcDir   string
+		err     error
+		svcname string
+		fi      os.FileInfo
+		tpl     *template.Template
+		f       *os.File
+		modName string
+		grpcDir string
 	)
 	grpcDir = filepath.Join(dir, "transport/grpc")
 	if err = os.MkdirAll(grpcDir, os.ModePerm); err != nil {
@@ -110,13 +106,7 @@ func GenGrpcProto(dir string, ic astutils.InterfaceCollector, p v3.ProtoGenerato
 		panic(err)
 	}
 	defer f.Close()
-	modfile = filepath.Join(dir, "go.mod")
-	if modf, err = Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(modf)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+	modName = readModName(dir)
 
 	service = p.NewService(svcname, modName+"/transport/grpc")
 	service.Comments = ic.Interfaces[0].Comments
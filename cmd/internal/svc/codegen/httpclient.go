@@ -1,13 +1,13 @@
 package codegen
 
 import (
-	"bufio"
 	"bytes"
 	"github.com/iancoleman/strcase"
 	"github.com/sirupsen/logrus"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/copier"
 	v3helper "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"github.com/unionj-cloud/go-doudou/v2/version"
 	"os"
 	"path/filepath"
@@ -22,6 +22,7 @@ var clientTmpl = `/**
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"github.com/go-resty/resty/v2"
@@ -46,9 +47,13 @@ import (
 )
 
 type {{.Meta.Name}}Client struct {
-	provider registry.IServiceProvider
-	client   *resty.Client
-	rootPath string
+	provider     registry.IServiceProvider
+	client       *resty.Client
+	rootPath     string
+	retry        *restclient.RetryConfig
+	breaker      *restclient.CircuitBreaker
+	transport    restclient.TransportConfig
+	errorDecoder restclient.ErrorDecoder
 }
 
 func (receiver *{{.Meta.Name}}Client) SetRootPath(rootPath string) {
@@ -63,6 +68,22 @@ func (receiver *{{.Meta.Name}}Client) SetClient(client *resty.Client) {
 	receiver.client = client
 }
 
+func (receiver *{{.Meta.Name}}Client) SetRetry(retry restclient.RetryConfig) {
+	receiver.retry = &retry
+}
+
+func (receiver *{{.Meta.Name}}Client) SetBreaker(breaker *restclient.CircuitBreaker) {
+	receiver.breaker = breaker
+}
+
+func (receiver *{{.Meta.Name}}Client) SetErrorDecoder(errorDecoder restclient.ErrorDecoder) {
+	receiver.errorDecoder = errorDecoder
+}
+
+func (receiver *{{.Meta.Name}}Client) TransportConfig() *restclient.TransportConfig {
+	return &receiver.transport
+}
+
 {{- range $m := .Meta.Methods }}
 	func (receiver *{{$.Meta.Name}}Client) {{$m.Name}}(ctx context.Context, _headers map[string]string, {{- range $i, $p := $m.Params}}
 	{{- if ne $p.Type "context.Context" }}
@@ -73,11 +94,22 @@ func (receiver *{{.Meta.Name}}Client) SetClient(client *resty.Client) {
                      {{- $r.Name}} {{$r.Type}}
                      {{- end }}) {
 		var _err error
+		if _err = ctx.Err(); _err != nil {
+			{{- range $r := $m.Results }}
+				{{- if eq $r.Type "error" }}
+			{{ $r.Name }} = errors.Wrap(_err, "error")
+				{{- end }}
+			{{- end }}
+			return
+		}
 		_urlValues := url.Values{}
 		_req := receiver.client.R()
 		if len(_headers) > 0 {
 			_req.SetHeaders(_headers)
 		}
+		if options.RetryNonIdempotent {
+			ctx = restclient.WithRetryNonIdempotent(ctx)
+		}
 		_req.SetContext(ctx)
 		{{- range $p := $m.Params }}
 		{{- if $p.IsPathVariable }}
@@ -98,17 +130,21 @@ func (receiver *{{.Meta.Name}}Client) SetClient(client *resty.Client) {
 				{{- end }}
 				return
 			}
+			defer _f.Close()
 			_req.SetFileReader("{{$p.Name}}", _fh.Filename, _f)
 		}
 		{{- else}}
-		if _f, _err := {{$p.Name}}.Open(); _err != nil {
-			{{- range $r := $m.Results }}
-				{{- if eq $r.Type "error" }}
-			{{ $r.Name }} = errors.Wrap(_err, "error")
+		if {{$p.Name}} != nil {
+			_f, _err := {{$p.Name}}.Open()
+			if _err != nil {
+				{{- range $r := $m.Results }}
+					{{- if eq $r.Type "error" }}
+				{{ $r.Name }} = errors.Wrap(_err, "error")
+					{{- end }}
 				{{- end }}
-			{{- end }}
-			return
-		} else {
+				return
+			}
+			defer _f.Close()
 			_req.SetFileReader("{{$p.Name}}", {{$p.Name}}.Filename, _f)
 		}
 		{{- end}}
@@ -148,7 +184,24 @@ func (receiver *{{.Meta.Name}}Client) SetClient(client *resty.Client) {
 		{{- end }}
 		{{- else if eq $p.Type "context.Context" }}
 		{{- else if not (isBuiltin $p)}}
-		{{- if and (eq $m.HttpMethod "GET") (not $.Config.AllowGetWithReqBody) }}
+		{{- if hasFile $m }}
+		{{- if isOptional $p.Type }}
+		if {{$p.Name}} != nil {
+		{{- end }}
+		if _data, _err := json.Marshal({{$p.Name}}); _err != nil {
+			{{- range $r := $m.Results }}
+				{{- if eq $r.Type "error" }}
+			{{ $r.Name }} = errors.Wrap(_err, "error")
+				{{- end }}
+			{{- end }}
+			return
+		} else {
+			_req.SetMultipartField("{{$p.Name}}", "", "application/json", bytes.NewReader(_data))
+		}
+		{{- if isOptional $p.Type }}
+		}
+		{{- end }}
+		{{- else if and (eq $m.HttpMethod "GET") (not $.Config.AllowGetWithReqBody) }}
 		{{$p.Name}}UrlValues, _err := rest.EncodeForm(&{{$p.Name}})
 		if _err != nil {
 			{{- range $r := $m.Results }}
@@ -258,16 +311,34 @@ func (receiver *{{.Meta.Name}}Client) SetClient(client *resty.Client) {
 		_path := "/{{$m.Name | pattern}}"
 		{{- end }}
 
-		{{- if eq $m.HttpMethod "GET" }}
+		{{- if or (eq $m.HttpMethod "GET") (eq $m.HttpMethod "DELETE") }}
 		_req.SetQueryParamsFromValues(_urlValues)
 		{{- else }}
+		{{- with queryParams $m }}
+		for _, _k := range []string{ {{- range $i, $q := . }}{{ if $i }}, {{ end }}"{{ $q }}"{{ end -}} } {
+			if _vs, _ok := _urlValues[_k]; _ok {
+				_req.SetQueryParamsFromValues(url.Values{_k: _vs})
+				delete(_urlValues, _k)
+			}
+		}
+		{{- end }}
+		{{- if hasFile $m }}
+		for _k, _vs := range _urlValues {
+			for _, _v := range _vs {
+				_req.SetMultipartField(_k, "", "", strings.NewReader(_v))
+			}
+		}
+		{{- else }}
 		if _req.Body != nil {
 			_req.SetQueryParamsFromValues(_urlValues)
 		} else {
 			_req.SetFormDataFromValues(_urlValues)
 		}
 		{{- end }}
-		_resp, _err = _req.{{$m.Name | restyMethod}}(_path)
+		{{- end }}
+		_resp, _err = receiver.breaker.Execute(func() (*resty.Response, error) {
+			return _req.{{$m.Name | restyMethod}}(_path)
+		})
 		if _err != nil {
 			{{- range $r := $m.Results }}
 				{{- if eq $r.Type "error" }}
@@ -277,12 +348,14 @@ func (receiver *{{.Meta.Name}}Client) SetClient(client *resty.Client) {
 			return
 		}
 		if _resp.IsError() {
-			{{- range $r := $m.Results }}
-				{{- if eq $r.Type "error" }}
-			{{ $r.Name }} = errors.New(_resp.String())
+			if _err = receiver.errorDecoder(_resp); _err != nil {
+				{{- range $r := $m.Results }}
+					{{- if eq $r.Type "error" }}
+				{{ $r.Name }} = _err
+					{{- end }}
 				{{- end }}
-			{{- end }}
-			return
+				return
+			}
 		}
 		{{- $done := false }}
 		{{- range $r := $m.Results }}
@@ -357,16 +430,30 @@ func New{{.Meta.Name}}Client(opts ...restclient.RestClientOption) *{{.Meta.Name}
 	defaultClient := restclient.NewClient()
 
 	svcClient := &{{.Meta.Name}}Client{
-		provider: defaultProvider,
-		client:   defaultClient,
+		provider:     defaultProvider,
+		client:       defaultClient,
+		errorDecoder: restclient.DecodeError,
 	}
 
 	for _, opt := range opts {
 		opt(svcClient)
 	}
 
+	if svcClient.retry != nil {
+		restclient.ApplyRetry(svcClient.client, *svcClient.retry)
+	}
+
+	restclient.ApplyTransport(svcClient.client, svcClient.transport)
+
 	svcClient.client.OnBeforeRequest(func(_ *resty.Client, request *resty.Request) error {
-		request.URL = svcClient.provider.SelectServer() + svcClient.rootPath + request.URL
+		_server, _err := restclient.SelectServer(svcClient.provider)
+		if _err != nil {
+			return _err
+		}
+		request.URL = _server + svcClient.rootPath + request.URL
+		restclient.PropagateTenant(request)
+		restclient.PropagateCorrelation(request)
+		restclient.PropagateTraceContext(request)
 		return nil
 	})
 
@@ -384,6 +471,30 @@ func New{{.Meta.Name}}Client(opts ...restclient.RestClientOption) *{{.Meta.Name}
 
 	return svcClient
 }
+
+// New{{.Meta.Name}}ClientFromRegistry is like New{{.Meta.Name}}ClientProvider, and requests fail with
+// restclient.ErrNoAvailableServer telling serviceName if there is no available node of the service.
+func New{{.Meta.Name}}ClientFromRegistry(serviceName string, provider registry.IServiceProvider, opts ...restclient.RestClientOption) *{{.Meta.Name}}Client {
+	if restclient.IsNilProvider(provider) {
+		return New{{.Meta.Name}}ClientProvider(nil, opts...)
+	}
+	return New{{.Meta.Name}}ClientProvider(restclient.NewRegistryProvider(serviceName, provider), opts...)
+}
+
+// New{{.Meta.Name}}ClientProvider creates {{.Meta.Name}}Client which selects a node from provider on every request attempt,
+// and builds request url from base url of the node, so failed requests are retried on other nodes if GDD_RETRY_COUNT
+// is greater than 0. It falls back to base url from environment variable
+{{- if .Config.Env }} {{.Config.Env}}
+{{- else }} {{.Meta.Name | toUpper}}
+{{- end }} if provider is nil, including a nil pointer.
+func New{{.Meta.Name}}ClientProvider(provider registry.IServiceProvider, opts ...restclient.RestClientOption) *{{.Meta.Name}}Client {
+	if restclient.IsNilProvider(provider) {
+		return New{{.Meta.Name}}Client(opts...)
+	}
+	return New{{.Meta.Name}}Client(append([]restclient.RestClientOption{
+		restclient.WithProvider(provider),
+	}, opts...)...)
+}
 `
 
 func restyMethod(method string) string {
@@ -398,6 +509,26 @@ type GenGoClientConfig struct {
 	CaseConvertor        func(string) string
 }
 
+// queryParams returns names of parameters listed in @query annotations of method, e.g. @query(ids,tags).
+// Built-in type parameters of GET and DELETE methods are always sent in query string. For other http methods,
+// they are sent in query string if the method has parameters sent as json request body, otherwise in form body,
+// unless they are listed in @query annotations. Slice parameters are sent as repeated key, e.g. ?ids=1&ids=2,
+// and optional ones are omitted if nil or empty.
+func queryParams(method astutils.MethodMeta) []string {
+	var params []string
+	for _, item := range method.Annotations {
+		if item.Name != "@query" {
+			continue
+		}
+		for _, param := range item.Params {
+			if param = strings.TrimSpace(param); stringutils.IsNotEmpty(param) {
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
+
 // GenGoClient generates golang http client code from result of parsing svc.go file in project root path
 func GenGoClient(dir string, ic astutils.InterfaceCollector, config GenGoClientConfig) {
 	var (
@@ -409,10 +540,7 @@ func GenGoClient(dir string, ic astutils.InterfaceCollector, config GenGoClientC
 		clientDir  string
 		fi         os.FileInfo
 		source     string
-		modfile    string
 		modName    string
-		firstLine  string
-		modf       *os.File
 		meta       astutils.InterfaceMeta
 	)
 	clientDir = filepath.Join(dir, "client")
@@ -435,13 +563,7 @@ func GenGoClient(dir string, ic astutils.InterfaceCollector, config GenGoClientC
 
 	_ = copier.DeepCopy(ic.Interfaces[0], &meta)
 
-	modfile = filepath.Join(dir, "go.mod")
-	if modf, err = Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(modf)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+	modName = readModName(dir)
 
 	funcMap := make(map[string]interface{})
 	funcMap["toLowerCamel"] = strcase.ToLowerCamel
@@ -458,6 +580,8 @@ func GenGoClient(dir string, ic astutils.InterfaceCollector, config GenGoClientC
 	funcMap["isSlice"] = v3helper.IsSlice
 	funcMap["isVarargs"] = v3helper.IsVarargs
 	funcMap["IsEnum"] = v3helper.IsEnum
+	funcMap["hasFile"] = v3helper.HasFile
+	funcMap["queryParams"] = queryParams
 	if tpl, err = template.New("client.go.tmpl").Funcs(funcMap).Parse(clientTmpl); err != nil {
 		panic(err)
 	}
@@ -4,9 +4,13 @@ import (
 	"github.com/iancoleman/strcase"
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +49,287 @@ func TestGenGoClient(t *testing.T) {
 	}
 }
 
+func TestGenGoClient_Provider(t *testing.T) {
+	dir := testDir + "clientprovider"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	svcfile := filepath.Join(dir, "svc.go")
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoClient(dir, ic, GenGoClientConfig{
+		Env:                  "TESTSVC_BASE_URL",
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+	b, err := os.ReadFile(filepath.Join(dir, "client", "client.go"))
+	require.NoError(t, err)
+	code := string(b)
+	require.Contains(t, code, "func NewTestdataclientproviderClientProvider(provider registry.IServiceProvider, opts ...restclient.RestClientOption) *TestdataclientproviderClient {")
+	require.Contains(t, code, "environment variable TESTSVC_BASE_URL if provider is nil, including a nil pointer.")
+	require.Contains(t, code, "restclient.WithProvider(provider),")
+	require.Contains(t, code, "if restclient.IsNilProvider(provider) {")
+	require.Contains(t, code, "return NewTestdataclientproviderClientProvider(restclient.NewRegistryProvider(serviceName, provider), opts...)")
+}
+
+func TestGenGoClient_Retry(t *testing.T) {
+	dir := testDir + "clientretry"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	svcfile := filepath.Join(dir, "svc.go")
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `func (receiver *TestdataclientretryClient) SetRetry(retry restclient.RetryConfig) {
+	receiver.retry = &retry
+}`)
+	require.Contains(t, code, `	if options.RetryNonIdempotent {
+		ctx = restclient.WithRetryNonIdempotent(ctx)
+	}
+	_req.SetContext(ctx)`)
+	require.Contains(t, code, `	if svcClient.retry != nil {
+		restclient.ApplyRetry(svcClient.client, *svcClient.retry)
+	}`)
+
+	optionsFile := filepath.Join(dir, "client", "options.go")
+	source, err = os.ReadFile(optionsFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), optionsFile, source, 0)
+	require.NoError(t, err)
+	require.Contains(t, string(source), "RetryNonIdempotent bool")
+}
+
+func TestGenGoClient_Multipart(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testfile\n\ngo 1.18\n"), 0644))
+	svcfile := filepath.Join(dir, "svc.go")
+	require.NoError(t, os.WriteFile(svcfile, []byte(`package service
+
+import (
+	"context"
+	v3 "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
+	"mime/multipart"
+	"testfile/dto"
+)
+
+type Testfile interface {
+	Upload(ctx context.Context, desc string, meta dto.Meta, file v3.FileModel, attachment *multipart.FileHeader, extras []*multipart.FileHeader) (id int, err error)
+}
+`), 0644))
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+	GenHttpHandlerImpl(dir, ic, GenHttpHandlerImplConfig{
+		CaseConvertor: strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `_req.SetFileReader("file", file.Filename, file.Reader)`)
+	require.Contains(t, code, `	if attachment != nil {
+		_f, _err := attachment.Open()
+		if _err != nil {
+			err = errors.Wrap(_err, "error")
+			return
+		}
+		defer _f.Close()
+		_req.SetFileReader("attachment", attachment.Filename, _f)
+	}`)
+	require.Contains(t, code, `_req.SetMultipartField("meta", "", "application/json", bytes.NewReader(_data))`)
+	require.Contains(t, code, `	for _k, _vs := range _urlValues {
+		for _, _v := range _vs {
+			_req.SetMultipartField(_k, "", "", strings.NewReader(_v))
+		}
+	}`)
+	require.NotContains(t, code, "SetBody")
+	require.NotContains(t, code, `"Content-Type"`)
+
+	handlerFile := filepath.Join(dir, "transport", "httpsrv", "handlerimpl.go")
+	source, err = os.ReadFile(handlerFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), handlerFile, source, 0)
+	require.NoError(t, err)
+	code = string(source)
+	require.Contains(t, code, `if _v := _req.FormValue("meta"); _v != "" {
+		if _err := json.Unmarshal([]byte(_v), &meta); _err != nil {`)
+	require.NotContains(t, code, "rest.DecodeJSON")
+	require.Equal(t, 1, strings.Count(code, "_req.ParseMultipartForm(32 << 20)"))
+}
+
+func TestGenGoClient_QueryParams(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testquery\n\ngo 1.18\n"), 0644))
+	svcfile := filepath.Join(dir, "svc.go")
+	require.NoError(t, os.WriteFile(svcfile, []byte(`package service
+
+import "context"
+
+type Testquery interface {
+	GetUsers(ctx context.Context, ids []int, tags *[]string) (err error)
+	// @query(ids,tags)
+	PostUsers(ctx context.Context, ids []int, tags *[]string, name string) (err error)
+	DeleteUsers(ctx context.Context, ids []int) (err error)
+}
+`), 0644))
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `	if tags != nil {
+		for _, _item := range *tags {
+			_urlValues.Add("tags", fmt.Sprintf("%v", _item))
+		}
+	}`)
+	require.Contains(t, code, `	for _, _k := range []string{"ids", "tags"} {
+		if _vs, _ok := _urlValues[_k]; _ok {
+			_req.SetQueryParamsFromValues(url.Values{_k: _vs})
+			delete(_urlValues, _k)
+		}
+	}`)
+	require.Equal(t, 1, strings.Count(code, "for _, _k := range"))
+	require.Equal(t, 1, strings.Count(code, "_req.SetFormDataFromValues(_urlValues)"))
+	require.Contains(t, code, "	_req.SetQueryParamsFromValues(_urlValues)\n	_resp, _err = receiver.breaker.Execute(func() (*resty.Response, error) {\n		return _req.Get(_path)\n	})")
+	require.Contains(t, code, "	_req.SetQueryParamsFromValues(_urlValues)\n	_resp, _err = receiver.breaker.Execute(func() (*resty.Response, error) {\n		return _req.Delete(_path)\n	})")
+}
+
+func TestGenGoClient_Context(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testctx\n\ngo 1.18\n"), 0644))
+	svcfile := filepath.Join(dir, "svc.go")
+	require.NoError(t, os.WriteFile(svcfile, []byte(`package service
+
+import "context"
+
+type Testctx interface {
+	GetUser(ctx context.Context, id int) (name string, err error)
+}
+`), 0644))
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `	var _err error
+	if _err = ctx.Err(); _err != nil {
+		err = errors.Wrap(_err, "error")
+		return
+	}`)
+	require.Contains(t, code, "_req.SetContext(ctx)")
+	require.Less(t, strings.Index(code, "ctx.Err()"), strings.Index(code, "receiver.client.R()"))
+}
+
+func TestGenGoClient_Breaker(t *testing.T) {
+	dir := testDir + "clientbreaker"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	svcfile := filepath.Join(dir, "svc.go")
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `func (receiver *TestdataclientbreakerClient) SetBreaker(breaker *restclient.CircuitBreaker) {
+	receiver.breaker = breaker
+}`)
+	require.Contains(t, code, `	_resp, _err = receiver.breaker.Execute(func() (*resty.Response, error) {
+		return _req.Post(_path)
+	})`)
+}
+
+func TestGenGoClient_Transport(t *testing.T) {
+	dir := testDir + "clienttransport"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	svcfile := filepath.Join(dir, "svc.go")
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `func (receiver *TestdataclienttransportClient) TransportConfig() *restclient.TransportConfig {
+	return &receiver.transport
+}`)
+	require.Contains(t, code, "	restclient.ApplyTransport(svcClient.client, svcClient.transport)")
+}
+
+func TestGenGoClient_ErrorDecoder(t *testing.T) {
+	dir := testDir + "clienterrordecoder"
+	InitSvc(dir)
+	defer os.RemoveAll(dir)
+	svcfile := filepath.Join(dir, "svc.go")
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	GenGoIClient(dir, ic, GenGoIClientConfig{})
+	GenGoClient(dir, ic, GenGoClientConfig{
+		RoutePatternStrategy: 1,
+		CaseConvertor:        strcase.ToLowerCamel,
+	})
+
+	clientFile := filepath.Join(dir, "client", "client.go")
+	source, err := os.ReadFile(clientFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), clientFile, source, 0)
+	require.NoError(t, err)
+	code := string(source)
+	require.Contains(t, code, `func (receiver *TestdataclienterrordecoderClient) SetErrorDecoder(errorDecoder restclient.ErrorDecoder) {
+	receiver.errorDecoder = errorDecoder
+}`)
+	require.Contains(t, code, `	if _resp.IsError() {
+		if _err = receiver.errorDecoder(_resp); _err != nil {
+			err = _err
+			return
+		}
+	}`)
+	require.Contains(t, code, "errorDecoder: restclient.DecodeError,")
+}
+
 func TestGenGoClientPanic_Stat(t *testing.T) {
 	Convey("Test GenGoClient panic from Stat", t, func() {
 		MkdirAll = os.MkdirAll
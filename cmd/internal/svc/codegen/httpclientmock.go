@@ -0,0 +1,158 @@
+package codegen
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/copier"
+	"github.com/unionj-cloud/go-doudou/v2/version"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// clientMockTmpl generates a mock of I{{.Meta.Name}}Client in the same shape as mockgen, so that tests can set
+// expectations and check call arguments by gomock. Params and results must be kept the same as iclientTmpl.
+// It is generated into client/mock package, so that consumers of client package don't depend on gomock.
+var clientMockTmpl = `/**
+* Generated by go-doudou {{.Version}}.
+* Don't edit!
+*/
+package mock
+
+import (
+	"context"
+	"{{.ClientPackage}}"
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+	"{{.VoPackage}}"
+	"{{.DtoPackage}}"
+	v3 "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
+	"os"
+	"reflect"
+)
+
+// MockI{{.Meta.Name}}Client is a mock of I{{.Meta.Name}}Client interface.
+type MockI{{.Meta.Name}}Client struct {
+	ctrl     *gomock.Controller
+	recorder *MockI{{.Meta.Name}}ClientMockRecorder
+}
+
+// MockI{{.Meta.Name}}ClientMockRecorder is the mock recorder for MockI{{.Meta.Name}}Client.
+type MockI{{.Meta.Name}}ClientMockRecorder struct {
+	mock *MockI{{.Meta.Name}}Client
+}
+
+// NewMockI{{.Meta.Name}}Client creates a new mock instance.
+func NewMockI{{.Meta.Name}}Client(ctrl *gomock.Controller) *MockI{{.Meta.Name}}Client {
+	mock := &MockI{{.Meta.Name}}Client{ctrl: ctrl}
+	mock.recorder = &MockI{{.Meta.Name}}ClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (_m *MockI{{.Meta.Name}}Client) EXPECT() *MockI{{.Meta.Name}}ClientMockRecorder {
+	return _m.recorder
+}
+
+var _ client.I{{.Meta.Name}}Client = (*MockI{{.Meta.Name}}Client)(nil)
+{{- range $m := .Meta.Methods }}
+
+// {{$m.Name}} mocks base method.
+func (_m *MockI{{$.Meta.Name}}Client) {{$m.Name}}(ctx context.Context, _headers map[string]string, {{- range $i, $p := $m.Params}}
+	{{- if ne $p.Type "context.Context" }}
+	{{- $p.Name}} {{$p.Type}},
+	{{- end }}
+	{{- end }} options client.Options) (_resp *resty.Response, {{- range $i, $r := $m.Results}}
+	{{- if $i}},{{end}}
+	{{- $r.Name}} {{$r.Type}}
+	{{- end }}) {
+	_m.ctrl.T.Helper()
+	_ret := _m.ctrl.Call(_m, "{{$m.Name}}", ctx, _headers, {{- range $i, $p := $m.Params}}
+	{{- if ne $p.Type "context.Context" }} {{$p.Name}},
+	{{- end }}
+	{{- end }} options)
+	_resp, _ = _ret[0].(*resty.Response)
+	{{- range $i, $r := $m.Results}}
+	{{$r.Name}}, _ = _ret[{{inc $i}}].({{$r.Type}})
+	{{- end }}
+	return
+}
+
+// {{$m.Name}} indicates an expected call of {{$m.Name}}.
+func (_mr *MockI{{$.Meta.Name}}ClientMockRecorder) {{$m.Name}}(ctx, _headers, {{- range $i, $p := $m.Params}}
+	{{- if ne $p.Type "context.Context" }} {{$p.Name}},
+	{{- end }}
+	{{- end }} options interface{}) *gomock.Call {
+	_mr.mock.ctrl.T.Helper()
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "{{$m.Name}}", reflect.TypeOf((*MockI{{$.Meta.Name}}Client)(nil).{{$m.Name}}), ctx, _headers, {{- range $i, $p := $m.Params}}
+	{{- if ne $p.Type "context.Context" }} {{$p.Name}},
+	{{- end }}
+	{{- end }} options)
+}
+{{- end }}
+`
+
+type GenGoClientMockConfig struct {
+	// Mode controls how existing mock_iclient.go is treated, it should be the same as Mode of GenGoIClientConfig
+	Mode OverwriteMode
+}
+
+// GenGoClientMock generates client/mock/mock_iclient.go, a gomock mock of the client interface generated by
+// GenGoIClient, from result of parsing svc.go file in project root path. It returns error if module path can't be
+// read from go.mod file under dir or ic has no interface.
+func GenGoClientMock(dir string, ic astutils.InterfaceCollector, config GenGoClientMockConfig) error {
+	var (
+		err     error
+		modName string
+		target  string
+		meta    astutils.InterfaceMeta
+		tpl     *template.Template
+		buf     bytes.Buffer
+	)
+	if len(ic.Interfaces) == 0 {
+		return errors.New("no service interface found")
+	}
+	if modName, err = ReadModulePath(dir); err != nil {
+		return err
+	}
+	mockDir := filepath.Join(dir, "client", "mock")
+	if err = os.MkdirAll(mockDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+	mockFile := filepath.Join(mockDir, "mock_iclient.go")
+	if target = resolveTarget(mockFile, config.Mode); target == "" {
+		return nil
+	}
+	if err = copier.DeepCopy(ic.Interfaces[0], &meta); err != nil {
+		return errors.WithStack(err)
+	}
+	funcMap := make(map[string]interface{})
+	funcMap["inc"] = func(i int) int {
+		return i + 1
+	}
+	if tpl, err = template.New("mock_iclient.go.tmpl").Funcs(funcMap).Parse(clientMockTmpl); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = tpl.Execute(&buf, struct {
+		ClientPackage string
+		VoPackage     string
+		DtoPackage    string
+		Meta          astutils.InterfaceMeta
+		Version       string
+	}{
+		ClientPackage: modName + "/client",
+		VoPackage:     modName + "/vo",
+		DtoPackage:    modName + "/dto",
+		Meta:          meta,
+		Version:       version.Release,
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+	astutils.FixImport([]byte(strings.TrimSpace(buf.String())), target)
+	if target != mockFile {
+		printDiff(mockFile, target)
+	}
+	return nil
+}
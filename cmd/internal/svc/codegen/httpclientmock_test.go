@@ -0,0 +1,123 @@
+package codegen
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"github.com/unionj-cloud/go-doudou/v2/version"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const clientMockGolden = `/**
+* Generated by go-doudou VERSION.
+* Don't edit!
+ */
+package mock
+
+import (
+	"context"
+	"reflect"
+	"testmock/client"
+	"testmock/vo"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/golang/mock/gomock"
+)
+
+// MockITestmockClient is a mock of ITestmockClient interface.
+type MockITestmockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockITestmockClientMockRecorder
+}
+
+// MockITestmockClientMockRecorder is the mock recorder for MockITestmockClient.
+type MockITestmockClientMockRecorder struct {
+	mock *MockITestmockClient
+}
+
+// NewMockITestmockClient creates a new mock instance.
+func NewMockITestmockClient(ctrl *gomock.Controller) *MockITestmockClient {
+	mock := &MockITestmockClient{ctrl: ctrl}
+	mock.recorder = &MockITestmockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (_m *MockITestmockClient) EXPECT() *MockITestmockClientMockRecorder {
+	return _m.recorder
+}
+
+var _ client.ITestmockClient = (*MockITestmockClient)(nil)
+
+// GetUser mocks base method.
+func (_m *MockITestmockClient) GetUser(ctx context.Context, _headers map[string]string, id int, options client.Options) (_resp *resty.Response, data vo.User, err error) {
+	_m.ctrl.T.Helper()
+	_ret := _m.ctrl.Call(_m, "GetUser", ctx, _headers, id, options)
+	_resp, _ = _ret[0].(*resty.Response)
+	data, _ = _ret[1].(vo.User)
+	err, _ = _ret[2].(error)
+	return
+}
+
+// GetUser indicates an expected call of GetUser.
+func (_mr *MockITestmockClientMockRecorder) GetUser(ctx, _headers, id, options interface{}) *gomock.Call {
+	_mr.mock.ctrl.T.Helper()
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "GetUser", reflect.TypeOf((*MockITestmockClient)(nil).GetUser), ctx, _headers, id, options)
+}
+
+// DeleteUsers mocks base method.
+func (_m *MockITestmockClient) DeleteUsers(ctx context.Context, _headers map[string]string, pi []int, options client.Options) (_resp *resty.Response, re error) {
+	_m.ctrl.T.Helper()
+	_ret := _m.ctrl.Call(_m, "DeleteUsers", ctx, _headers, pi, options)
+	_resp, _ = _ret[0].(*resty.Response)
+	re, _ = _ret[1].(error)
+	return
+}
+
+// DeleteUsers indicates an expected call of DeleteUsers.
+func (_mr *MockITestmockClientMockRecorder) DeleteUsers(ctx, _headers, pi, options interface{}) *gomock.Call {
+	_mr.mock.ctrl.T.Helper()
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "DeleteUsers", reflect.TypeOf((*MockITestmockClient)(nil).DeleteUsers), ctx, _headers, pi, options)
+}
+`
+
+func TestGenGoClientMock(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testmock\n\ngo 1.18\n"), 0644))
+	svcfile := filepath.Join(dir, "svc.go")
+	require.NoError(t, os.WriteFile(svcfile, []byte(`package service
+
+import (
+	"context"
+	"testmock/vo"
+)
+
+type Testmock interface {
+	GetUser(ctx context.Context, id int) (data vo.User, err error)
+	DeleteUsers(context.Context, []int) error
+}
+`), 0644))
+	ic := astutils.BuildInterfaceCollector(svcfile, astutils.ExprString)
+	require.NoError(t, GenGoClientMock(dir, ic, GenGoClientMockConfig{}))
+
+	mockFile := filepath.Join(dir, "client", "mock", "mock_iclient.go")
+	source, err := os.ReadFile(mockFile)
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), mockFile, source, 0)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(clientMockGolden, "VERSION", version.Release, 1), string(source))
+
+	require.NoError(t, os.WriteFile(mockFile, []byte("package mock\n"), 0644))
+	require.NoError(t, GenGoClientMock(dir, ic, GenGoClientMockConfig{Mode: SkipIfExists}))
+	source, err = os.ReadFile(mockFile)
+	require.NoError(t, err)
+	require.Equal(t, "package mock\n", string(source))
+}
+
+func TestGenGoClientMock_NoInterface(t *testing.T) {
+	require.Error(t, GenGoClientMock(t.TempDir(), astutils.InterfaceCollector{}, GenGoClientMockConfig{}))
+}
@@ -1,7 +1,6 @@
 package codegen
 
 import (
-	"bufio"
 	"bytes"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/copier"
@@ -14,7 +13,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 	"text/template"
 )
 
@@ -187,10 +185,7 @@ func GenGoClientProxy(dir string, ic astutils.InterfaceCollector) {
 		buf             bytes.Buffer
 		clientDir       string
 		fi              os.FileInfo
-		modfile         string
 		modName         string
-		firstLine       string
-		modf            *os.File
 		meta            astutils.InterfaceMeta
 		clientProxyTmpl string
 		importBuf       bytes.Buffer
@@ -226,13 +221,7 @@ func GenGoClientProxy(dir string, ic astutils.InterfaceCollector) {
 		clientProxyTmpl = baseTmpl
 	}
 
-	modfile = filepath.Join(dir, "go.mod")
-	if modf, err = os.Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(modf)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+	modName = readModName(dir)
 
 	funcMap := make(map[string]interface{})
 	funcMap["isVarargs"] = v3helper.IsVarargs
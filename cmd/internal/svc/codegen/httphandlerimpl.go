@@ -1,7 +1,6 @@
 package codegen
 
 import (
-	"bufio"
 	"bytes"
 	"github.com/iancoleman/strcase"
 	"github.com/sirupsen/logrus"
@@ -159,9 +158,34 @@ var appendHttpHandlerImplTmpl = `
 			}
 			{{- end }}
 		}
+		{{- else if hasFile $m }}
+		{{- if not $multipartFormParsed }}
+		if _err := _req.ParseMultipartForm(32 << 20); _err != nil {
+			rest.HandleBadRequestErr(_err)
+		}
+		{{- $multipartFormParsed = true }}
+		{{- end }}
+		if _v := _req.FormValue("{{$p.Name}}"); _v != "" {
+			if _err := json.Unmarshal([]byte(_v), &{{$p.Name}}); _err != nil {
+				rest.HandleBadRequestErr(_err)
+			}
+			{{- if isStruct $p }}
+			if _err := rest.ValidateStruct({{$p.Name}}); _err != nil {
+				rest.HandleBadRequestErr(_err)
+			}
+			{{- else }}
+			if _err := rest.ValidateVar({{$p.Name}}, "{{$p.ValidateTag}}", ""); _err != nil {
+				rest.HandleBadRequestErr(_err)
+			}
+			{{- end }}
+		}
+		{{- if not (isOptional $p.Type) }} else {
+			rest.HandleBadRequestErr(errors.New("missing parameter {{$p.Name}}"))
+		}
+		{{- end }}
 		{{- else }}
 		{{- if isOptional $p.Type }}
-		if _err := json.NewDecoder(_req.Body).Decode(&{{$p.Name}}); _err != nil {
+		if _err := rest.DecodeJSON(_req, &{{$p.Name}}); _err != nil {
 			if _err != io.EOF {
 				rest.HandleBadRequestErr(_err)				
 			}
@@ -177,7 +201,7 @@ var appendHttpHandlerImplTmpl = `
 			{{- end }}
 		}
 		{{- else }}
-		if _err := json.NewDecoder(_req.Body).Decode(&{{$p.Name}}); _err != nil {
+		if _err := rest.DecodeJSON(_req, &{{$p.Name}}); _err != nil {
 			rest.HandleBadRequestErr(_err)	
 		} else {
 			{{- if isStruct $p }}
@@ -426,12 +450,9 @@ type GenHttpHandlerImplConfig struct {
 func GenHttpHandlerImpl(dir string, ic astutils.InterfaceCollector, config GenHttpHandlerImplConfig) {
 	var (
 		err             error
-		modfile         string
 		modName         string
-		firstLine       string
 		handlerimplfile string
 		f               *os.File
-		modf            *os.File
 		tpl             *template.Template
 		buf             bytes.Buffer
 		httpDir         string
@@ -471,13 +492,7 @@ func GenHttpHandlerImpl(dir string, ic astutils.InterfaceCollector, config GenHt
 		tmpl = initHttpHandlerImplTmpl
 	}
 
-	modfile = filepath.Join(dir, "go.mod")
-	if modf, err = os.Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(modf)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+	modName = readModName(dir)
 
 	funcMap := make(map[string]interface{})
 	funcMap["toLowerCamel"] = strcase.ToLowerCamel
@@ -496,6 +511,7 @@ func GenHttpHandlerImpl(dir string, ic astutils.InterfaceCollector, config GenHt
 	funcMap["TrimPrefix"] = strings.TrimPrefix
 	funcMap["ElementType"] = v3helper.ElementType
 	funcMap["title"] = strings.Title
+	funcMap["hasFile"] = v3helper.HasFile
 	if tpl, err = template.New("handlerimpl.go.tmpl").Funcs(funcMap).Parse(tmpl); err != nil {
 		panic(err)
 	}
@@ -1,12 +1,18 @@
 package codegen
 
 import (
-	"bufio"
 	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/sirupsen/logrus"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/copier"
 	"github.com/unionj-cloud/go-doudou/v2/version"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -28,10 +34,6 @@ import (
 	"os"
 )
 
-type Options struct {
-	GzipReqBody bool
-}
-
 type I{{.Meta.Name}}Client interface {
 {{- range $m := .Meta.Methods }}
 	{{$m.Name}}(ctx context.Context, _headers map[string]string, {{- range $i, $p := $m.Params}}
@@ -46,37 +48,86 @@ type I{{.Meta.Name}}Client interface {
 }
 `
 
-// GenGoIClient generates golang http client interface code from result of parsing svc.go file in project root path
-func GenGoIClient(dir string, ic astutils.InterfaceCollector) {
+// optionsTmpl generates Options of generated client methods into client/options.go, which is always overwritten
+// regardless of OverwriteMode, as client.go generated with it may refer to new fields
+var optionsTmpl = `/**
+* Generated by go-doudou {{.Version}}.
+* Don't edit!
+*/
+package client
+
+type Options struct {
+	GzipReqBody bool
+	// RetryNonIdempotent opts in retrying non-idempotent requests like POST if client is created with restclient.WithRetry
+	RetryNonIdempotent bool
+}
+`
+
+// OverwriteMode controls how a generator treats existing generated file
+type OverwriteMode int
+
+const (
+	// Overwrite overwrites existing file
+	Overwrite OverwriteMode = iota
+	// SkipIfExists leaves existing file untouched
+	SkipIfExists
+	// WriteNewAndDiff writes to a file with .new suffix next to existing file, and prints unified diff against it
+	WriteNewAndDiff
+)
+
+// ParseOverwriteMode parses overwrite, skip or diff into OverwriteMode
+func ParseOverwriteMode(mode string) (OverwriteMode, error) {
+	switch strings.ToLower(mode) {
+	case "", "overwrite":
+		return Overwrite, nil
+	case "skip":
+		return SkipIfExists, nil
+	case "diff":
+		return WriteNewAndDiff, nil
+	default:
+		return Overwrite, errors.Errorf("unknown overwrite mode %s, optional values: overwrite, skip, diff", mode)
+	}
+}
+
+type GenGoIClientConfig struct {
+	// Mode controls how existing iclient.go is treated, which may have been edited by hand
+	Mode OverwriteMode
+}
+
+// GenGoIClient generates golang http client interface code from result of parsing svc.go file in project root path.
+// It returns error if module path can't be read from go.mod file under dir.
+func GenGoIClient(dir string, ic astutils.InterfaceCollector, config GenGoIClientConfig) error {
 	var (
 		err        error
 		clientfile string
+		target     string
 		f          *os.File
 		tpl        *template.Template
 		sqlBuf     bytes.Buffer
 		clientDir  string
-		fi         os.FileInfo
 		source     string
-		modfile    string
 		modName    string
-		firstLine  string
-		modf       *os.File
 		meta       astutils.InterfaceMeta
 	)
+	if modName, err = ReadModulePath(dir); err != nil {
+		return err
+	}
 	clientDir = filepath.Join(dir, "client")
 	if err = os.MkdirAll(clientDir, os.ModePerm); err != nil {
 		panic(err)
 	}
 
+	if err = genGoClientOptions(clientDir); err != nil {
+		return err
+	}
 	clientfile = filepath.Join(clientDir, "iclient.go")
-	fi, err = os.Stat(clientfile)
-	if err != nil && !os.IsNotExist(err) {
-		panic(err)
+	if err = checkLegacyOptions(clientfile, config.Mode); err != nil {
+		return err
 	}
-	if fi != nil {
-		logrus.Warningln("file iclient.go will be overwritten")
+	if target = resolveTarget(clientfile, config.Mode); target == "" {
+		return nil
 	}
-	if f, err = os.Create(clientfile); err != nil {
+	if f, err = os.Create(target); err != nil {
 		panic(err)
 	}
 	defer f.Close()
@@ -86,14 +137,6 @@ func GenGoIClient(dir string, ic astutils.InterfaceCollector) {
 		panic(err)
 	}
 
-	modfile = filepath.Join(dir, "go.mod")
-	if modf, err = os.Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(modf)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
-
 	if tpl, err = template.New("iclient.go.tmpl").Parse(iclientTmpl); err != nil {
 		panic(err)
 	}
@@ -112,5 +155,102 @@ func GenGoIClient(dir string, ic astutils.InterfaceCollector) {
 	}
 
 	source = strings.TrimSpace(sqlBuf.String())
-	astutils.FixImport([]byte(source), clientfile)
+	astutils.FixImport([]byte(source), target)
+	if target != clientfile {
+		printDiff(clientfile, target)
+	}
+	return nil
+}
+
+// genGoClientOptions generates client/options.go
+func genGoClientOptions(clientDir string) error {
+	var buf bytes.Buffer
+	tpl, err := template.New("options.go.tmpl").Parse(optionsTmpl)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err = tpl.Execute(&buf, struct {
+		Version string
+	}{
+		Version: version.Release,
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+	astutils.FixImport([]byte(strings.TrimSpace(buf.String())), filepath.Join(clientDir, "options.go"))
+	return nil
+}
+
+// checkLegacyOptions returns error if existing iclient.go kept by mode still declares Options, which is generated
+// into options.go since then, so that the client package would not compile
+func checkLegacyOptions(clientfile string, mode OverwriteMode) error {
+	if mode == Overwrite {
+		return nil
+	}
+	source, err := ioutil.ReadFile(clientfile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), clientfile, source, 0)
+	if err != nil {
+		return errors.Wrapf(err, "fail to parse %s", clientfile)
+	}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if spec.(*ast.TypeSpec).Name.Name == "Options" {
+				return errors.Errorf("%s declares Options, which is generated into options.go now, "+
+					"please remove it from %s by hand or regenerate it in overwrite mode", clientfile, filepath.Base(clientfile))
+			}
+		}
+	}
+	return nil
+}
+
+// resolveTarget returns the file which generated code should be written into according to mode if file exists,
+// or empty string if the existing file should be kept untouched
+func resolveTarget(file string, mode OverwriteMode) string {
+	fi, err := os.Stat(file)
+	if err != nil && !os.IsNotExist(err) {
+		panic(err)
+	}
+	if fi == nil {
+		return file
+	}
+	switch mode {
+	case SkipIfExists:
+		logrus.Warningf("file %s exists, skipped", filepath.Base(file))
+		return ""
+	case WriteNewAndDiff:
+		logrus.Warningf("file %s exists, write to %s instead", filepath.Base(file), filepath.Base(file)+".new")
+		return file + ".new"
+	default:
+		logrus.Warningf("file %s will be overwritten", filepath.Base(file))
+		return file
+	}
+}
+
+// printDiff prints unified diff of file b against file a
+func printDiff(a, b string) {
+	before, err := ioutil.ReadFile(a)
+	if err != nil {
+		panic(err)
+	}
+	after, err := ioutil.ReadFile(b)
+	if err != nil {
+		panic(err)
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(before)),
+		B:        difflib.SplitLines(string(after)),
+		FromFile: filepath.Base(a),
+		ToFile:   filepath.Base(b),
+		Context:  3,
+	})
+	fmt.Print(diff)
 }
@@ -1,7 +1,10 @@
 package codegen
 
 import (
+	"github.com/stretchr/testify/require"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -28,7 +31,103 @@ func TestGenGoIClient(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			GenGoIClient(tt.args.dir, tt.args.ic)
+			require.NoError(t, GenGoIClient(tt.args.dir, tt.args.ic, GenGoIClientConfig{}))
 		})
 	}
 }
+
+func TestGenGoIClient_Mode(t *testing.T) {
+	dir := t.TempDir()
+	mod, err := ioutil.ReadFile(filepath.Join(testDir, "go.mod"))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), mod, 0644))
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), astutils.ExprString)
+	clientfile := filepath.Join(dir, "client", "iclient.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(clientfile), os.ModePerm))
+	edited := []byte("package client\n\n// edited by hand\n")
+
+	require.NoError(t, ioutil.WriteFile(clientfile, edited, 0644))
+	require.NoError(t, GenGoIClient(dir, ic, GenGoIClientConfig{Mode: SkipIfExists}))
+	content, err := ioutil.ReadFile(clientfile)
+	require.NoError(t, err)
+	require.Equal(t, edited, content)
+	require.NoFileExists(t, clientfile+".new")
+
+	require.NoError(t, GenGoIClient(dir, ic, GenGoIClientConfig{Mode: WriteNewAndDiff}))
+	content, err = ioutil.ReadFile(clientfile)
+	require.NoError(t, err)
+	require.Equal(t, edited, content)
+	generated, err := ioutil.ReadFile(clientfile + ".new")
+	require.NoError(t, err)
+	require.Contains(t, string(generated), "type IUsersvcClient interface {")
+
+	require.NoError(t, GenGoIClient(dir, ic, GenGoIClientConfig{Mode: Overwrite}))
+	content, err = ioutil.ReadFile(clientfile)
+	require.NoError(t, err)
+	require.Equal(t, generated, content)
+}
+
+func TestGenGoIClient_Options(t *testing.T) {
+	dir := t.TempDir()
+	mod, err := ioutil.ReadFile(filepath.Join(testDir, "go.mod"))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), mod, 0644))
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), astutils.ExprString)
+	clientfile := filepath.Join(dir, "client", "iclient.go")
+	require.NoError(t, os.MkdirAll(filepath.Dir(clientfile), os.ModePerm))
+
+	require.NoError(t, ioutil.WriteFile(clientfile, []byte("package client\n\ntype IUsersvcClient interface {}\n"), 0644))
+	require.NoError(t, GenGoIClient(dir, ic, GenGoIClientConfig{Mode: SkipIfExists}))
+	options, err := ioutil.ReadFile(filepath.Join(dir, "client", "options.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(options), "RetryNonIdempotent bool")
+
+	legacy := []byte("package client\n\ntype Options struct {\n\tGzipReqBody bool\n}\n")
+	require.NoError(t, ioutil.WriteFile(clientfile, legacy, 0644))
+	err = GenGoIClient(dir, ic, GenGoIClientConfig{Mode: SkipIfExists})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "declares Options")
+	require.Error(t, GenGoIClient(dir, ic, GenGoIClientConfig{Mode: WriteNewAndDiff}))
+
+	require.NoError(t, GenGoIClient(dir, ic, GenGoIClientConfig{Mode: Overwrite}))
+	content, err := ioutil.ReadFile(clientfile)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "type Options struct")
+}
+
+func TestParseOverwriteMode(t *testing.T) {
+	for value, want := range map[string]OverwriteMode{
+		"":          Overwrite,
+		"overwrite": Overwrite,
+		"Skip":      SkipIfExists,
+		"diff":      WriteNewAndDiff,
+	} {
+		mode, err := ParseOverwriteMode(value)
+		require.NoError(t, err)
+		require.Equal(t, want, mode)
+	}
+	_, err := ParseOverwriteMode("merge")
+	require.Error(t, err)
+}
+
+func TestGenGoIClient_ModComment(t *testing.T) {
+	dir := t.TempDir()
+	mod := "// Copyright go-doudou\n\nmodule \"example.com/usersvc\" // usersvc\n\ngo 1.18\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0644))
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), astutils.ExprString)
+	require.NoError(t, GenGoIClient(dir, ic, GenGoIClientConfig{}))
+	content, err := ioutil.ReadFile(filepath.Join(dir, "client", "iclient.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"example.com/usersvc/vo"`)
+	require.Contains(t, string(content), `"example.com/usersvc/dto"`)
+}
+
+func TestGenGoIClient_NoModule(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("// module example.com/usersvc\n\ngo 1.18\n"), 0644))
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), astutils.ExprString)
+	require.Error(t, GenGoIClient(dir, ic, GenGoIClientConfig{}))
+	require.NoFileExists(t, filepath.Join(dir, "client", "iclient.go"))
+
+	require.Error(t, GenGoIClient(t.TempDir(), ic, GenGoIClientConfig{}))
+}
@@ -196,7 +196,6 @@ func InitProj(dir string, modName string, runner executils.Runner) {
 		dtodir    string
 		dtofile   string
 		goVersion string
-		firstLine string
 		f         *os.File
 		tpl       *template.Template
 		envfile   string
@@ -278,13 +277,7 @@ func InitProj(dir string, modName string, runner executils.Runner) {
 	svcName = strcase.ToCamel(filepath.Base(dir))
 	svcfile = filepath.Join(dir, "svc.go")
 	if _, err = os.Stat(svcfile); os.IsNotExist(err) {
-		if f, err = os.Open(modfile); err != nil {
-			panic(err)
-		}
-		defer f.Close()
-		reader := bufio.NewReader(f)
-		firstLine, _ = reader.ReadString('\n')
-		modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+		modName = readModName(dir)
 
 		if f, err = os.Create(svcfile); err != nil {
 			panic(err)
@@ -3,7 +3,9 @@ package codegen
 import (
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/require"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -93,3 +95,22 @@ func TestGenMainPanic_MkdirAll(t *testing.T) {
 		}, ShouldPanic)
 	})
 }
+
+func TestGenMain_ModComment(t *testing.T) {
+	MkdirAll = os.MkdirAll
+	Open = os.Open
+	Create = os.Create
+	Stat = os.Stat
+	dir := t.TempDir()
+	mod := "// Copyright go-doudou\n\nmodule example.com/usersvc\n\ngo 1.18\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0644))
+	ic := astutils.BuildInterfaceCollector(filepath.Join(testDir, "svc.go"), astutils.ExprString)
+	GenMain(dir, ic)
+	content, err := ioutil.ReadFile(filepath.Join(dir, "cmd", "main.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"example.com/usersvc/config"`)
+
+	require.Panics(t, func() {
+		GenMain(t.TempDir(), ic)
+	})
+}
@@ -0,0 +1,45 @@
+package codegen
+
+import (
+	"bufio"
+	"github.com/pkg/errors"
+	"path/filepath"
+	"strings"
+)
+
+// ReadModulePath returns module path declared by module directive of go.mod file under dir, skipping blank lines and
+// comments before it
+func ReadModulePath(dir string) (string, error) {
+	modfile := filepath.Join(dir, "go.mod")
+	modf, err := Open(modfile)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer modf.Close()
+	scanner := bufio.NewScanner(modf)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if modName := strings.Trim(fields[1], "\"`"); modName != "" {
+			return modName, nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return "", errors.Errorf("no module directive found in %s", modfile)
+}
+
+func readModName(dir string) string {
+	modName, err := ReadModulePath(dir)
+	if err != nil {
+		panic(err)
+	}
+	return modName
+}
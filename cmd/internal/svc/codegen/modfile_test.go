@@ -0,0 +1,40 @@
+package codegen
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadModulePath(t *testing.T) {
+	Open = os.Open
+	cases := map[string]string{
+		"module example.com/usersvc\n\ngo 1.18\n":                               "example.com/usersvc",
+		"// Copyright go-doudou\n\nmodule example.com/usersvc\n":                "example.com/usersvc",
+		"module \"example.com/usersvc\" // quoted\n":                            "example.com/usersvc",
+		"\n  module   example.com/usersvc/v2  \nrequire example.com/other v1\n": "example.com/usersvc/v2",
+	}
+	for mod, want := range cases {
+		dir := t.TempDir()
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0644))
+		got, err := ReadModulePath(dir)
+		require.NoError(t, err, mod)
+		require.Equal(t, want, got, mod)
+	}
+}
+
+func TestReadModulePath_Error(t *testing.T) {
+	Open = os.Open
+	_, err := ReadModulePath(t.TempDir())
+	require.Error(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("// module example.com/usersvc\ngo 1.18\n"), 0644))
+	_, err = ReadModulePath(dir)
+	require.Error(t, err)
+	require.Panics(t, func() {
+		readModName(dir)
+	})
+}
@@ -1,7 +1,6 @@
 package codegen
 
 import (
-	"bufio"
 	"bytes"
 	"github.com/iancoleman/strcase"
 	"github.com/sirupsen/logrus"
@@ -15,7 +14,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 	"text/template"
 )
 
@@ -150,10 +148,8 @@ func New{{.Meta.Name}}(conf *config.Config) *{{.Meta.Name}}Impl {
 func GenSvcImpl(dir string, ic astutils.InterfaceCollector) {
 	var (
 		err         error
-		modfile     string
 		modName     string
 		svcimplfile string
-		firstLine   string
 		f           *os.File
 		tpl         *template.Template
 		buf         bytes.Buffer
@@ -166,13 +162,7 @@ func GenSvcImpl(dir string, ic astutils.InterfaceCollector) {
 	if err != nil {
 		panic(err)
 	}
-	modfile = filepath.Join(dir, "go.mod")
-	if f, err = os.Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(f)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+	modName = readModName(dir)
 	if _, err = os.Stat(svcimplfile); os.IsNotExist(err) {
 		if f, err = os.Create(svcimplfile); err != nil {
 			panic(err)
@@ -271,10 +261,8 @@ func convert(m v3.Message) string {
 func GenSvcImplGrpc(dir string, ic astutils.InterfaceCollector, grpcSvc v3.Service) {
 	var (
 		err         error
-		modfile     string
 		modName     string
 		svcimplfile string
-		firstLine   string
 		f           *os.File
 		tpl         *template.Template
 		buf         bytes.Buffer
@@ -287,13 +275,7 @@ func GenSvcImplGrpc(dir string, ic astutils.InterfaceCollector, grpcSvc v3.Servi
 	if err != nil {
 		panic(err)
 	}
-	modfile = filepath.Join(dir, "go.mod")
-	if f, err = os.Open(modfile); err != nil {
-		panic(err)
-	}
-	reader := bufio.NewReader(f)
-	firstLine, _ = reader.ReadString('\n')
-	modName = strings.TrimSpace(strings.TrimPrefix(firstLine, "module"))
+	modName = readModName(dir)
 	if _, err = os.Stat(svcimplfile); os.IsNotExist(err) {
 		if f, err = os.Create(svcimplfile); err != nil {
 			panic(err)
@@ -1,5 +1,5 @@
 /**
-* Generated by go-doudou v1.3.8.
+* Generated by go-doudou v2.0.6.
 * You can edit it as your need.
  */
 package httpsrv
@@ -16,7 +16,9 @@ import (
 	"testdata/vo"
 
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest/httprouter"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	v3 "github.com/unionj-cloud/go-doudou/v2/toolkit/openapi/v3"
 )
@@ -34,18 +36,11 @@ func (receiver *UsersvcHandlerImpl) PageUsers(_writer http.ResponseWriter, _req
 		msg   error
 	)
 	ctx = _req.Context()
-	if _req.Body == nil {
-		http.Error(_writer, "missing request body", http.StatusBadRequest)
-		return
+	if _err := rest.DecodeJSON(_req, &query); _err != nil {
+		rest.HandleBadRequestErr(_err)
 	} else {
-		if _err := json.NewDecoder(_req.Body).Decode(&query); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
-		} else {
-			if _err := rest.ValidateStruct(query); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
-			}
+		if _err := rest.ValidateStruct(query); _err != nil {
+			rest.HandleBadRequestErr(_err)
 		}
 	}
 	code, data, msg = receiver.usersvc.PageUsers(
@@ -53,14 +48,7 @@ func (receiver *UsersvcHandlerImpl) PageUsers(_writer http.ResponseWriter, _req
 		query,
 	)
 	if msg != nil {
-		if errors.Is(msg, context.Canceled) {
-			http.Error(_writer, msg.Error(), http.StatusBadRequest)
-		} else if _err, ok := msg.(*rest.BizError); ok {
-			http.Error(_writer, _err.Error(), _err.StatusCode)
-		} else {
-			http.Error(_writer, msg.Error(), http.StatusInternalServerError)
-		}
-		return
+		panic(msg)
 	}
 	if _err := json.NewEncoder(_writer).Encode(struct {
 		Code int        `json:"code,omitempty"`
@@ -69,8 +57,7 @@ func (receiver *UsersvcHandlerImpl) PageUsers(_writer http.ResponseWriter, _req
 		Code: code,
 		Data: data,
 	}); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusInternalServerError)
-		return
+		rest.HandleInternalServerError(_err)
 	}
 }
 func (receiver *UsersvcHandlerImpl) GetUser(_writer http.ResponseWriter, _req *http.Request) {
@@ -84,28 +71,23 @@ func (receiver *UsersvcHandlerImpl) GetUser(_writer http.ResponseWriter, _req *h
 	)
 	ctx = _req.Context()
 	if _err := _req.ParseForm(); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(_err)
 	}
 	if _, exists := _req.Form["userId"]; exists {
 		userId = _req.FormValue("userId")
 		if _err := rest.ValidateVar(userId, "", "userId"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
-		http.Error(_writer, "missing parameter userId", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter userId"))
 	}
 	if _, exists := _req.Form["photo"]; exists {
 		photo = _req.FormValue("photo")
 		if _err := rest.ValidateVar(photo, "", "photo"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
-		http.Error(_writer, "missing parameter photo", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter photo"))
 	}
 	code, data, msg = receiver.usersvc.GetUser(
 		ctx,
@@ -113,14 +95,7 @@ func (receiver *UsersvcHandlerImpl) GetUser(_writer http.ResponseWriter, _req *h
 		photo,
 	)
 	if msg != nil {
-		if errors.Is(msg, context.Canceled) {
-			http.Error(_writer, msg.Error(), http.StatusBadRequest)
-		} else if _err, ok := msg.(*rest.BizError); ok {
-			http.Error(_writer, _err.Error(), _err.StatusCode)
-		} else {
-			http.Error(_writer, msg.Error(), http.StatusInternalServerError)
-		}
-		return
+		panic(msg)
 	}
 	if _err := json.NewEncoder(_writer).Encode(struct {
 		Code int    `json:"code,omitempty"`
@@ -129,8 +104,7 @@ func (receiver *UsersvcHandlerImpl) GetUser(_writer http.ResponseWriter, _req *h
 		Code: code,
 		Data: data,
 	}); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusInternalServerError)
-		return
+		rest.HandleInternalServerError(_err)
 	}
 }
 func (receiver *UsersvcHandlerImpl) SignUp(_writer http.ResponseWriter, _req *http.Request) {
@@ -146,75 +120,61 @@ func (receiver *UsersvcHandlerImpl) SignUp(_writer http.ResponseWriter, _req *ht
 	)
 	ctx = _req.Context()
 	if _err := _req.ParseForm(); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(_err)
 	}
 	if _, exists := _req.Form["username"]; exists {
 		username = _req.FormValue("username")
 		if _err := rest.ValidateVar(username, "", "username"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
-		http.Error(_writer, "missing parameter username", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter username"))
 	}
 	if _, exists := _req.Form["password"]; exists {
 		if casted, _err := cast.ToIntE(_req.FormValue("password")); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		} else {
 			password = casted
 		}
 		if _err := rest.ValidateVar(password, "", "password"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
-		http.Error(_writer, "missing parameter password", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter password"))
 	}
 	if _, exists := _req.Form["actived"]; exists {
 		if casted, _err := cast.ToBoolE(_req.FormValue("actived")); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		} else {
 			actived = casted
 		}
 		if _err := rest.ValidateVar(actived, "", "actived"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
-		http.Error(_writer, "missing parameter actived", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter actived"))
 	}
 	if _, exists := _req.Form["score"]; exists {
 		if casted, _err := cast.ToIntSliceE(_req.Form["score"]); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		} else {
 			score = casted
 		}
 		if _err := rest.ValidateVar(score, "", "score"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
 		if _, exists := _req.Form["score[]"]; exists {
 			if casted, _err := cast.ToIntSliceE(_req.Form["score[]"]); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			} else {
 				score = casted
 			}
 			if _err := rest.ValidateVar(score, "", "score"); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			}
 		} else {
-			http.Error(_writer, "missing parameter score", http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(errors.New("missing parameter score"))
 		}
 	}
 	code, data, msg = receiver.usersvc.SignUp(
@@ -225,14 +185,7 @@ func (receiver *UsersvcHandlerImpl) SignUp(_writer http.ResponseWriter, _req *ht
 		score,
 	)
 	if msg != nil {
-		if errors.Is(msg, context.Canceled) {
-			http.Error(_writer, msg.Error(), http.StatusBadRequest)
-		} else if _err, ok := msg.(*rest.BizError); ok {
-			http.Error(_writer, _err.Error(), _err.StatusCode)
-		} else {
-			http.Error(_writer, msg.Error(), http.StatusInternalServerError)
-		}
-		return
+		panic(msg)
 	}
 	if _err := json.NewEncoder(_writer).Encode(struct {
 		Code int    `json:"code,omitempty"`
@@ -241,8 +194,7 @@ func (receiver *UsersvcHandlerImpl) SignUp(_writer http.ResponseWriter, _req *ht
 		Code: code,
 		Data: data,
 	}); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusInternalServerError)
-		return
+		rest.HandleInternalServerError(_err)
 	}
 }
 func (receiver *UsersvcHandlerImpl) UploadAvatar(_writer http.ResponseWriter, _req *http.Request) {
@@ -259,20 +211,17 @@ func (receiver *UsersvcHandlerImpl) UploadAvatar(_writer http.ResponseWriter, _r
 	)
 	pc = _req.Context()
 	if _err := _req.ParseMultipartForm(32 << 20); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(_err)
 	}
 	pfFileHeaders, exists := _req.MultipartForm.File["pf"]
 	if exists {
 		if len(pfFileHeaders) == 0 {
-			http.Error(_writer, "no file uploaded for parameter pf", http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(errors.New("no file uploaded for parameter pf"))
 		}
 		for _, _fh := range pfFileHeaders {
 			_f, _err := _fh.Open()
 			if _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			}
 			pf = append(pf, v3.FileModel{
 				Filename: _fh.Filename,
@@ -280,35 +229,29 @@ func (receiver *UsersvcHandlerImpl) UploadAvatar(_writer http.ResponseWriter, _r
 			})
 		}
 	} else {
-		http.Error(_writer, "missing parameter pf", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter pf"))
 	}
 	if _err := _req.ParseForm(); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(_err)
 	}
 	if _, exists := _req.Form["ps"]; exists {
 		ps = _req.FormValue("ps")
 		if _err := rest.ValidateVar(ps, "", "ps"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
-		http.Error(_writer, "missing parameter ps", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter ps"))
 	}
 	pf2FileHeaders, exists := _req.MultipartForm.File["pf2"]
 	if exists {
 		if len(pf2FileHeaders) == 0 {
-			http.Error(_writer, "no file uploaded for parameter pf2", http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(errors.New("no file uploaded for parameter pf2"))
 		}
 		if len(pf2FileHeaders) > 0 {
 			_fh := pf2FileHeaders[0]
 			_f, _err := _fh.Open()
 			if _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			}
 			pf2 = v3.FileModel{
 				Filename: _fh.Filename,
@@ -316,8 +259,7 @@ func (receiver *UsersvcHandlerImpl) UploadAvatar(_writer http.ResponseWriter, _r
 			}
 		}
 	} else {
-		http.Error(_writer, "missing parameter pf2", http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(errors.New("missing parameter pf2"))
 	}
 	pf3Files := _req.MultipartForm.File["pf3"]
 	if len(pf3Files) > 0 {
@@ -333,14 +275,7 @@ func (receiver *UsersvcHandlerImpl) UploadAvatar(_writer http.ResponseWriter, _r
 		pf4,
 	)
 	if re != nil {
-		if errors.Is(re, context.Canceled) {
-			http.Error(_writer, re.Error(), http.StatusBadRequest)
-		} else if _err, ok := re.(*rest.BizError); ok {
-			http.Error(_writer, _err.Error(), _err.StatusCode)
-		} else {
-			http.Error(_writer, re.Error(), http.StatusInternalServerError)
-		}
-		return
+		panic(re)
 	}
 	if _err := json.NewEncoder(_writer).Encode(struct {
 		Ri  int         `json:"ri,omitempty"`
@@ -349,8 +284,7 @@ func (receiver *UsersvcHandlerImpl) UploadAvatar(_writer http.ResponseWriter, _r
 		Ri:  ri,
 		Ri2: ri2,
 	}); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusInternalServerError)
-		return
+		rest.HandleInternalServerError(_err)
 	}
 }
 func (receiver *UsersvcHandlerImpl) DownloadAvatar(_writer http.ResponseWriter, _req *http.Request) {
@@ -358,71 +292,69 @@ func (receiver *UsersvcHandlerImpl) DownloadAvatar(_writer http.ResponseWriter,
 		ctx       context.Context
 		userId    interface{}
 		data      []byte
+		price     decimal.Decimal
 		userAttrs = new([]string)
 		rf        *os.File
 		re        error
 	)
 	ctx = _req.Context()
-	if _req.Body == nil {
-		http.Error(_writer, "missing request body", http.StatusBadRequest)
-		return
+	if _err := rest.DecodeJSON(_req, &userId); _err != nil {
+		rest.HandleBadRequestErr(_err)
 	} else {
-		if _err := json.NewDecoder(_req.Body).Decode(&userId); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
-		} else {
-			if _err := rest.ValidateVar(userId, "", ""); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
-			}
+		if _err := rest.ValidateVar(userId, "", ""); _err != nil {
+			rest.HandleBadRequestErr(_err)
 		}
 	}
 	if _err := _req.ParseForm(); _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusBadRequest)
-		return
+		rest.HandleBadRequestErr(_err)
 	}
 	if _, exists := _req.Form["data"]; exists {
 		if casted, _err := cast.ToByteSliceE(_req.Form["data"]); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		} else {
 			data = casted
 		}
 		if _err := rest.ValidateVar(data, "", "data"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
 		if _, exists := _req.Form["data[]"]; exists {
 			if casted, _err := cast.ToByteSliceE(_req.Form["data[]"]); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			} else {
 				data = casted
 			}
 			if _err := rest.ValidateVar(data, "", "data"); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			}
 		} else {
-			http.Error(_writer, "missing parameter data", http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(errors.New("missing parameter data"))
+		}
+	}
+	if _, exists := _req.Form["price"]; exists {
+		if casted, _err := cast.ToDecimalE(_req.FormValue("price")); _err != nil {
+			rest.HandleBadRequestErr(_err)
+		} else {
+			price = casted
+		}
+		if _err := rest.ValidateVar(price, "", "price"); _err != nil {
+			rest.HandleBadRequestErr(_err)
 		}
+	} else {
+		rest.HandleBadRequestErr(errors.New("missing parameter price"))
 	}
 	if _, exists := _req.Form["userAttrs"]; exists {
 		_userAttrs := _req.Form["userAttrs"]
 		userAttrs = &_userAttrs
 		if _err := rest.ValidateVar(userAttrs, "", "userAttrs"); _err != nil {
-			http.Error(_writer, _err.Error(), http.StatusBadRequest)
-			return
+			rest.HandleBadRequestErr(_err)
 		}
 	} else {
 		if _, exists := _req.Form["userAttrs[]"]; exists {
 			_userAttrs := _req.Form["userAttrs[]"]
 			userAttrs = &_userAttrs
 			if _err := rest.ValidateVar(userAttrs, "", "userAttrs"); _err != nil {
-				http.Error(_writer, _err.Error(), http.StatusBadRequest)
-				return
+				rest.HandleBadRequestErr(_err)
 			}
 		}
 	}
@@ -430,34 +362,61 @@ func (receiver *UsersvcHandlerImpl) DownloadAvatar(_writer http.ResponseWriter,
 		ctx,
 		userId,
 		data,
+		price,
 		*userAttrs...,
 	)
 	if re != nil {
-		if errors.Is(re, context.Canceled) {
-			http.Error(_writer, re.Error(), http.StatusBadRequest)
-		} else if _err, ok := re.(*rest.BizError); ok {
-			http.Error(_writer, _err.Error(), _err.StatusCode)
-		} else {
-			http.Error(_writer, re.Error(), http.StatusInternalServerError)
-		}
-		return
+		panic(re)
 	}
 	if rf == nil {
-		http.Error(_writer, "No file returned", http.StatusInternalServerError)
-		return
+		rest.HandleInternalServerError(errors.New("No file returned"))
 	}
 	defer rf.Close()
 	var _fi os.FileInfo
 	_fi, _err := rf.Stat()
 	if _err != nil {
-		http.Error(_writer, _err.Error(), http.StatusInternalServerError)
-		return
+		rest.HandleInternalServerError(_err)
 	}
 	_writer.Header().Set("Content-Disposition", "attachment; filename="+_fi.Name())
 	_writer.Header().Set("Content-Type", "application/octet-stream")
 	_writer.Header().Set("Content-Length", fmt.Sprintf("%d", _fi.Size()))
 	io.Copy(_writer, rf)
 }
+func (receiver *UsersvcHandlerImpl) GetQuery_range(_writer http.ResponseWriter, _req *http.Request) {
+	var (
+		ctx context.Context
+		re  error
+	)
+	ctx = _req.Context()
+	re = receiver.usersvc.GetQuery_range(
+		ctx,
+	)
+	if re != nil {
+		panic(re)
+	}
+	if _err := json.NewEncoder(_writer).Encode(struct {
+	}{}); _err != nil {
+		rest.HandleInternalServerError(_err)
+	}
+}
+func (receiver *UsersvcHandlerImpl) GetShelves_ShelfBooks_Book(_writer http.ResponseWriter, _req *http.Request) {
+	var (
+		ctx context.Context
+		re  error
+	)
+	paramsFromCtx := httprouter.ParamsFromContext(_req.Context())
+	ctx = _req.Context()
+	re = receiver.usersvc.GetShelves_ShelfBooks_Book(
+		ctx,
+	)
+	if re != nil {
+		panic(re)
+	}
+	if _err := json.NewEncoder(_writer).Encode(struct {
+	}{}); _err != nil {
+		rest.HandleInternalServerError(_err)
+	}
+}
 
 func NewUsersvcHandler(usersvc service.Usersvc) UsersvcHandler {
 	return &UsersvcHandlerImpl{
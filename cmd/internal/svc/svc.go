@@ -91,6 +91,17 @@ type Svc struct {
 	// If true, when you defined a get api with struct type parameter in svc.go file,
 	// it will try to decode json format encoded request body.
 	AllowGetWithReqBody bool
+
+	// Batch indicates whether generate batch endpoint and batch client bundling multiple calls into one request
+	Batch bool
+
+	// IClientMode controls how existing client/iclient.go is treated, overwrite by default
+	IClientMode codegen.OverwriteMode
+
+	// Backends are backend services of generated aggregator client in the form of [serviceName=]dir
+	Backends []string
+	// AggregatorPkg is aggregator client package name
+	AggregatorPkg string
 }
 
 func ValidateDataType(dir string) {
@@ -154,7 +165,16 @@ func (receiver *Svc) Http() {
 		CaseConvertor:       caseConvertor,
 	})
 	if receiver.Client {
-		codegen.GenGoIClient(dir, ic)
+		if err := codegen.GenGoIClient(dir, ic, codegen.GenGoIClientConfig{
+			Mode: receiver.IClientMode,
+		}); err != nil {
+			panic(err)
+		}
+		if err := codegen.GenGoClientMock(dir, ic, codegen.GenGoClientMockConfig{
+			Mode: receiver.IClientMode,
+		}); err != nil {
+			panic(err)
+		}
 		codegen.GenGoClient(dir, ic, codegen.GenGoClientConfig{
 			Env:                  receiver.Env,
 			RoutePatternStrategy: receiver.RoutePatternStrategy,
@@ -163,6 +183,17 @@ func (receiver *Svc) Http() {
 		})
 		codegen.GenGoClientProxy(dir, ic)
 	}
+	if receiver.Batch {
+		batchConfig := codegen.GenHttpBatchConfig{
+			RoutePatternStrategy: receiver.RoutePatternStrategy,
+			Omitempty:            receiver.Omitempty,
+			CaseConvertor:        caseConvertor,
+		}
+		codegen.GenHttpBatch(dir, ic, batchConfig)
+		if receiver.Client {
+			codegen.GenGoClientBatch(dir, ic, batchConfig)
+		}
+	}
 	codegen.GenSvcImpl(dir, ic)
 	codegen.GenDoc(dir, ic, codegen.GenDocConfig{
 		RoutePatternStrategy: receiver.RoutePatternStrategy,
@@ -374,6 +405,15 @@ func (receiver *Svc) GenIntegrationTestingCode() {
 	codegen.GenHttpIntegrationTesting(receiver.dir, ic, receiver.PostmanCollectionPath, receiver.DotenvPath)
 }
 
+// GenAggregator generates aggregator client code which calls several backend services concurrently for BFF
+func (receiver *Svc) GenAggregator() {
+	var backends []codegen.AggregatorBackend
+	for _, item := range receiver.Backends {
+		backends = append(backends, codegen.ParseAggregatorBackend(item))
+	}
+	codegen.GenAggregator(receiver.dir, receiver.AggregatorPkg, backends)
+}
+
 func (receiver *Svc) DoRun() {
 	err := receiver.runner.Run("go", "build", filepath.FromSlash("cmd/main.go"))
 	if err != nil {
@@ -7,10 +7,12 @@ import (
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/astutils"
 	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/executils"
 	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc"
+	"github.com/unionj-cloud/go-doudou/v2/cmd/internal/svc/codegen"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/pathutils"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -453,3 +455,61 @@ func TestNewSvc(t *testing.T) {
 		svc.NewSvc("")
 	})
 }
+
+func TestSvc_Check(t *testing.T) {
+	dir := testDir + "check"
+	receiver := svc.NewSvc(dir)
+	s := receiver.(*svc.Svc)
+	s.Client = true
+	assert.NotPanics(t, func() {
+		receiver.Init()
+	})
+	defer os.RemoveAll(dir)
+	assert.NotPanics(t, func() {
+		receiver.Http()
+	})
+	assert.NoError(t, s.Check())
+
+	svcfile := filepath.Join(dir, "svc.go")
+	source, err := os.ReadFile(svcfile)
+	assert.NoError(t, err)
+	source = []byte(strings.Replace(string(source), "PageUsers(ctx context.Context, query dto.PageQuery) (data dto.PageRet, err error)",
+		"PageUsers(ctx context.Context, query dto.PageQuery) (data dto.PageRet, err error)\n\tGetUser(ctx context.Context, id int) (data string, err error)", 1))
+	assert.NoError(t, os.WriteFile(svcfile, source, os.ModePerm))
+	assert.ErrorIs(t, s.Check(), svc.ErrStale)
+}
+
+func TestSvc_Check_NoInterface(t *testing.T) {
+	dir := filepath.Join(testDir, "checknointerface")
+	assert.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	defer os.RemoveAll(dir)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "svc.go"), []byte("package service\n"), os.ModePerm))
+	s := svc.NewSvc(dir).(*svc.Svc)
+	err := s.Check()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, svc.ErrStale)
+}
+
+func TestSvc_Check_LegacyOptions(t *testing.T) {
+	dir := testDir + "checklegacy"
+	receiver := svc.NewSvc(dir)
+	s := receiver.(*svc.Svc)
+	s.Client = true
+	assert.NotPanics(t, func() {
+		receiver.Init()
+	})
+	defer os.RemoveAll(dir)
+	assert.NotPanics(t, func() {
+		receiver.Http()
+	})
+	iclient := filepath.Join(dir, "client", "iclient.go")
+	source, err := os.ReadFile(iclient)
+	assert.NoError(t, err)
+	source = append(source, []byte("\ntype Options struct {\n\tGzipReqBody bool\n}\n")...)
+	assert.NoError(t, os.WriteFile(iclient, source, os.ModePerm))
+
+	s.IClientMode = codegen.SkipIfExists
+	err = s.Check()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "declares Options")
+}
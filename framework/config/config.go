@@ -0,0 +1,51 @@
+// Package config provides a typed snapshot of all GDD_* configs, so that application code reads fields
+// of Config instead of parsing environment variables again and again.
+package config
+
+import (
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"sync"
+)
+
+// Config is a typed snapshot of all GDD_* configs. Field names are config keys without GDD_ prefix
+// in camel case, e.g. GraceTimeout for GDD_GRACE_TIMEOUT.
+type Config = config.Config
+
+// Snapshot loads all GDD_* configs into a new Config, falling back to defaults for configs not set.
+// It returns an error listing all invalid configs, together with a Config filled by defaults for them.
+// Configs changed by hot-reload from remote config center after Snapshot returned are not reflected in
+// the returned Config, call Snapshot again or Reload to get changes.
+func Snapshot() (*Config, error) {
+	return config.Snapshot()
+}
+
+var (
+	current *Config
+	lock    sync.RWMutex
+)
+
+// Get returns Config loaded by the first call of Get or the latest call of Reload.
+// Invalid configs fall back to defaults, they have been warned about on startup.
+func Get() *Config {
+	lock.RLock()
+	c := current
+	lock.RUnlock()
+	if c != nil {
+		return c
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if current == nil {
+		current, _ = config.Snapshot()
+	}
+	return current
+}
+
+// Reload loads a new Config returned by subsequent calls of Get
+func Reload() (*Config, error) {
+	c, err := config.Snapshot()
+	lock.Lock()
+	defer lock.Unlock()
+	current = c
+	return c, err
+}
@@ -3,6 +3,8 @@ package framework
 import (
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"os"
+	"strings"
+	"sync/atomic"
 )
 
 type Annotation struct {
@@ -33,3 +35,25 @@ func (receiver AnnotationStore) GetParams(key string, annotationName string) []s
 func CheckDev() bool {
 	return stringutils.IsEmpty(os.Getenv("GDD_ENV")) || os.Getenv("GDD_ENV") == "dev"
 }
+
+var configPrefix atomic.Value
+
+// SetConfigPrefix sets an instance prefix for all GDD_* config keys, e.g. if prefix is MYSVC,
+// MYSVC_GDD_PORT will take precedence over GDD_PORT. It takes precedence over GDD_CONFIG_PREFIX
+// environment variable. Pass empty string to fall back to GDD_CONFIG_PREFIX.
+func SetConfigPrefix(prefix string) {
+	configPrefix.Store(prefix)
+}
+
+// ConfigPrefix returns normalized config key prefix with trailing underscore, or empty string if not set
+func ConfigPrefix() string {
+	prefix, _ := configPrefix.Load().(string)
+	if stringutils.IsEmpty(prefix) {
+		prefix = os.Getenv("GDD_CONFIG_PREFIX")
+	}
+	prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "_")
+	if stringutils.IsEmpty(prefix) {
+		return ""
+	}
+	return strings.ToUpper(prefix) + "_"
+}
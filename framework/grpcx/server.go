@@ -17,6 +17,7 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -78,7 +79,9 @@ func (srv *GrpcServer) printServices() {
 // Run runs grpc server
 func (srv *GrpcServer) Run() {
 	banner.Print()
-	register.NewGrpc(srv.data)
+	if err := register.NewGrpc(srv.data); err != nil {
+		logger.Panic().Err(err).Msg("")
+	}
 	port := config.DefaultGddGrpcPort
 	if p, err := cast.ToIntE(config.GddGrpcPort.Load()); err == nil {
 		port = p
@@ -98,7 +101,15 @@ func (srv *GrpcServer) Run() {
 	}()
 
 	defer func() {
-		register.ShutdownGrpc()
+		shutdownCtx, shutdownCancel := register.ShutdownContext()
+		defer shutdownCancel()
+		if err := timeutils.CallWithCtx(shutdownCtx, func() struct{} {
+			register.ShutdownGrpc()
+			return struct{}{}
+		}); err != nil {
+			logger.Error().Err(err).Msg("[go-doudou] shutdown: leaving service registries timed out")
+		}
+		register.WaitLeavePropagation(shutdownCtx)
 
 		grace, err := time.ParseDuration(config.GddGraceTimeout.Load())
 		if err != nil {
@@ -108,7 +119,7 @@ func (srv *GrpcServer) Run() {
 		}
 		logger.Info().Msgf("Grpc server is gracefully shutting down in %s", grace)
 
-		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		ctx, cancel := context.WithTimeout(shutdownCtx, grace)
 		defer cancel()
 		if err := timeutils.CallWithCtx(ctx, func() struct{} {
 			srv.GracefulStop()
@@ -119,9 +130,9 @@ func (srv *GrpcServer) Run() {
 	}()
 
 	c := make(chan os.Signal, 1)
-	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-	signal.Notify(c, os.Interrupt)
+	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C) or SIGTERM (e.g. sent by kubernetes).
+	// SIGKILL or SIGQUIT (Ctrl+/) will not be caught.
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	// Block until we receive our signal.
 	<-c
@@ -6,11 +6,46 @@ import (
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"path"
+	"strings"
 	"sync"
 )
 
 var once sync.Once
 
+// colors are supported by figure.NewColorFigure, which exits the process on other colors
+var colors = map[string]struct{}{
+	"red":    {},
+	"green":  {},
+	"yellow": {},
+	"blue":   {},
+	"purple": {},
+	"cyan":   {},
+	"gray":   {},
+	"white":  {},
+}
+
+// bannerFont returns GddBannerFont if figure library has it, otherwise DefaultGddBannerFont
+func bannerFont() string {
+	font := config.GddBannerFont.LoadOrDefault(config.DefaultGddBannerFont)
+	if _, err := figure.Asset(path.Join("fonts", font+".flf")); err != nil {
+		logger.Warn().Msgf("[go-doudou] unknown %s %s, use %s instead", string(config.GddBannerFont), font, config.DefaultGddBannerFont)
+		return config.DefaultGddBannerFont
+	}
+	return font
+}
+
+// bannerColor returns GddBannerColor if it is supported, otherwise DefaultGddBannerColor
+func bannerColor() string {
+	color := strings.ToLower(config.GddBannerColor.LoadOrDefault(config.DefaultGddBannerColor))
+	if _, ok := colors[color]; !ok {
+		logger.Warn().Msgf("[go-doudou] unknown %s %s, use %s instead", string(config.GddBannerColor), color, config.DefaultGddBannerColor)
+		return config.DefaultGddBannerColor
+	}
+	return color
+}
+
 func Print() {
 	once.Do(func() {
 		if !framework.CheckDev() {
@@ -25,7 +60,7 @@ func Print() {
 			if stringutils.IsNotEmpty(config.GddBannerText.Load()) {
 				bannerText = config.GddBannerText.Load()
 			}
-			figure.NewColorFigure(bannerText, "doom", "green", true).Print()
+			figure.NewColorFigure(bannerText, bannerFont(), bannerColor(), true).Print()
 		}
 	})
 }
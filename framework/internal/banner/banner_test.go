@@ -0,0 +1,25 @@
+package banner
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"os"
+	"testing"
+)
+
+func Test_bannerFont_bannerColor(t *testing.T) {
+	defer os.Unsetenv(string(config.GddBannerFont))
+	defer os.Unsetenv(string(config.GddBannerColor))
+	require.Equal(t, "doom", bannerFont())
+	require.Equal(t, "green", bannerColor())
+
+	config.GddBannerFont.Write("slant")
+	config.GddBannerColor.Write("Red")
+	require.Equal(t, "slant", bannerFont())
+	require.Equal(t, "red", bannerColor())
+
+	config.GddBannerFont.Write("no-such-font")
+	config.GddBannerColor.Write("pink")
+	require.Equal(t, "doom", bannerFont())
+	require.Equal(t, "green", bannerColor())
+}
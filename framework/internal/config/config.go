@@ -17,13 +17,20 @@ import (
 	"github.com/wubin1989/nacos-sdk-go/v2/common/constant"
 	"github.com/wubin1989/nacos-sdk-go/v2/vo"
 	_ "go.uber.org/automaxprocs"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
 	"net"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 )
 
+// LoadConfigFromLocal loads local config files for env GDD_ENV, dev by default, into environment variables. It is called
+// by init before any config is read. Real environment variables take precedence over all files, then yaml files, then
+// dotenv files in order of precedence documented by dotenv.Load, e.g. .env.prod overrides .env if GDD_ENV is prod.
+// Missing files are skipped.
 func LoadConfigFromLocal() {
 	env := os.Getenv("GDD_ENV")
 	if "" == env {
@@ -31,6 +38,115 @@ func LoadConfigFromLocal() {
 	}
 	yaml.Load(env)
 	dotenv.Load(env)
+	values := readLocal(env)
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	for _, key := range HotReloadable {
+		if value, ok := values[string(key)]; ok && !isRealEnv(key) {
+			localValues[key] = value
+		}
+	}
+}
+
+// realEnv holds names of environment variables set before local config files are loaded. They always take
+// precedence over local config files, on reload too.
+var realEnv = environKeys()
+
+// localValues holds values of HotReloadable keys last loaded from local config files, so that keys removed from
+// the files can be unset on reload
+var localValues = make(map[envVariable]string)
+
+// reloadLock serializes ReloadLocal calls
+var reloadLock sync.Mutex
+
+func environKeys() map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, kv := range os.Environ() {
+		keys[strings.SplitN(kv, "=", 2)[0]] = struct{}{}
+	}
+	return keys
+}
+
+func isRealEnv(key envVariable) bool {
+	_, ok := realEnv[key.key()]
+	return ok
+}
+
+// readLocal reads local yaml and dotenv config files for env, yaml files take precedence
+func readLocal(env string) map[string]string {
+	values := yaml.Read(env)
+	for k, v := range dotenv.Read(env) {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// HotReloadable lists config keys which can be reloaded by SIGHUP signal without restarting
+var HotReloadable = []envVariable{
+	GddLogLevel,
+	GddLogAccessLevel,
+	GddMemLogLevel,
+	GddManageUser,
+	GddManagePass,
+	GddMaintenanceEnable,
+	GddMaintenanceMessage,
+	GddMaintenanceRetryAfter,
+}
+
+// ReloadLocal re-reads local yaml and dotenv config files with the same precedence as LoadConfigFromLocal,
+// then writes values of HotReloadable keys to environment variables. Keys set by real environment variables at
+// startup are never overridden, and keys removed from the files since last load are unset. It returns keys whose
+// values changed. Other changed GDD_* keys like GDD_PORT can't take effect without restarting, so they are logged
+// as ignored.
+func ReloadLocal() []envVariable {
+	env := os.Getenv("GDD_ENV")
+	if "" == env {
+		env = "dev"
+	}
+	values := readLocal(env)
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	hot := make(map[envVariable]struct{})
+	var changed []envVariable
+	for _, key := range HotReloadable {
+		hot[key] = struct{}{}
+		value, ok := values[string(key)]
+		if isRealEnv(key) {
+			if ok && value != key.Load() {
+				zlogger.Warn().Msgf("[go-doudou] %s is set by environment variable, change in config files ignored", string(key))
+			}
+			continue
+		}
+		if !ok {
+			// only unset value loaded from local config files, not the one set by remote config or code since then
+			if loaded, ok := localValues[key]; ok && loaded == key.Load() {
+				_ = os.Unsetenv(key.key())
+				changed = append(changed, key)
+				zlogger.Info().Msgf("[go-doudou] %s removed from config files, unset", string(key))
+			}
+			delete(localValues, key)
+			continue
+		}
+		localValues[key] = value
+		if value == key.Load() {
+			continue
+		}
+		_ = key.Write(value)
+		changed = append(changed, key)
+		zlogger.Info().Msgf("[go-doudou] %s reloaded", string(key))
+	}
+	for k, v := range values {
+		key := envVariable(k)
+		if _, ok := hot[key]; ok || !strings.HasPrefix(k, "GDD_") {
+			continue
+		}
+		if v != key.Load() {
+			zlogger.Warn().Msgf("[go-doudou] %s changed but can't be reloaded without restarting, ignored", k)
+		}
+	}
+	return changed
 }
 
 func LoadConfigFromRemote() {
@@ -93,7 +209,75 @@ func init() {
 		zlogger.WithDiscard(cast.ToBoolOrDefault(GddLogDiscard.Load(), DefaultGddLogDiscard)),
 		zlogger.WithZeroLogLevel(zl),
 	}
+	if w := LogFileWriter(); w != nil {
+		opts = append(opts, zlogger.WithWriter(w))
+		logrus.SetOutput(w)
+	}
 	zlogger.InitEntry(zlogger.NewLoggerConfig(opts...))
+	ApplyLogLevels()
+	if _, err := Snapshot(); err != nil {
+		zlogger.Warn().Err(err).Msg("[go-doudou] defaults are used for invalid configs")
+	}
+}
+
+const (
+	// LogSubsystemAccess is name of log subsystem of http access logs
+	LogSubsystemAccess = "access"
+	// LogSubsystemMemberlist is name of log subsystem of memberlist gossip logs
+	LogSubsystemMemberlist = "memberlist"
+)
+
+// LogSubsystems maps names of log subsystems to config keys of their levels
+var LogSubsystems = map[string]envVariable{
+	LogSubsystemAccess:     GddLogAccessLevel,
+	LogSubsystemMemberlist: GddMemLogLevel,
+}
+
+// ApplyLogLevels sets levels of log subsystems from their configs. Subsystems whose level is not set or invalid
+// follow level of application logs.
+func ApplyLogLevels() {
+	for name, key := range LogSubsystems {
+		subsystem := zlogger.NewSubsystem(name)
+		if stringutils.IsEmpty(key.Load()) {
+			subsystem.ResetLevel()
+			continue
+		}
+		level, err := zerolog.ParseLevel(key.Load())
+		if err != nil {
+			zlogger.Warn().Err(err).Msgf("[go-doudou] invalid %s, follow %s instead", key.key(), GddLogLevel.key())
+			subsystem.ResetLevel()
+			continue
+		}
+		subsystem.SetLevel(level)
+	}
+}
+
+// LogFileWriter returns a rotating file writer if GddLogFile is set, otherwise returns nil
+func LogFileWriter() io.Writer {
+	logFile := GddLogFile.LoadOrDefault(DefaultGddLogFile)
+	if stringutils.IsEmpty(logFile) {
+		return nil
+	}
+	maxSize := DefaultGddLogMaxSize
+	if size, err := cast.ToIntE(GddLogMaxSize.Load()); err == nil {
+		maxSize = size
+	}
+	maxAge := DefaultGddLogMaxAge
+	if age, err := cast.ToIntE(GddLogMaxAge.Load()); err == nil {
+		maxAge = age
+	}
+	maxBackups := DefaultGddLogMaxBackups
+	if backups, err := cast.ToIntE(GddLogMaxBackups.Load()); err == nil {
+		maxBackups = backups
+	}
+	return &lumberjack.Logger{
+		Filename:   logFile,
+		MaxSize:    maxSize,
+		MaxAge:     maxAge,
+		MaxBackups: maxBackups,
+		Compress:   cast.ToBoolOrDefault(GddLogCompress.Load(), DefaultGddLogCompress),
+		LocalTime:  true,
+	}
 }
 
 type envVariable string
@@ -108,21 +292,94 @@ const (
 )
 
 const (
+	// GddConfigPrefix sets instance prefix for all config keys, e.g. MYSVC, then MYSVC_GDD_PORT takes precedence over GDD_PORT.
+	// It is useful when multiple go-doudou services are configured via environment variables on one host.
+	// It can also be set by framework.SetConfigPrefix function.
+	GddConfigPrefix envVariable = "GDD_CONFIG_PREFIX"
 	// GddBanner indicates banner enabled or not
 	GddBanner envVariable = "GDD_BANNER"
 	// GddBannerText sets text content of banner
 	GddBannerText envVariable = "GDD_BANNER_TEXT"
+	// GddBannerFont sets figlet font of banner, e.g. doom, standard, slant. Unknown fonts fall back to doom.
+	GddBannerFont envVariable = "GDD_BANNER_FONT"
+	// GddBannerColor sets color of banner, accepts red, green, yellow, blue, purple, cyan, gray and white.
+	// Unknown colors fall back to green.
+	GddBannerColor envVariable = "GDD_BANNER_COLOR"
 	// GddLogLevel accepts panic, fatal, error, warn, warning, info, debug, trace, disabled. please reference zerolog.ParseLevel
 	GddLogLevel envVariable = "GDD_LOG_LEVEL"
+	// GddLogAccessLevel sets level of http access logs, including request logs and slow request logs.
+	// It accepts the same values as GddLogLevel and follows GddLogLevel if not set.
+	GddLogAccessLevel envVariable = "GDD_LOG_ACCESS_LEVEL"
 	// GddLogFormat text or json
 	// Deprecated: move to zerolog
 	GddLogFormat envVariable = "GDD_LOG_FORMAT"
 	// GddLogReqEnable enables request and response logging
 	GddLogReqEnable envVariable = "GDD_LOG_REQ_ENABLE"
+	// GddLogReqFormat sets format of request logs enabled by GddLogReqEnable. text logs request and response
+	// with bodies and headers as indented json message, json logs one line per request with method, path, status code,
+	// bytes written, remote address, request id and duration as structured fields, for ingestion into Loki or ELK.
+	GddLogReqFormat envVariable = "GDD_LOG_REQ_FORMAT"
 	GddLogCaller    envVariable = "GDD_LOG_CALLER"
 	GddLogDiscard   envVariable = "GDD_LOG_DISCARD"
+	// GddLogFile sets log output file path, logs will be written to stdout if not set
+	GddLogFile envVariable = "GDD_LOG_FILE"
+	// GddLogMaxSize is the maximum size in megabytes of the log file before it gets rotated
+	GddLogMaxSize envVariable = "GDD_LOG_MAX_SIZE"
+	// GddLogMaxAge is the maximum number of days to retain old log files, 0 means not to remove old log files based on age
+	GddLogMaxAge envVariable = "GDD_LOG_MAX_AGE"
+	// GddLogMaxBackups is the maximum number of old log files to retain, 0 means to retain all old log files
+	GddLogMaxBackups envVariable = "GDD_LOG_MAX_BACKUPS"
+	// GddLogCompress determines if the rotated log files should be compressed using gzip
+	GddLogCompress envVariable = "GDD_LOG_COMPRESS"
+	// GddRecentRequestsEnable keeps summaries of recent requests in memory for live debugging,
+	// exposed by /go-doudou/requests endpoint
+	GddRecentRequestsEnable envVariable = "GDD_RECENT_REQUESTS_ENABLE"
+	// GddRecentRequestsSize sets how many recent requests to keep, capped by 10000
+	GddRecentRequestsSize envVariable = "GDD_RECENT_REQUESTS_SIZE"
+	// GddSlowRequestThreshold logs requests taking longer than it at warn level, e.g. 500ms. Set to 0s or leave empty to disable.
+	GddSlowRequestThreshold envVariable = "GDD_SLOW_REQUEST_THRESHOLD"
+	// GddSlowRequestDetail accepts basic or full. full also logs query params, request headers listed in
+	// GddSlowRequestHeaders and duration breakdown.
+	GddSlowRequestDetail envVariable = "GDD_SLOW_REQUEST_DETAIL"
+	// GddSlowRequestHeaders sets comma separated request headers logged by slow request log in full detail level
+	GddSlowRequestHeaders envVariable = "GDD_SLOW_REQUEST_HEADERS"
+	// GddRateLimitEnable limits requests of each client ip to biz routes by token bucket algorithm.
+	// Requests exceeding the limit are rejected with 429 status code.
+	GddRateLimitEnable envVariable = "GDD_RATE_LIMIT_ENABLE"
+	// GddRateLimitRate sets requests per second allowed for each client ip
+	GddRateLimitRate envVariable = "GDD_RATE_LIMIT_RATE"
+	// GddRateLimitBurst sets max burst of requests allowed for each client ip
+	GddRateLimitBurst envVariable = "GDD_RATE_LIMIT_BURST"
+	// GddTrustedProxies sets comma separated CIDRs or ips of trusted reverse proxies. Client ip is taken from
+	// X-Forwarded-For or X-Real-IP header only if the request is from one of them, e.g. for rate limit by ip.
+	// Default is empty, which trusts no proxy, so client ip is always the peer address of the connection.
+	GddTrustedProxies envVariable = "GDD_TRUSTED_PROXIES"
+	// GddBatchMaxItems sets max number of calls bundled in one request to generated batch endpoint
+	GddBatchMaxItems envVariable = "GDD_BATCH_MAX_ITEMS"
+	// GddShutdownTimeout sets the budget of the whole shutdown sequence on SIGTERM, including leaving service registries,
+	// waiting for the leave to propagate, graceful shutdown and draining long-lived connections. Each step gets what is
+	// left of the budget at most. Keep it under terminationGracePeriodSeconds of kubernetes pods, which is 30s by default.
+	GddShutdownTimeout envVariable = "GDD_SHUTDOWN_TIMEOUT"
 	// GddGraceTimeout sets graceful shutdown timeout
 	GddGraceTimeout envVariable = "GDD_GRACE_TIMEOUT"
+	// GddDrainTimeout sets how long to wait for long-lived connections such as SSE and WebSocket
+	// after graceful shutdown timeout expired before forcibly closing them
+	GddDrainTimeout envVariable = "GDD_DRAIN_TIMEOUT"
+	// GddRegistryLeaveWait sets how long to wait after leaving service registries before shutting down server,
+	// so that other nodes have a chance to stop routing requests to this node. Set to 0s to disable.
+	// It is ignored if no service discovery mode is enabled.
+	GddRegistryLeaveWait envVariable = "GDD_REGISTRY_LEAVE_WAIT"
+	// GddPreStopDelay sets how long to keep serving after leaving service registries and before starting graceful shutdown,
+	// so that load balancers like kubernetes service have time to stop routing new connections to this node.
+	// It shares one phase with GddRegistryLeaveWait, the longer one is waited. 0 disables the delay.
+	GddPreStopDelay envVariable = "GDD_PRE_STOP_DELAY"
+	// GddDrainPropagationWait sets how long DrainAndLeave waits for draining status of local node to be gossiped
+	// to other nodes before flipping readiness to false
+	GddDrainPropagationWait envVariable = "GDD_DRAIN_PROPAGATION_WAIT"
+	// GddDrainInFlightTimeout sets max time DrainAndLeave waits for in-flight requests to complete
+	GddDrainInFlightTimeout envVariable = "GDD_DRAIN_INFLIGHT_TIMEOUT"
+	// GddDrainLeaveTimeout sets max time DrainAndLeave waits for leave message to be broadcast to other nodes
+	GddDrainLeaveTimeout envVariable = "GDD_DRAIN_LEAVE_TIMEOUT"
 	// GddWriteTimeout sets http connection write timeout
 	GddWriteTimeout envVariable = "GDD_WRITE_TIMEOUT"
 	// GddReadTimeout sets http connection read timeout
@@ -131,24 +388,98 @@ const (
 	GddIdleTimeout envVariable = "GDD_IDLE_TIMEOUT"
 	// GddRouteRootPath sets root path for all routes
 	GddRouteRootPath envVariable = "GDD_ROUTE_ROOT_PATH"
+	// GddRouteManifest sets path of file which routes are written into as json on startup, e.g. for api gateways.
+	// No file is written if empty.
+	GddRouteManifest envVariable = "GDD_ROUTE_MANIFEST"
+	// GddExternalBasePath sets external base path prefixed by reverse proxy in front of the service, e.g. /api/user-svc.
+	// It is used by online api document to resolve openapi.json and servers url correctly behind a proxy.
+	// If empty or not set, X-Forwarded-Prefix request header will be used instead if present.
+	GddExternalBasePath envVariable = "GDD_EXTERNAL_BASE_PATH"
 	// GddServiceName sets service name
 	GddServiceName    envVariable = "GDD_SERVICE_NAME"
 	GddServiceGroup   envVariable = "GDD_SERVICE_GROUP"
 	GddServiceVersion envVariable = "GDD_SERVICE_VERSION"
 	// GddHost sets bind host for http server
 	GddHost envVariable = "GDD_HOST"
-	// GddPort sets bind port for http server
+	// GddPort sets bind port for http server. 0 means binding a free port picked by kernel, the actual port is
+	// written back to GddPort before registering the service to service registries.
 	GddPort envVariable = "GDD_PORT"
+	// GddBindRetry binds http server and memberlist to a free port picked by kernel if configured port is
+	// already in use, which reduces flaky startups when many services start concurrently, e.g. in CI.
+	// The actual port is registered to service registry.
+	GddBindRetry envVariable = "GDD_BIND_RETRY"
+	// GddTLSEnable enables serving https with GddTLSCert and GddTLSKey. If not set, https is enabled
+	// when both GddTLSCert and GddTLSKey are set.
+	GddTLSEnable envVariable = "GDD_TLS_ENABLE"
+	// GddTLSCert sets certificate file path for serving https
+	GddTLSCert envVariable = "GDD_TLS_CERT"
+	// GddTLSKey sets private key file path for serving https
+	GddTLSKey envVariable = "GDD_TLS_KEY"
+	// GddClientCAFile sets CA certificates file path for verifying client certificates (mutual TLS).
+	// Only works when https is enabled.
+	GddClientCAFile envVariable = "GDD_CLIENT_CA_FILE"
+	// GddClientAuthMode has two options available: require_and_verify, verify_if_given
+	GddClientAuthMode envVariable = "GDD_CLIENT_AUTH_MODE"
+	// GddHttp3Enable enables experimental HTTP/3 server listening on udp port of the same number as GddPort,
+	// alongside http server. It requires GddTLSCert, GddTLSKey and a server factory registered by
+	// rest.RegisterHTTP3ServerFactory
+	GddHttp3Enable envVariable = "GDD_HTTP3_ENABLE"
+	// GddEnableH2C enables serving HTTP/2 over cleartext (h2c) alongside HTTP/1.1, e.g. for gateways speaking
+	// HTTP/2 without tls. It is ignored if https is enabled, as HTTP/2 is negotiated by tls then.
+	GddEnableH2C envVariable = "GDD_ENABLE_H2C"
 	// GddGrpcPort sets bind port for grpc server
 	GddGrpcPort envVariable = "GDD_GRPC_PORT"
 	// GddManage if true, it will add built-in apis with /go-doudou path prefix for online api document and service status monitor etc.
 	GddManage envVariable = "GDD_MANAGE_ENABLE"
+	// GddJwtSigningKey is the HMAC key verifying signature of bearer tokens by JWTAuthenticatorFromConfig
+	GddJwtSigningKey envVariable = "GDD_JWT_SIGNING_KEY"
+	// GddJwtJwksURL is the JWKS endpoint serving RSA public keys verifying signature of bearer tokens by
+	// JWTAuthenticatorFromConfig, used if GddJwtSigningKey is not set
+	GddJwtJwksURL envVariable = "GDD_JWT_JWKS_URL"
+	// GddJwtRequireExp makes JWTAuthenticatorFromConfig reject bearer tokens without exp claim
+	GddJwtRequireExp envVariable = "GDD_JWT_REQUIRE_EXP"
 	// GddManageUser manage api endpoint http basic auth user
 	GddManageUser envVariable = "GDD_MANAGE_USER"
 	// GddManagePass manage api endpoint http basic auth password
 	GddManagePass envVariable = "GDD_MANAGE_PASS"
+	// GddManageAllowedIPs is a comma separated list of CIDRs like 10.0.0.0/8,fd00::/8, if set, only requests from these
+	// ranges are allowed to access management routes besides basic auth. Single ip without prefix length is accepted too.
+	GddManageAllowedIPs envVariable = "GDD_MANAGE_ALLOWED_IPS"
+	// GddManageDoc mounts online api document routes /go-doudou/doc and /go-doudou/openapi.json if GddManage is enabled
+	GddManageDoc envVariable = "GDD_MANAGE_DOC"
+	// GddManageConfigUI mounts config viewing route /go-doudou/config if GddManage is enabled
+	GddManageConfigUI envVariable = "GDD_MANAGE_CONFIG_UI"
+	// GddManageStatsviz mounts runtime statistics visualization routes /go-doudou/statsviz if GddManage is enabled
+	GddManageStatsviz envVariable = "GDD_MANAGE_STATSVIZ"
+	// GddManagePprof mounts profiling routes /debug/pprof and /go-doudou/profile if GddManage is enabled
+	GddManagePprof envVariable = "GDD_MANAGE_PPROF"
+	// GddHealthCheckTimeout sets timeout of each HealthChecker run by health check endpoint, a checker not returning
+	// within the timeout is reported down
+	GddHealthCheckTimeout envVariable = "GDD_HEALTH_CHECK_TIMEOUT"
+	// GddMaintenanceEnable turns on maintenance mode on startup or on SIGHUP reload
+	GddMaintenanceEnable envVariable = "GDD_MAINTENANCE_ENABLE"
+	// GddMaintenanceMessage is the default response message when maintenance mode is enabled
+	GddMaintenanceMessage envVariable = "GDD_MAINTENANCE_MESSAGE"
+	// GddMaintenanceRetryAfter is the default Retry-After response header value when maintenance mode is enabled
+	GddMaintenanceRetryAfter envVariable = "GDD_MAINTENANCE_RETRY_AFTER"
+	// GddProfileMaxDuration caps duration of cpu profile captured by /go-doudou/profile endpoint
+	GddProfileMaxDuration envVariable = "GDD_PROFILE_MAX_DURATION"
 
 	GddEnableResponseGzip envVariable = "GDD_ENABLE_RESPONSE_GZIP"
+	// GddGzipContentTypes replaces the built-in list of response content types to be compressed if set, comma separated,
+	// e.g. application/json,application/x-ndjson
+	GddGzipContentTypes envVariable = "GDD_GZIP_CONTENT_TYPES"
+	// GddGzipMinLength sets min size in bytes of responses to be compressed
+	GddGzipMinLength envVariable = "GDD_GZIP_MIN_LENGTH"
+	// GddErrorDetailsEnable returns error chain with stack trace in error response even if GDD_ENV is not dev
+	GddErrorDetailsEnable envVariable = "GDD_ERROR_DETAILS_ENABLE"
+	// GddMaxBodySize sets max size in bytes of request body of biz routes, 0 means unlimited. Requests with larger body
+	// are rejected with 413 status code. It can be overridden by MaxBodySize of rest.Route.
+	GddMaxBodySize envVariable = "GDD_MAX_BODY_SIZE"
+	// GddHandlerTimeout sets max processing time of requests to biz routes, e.g. 30s, 0 means no timeout. Requests
+	// taking longer are responded with 503 status code, and their context is canceled. It can be overridden by
+	// Timeout of rest.Route.
+	GddHandlerTimeout envVariable = "GDD_HANDLER_TIMEOUT"
 	// Deprecated: move to GddFallbackContentType
 	GddAppType envVariable = "GDD_APP_TYPE"
 	// GddFallbackContentType fallback response content-type header value
@@ -160,6 +491,32 @@ const (
 
 	GddRetryCount         envVariable = "GDD_RETRY_COUNT"
 	GddTracingMetricsRoot envVariable = "GDD_TRACING_METRICS_ROOT"
+	// GddTracingProvider selects tracing middleware of http server, accepts jaeger and otel. jaeger by default.
+	// otel starts an OpenTelemetry server span for each request with W3C traceparent propagation, exported by
+	// global TracerProvider of go.opentelemetry.io/otel, so register one with OTLP exporter in main function.
+	GddTracingProvider envVariable = "GDD_TRACING_PROVIDER"
+	// GddRetryMaxWait caps waiting time between retries of http clients, including waiting time indicated by
+	// Retry-After header of 429 and 503 responses
+	GddRetryMaxWait envVariable = "GDD_RETRY_MAX_WAIT"
+	// GddClientMaxIdleConns sets max idle connections across all hosts of http clients
+	GddClientMaxIdleConns envVariable = "GDD_CLIENT_MAX_IDLE_CONNS"
+	// GddClientMaxIdleConnsPerHost sets max idle connections per host of http clients. It is much higher than
+	// net/http's default 2 to avoid connection churn under load of service-to-service calls.
+	GddClientMaxIdleConnsPerHost envVariable = "GDD_CLIENT_MAX_IDLE_CONNS_PER_HOST"
+	// GddClientMaxConnsPerHost limits total connections per host of http clients, 0 or negative means no limit
+	GddClientMaxConnsPerHost envVariable = "GDD_CLIENT_MAX_CONNS_PER_HOST"
+	// GddClientIdleConnTimeout sets how long an idle connection of http clients is kept in pool
+	GddClientIdleConnTimeout envVariable = "GDD_CLIENT_IDLE_CONN_TIMEOUT"
+	// GddClientDialTimeout sets timeout of establishing tcp connections of http clients
+	GddClientDialTimeout envVariable = "GDD_CLIENT_DIAL_TIMEOUT"
+	// GddClientTLSHandshakeTimeout sets timeout of tls handshake of http clients
+	GddClientTLSHandshakeTimeout envVariable = "GDD_CLIENT_TLS_HANDSHAKE_TIMEOUT"
+	// GddTraceSampleRatio sets fraction of requests to be traced from 0 to 1. Requests carrying sampled
+	// trace context from upstream are always traced.
+	GddTraceSampleRatio envVariable = "GDD_TRACE_SAMPLE_RATIO"
+	// GddPromExemplarEnable attaches trace id as exemplar to http request duration histogram.
+	// Prometheus server should be started with --enable-feature=exemplar-storage to scrape them.
+	GddPromExemplarEnable envVariable = "GDD_PROM_EXEMPLAR_ENABLE"
 
 	GddServiceDiscoveryMode envVariable = "GDD_SERVICE_DISCOVERY_MODE"
 
@@ -178,6 +535,9 @@ const (
 	GddNacosConfigFormat envVariable = "GDD_NACOS_CONFIG_FORMAT"
 	GddNacosConfigGroup  envVariable = "GDD_NACOS_CONFIG_GROUP"
 	GddNacosConfigDataid envVariable = "GDD_NACOS_CONFIG_DATAID"
+	// GddNacosHeartbeatInterval sets interval of heartbeats of registered instances, e.g. 5s. Nacos server marks an
+	// instance unhealthy after three intervals without heartbeat and removes it after six.
+	GddNacosHeartbeatInterval envVariable = "GDD_NACOS_HEARTBEAT_INTERVAL"
 
 	// GddWeight node weight
 	GddWeight envVariable = "GDD_WEIGHT"
@@ -200,9 +560,31 @@ const (
 	GddEtcdEndpoints envVariable = "GDD_ETCD_ENDPOINTS"
 	GddEtcdLease     envVariable = "GDD_ETCD_LEASE"
 
+	// GddConsulAddress sets address of consul agent, e.g. 127.0.0.1:8500. If empty, CONSUL_HTTP_ADDR or 127.0.0.1:8500 is used.
+	GddConsulAddress envVariable = "GDD_CONSUL_ADDRESS"
+	// GddConsulToken sets acl token for consul agent
+	GddConsulToken envVariable = "GDD_CONSUL_TOKEN"
+	// GddConsulTTL sets ttl of health check of registered services. Local node passes the check every third of it.
+	GddConsulTTL envVariable = "GDD_CONSUL_TTL"
+	// GddConsulDeregisterAfter sets how long a service whose health check is critical stays in consul before deregistered
+	// automatically, e.g. the process crashed without deregistering itself
+	GddConsulDeregisterAfter envVariable = "GDD_CONSUL_DEREGISTER_AFTER"
+
 	// configs for memberlist component
-	// GddMemSeed sets cluster seeds for joining
+	// GddMemSeed sets comma separated cluster seeds for joining. A seed prefixed with srv+ is a DNS SRV record name
+	// resolved into seeds each time the node joins, e.g. srv+_gossip._tcp.myservice.default.svc.cluster.local
 	GddMemSeed envVariable = "GDD_MEM_SEED"
+	// GddMemJoinRetries sets how many times to retry joining cluster after the first failed attempt, e.g. when seeds
+	// are not up yet because the whole cluster is restarting
+	GddMemJoinRetries envVariable = "GDD_MEM_JOIN_RETRIES"
+	// GddMemJoinInterval sets waiting time between attempts to join cluster
+	GddMemJoinInterval envVariable = "GDD_MEM_JOIN_INTERVAL"
+	// GddMemMinMembers makes NewNode wait until the cluster has at least this many members including local node, so that
+	// service providers have downstream nodes before local node starts serving. 0 or negative disables waiting.
+	GddMemMinMembers envVariable = "GDD_MEM_MIN_MEMBERS"
+	// GddMemMinMembersTimeout sets max waiting time for GddMemMinMembers, after which a warning is logged and NewNode
+	// returns anyway, so that a single node deployment still works
+	GddMemMinMembersTimeout envVariable = "GDD_MEM_MIN_MEMBERS_TIMEOUT"
 	// GddMemName unique name of this node in cluster. if empty or not set, hostname will be used instead
 	GddMemName envVariable = "GDD_MEM_NAME"
 	// GddMemHost specify AdvertiseAddr attribute of memberlist config struct.
@@ -213,6 +595,23 @@ const (
 	GddMemHost envVariable = "GDD_MEM_HOST"
 	// GddMemPort if empty or not set, an available port will be chosen randomly. recommend specifying a port
 	GddMemPort envVariable = "GDD_MEM_PORT"
+	// GddMemBindAddr sets local ip address memberlist binds to, e.g. the private ip of a cloud vm behind NAT.
+	// if empty or not set, memberlist binds to all interfaces.
+	GddMemBindAddr envVariable = "GDD_MEM_BIND_ADDR"
+	// GddMemAdvertisePort sets port advertised to other nodes if it differs from GddMemPort,
+	// e.g. the public port NAT forwards to GddMemPort. if empty or not set, GddMemPort is advertised.
+	GddMemAdvertisePort envVariable = "GDD_MEM_ADVERTISE_PORT"
+	// GddMemAdvertiseURL sets a cloud metadata endpoint responding routable ip of this node in plain text,
+	// e.g. http://169.254.169.254/latest/meta-data/public-ipv4 on AWS. It is used only if GddMemHost is not set.
+	GddMemAdvertiseURL envVariable = "GDD_MEM_ADVERTISE_URL"
+	// GddMemAdvertiseURLHeaders sets comma separated request headers in the form of key:value sent to GddMemAdvertiseURL,
+	// e.g. Metadata-Flavor:Google on GCP
+	GddMemAdvertiseURLHeaders envVariable = "GDD_MEM_ADVERTISE_URL_HEADERS"
+	// GddMemAdvertiseURLTimeout sets timeout of requesting GddMemAdvertiseURL
+	GddMemAdvertiseURLTimeout envVariable = "GDD_MEM_ADVERTISE_URL_TIMEOUT"
+	// GddMemAdvertiseCheck checks whether advertised address is reachable by tcp if it differs from GddMemBindAddr,
+	// and logs a warning if not
+	GddMemAdvertiseCheck envVariable = "GDD_MEM_ADVERTISE_CHECK"
 	// GddMemDeadTimeout dead node will be removed from node map if not received refute messages from it in GddMemDeadTimeout second
 	// expose GossipToTheDeadTime property of memberlist.Config
 	GddMemDeadTimeout envVariable = "GDD_MEM_DEAD_TIMEOUT"
@@ -241,15 +640,33 @@ const (
 	// GddMemTCPTimeout tcp timeout
 	// expose TCPTimeout property of memberlist.Config
 	GddMemTCPTimeout envVariable = "GDD_MEM_TCP_TIMEOUT"
-	// GddMemWeight node weight
+	// GddMemWeight node weight, used only if GddWeight is not set. A positive weight is static and disables
+	// weight calculation, a non-positive weight enables calculating weight every GddMemWeightInterval
 	GddMemWeight envVariable = "GDD_MEM_WEIGHT"
-	// GddMemWeightInterval node weight will be calculated every GddMemWeightInterval
+	// GddMemWeightInterval node weight will be calculated every GddMemWeightInterval if static weight is not positive
 	GddMemWeightInterval envVariable = "GDD_MEM_WEIGHT_INTERVAL"
 	GddMemIndirectChecks envVariable = "GDD_MEM_INDIRECT_CHECKS"
 	GddMemLogDisable     envVariable = "GDD_MEM_LOG_DISABLE"
 	// GddMemCIDRsAllowed If not set, allow any connection (default), otherwise specify all networks
 	// allowed connecting (you must specify IPv6/IPv4 separately)
 	GddMemCIDRsAllowed envVariable = "GDD_MEM_CIDRS_ALLOWED"
+	// GddMemLogLevel sets level of memberlist gossip logs independently of application logs.
+	// It accepts the same values as GddLogLevel and follows GddLogLevel if not set.
+	GddMemLogLevel envVariable = "GDD_MEM_LOG_LEVEL"
+	// GddMemReadinessInterval sets interval of running readiness checks before local node becomes ready
+	GddMemReadinessInterval envVariable = "GDD_MEM_READINESS_INTERVAL"
+	// GddMemStateCompression enables lzw compression of user state exchanged by push/pull syncs,
+	// independent of compression of gossip messages. Older versions can't decompress the state,
+	// so enable it only after all services in the cluster are upgraded.
+	GddMemStateCompression envVariable = "GDD_MEM_STATE_COMPRESSION"
+	// GddMemSecretKey sets base64-encoded 16, 24 or 32 bytes key to encrypt gossip traffic with AES-128, AES-192 or AES-256.
+	// if empty or not set, gossip traffic is not encrypted.
+	GddMemSecretKey envVariable = "GDD_MEM_SECRET_KEY"
+	// GddMemGossipVerifyIncoming enforces encryption of incoming gossip if GddMemSecretKey is set.
+	// set it to false together with GddMemGossipVerifyOutgoing to roll out encryption on a running cluster.
+	GddMemGossipVerifyIncoming envVariable = "GDD_MEM_GOSSIP_VERIFY_INCOMING"
+	// GddMemGossipVerifyOutgoing enforces encryption of outgoing gossip if GddMemSecretKey is set
+	GddMemGossipVerifyOutgoing envVariable = "GDD_MEM_GOSSIP_VERIFY_OUTGOING"
 
 	GddDBDisableAutoConfigure envVariable = "GDD_DB_DISABLEAUTOCONFIGURE"
 	GddDBDriver               envVariable = "GDD_DB_DRIVER"
@@ -283,9 +700,24 @@ const (
 	GddZkDirectoryPattern envVariable = "GDD_ZK_DIRECTORY_PATTERN"
 )
 
-// Load loads value from environment variable
+// key returns the prefixed environment variable name if config prefix is set and the prefixed variable exists,
+// otherwise returns the plain one
+func (receiver envVariable) key() string {
+	if receiver == GddConfigPrefix {
+		return string(receiver)
+	}
+	if prefix := framework.ConfigPrefix(); stringutils.IsNotEmpty(prefix) {
+		if _, ok := os.LookupEnv(prefix + string(receiver)); ok {
+			return prefix + string(receiver)
+		}
+	}
+	return string(receiver)
+}
+
+// Load loads value from environment variable. If config prefix is set, the prefixed environment variable
+// such as MYSVC_GDD_PORT takes precedence over the plain one GDD_PORT.
 func (receiver envVariable) Load() string {
-	return os.Getenv(string(receiver))
+	return os.Getenv(receiver.key())
 }
 
 func (receiver envVariable) LoadOrDefault(d string) string {
@@ -301,9 +733,10 @@ func (receiver envVariable) String() string {
 	return receiver.Load()
 }
 
-// Write sets the environment variable to value
+// Write sets the environment variable to value. If the prefixed environment variable exists, it will be written
+// instead of the plain one, so that Load returns the value just written.
 func (receiver envVariable) Write(value string) error {
-	return os.Setenv(string(receiver), value)
+	return os.Setenv(receiver.key(), value)
 }
 
 func GetNacosClientParam() vo.NacosClientParam {
@@ -7,13 +7,19 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/unionj-cloud/go-doudou/v2/framework"
 	"github.com/unionj-cloud/go-doudou/v2/framework/configmgr"
 	"github.com/unionj-cloud/go-doudou/v2/framework/configmgr/mock"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/wubin1989/nacos-sdk-go/v2/clients/cache"
 	"github.com/wubin1989/nacos-sdk-go/v2/clients/config_client"
 	"github.com/wubin1989/nacos-sdk-go/v2/vo"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_envVariable_String(t *testing.T) {
@@ -24,6 +30,28 @@ func Test_envVariable_String(t *testing.T) {
 	})
 }
 
+func Test_envVariable_Prefix(t *testing.T) {
+	Convey("Prefixed config key should take precedence over plain one", t, func() {
+		defer os.Unsetenv("MYSVC_GDD_SERVICE_GROUP")
+		defer framework.SetConfigPrefix("")
+		config.GddServiceGroup.Write("plain")
+		So(config.GddServiceGroup.Load(), ShouldEqual, "plain")
+
+		framework.SetConfigPrefix("mysvc")
+		So(config.GddServiceGroup.Load(), ShouldEqual, "plain")
+
+		os.Setenv("MYSVC_GDD_SERVICE_GROUP", "prefixed")
+		So(config.GddServiceGroup.Load(), ShouldEqual, "prefixed")
+
+		config.GddServiceGroup.Write("written")
+		So(os.Getenv("MYSVC_GDD_SERVICE_GROUP"), ShouldEqual, "written")
+		So(os.Getenv("GDD_SERVICE_GROUP"), ShouldEqual, "plain")
+
+		framework.SetConfigPrefix("")
+		So(config.GddServiceGroup.Load(), ShouldEqual, "plain")
+	})
+}
+
 func TestMain(m *testing.M) {
 	config.GddNacosServerAddr.Write("http://localhost:8848")
 	m.Run()
@@ -337,3 +365,110 @@ func Test_envVariable_MarshalJSON(t *testing.T) {
 		So(string(data), ShouldEqual, `"8080"`)
 	})
 }
+
+func Test_LogFileWriter(t *testing.T) {
+	Convey("Should return nil if GddLogFile is not set", t, func() {
+		_ = config.GddLogFile.Write("")
+		So(config.LogFileWriter(), ShouldBeNil)
+	})
+
+	Convey("Should return rotating file writer if GddLogFile is set", t, func() {
+		_ = config.GddLogFile.Write("/tmp/go-doudou/app.log")
+		_ = config.GddLogMaxSize.Write("10")
+		_ = config.GddLogMaxBackups.Write("3")
+		defer func() {
+			_ = config.GddLogFile.Write("")
+			_ = config.GddLogMaxSize.Write("")
+			_ = config.GddLogMaxBackups.Write("")
+		}()
+		w, ok := config.LogFileWriter().(*lumberjack.Logger)
+		So(ok, ShouldBeTrue)
+		So(w.Filename, ShouldEqual, "/tmp/go-doudou/app.log")
+		So(w.MaxSize, ShouldEqual, 10)
+		So(w.MaxBackups, ShouldEqual, 3)
+		So(w.MaxAge, ShouldEqual, config.DefaultGddLogMaxAge)
+	})
+}
+
+func TestReloadLocal(t *testing.T) {
+	Convey("Should only reload hot-reloadable config", t, func() {
+		dir := t.TempDir()
+		wd, _ := os.Getwd()
+		So(os.Chdir(dir), ShouldBeNil)
+		defer os.Chdir(wd)
+		_ = config.GddLogLevel.Write("info")
+		_ = config.GddPort.Write("6060")
+		defer config.GddLogLevel.Write("")
+		So(os.WriteFile(".env", []byte("GDD_LOG_LEVEL=debug\nGDD_PORT=8080\n"), 0644), ShouldBeNil)
+
+		changed := config.ReloadLocal()
+		So(len(changed), ShouldEqual, 1)
+		So(changed[0], ShouldEqual, config.GddLogLevel)
+		So(config.GddLogLevel.Load(), ShouldEqual, "debug")
+		So(config.GddPort.Load(), ShouldEqual, "6060")
+
+		So(os.WriteFile(".env", []byte("GDD_PORT=8080\n"), 0644), ShouldBeNil)
+		changed = config.ReloadLocal()
+		So(len(changed), ShouldEqual, 1)
+		So(changed[0], ShouldEqual, config.GddLogLevel)
+		_, ok := os.LookupEnv(string(config.GddLogLevel))
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestReloadLocal_RealEnv(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") == "1" {
+		dir := t.TempDir()
+		wd, _ := os.Getwd()
+		_ = os.Chdir(dir)
+		defer os.Chdir(wd)
+		_ = os.WriteFile(".env", []byte("GDD_LOG_LEVEL=debug\n"), 0644)
+		if changed := config.ReloadLocal(); len(changed) > 0 || config.GddLogLevel.Load() != "warn" {
+			t.Fatalf("real environment variable is overridden: %v", changed)
+		}
+		_ = os.WriteFile(".env", []byte(""), 0644)
+		if changed := config.ReloadLocal(); len(changed) > 0 || config.GddLogLevel.Load() != "warn" {
+			t.Fatalf("real environment variable is unset: %v", changed)
+		}
+		return
+	}
+	Convey("Should never override real environment variables", t, func() {
+		// real environment variables are snapshotted at startup, so run the test in a child process
+		cmd := exec.Command(os.Args[0], "-test.run=^TestReloadLocal_RealEnv$")
+		// drop configs left by other tests
+		for _, kv := range os.Environ() {
+			if !strings.HasPrefix(kv, "GDD_") {
+				cmd.Env = append(cmd.Env, kv)
+			}
+		}
+		cmd.Env = append(cmd.Env, "GO_WANT_HELPER_PROCESS=1", "GDD_LOG_LEVEL=warn")
+		out, err := cmd.CombinedOutput()
+		So(err, ShouldBeNil)
+		So(string(out), ShouldContainSubstring, "PASS")
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	Convey("Should load typed configs and collect invalid ones", t, func() {
+		config.GddGraceTimeout.Write("30s")
+		config.GddPort.Write("8080")
+		config.GddMemPort.Write("abc")
+		config.GddManage.Write("maybe")
+		defer func() {
+			os.Unsetenv(string(config.GddGraceTimeout))
+			os.Unsetenv(string(config.GddPort))
+			os.Unsetenv(string(config.GddMemPort))
+			os.Unsetenv(string(config.GddManage))
+		}()
+		c, err := config.Snapshot()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "GDD_MEM_PORT")
+		So(err.Error(), ShouldContainSubstring, "GDD_MANAGE")
+		So(c.GraceTimeout, ShouldEqual, 30*time.Second)
+		So(c.Port, ShouldEqual, 8080)
+		So(c.MemPort, ShouldEqual, config.DefaultGddMemPort)
+		So(c.Manage, ShouldEqual, config.DefaultGddManage)
+		So(c.ManageUser, ShouldEqual, config.DefaultGddManageUser)
+		So(c.NacosConfigFormat, ShouldEqual, config.DefaultGddNacosConfigFormat)
+	})
+}
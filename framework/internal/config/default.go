@@ -7,16 +7,44 @@ import (
 
 const FrameworkName = "go-doudou"
 
+const (
+	LogReqFormatText = "text"
+	LogReqFormatJSON = "json"
+)
+
+const (
+	ClientAuthRequireAndVerify = "require_and_verify"
+	ClientAuthVerifyIfGiven    = "verify_if_given"
+)
+
+const (
+	TracingProviderJaeger = "jaeger"
+	TracingProviderOtel   = "otel"
+)
+
 const (
 	// Default configs for framework component
 	DefaultGddBanner             = true
 	DefaultGddBannerText         = FrameworkName
+	DefaultGddBannerFont         = "doom"
+	DefaultGddBannerColor        = "green"
 	DefaultGddLogLevel           = "info"
 	DefaultGddLogFormat          = "text"
 	DefaultGddLogReqEnable       = false
+	DefaultGddLogReqFormat       = LogReqFormatText
+	DefaultGddTracingProvider    = TracingProviderJaeger
 	DefaultGddLogCaller          = false
 	DefaultGddLogDiscard         = false
+	DefaultGddLogFile            = ""
+	DefaultGddLogMaxSize         = 100
+	DefaultGddLogMaxAge          = 0
+	DefaultGddLogMaxBackups      = 0
+	DefaultGddLogCompress        = false
+	DefaultGddShutdownTimeout    = "25s"
 	DefaultGddGraceTimeout       = "15s"
+	DefaultGddDrainTimeout       = "0s"
+	DefaultGddRegistryLeaveWait  = "2s"
+	DefaultGddPreStopDelay       = "0s"
 	DefaultGddWriteTimeout       = "15s"
 	DefaultGddReadTimeout        = "15s"
 	DefaultGddIdleTimeout        = "60s"
@@ -24,16 +52,68 @@ const (
 	DefaultGddServiceGroup       = ""
 	DefaultGddServiceVersion     = ""
 	DefaultGddRouteRootPath      = ""
+	DefaultGddRouteManifest      = ""
+	DefaultGddExternalBasePath   = ""
 	DefaultGddHost               = ""
 	DefaultGddPort               = 6060
+	DefaultGddBindRetry          = false
 	DefaultGddGrpcPort           = 50051
+	DefaultGddTLSCert            = ""
+	DefaultGddTLSKey             = ""
+	DefaultGddClientCAFile       = ""
+	DefaultGddClientAuthMode     = ClientAuthRequireAndVerify
+	DefaultGddHttp3Enable        = false
+	DefaultGddEnableH2C          = false
 	DefaultGddRetryCount         = 0
+	DefaultGddRetryMaxWait       = "10s"
+	DefaultGddJwtSigningKey      = ""
+	DefaultGddJwtJwksURL         = ""
+	DefaultGddJwtRequireExp      = false
 	DefaultGddManage             = true
 	DefaultGddManageUser         = "admin"
 	DefaultGddManagePass         = "admin"
+	DefaultGddManageAllowedIPs   = ""
+	DefaultGddManageDoc          = true
+	DefaultGddManageConfigUI     = true
+	DefaultGddManageStatsviz     = true
+	DefaultGddManagePprof        = true
+	DefaultGddHealthCheckTimeout = "3s"
 	DefaultGddTracingMetricsRoot = "tracing"
+	DefaultGddPromExemplarEnable = false
+	DefaultGddTraceSampleRatio   = 1.0
 	DefaultGddWeight             = 1
 
+	DefaultGddMaintenanceEnable     = false
+	DefaultGddMaintenanceMessage    = "service is under maintenance, please try again later"
+	DefaultGddMaintenanceRetryAfter = "60s"
+
+	DefaultGddProfileMaxDuration = "60s"
+
+	DefaultGddClientMaxIdleConns        = 256
+	DefaultGddClientMaxIdleConnsPerHost = 32
+	DefaultGddClientMaxConnsPerHost     = 10000
+	DefaultGddClientIdleConnTimeout     = "90s"
+	DefaultGddClientDialTimeout         = "30s"
+	DefaultGddClientTLSHandshakeTimeout = "10s"
+
+	DefaultGddRecentRequestsEnable = false
+	DefaultGddRecentRequestsSize   = 100
+
+	DefaultGddSlowRequestThreshold = ""
+	DefaultGddSlowRequestDetail    = "basic"
+	DefaultGddSlowRequestHeaders   = "User-Agent,Content-Type,Content-Length,X-Forwarded-For,Referer"
+
+	DefaultGddRateLimitEnable = false
+	DefaultGddRateLimitRate   = 100.0
+	DefaultGddRateLimitBurst  = 100
+	DefaultGddTrustedProxies  = ""
+
+	DefaultGddBatchMaxItems = 100
+
+	DefaultGddDrainPropagationWait = "5s"
+	DefaultGddDrainInFlightTimeout = "30s"
+	DefaultGddDrainLeaveTimeout    = "5s"
+
 	DefaultGddServiceDiscoveryMode = ""
 
 	DefaultGddNacosNamespaceId         = "public"
@@ -52,7 +132,14 @@ const (
 	DefaultGddNacosConfigGroup  = "DEFAULT_GROUP"
 	DefaultGddNacosConfigDataid = ""
 
+	DefaultGddNacosHeartbeatInterval = "5s"
+
 	DefaultGddEnableResponseGzip         = true
+	DefaultGddGzipContentTypes           = ""
+	DefaultGddGzipMinLength              = 1024
+	DefaultGddErrorDetailsEnable         = false
+	DefaultGddMaxBodySize                = 32 << 20
+	DefaultGddHandlerTimeout             = "0s"
 	DefaultGddAppType                    = "rest"
 	DefaultGddFallbackContentType        = "application/json; charset=UTF-8"
 	DefaultGddRouterSaveMatchedRoutePath = true
@@ -76,6 +163,11 @@ const (
 	DefaultGddEtcdEndpoints       = ""
 	DefaultGddEtcdLease     int64 = 5
 
+	DefaultGddConsulAddress         = ""
+	DefaultGddConsulToken           = ""
+	DefaultGddConsulTTL             = "15s"
+	DefaultGddConsulDeregisterAfter = "1m"
+
 	// Default configs for memberlist component
 	DefaultGddMemSeed           = ""
 	DefaultGddMemPort           = 7946
@@ -97,6 +189,25 @@ const (
 	DefaultGddMemCIDRsAllowed   = ""
 	DefaultGddMemLogDisable     = false
 
+	DefaultGddMemReadinessInterval = "1s"
+	DefaultGddMemStateCompression  = false
+
+	DefaultGddMemSecretKey            = ""
+	DefaultGddMemGossipVerifyIncoming = true
+	DefaultGddMemGossipVerifyOutgoing = true
+
+	DefaultGddMemBindAddr            = ""
+	DefaultGddMemAdvertiseURL        = ""
+	DefaultGddMemAdvertiseURLHeaders = ""
+	DefaultGddMemAdvertiseURLTimeout = "2s"
+	DefaultGddMemAdvertiseCheck      = true
+
+	DefaultGddMemJoinRetries  = 3
+	DefaultGddMemJoinInterval = "3s"
+
+	DefaultGddMemMinMembers        = 0
+	DefaultGddMemMinMembersTimeout = "30s"
+
 	DefaultGddDBDisableAutoConfigure = false
 	DefaultGddDBDriver               = ""
 	DefaultGddDBDsn                  = ""
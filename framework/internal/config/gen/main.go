@@ -0,0 +1,190 @@
+// Command gen generates typed Config struct and Snapshot function from GDD_* config keys and their defaults
+// declared in package config. Run go generate in package config after adding or changing config keys.
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const output = "snapshot_gen.go"
+
+var tmpl = `// Code generated by go run ./gen; DO NOT EDIT.
+
+package config
+
+import "time"
+
+// Config is a typed snapshot of all GDD_* configs
+type Config struct {
+{{- range .}}
+{{- range .Doc}}
+	// {{.}}
+{{- end}}
+	{{.Field}} {{.Type}}
+{{- end}}
+}
+
+// Snapshot loads all GDD_* configs into a Config once, falling back to defaults for configs not set.
+// It returns an error listing all configs which failed to be parsed, together with a Config filled by defaults for them.
+func Snapshot() (*Config, error) {
+	p := &snapshotParser{}
+	c := &Config{
+{{- range .}}
+		{{.Field}}: p.{{.Parser}}({{.Key}}, {{.Default}}),
+{{- end}}
+	}
+	return c, p.err()
+}
+`
+
+type field struct {
+	Key     string
+	Field   string
+	Type    string
+	Parser  string
+	Default string
+	Doc     []string
+}
+
+func main() {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		panic(err)
+	}
+	var filenames []string
+	for filename := range pkgs["config"].Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	var files []*ast.File
+	for _, filename := range filenames {
+		files = append(files, pkgs["config"].Files[filename])
+	}
+	// Only constants declared in package config matter, so imported packages are faked and
+	// type errors caused by them are ignored
+	conf := types.Config{
+		Importer: fakeImporter{},
+		Error:    func(err error) {},
+	}
+	pkg, _ := conf.Check("config", fset, files, nil)
+	scope := pkg.Scope()
+	var fields []field
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, name := range vs.Names {
+					obj, ok := scope.Lookup(name.Name).(*types.Const)
+					if !ok || !strings.HasPrefix(name.Name, "Gdd") {
+						continue
+					}
+					if named, ok := obj.Type().(*types.Named); !ok || named.Obj().Name() != "envVariable" {
+						continue
+					}
+					fields = append(fields, newField(scope, name.Name, vs.Doc))
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err = template.Must(template.New("snapshot").Parse(tmpl)).Execute(&buf, fields); err != nil {
+		panic(err)
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	if err = os.WriteFile(output, source, 0644); err != nil {
+		panic(err)
+	}
+}
+
+// stringDefaults are defaults of string configs whose default constants are typed by imported packages,
+// so they cannot be inferred by fake imported types
+var stringDefaults = map[string]string{
+	"GddNacosConfigFormat": "string(DefaultGddNacosConfigFormat)",
+}
+
+// durationConfigs are configs parsed as durations whose defaults are integers in nanoseconds
+var durationConfigs = map[string]bool{
+	"GddMemWeightInterval": true,
+	"GddDBConnMaxLifetime": true,
+	"GddDBConnMaxIdleTime": true,
+}
+
+type fakeImporter struct{}
+
+func (fakeImporter) Import(importPath string) (*types.Package, error) {
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// newField maps config key to struct field typed by its default value. Configs without default value
+// or with default value of non-basic types are kept as string.
+func newField(scope *types.Scope, key string, doc *ast.CommentGroup) field {
+	f := field{
+		Key:     key,
+		Field:   strings.TrimPrefix(key, "Gdd"),
+		Type:    "string",
+		Parser:  "string",
+		Default: `""`,
+	}
+	if doc != nil {
+		for _, line := range strings.Split(strings.TrimSpace(doc.Text()), "\n") {
+			f.Doc = append(f.Doc, line)
+		}
+	}
+	if d, ok := stringDefaults[key]; ok {
+		f.Default = d
+		return f
+	}
+	def, ok := scope.Lookup("Default" + key).(*types.Const)
+	if !ok {
+		return f
+	}
+	basic, ok := def.Type().(*types.Basic)
+	if !ok {
+		return f
+	}
+	switch {
+	case basic.Info()&types.IsBoolean != 0:
+		f.Type, f.Parser = "bool", "bool"
+	case basic.Kind() == types.Int64:
+		f.Type, f.Parser = "int64", "int64"
+	case basic.Info()&types.IsInteger != 0 && durationConfigs[key]:
+		f.Type, f.Parser = "time.Duration", "durationOf"
+	case basic.Info()&types.IsInteger != 0:
+		f.Type, f.Parser = "int", "int"
+	case basic.Info()&types.IsFloat != 0:
+		f.Type, f.Parser = "float64", "float64"
+	case basic.Info()&types.IsString != 0:
+		if _, err := time.ParseDuration(constant.StringVal(def.Val())); err == nil {
+			f.Type, f.Parser = "time.Duration", "duration"
+		}
+	default:
+		// e.g. DefaultGddDBLogLevel of gorm logger.LogLevel type, raw string value is kept
+		return f
+	}
+	f.Default = def.Name()
+	return f
+}
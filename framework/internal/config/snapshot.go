@@ -0,0 +1,102 @@
+package config
+
+//go:generate go run ./gen
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"strings"
+	"time"
+)
+
+// snapshotParser parses configs for Snapshot and collects errors of all invalid configs
+type snapshotParser struct {
+	errs []string
+}
+
+func (p *snapshotParser) fail(key envVariable, value string, err error) {
+	p.errs = append(p.errs, fmt.Sprintf("%s=%q: %s", key.key(), value, err))
+}
+
+func (p *snapshotParser) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	return errors.Errorf("[go-doudou] invalid configs: %s", strings.Join(p.errs, "; "))
+}
+
+func (p *snapshotParser) string(key envVariable, d string) string {
+	return key.LoadOrDefault(d)
+}
+
+func (p *snapshotParser) bool(key envVariable, d bool) bool {
+	value := key.Load()
+	if stringutils.IsEmpty(value) {
+		return d
+	}
+	result, err := cast.ToBoolE(value)
+	if err != nil {
+		p.fail(key, value, err)
+		return d
+	}
+	return result
+}
+
+func (p *snapshotParser) int(key envVariable, d int) int {
+	value := key.Load()
+	if stringutils.IsEmpty(value) {
+		return d
+	}
+	result, err := cast.ToIntE(value)
+	if err != nil {
+		p.fail(key, value, err)
+		return d
+	}
+	return result
+}
+
+func (p *snapshotParser) int64(key envVariable, d int64) int64 {
+	value := key.Load()
+	if stringutils.IsEmpty(value) {
+		return d
+	}
+	result, err := cast.ToInt64E(value)
+	if err != nil {
+		p.fail(key, value, err)
+		return d
+	}
+	return result
+}
+
+func (p *snapshotParser) float64(key envVariable, d float64) float64 {
+	value := key.Load()
+	if stringutils.IsEmpty(value) {
+		return d
+	}
+	result, err := cast.ToFloat64E(value)
+	if err != nil {
+		p.fail(key, value, err)
+		return d
+	}
+	return result
+}
+
+func (p *snapshotParser) duration(key envVariable, d string) time.Duration {
+	def, _ := time.ParseDuration(d)
+	return p.durationOf(key, def)
+}
+
+func (p *snapshotParser) durationOf(key envVariable, d time.Duration) time.Duration {
+	value := key.Load()
+	if stringutils.IsEmpty(value) {
+		return d
+	}
+	result, err := time.ParseDuration(value)
+	if err != nil {
+		p.fail(key, value, err)
+		return d
+	}
+	return result
+}
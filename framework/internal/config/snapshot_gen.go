@@ -0,0 +1,594 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+
+package config
+
+import "time"
+
+// Config is a typed snapshot of all GDD_* configs
+type Config struct {
+	// GddConfigPrefix sets instance prefix for all config keys, e.g. MYSVC, then MYSVC_GDD_PORT takes precedence over GDD_PORT.
+	// It is useful when multiple go-doudou services are configured via environment variables on one host.
+	// It can also be set by framework.SetConfigPrefix function.
+	ConfigPrefix string
+	// GddBanner indicates banner enabled or not
+	Banner bool
+	// GddBannerText sets text content of banner
+	BannerText string
+	// GddBannerFont sets figlet font of banner, e.g. doom, standard, slant. Unknown fonts fall back to doom.
+	BannerFont string
+	// GddBannerColor sets color of banner, accepts red, green, yellow, blue, purple, cyan, gray and white.
+	// Unknown colors fall back to green.
+	BannerColor string
+	// GddLogLevel accepts panic, fatal, error, warn, warning, info, debug, trace, disabled. please reference zerolog.ParseLevel
+	LogLevel string
+	// GddLogAccessLevel sets level of http access logs, including request logs and slow request logs.
+	// It accepts the same values as GddLogLevel and follows GddLogLevel if not set.
+	LogAccessLevel string
+	// GddLogFormat text or json
+	// Deprecated: move to zerolog
+	LogFormat string
+	// GddLogReqEnable enables request and response logging
+	LogReqEnable bool
+	// GddLogReqFormat sets format of request logs enabled by GddLogReqEnable. text logs request and response
+	// with bodies and headers as indented json message, json logs one line per request with method, path, status code,
+	// bytes written, remote address, request id and duration as structured fields, for ingestion into Loki or ELK.
+	LogReqFormat string
+	LogCaller    bool
+	LogDiscard   bool
+	// GddLogFile sets log output file path, logs will be written to stdout if not set
+	LogFile string
+	// GddLogMaxSize is the maximum size in megabytes of the log file before it gets rotated
+	LogMaxSize int
+	// GddLogMaxAge is the maximum number of days to retain old log files, 0 means not to remove old log files based on age
+	LogMaxAge int
+	// GddLogMaxBackups is the maximum number of old log files to retain, 0 means to retain all old log files
+	LogMaxBackups int
+	// GddLogCompress determines if the rotated log files should be compressed using gzip
+	LogCompress bool
+	// GddRecentRequestsEnable keeps summaries of recent requests in memory for live debugging,
+	// exposed by /go-doudou/requests endpoint
+	RecentRequestsEnable bool
+	// GddRecentRequestsSize sets how many recent requests to keep, capped by 10000
+	RecentRequestsSize int
+	// GddSlowRequestThreshold logs requests taking longer than it at warn level, e.g. 500ms. Set to 0s or leave empty to disable.
+	SlowRequestThreshold string
+	// GddSlowRequestDetail accepts basic or full. full also logs query params, request headers listed in
+	// GddSlowRequestHeaders and duration breakdown.
+	SlowRequestDetail string
+	// GddSlowRequestHeaders sets comma separated request headers logged by slow request log in full detail level
+	SlowRequestHeaders string
+	// GddRateLimitEnable limits requests of each client ip to biz routes by token bucket algorithm.
+	// Requests exceeding the limit are rejected with 429 status code.
+	RateLimitEnable bool
+	// GddRateLimitRate sets requests per second allowed for each client ip
+	RateLimitRate float64
+	// GddRateLimitBurst sets max burst of requests allowed for each client ip
+	RateLimitBurst int
+	// GddTrustedProxies sets comma separated CIDRs or ips of trusted reverse proxies. Client ip is taken from
+	// X-Forwarded-For or X-Real-IP header only if the request is from one of them, e.g. for rate limit by ip.
+	// Default is empty, which trusts no proxy, so client ip is always the peer address of the connection.
+	TrustedProxies string
+	// GddBatchMaxItems sets max number of calls bundled in one request to generated batch endpoint
+	BatchMaxItems int
+	// GddShutdownTimeout sets the budget of the whole shutdown sequence on SIGTERM, including leaving service registries,
+	// waiting for the leave to propagate, graceful shutdown and draining long-lived connections. Each step gets what is
+	// left of the budget at most. Keep it under terminationGracePeriodSeconds of kubernetes pods, which is 30s by default.
+	ShutdownTimeout time.Duration
+	// GddGraceTimeout sets graceful shutdown timeout
+	GraceTimeout time.Duration
+	// GddDrainTimeout sets how long to wait for long-lived connections such as SSE and WebSocket
+	// after graceful shutdown timeout expired before forcibly closing them
+	DrainTimeout time.Duration
+	// GddRegistryLeaveWait sets how long to wait after leaving service registries before shutting down server,
+	// so that other nodes have a chance to stop routing requests to this node. Set to 0s to disable.
+	// It is ignored if no service discovery mode is enabled.
+	RegistryLeaveWait time.Duration
+	// GddPreStopDelay sets how long to keep serving after leaving service registries and before starting graceful shutdown,
+	// so that load balancers like kubernetes service have time to stop routing new connections to this node.
+	// It shares one phase with GddRegistryLeaveWait, the longer one is waited. 0 disables the delay.
+	PreStopDelay time.Duration
+	// GddDrainPropagationWait sets how long DrainAndLeave waits for draining status of local node to be gossiped
+	// to other nodes before flipping readiness to false
+	DrainPropagationWait time.Duration
+	// GddDrainInFlightTimeout sets max time DrainAndLeave waits for in-flight requests to complete
+	DrainInFlightTimeout time.Duration
+	// GddDrainLeaveTimeout sets max time DrainAndLeave waits for leave message to be broadcast to other nodes
+	DrainLeaveTimeout time.Duration
+	// GddWriteTimeout sets http connection write timeout
+	WriteTimeout time.Duration
+	// GddReadTimeout sets http connection read timeout
+	ReadTimeout time.Duration
+	// GddIdleTimeout sets http connection idle timeout
+	IdleTimeout time.Duration
+	// GddRouteRootPath sets root path for all routes
+	RouteRootPath string
+	// GddRouteManifest sets path of file which routes are written into as json on startup, e.g. for api gateways.
+	// No file is written if empty.
+	RouteManifest string
+	// GddExternalBasePath sets external base path prefixed by reverse proxy in front of the service, e.g. /api/user-svc.
+	// It is used by online api document to resolve openapi.json and servers url correctly behind a proxy.
+	// If empty or not set, X-Forwarded-Prefix request header will be used instead if present.
+	ExternalBasePath string
+	// GddServiceName sets service name
+	ServiceName    string
+	ServiceGroup   string
+	ServiceVersion string
+	// GddHost sets bind host for http server
+	Host string
+	// GddPort sets bind port for http server. 0 means binding a free port picked by kernel, the actual port is
+	// written back to GddPort before registering the service to service registries.
+	Port int
+	// GddBindRetry binds http server and memberlist to a free port picked by kernel if configured port is
+	// already in use, which reduces flaky startups when many services start concurrently, e.g. in CI.
+	// The actual port is registered to service registry.
+	BindRetry bool
+	// GddTLSEnable enables serving https with GddTLSCert and GddTLSKey. If not set, https is enabled
+	// when both GddTLSCert and GddTLSKey are set.
+	TLSEnable string
+	// GddTLSCert sets certificate file path for serving https
+	TLSCert string
+	// GddTLSKey sets private key file path for serving https
+	TLSKey string
+	// GddClientCAFile sets CA certificates file path for verifying client certificates (mutual TLS).
+	// Only works when https is enabled.
+	ClientCAFile string
+	// GddClientAuthMode has two options available: require_and_verify, verify_if_given
+	ClientAuthMode string
+	// GddHttp3Enable enables experimental HTTP/3 server listening on udp port of the same number as GddPort,
+	// alongside http server. It requires GddTLSCert, GddTLSKey and a server factory registered by
+	// rest.RegisterHTTP3ServerFactory
+	Http3Enable bool
+	// GddEnableH2C enables serving HTTP/2 over cleartext (h2c) alongside HTTP/1.1, e.g. for gateways speaking
+	// HTTP/2 without tls. It is ignored if https is enabled, as HTTP/2 is negotiated by tls then.
+	EnableH2C bool
+	// GddGrpcPort sets bind port for grpc server
+	GrpcPort int
+	// GddManage if true, it will add built-in apis with /go-doudou path prefix for online api document and service status monitor etc.
+	Manage bool
+	// GddJwtSigningKey is the HMAC key verifying signature of bearer tokens by JWTAuthenticatorFromConfig
+	JwtSigningKey string
+	// GddJwtJwksURL is the JWKS endpoint serving RSA public keys verifying signature of bearer tokens by
+	// JWTAuthenticatorFromConfig, used if GddJwtSigningKey is not set
+	JwtJwksURL string
+	// GddJwtRequireExp makes JWTAuthenticatorFromConfig reject bearer tokens without exp claim
+	JwtRequireExp bool
+	// GddManageUser manage api endpoint http basic auth user
+	ManageUser string
+	// GddManagePass manage api endpoint http basic auth password
+	ManagePass string
+	// GddManageAllowedIPs is a comma separated list of CIDRs like 10.0.0.0/8,fd00::/8, if set, only requests from these
+	// ranges are allowed to access management routes besides basic auth. Single ip without prefix length is accepted too.
+	ManageAllowedIPs string
+	// GddManageDoc mounts online api document routes /go-doudou/doc and /go-doudou/openapi.json if GddManage is enabled
+	ManageDoc bool
+	// GddManageConfigUI mounts config viewing route /go-doudou/config if GddManage is enabled
+	ManageConfigUI bool
+	// GddManageStatsviz mounts runtime statistics visualization routes /go-doudou/statsviz if GddManage is enabled
+	ManageStatsviz bool
+	// GddManagePprof mounts profiling routes /debug/pprof and /go-doudou/profile if GddManage is enabled
+	ManagePprof bool
+	// GddHealthCheckTimeout sets timeout of each HealthChecker run by health check endpoint, a checker not returning
+	// within the timeout is reported down
+	HealthCheckTimeout time.Duration
+	// GddMaintenanceEnable turns on maintenance mode on startup or on SIGHUP reload
+	MaintenanceEnable bool
+	// GddMaintenanceMessage is the default response message when maintenance mode is enabled
+	MaintenanceMessage string
+	// GddMaintenanceRetryAfter is the default Retry-After response header value when maintenance mode is enabled
+	MaintenanceRetryAfter time.Duration
+	// GddProfileMaxDuration caps duration of cpu profile captured by /go-doudou/profile endpoint
+	ProfileMaxDuration time.Duration
+	EnableResponseGzip bool
+	// GddGzipContentTypes replaces the built-in list of response content types to be compressed if set, comma separated,
+	// e.g. application/json,application/x-ndjson
+	GzipContentTypes string
+	// GddGzipMinLength sets min size in bytes of responses to be compressed
+	GzipMinLength int
+	// GddErrorDetailsEnable returns error chain with stack trace in error response even if GDD_ENV is not dev
+	ErrorDetailsEnable bool
+	// GddMaxBodySize sets max size in bytes of request body of biz routes, 0 means unlimited. Requests with larger body
+	// are rejected with 413 status code. It can be overridden by MaxBodySize of rest.Route.
+	MaxBodySize int
+	// GddHandlerTimeout sets max processing time of requests to biz routes, e.g. 30s, 0 means no timeout. Requests
+	// taking longer are responded with 503 status code, and their context is canceled. It can be overridden by
+	// Timeout of rest.Route.
+	HandlerTimeout time.Duration
+	// Deprecated: move to GddFallbackContentType
+	AppType string
+	// GddFallbackContentType fallback response content-type header value
+	FallbackContentType        string
+	RouterSaveMatchedRoutePath bool
+	// GddConfigRemoteType has two options available: nacos, apollo
+	ConfigRemoteType   string
+	RetryCount         int
+	TracingMetricsRoot string
+	// GddTracingProvider selects tracing middleware of http server, accepts jaeger and otel. jaeger by default.
+	// otel starts an OpenTelemetry server span for each request with W3C traceparent propagation, exported by
+	// global TracerProvider of go.opentelemetry.io/otel, so register one with OTLP exporter in main function.
+	TracingProvider string
+	// GddRetryMaxWait caps waiting time between retries of http clients, including waiting time indicated by
+	// Retry-After header of 429 and 503 responses
+	RetryMaxWait time.Duration
+	// GddClientMaxIdleConns sets max idle connections across all hosts of http clients
+	ClientMaxIdleConns int
+	// GddClientMaxIdleConnsPerHost sets max idle connections per host of http clients. It is much higher than
+	// net/http's default 2 to avoid connection churn under load of service-to-service calls.
+	ClientMaxIdleConnsPerHost int
+	// GddClientMaxConnsPerHost limits total connections per host of http clients, 0 or negative means no limit
+	ClientMaxConnsPerHost int
+	// GddClientIdleConnTimeout sets how long an idle connection of http clients is kept in pool
+	ClientIdleConnTimeout time.Duration
+	// GddClientDialTimeout sets timeout of establishing tcp connections of http clients
+	ClientDialTimeout time.Duration
+	// GddClientTLSHandshakeTimeout sets timeout of tls handshake of http clients
+	ClientTLSHandshakeTimeout time.Duration
+	// GddTraceSampleRatio sets fraction of requests to be traced from 0 to 1. Requests carrying sampled
+	// trace context from upstream are always traced.
+	TraceSampleRatio float64
+	// GddPromExemplarEnable attaches trace id as exemplar to http request duration histogram.
+	// Prometheus server should be started with --enable-feature=exemplar-storage to scrape them.
+	PromExemplarEnable       bool
+	ServiceDiscoveryMode     string
+	NacosNamespaceId         string
+	NacosTimeoutMs           int
+	NacosNotLoadCacheAtStart bool
+	NacosLogDir              string
+	NacosCacheDir            string
+	NacosLogLevel            string
+	NacosLogDiscard          bool
+	NacosServerAddr          string
+	NacosRegisterHost        string
+	NacosClusterName         string
+	NacosGroupName           string
+	// GddNacosConfigFormat has two options available: dotenv, yaml
+	NacosConfigFormat string
+	NacosConfigGroup  string
+	NacosConfigDataid string
+	// GddNacosHeartbeatInterval sets interval of heartbeats of registered instances, e.g. 5s. Nacos server marks an
+	// instance unhealthy after three intervals without heartbeat and removes it after six.
+	NacosHeartbeatInterval time.Duration
+	// GddWeight node weight
+	Weight             int
+	ApolloCluster      string
+	ApolloAddr         string
+	ApolloNamespace    string
+	ApolloBackupEnable bool
+	ApolloBackupPath   string
+	ApolloMuststart    bool
+	ApolloSecret       string
+	ApolloLogEnable    bool
+	// GddSqlLogEnable only for doc purpose
+	SqlLogEnable  bool
+	StatsFreq     time.Duration
+	RegisterHost  string
+	EtcdEndpoints string
+	EtcdLease     int64
+	// GddConsulAddress sets address of consul agent, e.g. 127.0.0.1:8500. If empty, CONSUL_HTTP_ADDR or 127.0.0.1:8500 is used.
+	ConsulAddress string
+	// GddConsulToken sets acl token for consul agent
+	ConsulToken string
+	// GddConsulTTL sets ttl of health check of registered services. Local node passes the check every third of it.
+	ConsulTTL time.Duration
+	// GddConsulDeregisterAfter sets how long a service whose health check is critical stays in consul before deregistered
+	// automatically, e.g. the process crashed without deregistering itself
+	ConsulDeregisterAfter time.Duration
+	// configs for memberlist component
+	// GddMemSeed sets comma separated cluster seeds for joining. A seed prefixed with srv+ is a DNS SRV record name
+	// resolved into seeds each time the node joins, e.g. srv+_gossip._tcp.myservice.default.svc.cluster.local
+	MemSeed string
+	// GddMemJoinRetries sets how many times to retry joining cluster after the first failed attempt, e.g. when seeds
+	// are not up yet because the whole cluster is restarting
+	MemJoinRetries int
+	// GddMemJoinInterval sets waiting time between attempts to join cluster
+	MemJoinInterval time.Duration
+	// GddMemMinMembers makes NewNode wait until the cluster has at least this many members including local node, so that
+	// service providers have downstream nodes before local node starts serving. 0 or negative disables waiting.
+	MemMinMembers int
+	// GddMemMinMembersTimeout sets max waiting time for GddMemMinMembers, after which a warning is logged and NewNode
+	// returns anyway, so that a single node deployment still works
+	MemMinMembersTimeout time.Duration
+	// GddMemName unique name of this node in cluster. if empty or not set, hostname will be used instead
+	MemName string
+	// GddMemHost specify AdvertiseAddr attribute of memberlist config struct.
+	// if GddMemHost starts with dot such as .seed-svc-headless.default.svc.cluster.local,
+	// it will be prefixed by hostname such as seed-2.seed-svc-headless.default.svc.cluster.local
+	// for supporting k8s stateful service
+	// if empty or not set, private ip will be used instead.
+	MemHost string
+	// GddMemPort if empty or not set, an available port will be chosen randomly. recommend specifying a port
+	MemPort int
+	// GddMemBindAddr sets local ip address memberlist binds to, e.g. the private ip of a cloud vm behind NAT.
+	// if empty or not set, memberlist binds to all interfaces.
+	MemBindAddr string
+	// GddMemAdvertisePort sets port advertised to other nodes if it differs from GddMemPort,
+	// e.g. the public port NAT forwards to GddMemPort. if empty or not set, GddMemPort is advertised.
+	MemAdvertisePort string
+	// GddMemAdvertiseURL sets a cloud metadata endpoint responding routable ip of this node in plain text,
+	// e.g. http://169.254.169.254/latest/meta-data/public-ipv4 on AWS. It is used only if GddMemHost is not set.
+	MemAdvertiseURL string
+	// GddMemAdvertiseURLHeaders sets comma separated request headers in the form of key:value sent to GddMemAdvertiseURL,
+	// e.g. Metadata-Flavor:Google on GCP
+	MemAdvertiseURLHeaders string
+	// GddMemAdvertiseURLTimeout sets timeout of requesting GddMemAdvertiseURL
+	MemAdvertiseURLTimeout time.Duration
+	// GddMemAdvertiseCheck checks whether advertised address is reachable by tcp if it differs from GddMemBindAddr,
+	// and logs a warning if not
+	MemAdvertiseCheck bool
+	// GddMemDeadTimeout dead node will be removed from node map if not received refute messages from it in GddMemDeadTimeout second
+	// expose GossipToTheDeadTime property of memberlist.Config
+	MemDeadTimeout time.Duration
+	// GddMemSyncInterval local node will synchronize states from other random node every GddMemSyncInterval second
+	// expose PushPullInterval property of memberlist.Config
+	MemSyncInterval time.Duration
+	// GddMemReclaimTimeout dead node will be replaced with new node with the same name but different full address in GddMemReclaimTimeout second
+	// expose DeadNodeReclaimTime property of memberlist.Config
+	MemReclaimTimeout time.Duration
+	// GddMemProbeInterval probe interval
+	// expose ProbeInterval property of memberlist.Config
+	MemProbeInterval time.Duration
+	// GddMemProbeTimeout probe timeout
+	// expose ProbeTimeout property of memberlist.Config
+	MemProbeTimeout time.Duration
+	// GddMemSuspicionMult is the multiplier for determining the time an inaccessible node is considered suspect before declaring it dead.
+	// expose SuspicionMult property of memberlist.Config
+	MemSuspicionMult  int
+	MemRetransmitMult int
+	// GddMemGossipNodes how many remote nodes you want to gossip messages
+	// expose GossipNodes property of memberlist.Config
+	MemGossipNodes int
+	// GddMemGossipInterval gossip interval
+	// expose GossipInterval property of memberlist.Config
+	MemGossipInterval time.Duration
+	// GddMemTCPTimeout tcp timeout
+	// expose TCPTimeout property of memberlist.Config
+	MemTCPTimeout time.Duration
+	// GddMemWeight node weight, used only if GddWeight is not set. A positive weight is static and disables
+	// weight calculation, a non-positive weight enables calculating weight every GddMemWeightInterval
+	MemWeight int
+	// GddMemWeightInterval node weight will be calculated every GddMemWeightInterval if static weight is not positive
+	MemWeightInterval time.Duration
+	MemIndirectChecks int
+	MemLogDisable     bool
+	// GddMemCIDRsAllowed If not set, allow any connection (default), otherwise specify all networks
+	// allowed connecting (you must specify IPv6/IPv4 separately)
+	MemCIDRsAllowed string
+	// GddMemLogLevel sets level of memberlist gossip logs independently of application logs.
+	// It accepts the same values as GddLogLevel and follows GddLogLevel if not set.
+	MemLogLevel string
+	// GddMemReadinessInterval sets interval of running readiness checks before local node becomes ready
+	MemReadinessInterval time.Duration
+	// GddMemStateCompression enables lzw compression of user state exchanged by push/pull syncs,
+	// independent of compression of gossip messages. Older versions can't decompress the state,
+	// so enable it only after all services in the cluster are upgraded.
+	MemStateCompression bool
+	// GddMemSecretKey sets base64-encoded 16, 24 or 32 bytes key to encrypt gossip traffic with AES-128, AES-192 or AES-256.
+	// if empty or not set, gossip traffic is not encrypted.
+	MemSecretKey string
+	// GddMemGossipVerifyIncoming enforces encryption of incoming gossip if GddMemSecretKey is set.
+	// set it to false together with GddMemGossipVerifyOutgoing to roll out encryption on a running cluster.
+	MemGossipVerifyIncoming bool
+	// GddMemGossipVerifyOutgoing enforces encryption of outgoing gossip if GddMemSecretKey is set
+	MemGossipVerifyOutgoing          bool
+	DBDisableAutoConfigure           bool
+	DBDriver                         string
+	DBDsn                            string
+	DBMaxIdleConns                   int
+	DBMaxOpenConns                   int
+	DBConnMaxLifetime                time.Duration
+	DBConnMaxIdleTime                time.Duration
+	DBLogSlowThreshold               time.Duration
+	DBLogIgnoreRecordNotFoundError   bool
+	DBLogParameterizedQueries        bool
+	DBLogLevel                       string
+	DBMysqlSkipInitializeWithVersion bool
+	DBMysqlDefaultStringSize         int
+	// GddDBMysqlDefaultDatetimePrecision      envVariable = "GDD_DB_MYSQL_DEFAULTDATETIMEPRECISION"
+	DBMysqlDisableWithReturning          bool
+	DBMysqlDisableDatetimePrecision      bool
+	DBMysqlDontSupportRenameIndex        bool
+	DBMysqlDontSupportRenameColumn       bool
+	DBMysqlDontSupportForShareClause     bool
+	DBMysqlDontSupportNullAsDefaultValue bool
+	DBMysqlDontSupportRenameColumnUnique bool
+	DBPostgresPreferSimpleProtocol       bool
+	DBPostgresWithoutReturning           bool
+	ZkServers                            string
+	ZkSequence                           bool
+	ZkDirectoryPattern                   string
+}
+
+// Snapshot loads all GDD_* configs into a Config once, falling back to defaults for configs not set.
+// It returns an error listing all configs which failed to be parsed, together with a Config filled by defaults for them.
+func Snapshot() (*Config, error) {
+	p := &snapshotParser{}
+	c := &Config{
+		ConfigPrefix:                         p.string(GddConfigPrefix, ""),
+		Banner:                               p.bool(GddBanner, DefaultGddBanner),
+		BannerText:                           p.string(GddBannerText, DefaultGddBannerText),
+		BannerFont:                           p.string(GddBannerFont, DefaultGddBannerFont),
+		BannerColor:                          p.string(GddBannerColor, DefaultGddBannerColor),
+		LogLevel:                             p.string(GddLogLevel, DefaultGddLogLevel),
+		LogAccessLevel:                       p.string(GddLogAccessLevel, ""),
+		LogFormat:                            p.string(GddLogFormat, DefaultGddLogFormat),
+		LogReqEnable:                         p.bool(GddLogReqEnable, DefaultGddLogReqEnable),
+		LogReqFormat:                         p.string(GddLogReqFormat, DefaultGddLogReqFormat),
+		LogCaller:                            p.bool(GddLogCaller, DefaultGddLogCaller),
+		LogDiscard:                           p.bool(GddLogDiscard, DefaultGddLogDiscard),
+		LogFile:                              p.string(GddLogFile, DefaultGddLogFile),
+		LogMaxSize:                           p.int(GddLogMaxSize, DefaultGddLogMaxSize),
+		LogMaxAge:                            p.int(GddLogMaxAge, DefaultGddLogMaxAge),
+		LogMaxBackups:                        p.int(GddLogMaxBackups, DefaultGddLogMaxBackups),
+		LogCompress:                          p.bool(GddLogCompress, DefaultGddLogCompress),
+		RecentRequestsEnable:                 p.bool(GddRecentRequestsEnable, DefaultGddRecentRequestsEnable),
+		RecentRequestsSize:                   p.int(GddRecentRequestsSize, DefaultGddRecentRequestsSize),
+		SlowRequestThreshold:                 p.string(GddSlowRequestThreshold, DefaultGddSlowRequestThreshold),
+		SlowRequestDetail:                    p.string(GddSlowRequestDetail, DefaultGddSlowRequestDetail),
+		SlowRequestHeaders:                   p.string(GddSlowRequestHeaders, DefaultGddSlowRequestHeaders),
+		RateLimitEnable:                      p.bool(GddRateLimitEnable, DefaultGddRateLimitEnable),
+		RateLimitRate:                        p.float64(GddRateLimitRate, DefaultGddRateLimitRate),
+		RateLimitBurst:                       p.int(GddRateLimitBurst, DefaultGddRateLimitBurst),
+		TrustedProxies:                       p.string(GddTrustedProxies, DefaultGddTrustedProxies),
+		BatchMaxItems:                        p.int(GddBatchMaxItems, DefaultGddBatchMaxItems),
+		ShutdownTimeout:                      p.duration(GddShutdownTimeout, DefaultGddShutdownTimeout),
+		GraceTimeout:                         p.duration(GddGraceTimeout, DefaultGddGraceTimeout),
+		DrainTimeout:                         p.duration(GddDrainTimeout, DefaultGddDrainTimeout),
+		RegistryLeaveWait:                    p.duration(GddRegistryLeaveWait, DefaultGddRegistryLeaveWait),
+		PreStopDelay:                         p.duration(GddPreStopDelay, DefaultGddPreStopDelay),
+		DrainPropagationWait:                 p.duration(GddDrainPropagationWait, DefaultGddDrainPropagationWait),
+		DrainInFlightTimeout:                 p.duration(GddDrainInFlightTimeout, DefaultGddDrainInFlightTimeout),
+		DrainLeaveTimeout:                    p.duration(GddDrainLeaveTimeout, DefaultGddDrainLeaveTimeout),
+		WriteTimeout:                         p.duration(GddWriteTimeout, DefaultGddWriteTimeout),
+		ReadTimeout:                          p.duration(GddReadTimeout, DefaultGddReadTimeout),
+		IdleTimeout:                          p.duration(GddIdleTimeout, DefaultGddIdleTimeout),
+		RouteRootPath:                        p.string(GddRouteRootPath, DefaultGddRouteRootPath),
+		RouteManifest:                        p.string(GddRouteManifest, DefaultGddRouteManifest),
+		ExternalBasePath:                     p.string(GddExternalBasePath, DefaultGddExternalBasePath),
+		ServiceName:                          p.string(GddServiceName, DefaultGddServiceName),
+		ServiceGroup:                         p.string(GddServiceGroup, DefaultGddServiceGroup),
+		ServiceVersion:                       p.string(GddServiceVersion, DefaultGddServiceVersion),
+		Host:                                 p.string(GddHost, DefaultGddHost),
+		Port:                                 p.int(GddPort, DefaultGddPort),
+		BindRetry:                            p.bool(GddBindRetry, DefaultGddBindRetry),
+		TLSEnable:                            p.string(GddTLSEnable, ""),
+		TLSCert:                              p.string(GddTLSCert, DefaultGddTLSCert),
+		TLSKey:                               p.string(GddTLSKey, DefaultGddTLSKey),
+		ClientCAFile:                         p.string(GddClientCAFile, DefaultGddClientCAFile),
+		ClientAuthMode:                       p.string(GddClientAuthMode, DefaultGddClientAuthMode),
+		Http3Enable:                          p.bool(GddHttp3Enable, DefaultGddHttp3Enable),
+		EnableH2C:                            p.bool(GddEnableH2C, DefaultGddEnableH2C),
+		GrpcPort:                             p.int(GddGrpcPort, DefaultGddGrpcPort),
+		Manage:                               p.bool(GddManage, DefaultGddManage),
+		JwtSigningKey:                        p.string(GddJwtSigningKey, DefaultGddJwtSigningKey),
+		JwtJwksURL:                           p.string(GddJwtJwksURL, DefaultGddJwtJwksURL),
+		JwtRequireExp:                        p.bool(GddJwtRequireExp, DefaultGddJwtRequireExp),
+		ManageUser:                           p.string(GddManageUser, DefaultGddManageUser),
+		ManagePass:                           p.string(GddManagePass, DefaultGddManagePass),
+		ManageAllowedIPs:                     p.string(GddManageAllowedIPs, DefaultGddManageAllowedIPs),
+		ManageDoc:                            p.bool(GddManageDoc, DefaultGddManageDoc),
+		ManageConfigUI:                       p.bool(GddManageConfigUI, DefaultGddManageConfigUI),
+		ManageStatsviz:                       p.bool(GddManageStatsviz, DefaultGddManageStatsviz),
+		ManagePprof:                          p.bool(GddManagePprof, DefaultGddManagePprof),
+		HealthCheckTimeout:                   p.duration(GddHealthCheckTimeout, DefaultGddHealthCheckTimeout),
+		MaintenanceEnable:                    p.bool(GddMaintenanceEnable, DefaultGddMaintenanceEnable),
+		MaintenanceMessage:                   p.string(GddMaintenanceMessage, DefaultGddMaintenanceMessage),
+		MaintenanceRetryAfter:                p.duration(GddMaintenanceRetryAfter, DefaultGddMaintenanceRetryAfter),
+		ProfileMaxDuration:                   p.duration(GddProfileMaxDuration, DefaultGddProfileMaxDuration),
+		EnableResponseGzip:                   p.bool(GddEnableResponseGzip, DefaultGddEnableResponseGzip),
+		GzipContentTypes:                     p.string(GddGzipContentTypes, DefaultGddGzipContentTypes),
+		GzipMinLength:                        p.int(GddGzipMinLength, DefaultGddGzipMinLength),
+		ErrorDetailsEnable:                   p.bool(GddErrorDetailsEnable, DefaultGddErrorDetailsEnable),
+		MaxBodySize:                          p.int(GddMaxBodySize, DefaultGddMaxBodySize),
+		HandlerTimeout:                       p.duration(GddHandlerTimeout, DefaultGddHandlerTimeout),
+		AppType:                              p.string(GddAppType, DefaultGddAppType),
+		FallbackContentType:                  p.string(GddFallbackContentType, DefaultGddFallbackContentType),
+		RouterSaveMatchedRoutePath:           p.bool(GddRouterSaveMatchedRoutePath, DefaultGddRouterSaveMatchedRoutePath),
+		ConfigRemoteType:                     p.string(GddConfigRemoteType, DefaultGddConfigRemoteType),
+		RetryCount:                           p.int(GddRetryCount, DefaultGddRetryCount),
+		TracingMetricsRoot:                   p.string(GddTracingMetricsRoot, DefaultGddTracingMetricsRoot),
+		TracingProvider:                      p.string(GddTracingProvider, DefaultGddTracingProvider),
+		RetryMaxWait:                         p.duration(GddRetryMaxWait, DefaultGddRetryMaxWait),
+		ClientMaxIdleConns:                   p.int(GddClientMaxIdleConns, DefaultGddClientMaxIdleConns),
+		ClientMaxIdleConnsPerHost:            p.int(GddClientMaxIdleConnsPerHost, DefaultGddClientMaxIdleConnsPerHost),
+		ClientMaxConnsPerHost:                p.int(GddClientMaxConnsPerHost, DefaultGddClientMaxConnsPerHost),
+		ClientIdleConnTimeout:                p.duration(GddClientIdleConnTimeout, DefaultGddClientIdleConnTimeout),
+		ClientDialTimeout:                    p.duration(GddClientDialTimeout, DefaultGddClientDialTimeout),
+		ClientTLSHandshakeTimeout:            p.duration(GddClientTLSHandshakeTimeout, DefaultGddClientTLSHandshakeTimeout),
+		TraceSampleRatio:                     p.float64(GddTraceSampleRatio, DefaultGddTraceSampleRatio),
+		PromExemplarEnable:                   p.bool(GddPromExemplarEnable, DefaultGddPromExemplarEnable),
+		ServiceDiscoveryMode:                 p.string(GddServiceDiscoveryMode, DefaultGddServiceDiscoveryMode),
+		NacosNamespaceId:                     p.string(GddNacosNamespaceId, DefaultGddNacosNamespaceId),
+		NacosTimeoutMs:                       p.int(GddNacosTimeoutMs, DefaultGddNacosTimeoutMs),
+		NacosNotLoadCacheAtStart:             p.bool(GddNacosNotLoadCacheAtStart, DefaultGddNacosNotLoadCacheAtStart),
+		NacosLogDir:                          p.string(GddNacosLogDir, DefaultGddNacosLogDir),
+		NacosCacheDir:                        p.string(GddNacosCacheDir, DefaultGddNacosCacheDir),
+		NacosLogLevel:                        p.string(GddNacosLogLevel, DefaultGddNacosLogLevel),
+		NacosLogDiscard:                      p.bool(GddNacosLogDiscard, DefaultGddNacosLogDiscard),
+		NacosServerAddr:                      p.string(GddNacosServerAddr, DefaultGddNacosServerAddr),
+		NacosRegisterHost:                    p.string(GddNacosRegisterHost, DefaultGddNacosRegisterHost),
+		NacosClusterName:                     p.string(GddNacosClusterName, DefaultGddNacosClusterName),
+		NacosGroupName:                       p.string(GddNacosGroupName, DefaultGddNacosGroupName),
+		NacosConfigFormat:                    p.string(GddNacosConfigFormat, string(DefaultGddNacosConfigFormat)),
+		NacosConfigGroup:                     p.string(GddNacosConfigGroup, DefaultGddNacosConfigGroup),
+		NacosConfigDataid:                    p.string(GddNacosConfigDataid, DefaultGddNacosConfigDataid),
+		NacosHeartbeatInterval:               p.duration(GddNacosHeartbeatInterval, DefaultGddNacosHeartbeatInterval),
+		Weight:                               p.int(GddWeight, DefaultGddWeight),
+		ApolloCluster:                        p.string(GddApolloCluster, DefaultGddApolloCluster),
+		ApolloAddr:                           p.string(GddApolloAddr, DefaultGddApolloAddr),
+		ApolloNamespace:                      p.string(GddApolloNamespace, DefaultGddApolloNamespace),
+		ApolloBackupEnable:                   p.bool(GddApolloBackupEnable, DefaultGddApolloBackupEnable),
+		ApolloBackupPath:                     p.string(GddApolloBackupPath, DefaultGddApolloBackupPath),
+		ApolloMuststart:                      p.bool(GddApolloMuststart, DefaultGddApolloMuststart),
+		ApolloSecret:                         p.string(GddApolloSecret, DefaultGddApolloSecret),
+		ApolloLogEnable:                      p.bool(GddApolloLogEnable, DefaultGddApolloLogEnable),
+		SqlLogEnable:                         p.bool(GddSqlLogEnable, DefaultGddSqlLogEnable),
+		StatsFreq:                            p.duration(GddStatsFreq, DefaultGddStatsFreq),
+		RegisterHost:                         p.string(GddRegisterHost, DefaultGddRegisterHost),
+		EtcdEndpoints:                        p.string(GddEtcdEndpoints, DefaultGddEtcdEndpoints),
+		EtcdLease:                            p.int64(GddEtcdLease, DefaultGddEtcdLease),
+		ConsulAddress:                        p.string(GddConsulAddress, DefaultGddConsulAddress),
+		ConsulToken:                          p.string(GddConsulToken, DefaultGddConsulToken),
+		ConsulTTL:                            p.duration(GddConsulTTL, DefaultGddConsulTTL),
+		ConsulDeregisterAfter:                p.duration(GddConsulDeregisterAfter, DefaultGddConsulDeregisterAfter),
+		MemSeed:                              p.string(GddMemSeed, DefaultGddMemSeed),
+		MemJoinRetries:                       p.int(GddMemJoinRetries, DefaultGddMemJoinRetries),
+		MemJoinInterval:                      p.duration(GddMemJoinInterval, DefaultGddMemJoinInterval),
+		MemMinMembers:                        p.int(GddMemMinMembers, DefaultGddMemMinMembers),
+		MemMinMembersTimeout:                 p.duration(GddMemMinMembersTimeout, DefaultGddMemMinMembersTimeout),
+		MemName:                              p.string(GddMemName, DefaultGddMemName),
+		MemHost:                              p.string(GddMemHost, DefaultGddMemHost),
+		MemPort:                              p.int(GddMemPort, DefaultGddMemPort),
+		MemBindAddr:                          p.string(GddMemBindAddr, DefaultGddMemBindAddr),
+		MemAdvertisePort:                     p.string(GddMemAdvertisePort, ""),
+		MemAdvertiseURL:                      p.string(GddMemAdvertiseURL, DefaultGddMemAdvertiseURL),
+		MemAdvertiseURLHeaders:               p.string(GddMemAdvertiseURLHeaders, DefaultGddMemAdvertiseURLHeaders),
+		MemAdvertiseURLTimeout:               p.duration(GddMemAdvertiseURLTimeout, DefaultGddMemAdvertiseURLTimeout),
+		MemAdvertiseCheck:                    p.bool(GddMemAdvertiseCheck, DefaultGddMemAdvertiseCheck),
+		MemDeadTimeout:                       p.duration(GddMemDeadTimeout, DefaultGddMemDeadTimeout),
+		MemSyncInterval:                      p.duration(GddMemSyncInterval, DefaultGddMemSyncInterval),
+		MemReclaimTimeout:                    p.duration(GddMemReclaimTimeout, DefaultGddMemReclaimTimeout),
+		MemProbeInterval:                     p.duration(GddMemProbeInterval, DefaultGddMemProbeInterval),
+		MemProbeTimeout:                      p.duration(GddMemProbeTimeout, DefaultGddMemProbeTimeout),
+		MemSuspicionMult:                     p.int(GddMemSuspicionMult, DefaultGddMemSuspicionMult),
+		MemRetransmitMult:                    p.int(GddMemRetransmitMult, DefaultGddMemRetransmitMult),
+		MemGossipNodes:                       p.int(GddMemGossipNodes, DefaultGddMemGossipNodes),
+		MemGossipInterval:                    p.duration(GddMemGossipInterval, DefaultGddMemGossipInterval),
+		MemTCPTimeout:                        p.duration(GddMemTCPTimeout, DefaultGddMemTCPTimeout),
+		MemWeight:                            p.int(GddMemWeight, DefaultGddMemWeight),
+		MemWeightInterval:                    p.durationOf(GddMemWeightInterval, DefaultGddMemWeightInterval),
+		MemIndirectChecks:                    p.int(GddMemIndirectChecks, DefaultGddMemIndirectChecks),
+		MemLogDisable:                        p.bool(GddMemLogDisable, DefaultGddMemLogDisable),
+		MemCIDRsAllowed:                      p.string(GddMemCIDRsAllowed, DefaultGddMemCIDRsAllowed),
+		MemLogLevel:                          p.string(GddMemLogLevel, ""),
+		MemReadinessInterval:                 p.duration(GddMemReadinessInterval, DefaultGddMemReadinessInterval),
+		MemStateCompression:                  p.bool(GddMemStateCompression, DefaultGddMemStateCompression),
+		MemSecretKey:                         p.string(GddMemSecretKey, DefaultGddMemSecretKey),
+		MemGossipVerifyIncoming:              p.bool(GddMemGossipVerifyIncoming, DefaultGddMemGossipVerifyIncoming),
+		MemGossipVerifyOutgoing:              p.bool(GddMemGossipVerifyOutgoing, DefaultGddMemGossipVerifyOutgoing),
+		DBDisableAutoConfigure:               p.bool(GddDBDisableAutoConfigure, DefaultGddDBDisableAutoConfigure),
+		DBDriver:                             p.string(GddDBDriver, DefaultGddDBDriver),
+		DBDsn:                                p.string(GddDBDsn, DefaultGddDBDsn),
+		DBMaxIdleConns:                       p.int(GddDBMaxIdleConns, DefaultGddDBMaxIdleConns),
+		DBMaxOpenConns:                       p.int(GddDBMaxOpenConns, DefaultGddDBMaxOpenConns),
+		DBConnMaxLifetime:                    p.durationOf(GddDBConnMaxLifetime, DefaultGddDBConnMaxLifetime),
+		DBConnMaxIdleTime:                    p.durationOf(GddDBConnMaxIdleTime, DefaultGddDBConnMaxIdleTime),
+		DBLogSlowThreshold:                   p.duration(GddDBLogSlowThreshold, DefaultGddDBLogSlowThreshold),
+		DBLogIgnoreRecordNotFoundError:       p.bool(GddDBLogIgnoreRecordNotFoundError, DefaultGddDBLogIgnoreRecordNotFoundError),
+		DBLogParameterizedQueries:            p.bool(GddDBLogParameterizedQueries, DefaultGddDBLogParameterizedQueries),
+		DBLogLevel:                           p.string(GddDBLogLevel, ""),
+		DBMysqlSkipInitializeWithVersion:     p.bool(GddDBMysqlSkipInitializeWithVersion, DefaultGddDBMysqlSkipInitializeWithVersion),
+		DBMysqlDefaultStringSize:             p.int(GddDBMysqlDefaultStringSize, DefaultGddDBMysqlDefaultStringSize),
+		DBMysqlDisableWithReturning:          p.bool(GddDBMysqlDisableWithReturning, DefaultGddDBMysqlDisableWithReturning),
+		DBMysqlDisableDatetimePrecision:      p.bool(GddDBMysqlDisableDatetimePrecision, DefaultGddDBMysqlDisableDatetimePrecision),
+		DBMysqlDontSupportRenameIndex:        p.bool(GddDBMysqlDontSupportRenameIndex, DefaultGddDBMysqlDontSupportRenameIndex),
+		DBMysqlDontSupportRenameColumn:       p.bool(GddDBMysqlDontSupportRenameColumn, DefaultGddDBMysqlDontSupportRenameColumn),
+		DBMysqlDontSupportForShareClause:     p.bool(GddDBMysqlDontSupportForShareClause, DefaultGddDBMysqlDontSupportForShareClause),
+		DBMysqlDontSupportNullAsDefaultValue: p.bool(GddDBMysqlDontSupportNullAsDefaultValue, DefaultGddDBMysqlDontSupportNullAsDefaultValue),
+		DBMysqlDontSupportRenameColumnUnique: p.bool(GddDBMysqlDontSupportRenameColumnUnique, DefaultGddDBMysqlDontSupportRenameColumnUnique),
+		DBPostgresPreferSimpleProtocol:       p.bool(GddDBPostgresPreferSimpleProtocol, DefaultGddDBPostgresPreferSimpleProtocol),
+		DBPostgresWithoutReturning:           p.bool(GddDBPostgresWithoutReturning, DefaultGddDBPostgresWithoutReturning),
+		ZkServers:                            p.string(GddZkServers, DefaultGddZkServers),
+		ZkSequence:                           p.bool(GddZkSequence, DefaultGddZkSequence),
+		ZkDirectoryPattern:                   p.string(GddZkDirectoryPattern, DefaultGddZkDirectoryPattern),
+	}
+	return c, p.err()
+}
@@ -0,0 +1,28 @@
+package kvstore
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a key value store with ttl for states of protective middlewares such as rate limiting, idempotency keys
+// and circuit breakers. The default MemoryStore keeps states in process memory, use RedisStore to make states
+// survive restarts and be shared across replicas in a horizontally-scaled deployment.
+type Store interface {
+	// Get returns value of key and false if key does not exist or has expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set sets value of key which will expire after ttl. Zero ttl means never expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Incr increments integer value of key by one and returns the result. If key does not exist,
+	// it will be created with value 1 and expire after ttl. Zero ttl means never expire.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Del deletes key
+	Del(ctx context.Context, key string) error
+}
+
+// Scanner is implemented by stores which are able to list keys, e.g. for invalidating cached responses by pattern
+type Scanner interface {
+	// Keys returns unexpired keys matching glob-style pattern, in which * matches any sequence of characters
+	// and ? matches any single character
+	Keys(ctx context.Context, pattern string) ([]string, error)
+}
@@ -0,0 +1,159 @@
+package kvstore
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Store = (*MemoryStore)(nil)
+var _ Scanner = (*MemoryStore)(nil)
+
+// sweepEvery is the number of writes after which expired keys will be swept from MemoryStore
+const sweepEvery = 1024
+
+type memoryItem struct {
+	value    []byte
+	expireAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expireAt.IsZero() && !now.Before(i.expireAt)
+}
+
+// MemoryStore is the default Store implementation keeping states in process memory
+type MemoryStore struct {
+	lock   sync.Mutex
+	items  map[string]memoryItem
+	writes int
+}
+
+// NewMemoryStore creates a MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]memoryItem),
+	}
+}
+
+func expireAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+// get returns unexpired item, caller must hold the lock
+func (s *MemoryStore) get(key string) (memoryItem, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return memoryItem{}, false
+	}
+	if item.expired(time.Now()) {
+		delete(s.items, key)
+		return memoryItem{}, false
+	}
+	return item, true
+}
+
+// put puts item and sweeps expired items every sweepEvery writes, caller must hold the lock
+func (s *MemoryStore) put(key string, item memoryItem) {
+	s.items[key] = item
+	s.writes++
+	if s.writes < sweepEvery {
+		return
+	}
+	s.writes = 0
+	now := time.Now()
+	for k, v := range s.items {
+		if v.expired(now) {
+			delete(s.items, k)
+		}
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	item, ok := s.get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	return item.value, true, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.put(key, memoryItem{
+		value:    value,
+		expireAt: expireAt(ttl),
+	})
+	return nil
+}
+
+func (s *MemoryStore) Incr(_ context.Context, key string, ttl time.Duration) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	item, ok := s.get(key)
+	if !ok {
+		s.put(key, memoryItem{
+			value:    []byte("1"),
+			expireAt: expireAt(ttl),
+		})
+		return 1, nil
+	}
+	n, err := strconv.ParseInt(string(item.value), 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "[go-doudou] value of key %s is not an integer", key)
+	}
+	n++
+	item.value = []byte(strconv.FormatInt(n, 10))
+	s.items[key] = item
+	return n, nil
+}
+
+func (s *MemoryStore) Del(_ context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+// globToRegexp converts glob-style pattern to anchored regexp
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func (s *MemoryStore) Keys(_ context.Context, pattern string) ([]string, error) {
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[go-doudou] invalid pattern %s", pattern)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	var keys []string
+	for k, v := range s.items {
+		if !v.expired(now) && re.MatchString(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,86 @@
+package kvstore
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_GetSet(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	_, ok, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, store.Set(ctx, "key", []byte("value"), 0))
+	value, ok, err := store.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("value"), value)
+
+	assert.NoError(t, store.Del(ctx, "key"))
+	_, ok, _ = store.Get(ctx, "key")
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	assert.NoError(t, store.Set(ctx, "key", []byte("value"), 20*time.Millisecond))
+	_, ok, _ := store.Get(ctx, "key")
+	assert.True(t, ok)
+	time.Sleep(30 * time.Millisecond)
+	_, ok, _ = store.Get(ctx, "key")
+	assert.False(t, ok)
+}
+
+func TestMemoryStore_Incr(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	for i := 1; i <= 3; i++ {
+		n, err := store.Incr(ctx, "counter", 20*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(i), n)
+	}
+	time.Sleep(30 * time.Millisecond)
+	n, err := store.Incr(ctx, "counter", 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	assert.NoError(t, store.Set(ctx, "key", []byte("value"), 0))
+	_, err = store.Incr(ctx, "key", 0)
+	assert.Error(t, err)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	assert.NoError(t, store.Set(ctx, "expired", []byte("value"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < sweepEvery; i++ {
+		assert.NoError(t, store.Set(ctx, "key", []byte("value"), 0))
+	}
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	assert.Len(t, store.items, 1)
+}
+
+func TestMemoryStore_Keys(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	assert.NoError(t, store.Set(ctx, "GET /users?page=1", []byte("value"), 0))
+	assert.NoError(t, store.Set(ctx, "GET /users/1", []byte("value"), 0))
+	assert.NoError(t, store.Set(ctx, "GET /orders", []byte("value"), 0))
+	assert.NoError(t, store.Set(ctx, "GET /users/2", []byte("value"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	keys, err := store.Keys(ctx, "GET /users*")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"GET /users?page=1", "GET /users/1"}, keys)
+
+	keys, err = store.Keys(ctx, "GET /users/?")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET /users/1"}, keys)
+}
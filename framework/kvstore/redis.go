@@ -0,0 +1,104 @@
+package kvstore
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+var _ Store = (*RedisStore)(nil)
+var _ Scanner = (*RedisStore)(nil)
+
+const redisPrefix = "go-doudou:kv:"
+
+// incrScript increments key and sets ttl in milliseconds only when key is created, so that ttl of a counter
+// will not be extended by later increments
+var incrScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Rediser defines redis commands used by RedisStore, satisfied by *redis.Client, *redis.ClusterClient and redis.UniversalClient
+type Rediser interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd
+	EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd
+	ScriptExists(ctx context.Context, hashes ...string) *redis.BoolSliceCmd
+	ScriptLoad(ctx context.Context, script string) *redis.StringCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+}
+
+// RedisStore is a Store implementation backed by redis, states are shared across replicas
+type RedisStore struct {
+	rdb    Rediser
+	prefix string
+}
+
+// RedisStoreOption configures RedisStore
+type RedisStoreOption func(*RedisStore)
+
+// WithPrefix sets key prefix, default is go-doudou:kv:
+func WithPrefix(prefix string) RedisStoreOption {
+	return func(store *RedisStore) {
+		store.prefix = prefix
+	}
+}
+
+// NewRedisStore creates a RedisStore
+func NewRedisStore(rdb Rediser, opts ...RedisStoreOption) *RedisStore {
+	store := &RedisStore{
+		rdb:    rdb,
+		prefix: redisPrefix,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	return store
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.rdb.Get(ctx, s.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, errors.WithStack(err)
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return errors.WithStack(s.rdb.Set(ctx, s.prefix+key, value, ttl).Err())
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := incrScript.Run(ctx, s.rdb, []string{s.prefix + key}, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return n, nil
+}
+
+func (s *RedisStore) Del(ctx context.Context, key string) error {
+	return errors.WithStack(s.rdb.Del(ctx, s.prefix+key).Err())
+}
+
+// Keys scans keys by SCAN command. Note that *redis.ClusterClient only scans one of the master nodes.
+func (s *RedisStore) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := s.rdb.Scan(ctx, 0, s.prefix+pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), s.prefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,92 @@
+package storerate
+
+import (
+	"context"
+	"fmt"
+	"github.com/unionj-cloud/go-doudou/v2/framework/kvstore"
+	"github.com/unionj-cloud/go-doudou/v2/framework/ratelimit"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"math"
+	"time"
+)
+
+var _ ratelimit.Limiter = (*Limiter)(nil)
+
+// Limiter is a fixed window ratelimit.Limiter backed by kvstore.Store. It allows at most ceil(Rate) requests
+// in every Period window, Burst is ignored. With kvstore.RedisStore, the limit is shared across replicas.
+type Limiter struct {
+	store kvstore.Store
+	key   string
+	limit ratelimit.Limit
+}
+
+// NewLimiter creates a Limiter for key
+func NewLimiter(store kvstore.Store, key string, limit ratelimit.Limit) *Limiter {
+	return &Limiter{
+		store: store,
+		key:   key,
+		limit: limit,
+	}
+}
+
+func (l *Limiter) max() int64 {
+	return int64(math.Max(1, math.Ceil(l.limit.Rate)))
+}
+
+func (l *Limiter) ReserveECtx(ctx context.Context) (time.Duration, bool, error) {
+	now := time.Now()
+	window := now.Truncate(l.limit.Period)
+	retryAfter := window.Add(l.limit.Period).Sub(now)
+	n, err := l.store.Incr(ctx, fmt.Sprintf("%s:%d", l.key, window.UnixNano()), l.limit.Period)
+	if err != nil {
+		return 0, false, err
+	}
+	if n > l.max() {
+		return retryAfter, false, nil
+	}
+	return 0, true, nil
+}
+
+func (l *Limiter) ReserveE() (time.Duration, bool, error) {
+	return l.ReserveECtx(context.Background())
+}
+
+func (l *Limiter) AllowECtx(ctx context.Context) (bool, error) {
+	_, allow, err := l.ReserveECtx(ctx)
+	return allow, err
+}
+
+func (l *Limiter) AllowE() (bool, error) {
+	return l.AllowECtx(context.Background())
+}
+
+func (l *Limiter) AllowCtx(ctx context.Context) bool {
+	allow, err := l.AllowECtx(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("")
+		return false
+	}
+	return allow
+}
+
+func (l *Limiter) Allow() bool {
+	return l.AllowCtx(context.Background())
+}
+
+// Wait you'd better pass a timeout or cancelable context
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		retryAfter, allow, err := l.ReserveECtx(ctx)
+		if err != nil {
+			return err
+		}
+		if allow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryAfter):
+		}
+	}
+}
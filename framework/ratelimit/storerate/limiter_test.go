@@ -0,0 +1,37 @@
+package storerate
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/unionj-cloud/go-doudou/v2/framework/kvstore"
+	"github.com/unionj-cloud/go-doudou/v2/framework/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	store := kvstore.NewMemoryStore()
+	limiter := NewLimiter(store, "api", ratelimit.PerHour(3))
+	for i := 0; i < 3; i++ {
+		assert.True(t, limiter.Allow())
+	}
+	retryAfter, allow, err := limiter.ReserveE()
+	assert.NoError(t, err)
+	assert.False(t, allow)
+	assert.True(t, retryAfter > 0 && retryAfter <= time.Hour)
+
+	// limiters sharing the same store and key share the same limit
+	assert.False(t, NewLimiter(store, "api", ratelimit.PerHour(3)).Allow())
+	assert.True(t, NewLimiter(store, "other", ratelimit.PerHour(3)).Allow())
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	limiter := NewLimiter(kvstore.NewMemoryStore(), "api", ratelimit.Limit{
+		Rate:   1,
+		Period: 50 * time.Millisecond,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, limiter.Wait(ctx))
+	assert.NoError(t, limiter.Wait(ctx))
+}
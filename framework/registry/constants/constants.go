@@ -5,6 +5,7 @@ const (
 	SD_ETCD       = "etcd"
 	SD_MEMBERLIST = "memberlist"
 	SD_ZK         = "zk"
+	SD_CONSUL     = "consul"
 )
 
 type ServiceType string
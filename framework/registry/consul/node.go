@@ -0,0 +1,395 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/consul/api"
+	"github.com/unionj-cloud/go-doudou/v2/framework/buildinfo"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	cons "github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/interfaces"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/utils"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/constants"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// watchWaitTime is the max time a blocking query for healthy instances waits for changes
+const watchWaitTime = 5 * time.Minute
+
+// watchRetryInterval is the interval for retrying failed blocking queries
+const watchRetryInterval = 3 * time.Second
+
+var onceConsul sync.Once
+var ConsulCli *api.Client
+
+var lock sync.Mutex
+var providers = map[string]interfaces.IServiceProvider{}
+var registrations = map[cons.ServiceType]*registration{}
+
+type registration struct {
+	id      string
+	checkID string
+	stop    chan struct{}
+}
+
+// InitConsulCli creates consul client from GddConsulAddress and GddConsulToken
+func InitConsulCli() {
+	conf := api.DefaultConfig()
+	if address := config.GddConsulAddress.LoadOrDefault(config.DefaultGddConsulAddress); stringutils.IsNotEmpty(address) {
+		conf.Address = address
+	}
+	if token := config.GddConsulToken.LoadOrDefault(config.DefaultGddConsulToken); stringutils.IsNotEmpty(token) {
+		conf.Token = token
+	}
+	var err error
+	if ConsulCli, err = api.NewClient(conf); err != nil {
+		zlogger.Panic().Err(err).Msg("[go-doudou] failed to create consul client")
+	}
+}
+
+func parseDuration(key, value, defaultValue string) time.Duration {
+	if stringutils.IsEmpty(value) {
+		value = defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		zlogger.Debug().Msgf("Parse %s %s as time.Duration failed, use default %s instead.\n", key, value, defaultValue)
+		d, _ = time.ParseDuration(defaultValue)
+	}
+	return d
+}
+
+func ttl() time.Duration {
+	return parseDuration(string(config.GddConsulTTL), config.GddConsulTTL.Load(), config.DefaultGddConsulTTL)
+}
+
+func weight() int {
+	weight := config.DefaultGddWeight
+	if stringutils.IsNotEmpty(config.GddWeight.Load()) {
+		if w, err := cast.ToIntE(config.GddWeight.Load()); err == nil {
+			weight = w
+		}
+	}
+	// consul requires positive passing weight
+	if weight <= 0 {
+		weight = 1
+	}
+	return weight
+}
+
+func populateMeta(meta map[string]string, isGrpc bool, userData ...map[string]interface{}) {
+	buildTime := buildinfo.BuildTime
+	if stringutils.IsNotEmpty(buildinfo.BuildTime) {
+		if t, err := time.Parse(constants.FORMAT15, buildinfo.BuildTime); err == nil {
+			buildTime = t.Local().Format(constants.FORMAT8)
+		}
+	}
+	rr := config.DefaultGddRouteRootPath
+	if stringutils.IsNotEmpty(config.GddRouteRootPath.Load()) {
+		rr = config.GddRouteRootPath.Load()
+	}
+	meta["registerAt"] = time.Now().Local().Format(constants.FORMAT8)
+	meta["goVer"] = runtime.Version()
+	meta["weight"] = strconv.Itoa(weight())
+	if stringutils.IsNotEmpty(buildinfo.GddVer) {
+		meta["gddVer"] = buildinfo.GddVer
+	}
+	if stringutils.IsNotEmpty(buildinfo.BuildUser) {
+		meta["buildUser"] = buildinfo.BuildUser
+	}
+	if stringutils.IsNotEmpty(buildTime) {
+		meta["buildTime"] = buildTime
+	}
+	if stringutils.IsNotEmpty(rr) && !isGrpc {
+		meta["rootPath"] = rr
+	}
+	for _, item := range userData {
+		for k, v := range item {
+			meta[k] = fmt.Sprint(v)
+		}
+	}
+}
+
+func registerService(serviceType cons.ServiceType, port uint64, userData ...map[string]interface{}) {
+	onceConsul.Do(func() {
+		InitConsulCli()
+	})
+	service := config.GetServiceName() + "_" + string(serviceType)
+	host := utils.GetRegisterHost()
+	id := service + "-" + host + "-" + strconv.Itoa(int(port))
+	meta := make(map[string]string)
+	populateMeta(meta, serviceType == cons.GRPC_TYPE, userData...)
+	checkTTL := ttl()
+	reg := &api.AgentServiceRegistration{
+		ID:      id,
+		Name:    service,
+		Address: host,
+		Port:    int(port),
+		Tags:    []string{string(serviceType)},
+		Meta:    meta,
+		Weights: &api.AgentWeights{
+			Passing: weight(),
+			Warning: 1,
+		},
+		Check: &api.AgentServiceCheck{
+			CheckID: id + "-ttl",
+			TTL:     checkTTL.String(),
+			// passing at once, so that the service is discoverable without waiting for the first ttl update
+			Status: api.HealthPassing,
+			DeregisterCriticalServiceAfter: parseDuration(string(config.GddConsulDeregisterAfter),
+				config.GddConsulDeregisterAfter.Load(), config.DefaultGddConsulDeregisterAfter).String(),
+		},
+	}
+	if err := ConsulCli.Agent().ServiceRegister(reg); err != nil {
+		zlogger.Panic().Err(err).Msgf("[go-doudou] register %s to consul failed", service)
+	}
+	r := &registration{
+		id:      id,
+		checkID: reg.Check.CheckID,
+		stop:    make(chan struct{}),
+	}
+	go keepAlive(r, checkTTL)
+	lock.Lock()
+	registrations[serviceType] = r
+	lock.Unlock()
+}
+
+// keepAlive passes ttl health check of r every third of ttl until r is deregistered
+func keepAlive(r *registration, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if err := ConsulCli.Agent().UpdateTTL(r.checkID, "", api.HealthPassing); err != nil {
+				zlogger.Error().Err(err).Msgf("[go-doudou] failed to pass consul health check %s", r.checkID)
+			}
+		}
+	}
+}
+
+func deregisterService(serviceType cons.ServiceType) {
+	lock.Lock()
+	r, ok := registrations[serviceType]
+	delete(registrations, serviceType)
+	lock.Unlock()
+	if !ok {
+		return
+	}
+	close(r.stop)
+	if err := ConsulCli.Agent().ServiceDeregister(r.id); err != nil {
+		zlogger.Error().Err(err).Msgf("[go-doudou] failed to deregister %s from consul", r.id)
+		return
+	}
+	zlogger.Info().Msgf("[go-doudou] deregistered %s from consul successfully", r.id)
+}
+
+// NewRest registers rest service to consul agent with a ttl health check passed by local node periodically
+func NewRest(data ...map[string]interface{}) {
+	registerService(cons.REST_TYPE, config.GetPort(), data...)
+	zlogger.Info().Msgf("[go-doudou] %s registered to consul successfully", config.GetServiceName()+"_"+string(cons.REST_TYPE))
+}
+
+// NewGrpc registers grpc service to consul agent with a ttl health check passed by local node periodically
+func NewGrpc(data ...map[string]interface{}) {
+	registerService(cons.GRPC_TYPE, config.GetGrpcPort(), data...)
+	zlogger.Info().Msgf("[go-doudou] %s registered to consul successfully", config.GetServiceName()+"_"+string(cons.GRPC_TYPE))
+}
+
+// ShutdownRest deregisters rest service from consul agent
+func ShutdownRest() {
+	deregisterService(cons.REST_TYPE)
+}
+
+// ShutdownGrpc deregisters grpc service from consul agent
+func ShutdownGrpc() {
+	deregisterService(cons.GRPC_TYPE)
+}
+
+// Shutdown deregisters all services of local node from consul agent and stops watching by service providers
+func Shutdown() {
+	ShutdownRest()
+	ShutdownGrpc()
+	lock.Lock()
+	defer lock.Unlock()
+	for name, p := range providers {
+		p.Close()
+		delete(providers, name)
+	}
+}
+
+// AllNodes returns instances of service passing all health checks
+func AllNodes(service string) ([]*api.ServiceEntry, error) {
+	onceConsul.Do(func() {
+		InitConsulCli()
+	})
+	entries, _, err := ConsulCli.Health().Service(service, "", true, nil)
+	return entries, err
+}
+
+// RRServiceProvider is a simple round-robin load balance implementation for IServiceProvider.
+// It watches healthy instances of the service by consul blocking queries.
+type RRServiceProvider struct {
+	current  uint64
+	lock     sync.Mutex
+	target   string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	curState atomic.Value
+}
+
+type address struct {
+	addr          string
+	rootPath      string
+	weight        int
+	currentWeight int
+}
+
+type state struct {
+	addresses []*address
+}
+
+func (r *RRServiceProvider) watch() {
+	var index uint64
+	for {
+		q := (&api.QueryOptions{
+			WaitIndex: index,
+			WaitTime:  watchWaitTime,
+		}).WithContext(r.ctx)
+		entries, meta, err := ConsulCli.Health().Service(r.target, "", true, q)
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			zlogger.Error().Err(err).Msgf("[go-doudou] failed to watch %s instances from consul", r.target)
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(watchRetryInterval):
+			}
+			continue
+		}
+		// index going backwards means consul state was reset, so start over
+		if meta.LastIndex < index {
+			index = 0
+		} else {
+			index = meta.LastIndex
+		}
+		r.lock.Lock()
+		r.curState.Store(state{addresses: convertToAddress(entries)})
+		r.lock.Unlock()
+	}
+}
+
+func (r *RRServiceProvider) Close() {
+	if r != nil {
+		r.cancel()
+	}
+}
+
+func convertToAddress(entries []*api.ServiceEntry) (addrs []*address) {
+	for _, entry := range entries {
+		addr := entry.Service.Address
+		if stringutils.IsEmpty(addr) {
+			addr = entry.Node.Address
+		}
+		weight := entry.Service.Weights.Passing
+		if weight <= 0 {
+			weight = 1
+		}
+		addrs = append(addrs, &address{
+			addr:     addr + ":" + strconv.Itoa(entry.Service.Port),
+			rootPath: entry.Service.Meta["rootPath"],
+			weight:   weight,
+		})
+	}
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return addrs[i].addr < addrs[j].addr
+	})
+	return
+}
+
+func (r *RRServiceProvider) instances() []*address {
+	if r.curState.Load() == nil {
+		return nil
+	}
+	instances := r.curState.Load().(state).addresses
+	if len(instances) == 0 {
+		zlogger.Error().Msgf("[go-doudou] %s server not found", r.target)
+	}
+	return instances
+}
+
+// SelectServer selects a healthy instance of the service in turn
+func (r *RRServiceProvider) SelectServer() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	instances := r.instances()
+	if len(instances) == 0 {
+		return ""
+	}
+	next := int(r.current % uint64(len(instances)))
+	r.current++
+	selected := instances[next]
+	return fmt.Sprintf("http://%s%s", selected.addr, selected.rootPath)
+}
+
+// NewRRServiceProvider creates new RRServiceProvider instance
+func NewRRServiceProvider(serviceName string) *RRServiceProvider {
+	onceConsul.Do(func() {
+		InitConsulCli()
+	})
+	r := &RRServiceProvider{
+		target: serviceName,
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	lock.Lock()
+	providers[serviceName] = r
+	lock.Unlock()
+	go r.watch()
+	return r
+}
+
+// SWRRServiceProvider is a smooth weighted round-robin service provider using passing weights of instances
+type SWRRServiceProvider struct {
+	*RRServiceProvider
+}
+
+// SelectServer selects a healthy instance of the service in proportion to its weight
+func (n *SWRRServiceProvider) SelectServer() string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	instances := n.instances()
+	if len(instances) == 0 {
+		return ""
+	}
+	var selected *address
+	total := 0
+	for i := 0; i < len(instances); i++ {
+		s := instances[i]
+		s.currentWeight += s.weight
+		total += s.weight
+		if selected == nil || s.currentWeight > selected.currentWeight {
+			selected = s
+		}
+	}
+	selected.currentWeight -= total
+	return fmt.Sprintf("http://%s%s", selected.addr, selected.rootPath)
+}
+
+// NewSWRRServiceProvider creates new SWRRServiceProvider instance
+func NewSWRRServiceProvider(serviceName string) *SWRRServiceProvider {
+	return &SWRRServiceProvider{
+		RRServiceProvider: NewRRServiceProvider(serviceName),
+	}
+}
@@ -0,0 +1,209 @@
+package consul_test
+
+import (
+	"encoding/json"
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/consul"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAgent is a minimal in-memory consul agent supporting service registration, ttl checks and
+// blocking queries of healthy service instances
+type fakeAgent struct {
+	lock     sync.Mutex
+	index    uint64
+	changed  chan struct{}
+	services map[string]*api.AgentServiceRegistration
+	ttls     map[string]int
+	token    string
+}
+
+func newFakeAgent() *fakeAgent {
+	return &fakeAgent{
+		index:    1,
+		changed:  make(chan struct{}),
+		services: make(map[string]*api.AgentServiceRegistration),
+		ttls:     make(map[string]int),
+	}
+}
+
+func (a *fakeAgent) change() {
+	a.index++
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+func (a *fakeAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.lock.Lock()
+	a.token = r.Header.Get("X-Consul-Token")
+	a.lock.Unlock()
+	switch {
+	case r.URL.Path == "/v1/agent/service/register":
+		var reg api.AgentServiceRegistration
+		if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		a.lock.Lock()
+		a.services[reg.ID] = &reg
+		a.change()
+		a.lock.Unlock()
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		a.lock.Lock()
+		delete(a.services, strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/"))
+		a.change()
+		a.lock.Unlock()
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/check/update/"):
+		a.lock.Lock()
+		a.ttls[strings.TrimPrefix(r.URL.Path, "/v1/agent/check/update/")]++
+		a.lock.Unlock()
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		name := strings.TrimPrefix(r.URL.Path, "/v1/health/service/")
+		a.lock.Lock()
+		if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index == a.index {
+			changed := a.changed
+			a.lock.Unlock()
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			a.lock.Lock()
+		}
+		var entries []*api.ServiceEntry
+		for _, reg := range a.services {
+			if reg.Name != name {
+				continue
+			}
+			entries = append(entries, &api.ServiceEntry{
+				Node: &api.Node{Address: "127.0.0.1"},
+				Service: &api.AgentService{
+					ID:      reg.ID,
+					Service: reg.Name,
+					Address: reg.Address,
+					Port:    reg.Port,
+					Meta:    reg.Meta,
+					Weights: *reg.Weights,
+				},
+			})
+		}
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(a.index, 10))
+		a.lock.Unlock()
+		json.NewEncoder(w).Encode(entries)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setup(t *testing.T) *fakeAgent {
+	agent := newFakeAgent()
+	server := httptest.NewServer(agent)
+	t.Cleanup(server.Close)
+	config.GddServiceName.Write("usersvc")
+	config.GddRegisterHost.Write("10.0.0.5")
+	config.GddPort.Write("6060")
+	config.GddConsulAddress.Write(strings.TrimPrefix(server.URL, "http://"))
+	config.GddConsulToken.Write("secret")
+	config.GddConsulTTL.Write("300ms")
+	config.GddWeight.Write("3")
+	config.GddRouteRootPath.Write("/api")
+	t.Cleanup(func() {
+		os.Unsetenv(string(config.GddServiceName))
+		os.Unsetenv(string(config.GddRegisterHost))
+		os.Unsetenv(string(config.GddPort))
+		os.Unsetenv(string(config.GddConsulAddress))
+		os.Unsetenv(string(config.GddConsulToken))
+		os.Unsetenv(string(config.GddConsulTTL))
+		os.Unsetenv(string(config.GddWeight))
+		os.Unsetenv(string(config.GddRouteRootPath))
+	})
+	consul.InitConsulCli()
+	return agent
+}
+
+func TestNewRest_Shutdown(t *testing.T) {
+	agent := setup(t)
+	consul.NewRest(map[string]interface{}{
+		"zone": "a",
+	})
+
+	agent.lock.Lock()
+	reg := agent.services["usersvc_rest-10.0.0.5-6060"]
+	agent.lock.Unlock()
+	require.NotNil(t, reg)
+	require.Equal(t, "usersvc_rest", reg.Name)
+	require.Equal(t, "10.0.0.5", reg.Address)
+	require.Equal(t, 6060, reg.Port)
+	require.Equal(t, 3, reg.Weights.Passing)
+	require.Equal(t, "3", reg.Meta["weight"])
+	require.Equal(t, "/api", reg.Meta["rootPath"])
+	require.Equal(t, "a", reg.Meta["zone"])
+	require.Equal(t, "300ms", reg.Check.TTL)
+	require.Equal(t, api.HealthPassing, reg.Check.Status)
+
+	nodes, err := consul.AllNodes("usersvc_rest")
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+
+	time.Sleep(500 * time.Millisecond)
+	agent.lock.Lock()
+	require.Greater(t, agent.ttls["usersvc_rest-10.0.0.5-6060-ttl"], 0)
+	require.Equal(t, "secret", agent.token)
+	agent.lock.Unlock()
+
+	consul.Shutdown()
+	agent.lock.Lock()
+	require.Empty(t, agent.services)
+	agent.lock.Unlock()
+}
+
+func TestSWRRServiceProvider(t *testing.T) {
+	agent := setup(t)
+	defer consul.Shutdown()
+	sp := consul.NewSWRRServiceProvider("usersvc_rest")
+	require.Equal(t, "", sp.SelectServer())
+
+	agent.lock.Lock()
+	for i, weight := range []int{3, 1} {
+		reg := &api.AgentServiceRegistration{
+			ID:      "usersvc_rest-" + strconv.Itoa(i),
+			Name:    "usersvc_rest",
+			Address: "10.0.0." + strconv.Itoa(i),
+			Port:    6060,
+			Meta: map[string]string{
+				"rootPath": "/api",
+			},
+			Weights: &api.AgentWeights{Passing: weight, Warning: 1},
+		}
+		agent.services[reg.ID] = reg
+	}
+	agent.change()
+	agent.lock.Unlock()
+
+	require.Eventually(t, func() bool {
+		return sp.SelectServer() != ""
+	}, 5*time.Second, 10*time.Millisecond)
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		counts[sp.SelectServer()]++
+	}
+	require.Equal(t, 300, counts["http://10.0.0.0:6060/api"])
+	require.Equal(t, 100, counts["http://10.0.0.1:6060/api"])
+
+	agent.lock.Lock()
+	delete(agent.services, "usersvc_rest-0")
+	agent.change()
+	agent.lock.Unlock()
+	require.Eventually(t, func() bool {
+		return sp.SelectServer() == "http://10.0.0.1:6060/api" && sp.SelectServer() == "http://10.0.0.1:6060/api"
+	}, 5*time.Second, 10*time.Millisecond)
+}
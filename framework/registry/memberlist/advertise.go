@@ -0,0 +1,108 @@
+package memberlist
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// setGddMemAdvertise sets addresses memberlist binds to and advertises to other nodes. In cloud environments a node is
+// often behind NAT, e.g. a vm whose private ip 10.0.0.5 is mapped to public ip 203.0.113.10, and nodes of other
+// networks can only reach it by the public one. In this case, set GddMemBindAddr to the private ip and GddMemHost to the
+// public ip, or GddMemAdvertiseURL to a cloud metadata endpoint to let the node find its public ip by itself.
+// If NAT forwards a different public port to GddMemPort, set GddMemAdvertisePort to the public port.
+// Both tcp and udp traffic to the advertised address must be forwarded to the bound address.
+func setGddMemAdvertise(conf *memberlist.Config) {
+	if bindAddr := config.GddMemBindAddr.LoadOrDefault(config.DefaultGddMemBindAddr); stringutils.IsNotEmpty(bindAddr) {
+		if net.ParseIP(bindAddr) != nil {
+			conf.BindAddr = bindAddr
+		} else {
+			logger.Warn().Msgf("[go-doudou] invalid %s %s, bind to %s instead", string(config.GddMemBindAddr), bindAddr, conf.BindAddr)
+		}
+	}
+	if port, err := cast.ToIntE(config.GddMemAdvertisePort.Load()); err == nil && port > 0 {
+		conf.AdvertisePort = port
+	}
+	memhost := config.GddMemHost.Load()
+	if stringutils.IsNotEmpty(memhost) {
+		if strings.HasPrefix(memhost, ".") {
+			hostname, _ := os.Hostname()
+			conf.AdvertiseAddr = hostname + memhost
+		} else {
+			conf.AdvertiseAddr = memhost
+		}
+		return
+	}
+	if url := config.GddMemAdvertiseURL.LoadOrDefault(config.DefaultGddMemAdvertiseURL); stringutils.IsNotEmpty(url) {
+		addr, err := fetchAdvertiseAddr(url)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("[go-doudou] failed to get advertise address from %s, fallback to private ip", url)
+			return
+		}
+		logger.Info().Msgf("[go-doudou] memberlist advertise address %s is got from %s", addr, url)
+		conf.AdvertiseAddr = addr
+	}
+}
+
+// fetchAdvertiseAddr gets routable ip from cloud metadata endpoint url, which responds the ip in plain text
+func fetchAdvertiseAddr(url string) (string, error) {
+	timeout, err := time.ParseDuration(config.GddMemAdvertiseURLTimeout.LoadOrDefault(config.DefaultGddMemAdvertiseURLTimeout))
+	if err != nil {
+		timeout, _ = time.ParseDuration(config.DefaultGddMemAdvertiseURLTimeout)
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	for _, item := range strings.Split(config.GddMemAdvertiseURLHeaders.LoadOrDefault(config.DefaultGddMemAdvertiseURLHeaders), ",") {
+		if kv := strings.SplitN(item, ":", 2); len(kv) == 2 {
+			req.Header.Set(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+		}
+	}
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	addr := strings.TrimSpace(string(body))
+	if net.ParseIP(addr) == nil {
+		return "", errors.Errorf("invalid ip %q", addr)
+	}
+	return addr, nil
+}
+
+// advertiseCheckTimeout is the timeout of dialing advertised address by checkAdvertise
+const advertiseCheckTimeout = 3 * time.Second
+
+// checkAdvertise dials advertised address of local node by tcp if it differs from the bound address, and returns error
+// if it is unreachable. Other nodes may not be able to reach local node in that case, e.g. NAT doesn't forward
+// the port. Some NAT gateways don't support hairpinning, so a failed check is only a hint.
+func checkAdvertise(conf *memberlist.Config, local *memberlist.Node) error {
+	if stringutils.IsEmpty(conf.AdvertiseAddr) || (conf.AdvertiseAddr == conf.BindAddr && conf.AdvertisePort == conf.BindPort) {
+		return nil
+	}
+	address := net.JoinHostPort(local.Addr, fmt.Sprint(local.Port))
+	conn, err := net.DialTimeout("tcp", address, advertiseCheckTimeout)
+	if err != nil {
+		return errors.Wrapf(err, "[go-doudou] memberlist advertise address %s is unreachable, check %s, %s and NAT port forwarding",
+			address, string(config.GddMemHost), string(config.GddMemAdvertisePort))
+	}
+	return conn.Close()
+}
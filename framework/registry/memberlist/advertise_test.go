@@ -0,0 +1,82 @@
+package memberlist
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestNewConf_NAT(t *testing.T) {
+	config.GddMemPort.Write("7946")
+	config.GddMemBindAddr.Write("10.0.0.5")
+	config.GddMemHost.Write("203.0.113.10")
+	config.GddMemAdvertisePort.Write("17946")
+	defer func() {
+		os.Unsetenv(string(config.GddMemPort))
+		os.Unsetenv(string(config.GddMemBindAddr))
+		os.Unsetenv(string(config.GddMemHost))
+		os.Unsetenv(string(config.GddMemAdvertisePort))
+	}()
+	conf := newConf()
+	require.Equal(t, "10.0.0.5", conf.BindAddr)
+	require.Equal(t, 7946, conf.BindPort)
+	require.Equal(t, "203.0.113.10", conf.AdvertiseAddr)
+	require.Equal(t, 17946, conf.AdvertisePort)
+
+	config.GddMemBindAddr.Write("not-an-ip")
+	require.Equal(t, "0.0.0.0", newConf().BindAddr)
+}
+
+func TestNewConf_AdvertiseURL(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(status)
+		fmt.Fprintln(w, "198.51.100.7")
+	}))
+	defer ts.Close()
+	config.GddMemAdvertiseURL.Write(ts.URL)
+	config.GddMemAdvertiseURLHeaders.Write("Metadata-Flavor:Google")
+	defer func() {
+		os.Unsetenv(string(config.GddMemAdvertiseURL))
+		os.Unsetenv(string(config.GddMemAdvertiseURLHeaders))
+		os.Unsetenv(string(config.GddMemHost))
+	}()
+	require.Equal(t, "198.51.100.7", newConf().AdvertiseAddr)
+
+	config.GddMemHost.Write("203.0.113.10")
+	require.Equal(t, "203.0.113.10", newConf().AdvertiseAddr)
+	os.Unsetenv(string(config.GddMemHost))
+
+	status = http.StatusInternalServerError
+	require.Empty(t, newConf().AdvertiseAddr)
+}
+
+func TestCheckAdvertise(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	conf := &memberlist.Config{
+		BindAddr:      "0.0.0.0",
+		BindPort:      port,
+		AdvertiseAddr: "127.0.0.1",
+		AdvertisePort: port,
+	}
+	local := &memberlist.Node{Addr: "127.0.0.1", Port: uint16(port)}
+	require.NoError(t, checkAdvertise(conf, local))
+
+	ln.Close()
+	require.Error(t, checkAdvertise(conf, local))
+
+	conf.AdvertiseAddr = ""
+	require.NoError(t, checkAdvertise(conf, local))
+}
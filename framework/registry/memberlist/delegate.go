@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/pkg/errors"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
@@ -41,35 +42,92 @@ type NodeMeta struct {
 	BuildUser  string     `json:"buildUser"`
 	BuildTime  string     `json:"buildTime"`
 	Weight     int        `json:"weight"`
+	Status     string     `json:"status,omitempty"`
+	// Draining is true if the node is migrating its traffic to other nodes before shutdown
+	Draining bool `json:"draining,omitempty"`
 }
 
 type delegate struct {
 	meta  NodeMeta
+	data  map[string]interface{}
 	lock  sync.Mutex
 	queue *memberlist.TransmitLimitedQueue
 }
 
-func (d *delegate) AddService(service Service) {
+// AddService adds service to node meta. It returns error and leaves node meta unchanged if encoded node meta
+// would exceed limit.
+func (d *delegate) AddService(service Service, limit int) error {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
-	d.meta.Services = append(d.meta.Services, service)
+	meta := d.meta
+	meta.Services = make([]Service, 0, len(d.meta.Services)+1)
+	meta.Services = append(meta.Services, d.meta.Services...)
+	meta.Services = append(meta.Services, service)
+	if _, err := encodeMeta(meta, limit); err != nil {
+		return err
+	}
+	d.meta = meta
+	return nil
 }
 
-// NodeMeta return user custom node meta data
-func (d *delegate) NodeMeta(limit int) []byte {
+// SetStatus sets readiness status of local node
+func (d *delegate) SetStatus(status string) {
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
+	d.meta.Status = status
+}
+
+// SetDraining sets draining status of local node
+func (d *delegate) SetDraining(draining bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.meta.Draining = draining
+}
+
+// SetServiceData replaces custom data of all services of local node. It returns error and leaves node meta unchanged
+// if encoded node meta would exceed limit.
+func (d *delegate) SetServiceData(data map[string]interface{}, limit int) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	meta := d.meta
+	meta.Services = make([]Service, len(d.meta.Services))
+	copy(meta.Services, d.meta.Services)
+	for i := range meta.Services {
+		meta.Services[i].Data = data
+	}
+	if _, err := encodeMeta(meta, limit); err != nil {
+		return err
+	}
+	d.meta = meta
+	return nil
+}
+
+// encodeMeta encodes meta by msgpack and returns error if the encoded size exceeds limit
+func encodeMeta(meta NodeMeta, limit int) ([]byte, error) {
 	var buf bytes.Buffer
 	enc := codec.NewEncoder(&buf, &codec.MsgpackHandle{})
-	if err := enc.Encode(d.meta); err != nil {
-		logger.Panic().Err(err).Msg("[go-doudou] Failed to encode node meta data")
+	if err := enc.Encode(meta); err != nil {
+		return nil, errors.Wrap(err, "[go-doudou] failed to encode node meta data")
 	}
 	raw := buf.Bytes()
-
 	if len(raw) > limit {
-		logger.Panic().Msgf("[go-doudou] Node meta data '%v' exceeds length limit of %d bytes", d.meta, limit)
+		return nil, errors.Errorf("[go-doudou] node meta data is %d bytes, exceeds length limit of %d bytes", len(raw), limit)
+	}
+	return raw, nil
+}
+
+// NodeMeta return user custom node meta data
+func (d *delegate) NodeMeta(limit int) []byte {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	raw, err := encodeMeta(d.meta, limit)
+	if err != nil {
+		logger.Panic().Err(err).Msgf("[go-doudou] invalid node meta data '%v'", d.meta)
 	}
 	return raw
 }
@@ -92,9 +150,10 @@ func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
 
 // LocalState also sends user data, but by tcp connection when pushPull-ing state with other node
 func (d *delegate) LocalState(join bool) []byte {
-	return nil
+	return d.encodeData()
 }
 
 // MergeRemoteState gets user data from remote node by tcp connection when pushPull-ing state with other node
 func (d *delegate) MergeRemoteState(s []byte, join bool) {
+	d.mergeData(s)
 }
@@ -0,0 +1,33 @@
+package memberlist
+
+import (
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"strings"
+)
+
+// setGddMemEncryption enables encryption of gossip traffic if GddMemSecretKey is set. To roll out encryption on a
+// running cluster, restart all nodes with the key and both GddMemGossipVerifyIncoming and GddMemGossipVerifyOutgoing
+// set to false, then set GddMemGossipVerifyOutgoing to true, and finally GddMemGossipVerifyIncoming to true.
+// It returns error if the key is not valid base64 or its decoded length is not 16, 24 or 32 bytes.
+func setGddMemEncryption(conf *memberlist.Config) error {
+	conf.GossipVerifyIncoming = cast.ToBoolOrDefault(config.GddMemGossipVerifyIncoming.Load(), config.DefaultGddMemGossipVerifyIncoming)
+	conf.GossipVerifyOutgoing = cast.ToBoolOrDefault(config.GddMemGossipVerifyOutgoing.Load(), config.DefaultGddMemGossipVerifyOutgoing)
+	encoded := strings.TrimSpace(config.GddMemSecretKey.LoadOrDefault(config.DefaultGddMemSecretKey))
+	if stringutils.IsEmpty(encoded) {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return errors.Wrapf(err, "[go-doudou] %s is not valid base64", string(config.GddMemSecretKey))
+	}
+	if err = memberlist.ValidateKey(key); err != nil {
+		return errors.Wrapf(err, "[go-doudou] invalid %s, got %d bytes after base64 decoding", string(config.GddMemSecretKey), len(key))
+	}
+	conf.SecretKey = key
+	return nil
+}
@@ -0,0 +1,38 @@
+package memberlist
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"os"
+	"testing"
+)
+
+func Test_setGddMemEncryption(t *testing.T) {
+	defer func() {
+		os.Unsetenv(string(config.GddMemSecretKey))
+		os.Unsetenv(string(config.GddMemGossipVerifyIncoming))
+		os.Unsetenv(string(config.GddMemGossipVerifyOutgoing))
+	}()
+	conf := newConf()
+	require.NoError(t, setGddMemEncryption(conf))
+	require.Nil(t, conf.SecretKey)
+	require.True(t, conf.GossipVerifyIncoming)
+	require.True(t, conf.GossipVerifyOutgoing)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	config.GddMemSecretKey.Write(base64.StdEncoding.EncodeToString(key))
+	config.GddMemGossipVerifyIncoming.Write("false")
+	config.GddMemGossipVerifyOutgoing.Write("false")
+	conf = newConf()
+	require.NoError(t, setGddMemEncryption(conf))
+	require.Equal(t, key, conf.SecretKey)
+	require.False(t, conf.GossipVerifyIncoming)
+	require.False(t, conf.GossipVerifyOutgoing)
+
+	config.GddMemSecretKey.Write(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.ErrorContains(t, setGddMemEncryption(newConf()), "9 bytes")
+
+	config.GddMemSecretKey.Write("not base64!")
+	require.ErrorContains(t, setGddMemEncryption(newConf()), "not valid base64")
+}
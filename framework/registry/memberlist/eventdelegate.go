@@ -6,6 +6,7 @@ import (
 
 type eventDelegate struct {
 	ServiceProviders []IMemberlistServiceProvider
+	dispatcher       eventDispatcher
 }
 
 func (e *eventDelegate) NotifySuspectSateChange(node *memberlist.Node) {
@@ -29,6 +30,7 @@ func (e *eventDelegate) NotifyJoin(node *memberlist.Node) {
 	for _, sp := range e.ServiceProviders {
 		sp.AddNode(node)
 	}
+	e.dispatcher.dispatch(nodeJoin, node)
 }
 
 // NotifyLeave callback function when node leave
@@ -36,6 +38,7 @@ func (e *eventDelegate) NotifyLeave(node *memberlist.Node) {
 	for _, sp := range e.ServiceProviders {
 		sp.RemoveNode(node)
 	}
+	e.dispatcher.dispatch(nodeLeave, node)
 }
 
 // NotifyUpdate callback function when node updated
@@ -43,4 +46,5 @@ func (e *eventDelegate) NotifyUpdate(node *memberlist.Node) {
 	for _, sp := range e.ServiceProviders {
 		sp.AddNode(node)
 	}
+	e.dispatcher.dispatch(nodeUpdate, node)
 }
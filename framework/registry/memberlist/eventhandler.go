@@ -0,0 +1,158 @@
+package memberlist
+
+import (
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"runtime/debug"
+	"sync"
+)
+
+// NodeInfo describes a node of memberlist cluster
+type NodeInfo struct {
+	// Name is the unique name of the node in cluster
+	Name string `json:"name"`
+	// Address is host:port of the node for gossip
+	Address string `json:"address"`
+	// State is the state of the node when the event happened
+	State memberlist.NodeStateType `json:"state"`
+	// Weight is the latest weight calculated by the node, only meaningful if Meta.Weight is not positive
+	Weight int `json:"weight"`
+	// Meta is the node meta with services supplied by the node
+	Meta NodeMeta `json:"meta"`
+	// Data is custom data of services supplied by the node merged in order, see NewRest, NewGrpc and UpdateData.
+	// Use DataString, DataInt, DataBool or UnmarshalData to read it.
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Info returns NodeInfo of node
+func Info(node *memberlist.Node) NodeInfo {
+	meta, _ := ParseMeta(node)
+	var data map[string]interface{}
+	for _, service := range meta.Services {
+		for k, v := range service.Data {
+			if data == nil {
+				data = make(map[string]interface{})
+			}
+			data[k] = v
+		}
+	}
+	return NodeInfo{
+		Name:    node.Name,
+		Address: node.Address(),
+		State:   node.State,
+		Weight:  node.Weight,
+		Meta:    meta,
+		Data:    data,
+	}
+}
+
+// SupplyService reports whether the node supplies service named svc, e.g. usersvc_rest
+func (n NodeInfo) SupplyService(svc string) bool {
+	for _, service := range n.Meta.Services {
+		if service.Name == svc {
+			return true
+		}
+	}
+	return false
+}
+
+// EventHandler receives membership changes of memberlist cluster, e.g. for cache invalidation or leader election.
+// Methods are called one by one in the order of events from a dedicated goroutine, never with memberlist lock held,
+// so a slow handler doesn't stall gossip but delays events to all handlers. Panics in handlers are recovered and logged.
+type EventHandler interface {
+	// OnJoin is called when a node joined the cluster
+	OnJoin(node NodeInfo)
+	// OnLeave is called when a node left the cluster or was declared dead
+	OnLeave(node NodeInfo)
+	// OnUpdate is called when node meta of a node was updated, e.g. a service was registered or readiness changed
+	OnUpdate(node NodeInfo)
+}
+
+// RegisterEventHandler registers h to receive membership changes of memberlist cluster from now on
+func RegisterEventHandler(h EventHandler) {
+	events.dispatcher.register(h)
+}
+
+type nodeEventType int
+
+const (
+	nodeJoin nodeEventType = iota
+	nodeLeave
+	nodeUpdate
+)
+
+type nodeEvent struct {
+	typ  nodeEventType
+	node memberlist.Node
+}
+
+// eventDispatcher queues node events without blocking and calls event handlers from a single goroutine
+type eventDispatcher struct {
+	lock     sync.Mutex
+	handlers []EventHandler
+	queue    []nodeEvent
+	notify   chan struct{}
+	once     sync.Once
+}
+
+func (d *eventDispatcher) register(h EventHandler) {
+	d.once.Do(func() {
+		d.notify = make(chan struct{}, 1)
+		go d.run()
+	})
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.handlers = append(d.handlers, h)
+}
+
+func (d *eventDispatcher) dispatch(typ nodeEventType, node *memberlist.Node) {
+	d.lock.Lock()
+	if len(d.handlers) == 0 {
+		d.lock.Unlock()
+		return
+	}
+	// node is copied as memberlist may change it after the event
+	d.queue = append(d.queue, nodeEvent{typ: typ, node: *node})
+	d.lock.Unlock()
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (d *eventDispatcher) run() {
+	for range d.notify {
+		for {
+			d.lock.Lock()
+			if len(d.queue) == 0 {
+				d.lock.Unlock()
+				break
+			}
+			event := d.queue[0]
+			d.queue = d.queue[1:]
+			handlers := make([]EventHandler, len(d.handlers))
+			copy(handlers, d.handlers)
+			d.lock.Unlock()
+			for _, h := range handlers {
+				d.handle(h, event)
+			}
+		}
+	}
+}
+
+func (d *eventDispatcher) handle(h EventHandler, event nodeEvent) {
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error().Msgf("[go-doudou] panic in memberlist event handler: %+v\n\nstacktrace from panic: %s\n", e, string(debug.Stack()))
+		}
+	}()
+	info := Info(&event.node)
+	switch event.typ {
+	case nodeJoin:
+		h.OnJoin(info)
+	case nodeLeave:
+		h.OnLeave(info)
+	case nodeUpdate:
+		h.OnUpdate(info)
+	}
+}
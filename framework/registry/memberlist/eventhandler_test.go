@@ -0,0 +1,106 @@
+package memberlist
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"testing"
+	"time"
+)
+
+type recordEventHandler struct {
+	events chan string
+}
+
+func (r *recordEventHandler) OnJoin(node NodeInfo) {
+	r.events <- "join " + node.Name + " " + node.Meta.Services[0].Name
+}
+
+func (r *recordEventHandler) OnLeave(node NodeInfo) {
+	r.events <- "leave " + node.Name
+}
+
+func (r *recordEventHandler) OnUpdate(node NodeInfo) {
+	r.events <- "update " + node.Name
+}
+
+type panicEventHandler struct {
+}
+
+func (p panicEventHandler) OnJoin(node NodeInfo) {
+	panic("join")
+}
+
+func (p panicEventHandler) OnLeave(node NodeInfo) {
+	panic("leave")
+}
+
+func (p panicEventHandler) OnUpdate(node NodeInfo) {
+	panic("update")
+}
+
+func TestRegisterEventHandler(t *testing.T) {
+	e := &eventDelegate{}
+	r := &recordEventHandler{
+		events: make(chan string, 3),
+	}
+	e.dispatcher.register(panicEventHandler{})
+	e.dispatcher.register(r)
+
+	node := newWeightedNode("node0", 6060, 1)
+	node.Addr = "127.0.0.1"
+	node.Port = 7946
+	e.NotifyJoin(node)
+	e.NotifyUpdate(node)
+	e.NotifyLeave(node)
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		select {
+		case event := <-r.events:
+			got = append(got, event)
+		case <-time.After(5 * time.Second):
+			t.Fatal("event handler was not called")
+		}
+	}
+	require.Equal(t, []string{"join node0 usersvc_rest", "update node0", "leave node0"}, got)
+}
+
+func TestRegisterEventHandler_NotBlocking(t *testing.T) {
+	e := &eventDelegate{}
+	release := make(chan struct{})
+	r := &recordEventHandler{
+		events: make(chan string),
+	}
+	e.dispatcher.register(r)
+	defer close(release)
+	go func() {
+		<-release
+		for range r.events {
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			e.NotifyUpdate(&memberlist.Node{Name: "node0"})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow event handler blocked notification")
+	}
+}
+
+func TestInfo(t *testing.T) {
+	node := newWeightedNode("node0", 6060, 3)
+	node.Addr = "127.0.0.1"
+	node.Port = 7946
+	node.State = memberlist.StateSuspect
+	info := Info(node)
+	require.Equal(t, "node0", info.Name)
+	require.Equal(t, "127.0.0.1:7946", info.Address)
+	require.Equal(t, memberlist.StateSuspect, info.State)
+	require.Equal(t, 3, info.Meta.Weight)
+}
@@ -0,0 +1,42 @@
+package memberlist
+
+import (
+	"github.com/hashicorp/logutils"
+	"github.com/rs/zerolog"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"sync"
+)
+
+// gossipLogWriter filters memberlist logs by level of memberlist log subsystem, which is set by GddMemLogLevel
+// or management endpoint at runtime and follows GddLogLevel if not set
+type gossipLogWriter struct {
+	lock      sync.Mutex
+	filter    *logutils.LevelFilter
+	subsystem *logger.Subsystem
+}
+
+func gossipMinLevel(level zerolog.Level) logutils.LogLevel {
+	switch {
+	case level <= zerolog.DebugLevel:
+		return "DEBUG"
+	case level == zerolog.InfoLevel:
+		return "INFO"
+	case level == zerolog.WarnLevel:
+		return "WARN"
+	default:
+		return "ERR"
+	}
+}
+
+func (w *gossipLogWriter) Write(p []byte) (n int, err error) {
+	level, _ := w.subsystem.Level()
+	if level == zerolog.Disabled {
+		return len(p), nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if minLevel := gossipMinLevel(level); w.filter.MinLevel != minLevel {
+		w.filter.SetMinLevel(minLevel)
+	}
+	return w.filter.Write(p)
+}
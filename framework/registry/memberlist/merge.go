@@ -0,0 +1,125 @@
+package memberlist
+
+import (
+	"bytes"
+	"github.com/hashicorp/go-msgpack/codec"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"sync"
+)
+
+// DataMergeFunc merges cluster data received from a remote node during push-pull into local cluster data and
+// returns the result, which replaces local cluster data. Both local and remote are copies, so they are safe to modify.
+//
+// It is called from memberlist background goroutines, once for each push-pull with a remote node. Remote nodes are
+// merged in no particular order and the same remote state may be merged several times, so a merge function must be
+// commutative and idempotent, e.g. keeps the value with max version for each key, otherwise nodes may never converge.
+// It must not block and must not call SetData or Data.
+type DataMergeFunc func(local, remote map[string]interface{}) map[string]interface{}
+
+// ReplaceData is the default DataMergeFunc, remote cluster data replaces local one as a whole
+func ReplaceData(local, remote map[string]interface{}) map[string]interface{} {
+	return remote
+}
+
+var dataMergeLock sync.Mutex
+var dataMerger DataMergeFunc = ReplaceData
+
+// RegisterDataMerger replaces the default DataMergeFunc ReplaceData with fn. It should be called before local
+// node created.
+func RegisterDataMerger(fn DataMergeFunc) {
+	dataMergeLock.Lock()
+	defer dataMergeLock.Unlock()
+	if fn == nil {
+		fn = ReplaceData
+	}
+	dataMerger = fn
+}
+
+func getDataMerger() DataMergeFunc {
+	dataMergeLock.Lock()
+	defer dataMergeLock.Unlock()
+	return dataMerger
+}
+
+func copyData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
+
+// SetData sets key of cluster data of local node, which is synced to other nodes by push-pull
+// and merged by registered DataMergeFunc
+func SetData(key string, value interface{}) {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	assertMlistNotNil()
+	delegator.SetData(key, value)
+}
+
+// Data returns a copy of cluster data of local node
+func Data() map[string]interface{} {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	assertMlistNotNil()
+	return delegator.Data()
+}
+
+// SetData sets key of cluster data
+func (d *delegate) SetData(key string, value interface{}) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.data == nil {
+		d.data = make(map[string]interface{})
+	}
+	d.data[key] = value
+}
+
+// Data returns a copy of cluster data
+func (d *delegate) Data() map[string]interface{} {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return copyData(d.data)
+}
+
+func (d *delegate) encodeData() []byte {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if len(d.data) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf, &codec.MsgpackHandle{})
+	if err := enc.Encode(d.data); err != nil {
+		logger.Error().Err(err).Msg("[go-doudou] Failed to encode cluster data")
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func (d *delegate) mergeData(s []byte) {
+	if len(s) == 0 {
+		return
+	}
+	var remote map[string]interface{}
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	dec := codec.NewDecoder(bytes.NewReader(s), handle)
+	if err := dec.Decode(&remote); err != nil {
+		logger.Error().Err(err).Msg("[go-doudou] Failed to decode cluster data from remote node")
+		return
+	}
+	merge := getDataMerger()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.data = copyData(merge(copyData(d.data), remote))
+}
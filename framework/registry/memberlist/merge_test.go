@@ -0,0 +1,68 @@
+package memberlist
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_delegate_MergeRemoteState_Replace(t *testing.T) {
+	local := &delegate{}
+	local.SetData("a", "1")
+	remote := &delegate{}
+	remote.SetData("b", "2")
+
+	local.MergeRemoteState(remote.LocalState(false), false)
+	require.Equal(t, map[string]interface{}{"b": "2"}, local.Data())
+
+	local.MergeRemoteState(nil, false)
+	require.Equal(t, map[string]interface{}{"b": "2"}, local.Data())
+}
+
+func Test_delegate_MergeRemoteState_Custom(t *testing.T) {
+	RegisterDataMerger(func(local, remote map[string]interface{}) map[string]interface{} {
+		if local == nil {
+			local = make(map[string]interface{})
+		}
+		for k, v := range remote {
+			if old, ok := local[k]; !ok || toInt(v) > toInt(old) {
+				local[k] = v
+			}
+		}
+		return local
+	})
+	defer RegisterDataMerger(nil)
+
+	local := &delegate{}
+	local.SetData("version", 3)
+	local.SetData("a", 1)
+	remote := &delegate{}
+	remote.SetData("version", 2)
+	remote.SetData("b", 5)
+	state := remote.LocalState(false)
+
+	local.MergeRemoteState(state, false)
+	local.MergeRemoteState(state, false)
+	data := local.Data()
+	require.EqualValues(t, 3, toInt(data["version"]))
+	require.EqualValues(t, 1, toInt(data["a"]))
+	require.EqualValues(t, 5, toInt(data["b"]))
+}
+
+func Test_delegate_MergeRemoteState_Invalid(t *testing.T) {
+	local := &delegate{}
+	local.SetData("a", "1")
+	local.MergeRemoteState([]byte("invalid"), false)
+	require.Equal(t, map[string]interface{}{"a": "1"}, local.Data())
+}
+
+func toInt(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case uint64:
+		return int64(n)
+	}
+	return 0
+}
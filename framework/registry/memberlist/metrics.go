@@ -0,0 +1,49 @@
+package memberlist
+
+import (
+	"github.com/armon/go-metrics"
+	prometheussink "github.com/armon/go-metrics/prometheus"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"sync"
+)
+
+var (
+	metricsOnce sync.Once
+	metricSink  metrics.MetricSink
+)
+
+// pushPullCounters are exposed to prometheus as memberlist_pushPull_sent, memberlist_pushPull_userState_sent
+// and memberlist_pushPull_userState_received. User state bytes are counted after compression.
+var pushPullCounters = []prometheussink.CounterDefinition{
+	{
+		Name: []string{"memberlist", "pushPull", "sent"},
+		Help: "Bytes of push/pull messages sent to other nodes",
+	},
+	{
+		Name: []string{"memberlist", "pushPull", "userState", "sent"},
+		Help: "Bytes of user state sent to other nodes by push/pull syncs",
+	},
+	{
+		Name: []string{"memberlist", "pushPull", "userState", "received"},
+		Help: "Bytes of user state received from other nodes by push/pull syncs",
+	},
+}
+
+// pushPullSink returns a prometheus sink exporting push/pull metrics of memberlist to prometheus default registry,
+// so they are available from /go-doudou/prometheus endpoint. It is set to MetricSink of memberlist config rather
+// than installed as the global go-metrics sink, which may be used by the application. Nil is returned if it
+// fails to be registered.
+func pushPullSink() metrics.MetricSink {
+	metricsOnce.Do(func() {
+		sink, err := prometheussink.NewPrometheusSinkFrom(prometheussink.PrometheusOpts{
+			Expiration:         prometheussink.DefaultPrometheusOpts.Expiration,
+			CounterDefinitions: pushPullCounters,
+		})
+		if err != nil {
+			logger.Error().Err(err).Msg("[go-doudou] failed to register memberlist metrics")
+			return
+		}
+		metricSink = sink
+	})
+	return metricSink
+}
@@ -13,15 +13,19 @@ import (
 	cons "github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/constants"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/ip"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
 	"io/ioutil"
+	"net"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,20 +33,78 @@ var mlist memberlist.IMemberlist
 var mconf *memberlist.Config
 var BroadcastQueue *memberlist.TransmitLimitedQueue
 var events = &eventDelegate{}
-var shutdownOnce sync.Once
 var delegator *delegate
 
+// nodeLock guards creation and shutdown of local memberlist node
+var nodeLock sync.Mutex
+
+// configListener is registered to remote config center only once, and its memConf is
+// replaced each time a new local node is created
+var configListener *memConfigListener
+
+// numMembers holds NumMembers of local memberlist node for numNodes. numNodes is called by memberlist goroutines
+// which Shutdown waits for while holding nodeLock, so it must not take nodeLock.
+var numMembers atomic.Value
+
+// assertMlistNotNil panics if local memberlist node has not been created. Callers must hold nodeLock.
 func assertMlistNotNil() {
 	if mlist == nil {
 		panic("create memberlist first")
 	}
 }
 
+// localList returns local memberlist node taken under nodeLock, or nil if it has not been created,
+// so that callers don't race with NewNode and Shutdown replacing it
+func localList() memberlist.IMemberlist {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	return mlist
+}
+
+// mustLocalList is like localList but panics if local memberlist node has not been created
+func mustLocalList() memberlist.IMemberlist {
+	m := localList()
+	if m == nil {
+		panic("create memberlist first")
+	}
+	return m
+}
+
+// ErrNodeExists is returned by NewNode if local memberlist node has already been created and not been shut down
+var ErrNodeExists = errors.New("[go-doudou] memberlist node already exists, call Shutdown first")
+
 func init() {
 	if _, ok := config.ServiceDiscoveryMap()[cons.SD_MEMBERLIST]; !ok {
 		return
 	}
-	mconf = newConf()
+	if err := NewNode(); err != nil {
+		panic(err)
+	}
+}
+
+// NewNode creates local memberlist node and joins the cluster. It is called automatically on package
+// initialization if memberlist service discovery mode is enabled. Calling it again without calling Shutdown first
+// returns ErrNodeExists. It is safe to be called concurrently with Shutdown. If GddMemMinMembers is positive,
+// it blocks until the cluster has that many members or GddMemMinMembersTimeout elapses.
+func NewNode() error {
+	if err := newNode(); err != nil {
+		return err
+	}
+	waitForMinMembersFromConfig()
+	return nil
+}
+
+func newNode() error {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if mlist != nil {
+		return ErrNodeExists
+	}
+	conf := newConf()
+	if err := setGddMemEncryption(conf); err != nil {
+		return err
+	}
+	mconf = conf
 	queue := &memberlist.TransmitLimitedQueue{
 		NumNodes:             numNodes,
 		RetransmitMultGetter: retransmitMultGetter,
@@ -54,15 +116,10 @@ func init() {
 			buildTime = t.Local().Format(constants.FORMAT8)
 		}
 	}
-	weight := config.DefaultGddWeight
-	if stringutils.IsNotEmpty(config.GddWeight.Load()) {
-		if w, err := cast.ToIntE(config.GddWeight.Load()); err == nil {
-			weight = w
-		}
-	} else if stringutils.IsNotEmpty(config.GddMemWeight.Load()) {
-		if w, err := cast.ToIntE(config.GddMemWeight.Load()); err == nil {
-			weight = w
-		}
+	weight := staticWeight()
+	status := NodeReady
+	if hasReadinessChecks() {
+		status = NodeNotReady
 	}
 	BroadcastQueue = queue
 	delegator = &delegate{
@@ -73,80 +130,198 @@ func init() {
 			BuildUser:  buildinfo.BuildUser,
 			BuildTime:  buildTime,
 			Weight:     weight,
+			Status:     status,
 		},
 		queue: queue,
 	}
+	// memberlist doesn't accept node meta larger than memberlist.MetaMaxSize
+	if _, err := encodeMeta(delegator.meta, memberlist.MetaMaxSize); err != nil {
+		return err
+	}
 	mconf.Delegate = delegator
 	mconf.Events = events
 	var err error
 	if mlist, err = createMemberlist(mconf); err != nil {
-		panic(errors.Wrap(err, "[go-doudou] Failed to create memberlist"))
+		mlist = nil
+		return errors.Wrap(err, "[go-doudou] Failed to create memberlist")
 	}
+	numMembers.Store(mlist.NumMembers)
 	if err = join(); err != nil {
-		mlist.Shutdown()
-		panic(errors.Wrap(err, "[go-doudou] Node register failed"))
+		_ = mlist.Shutdown()
+		mlist = nil
+		numMembers.Store((func() int)(nil))
+		return errors.Wrap(err, "[go-doudou] Node register failed")
 	}
 	local := mlist.LocalNode()
 	logger.Info().Msgf("memberlist created. local node is Node %s, memberlist port %s", local.Name, fmt.Sprint(local.Port))
+	if cast.ToBoolOrDefault(config.GddMemAdvertiseCheck.Load(), config.DefaultGddMemAdvertiseCheck) {
+		go func(conf *memberlist.Config, local *memberlist.Node) {
+			if err := checkAdvertise(conf, local); err != nil {
+				logger.Warn().Err(err).Msg("")
+			}
+		}(mconf, local)
+	}
 	registerConfigListener(mconf)
+	if status == NodeNotReady {
+		logger.Info().Msg("local node joined cluster in not ready status, waiting for readiness checks to pass")
+		readinessStop = make(chan struct{})
+		go waitReady(readinessStop)
+	}
+	return nil
 }
 
+// seeds parses comma separated seedstr into host:port pairs. A seed prefixed with srvSeedPrefix is expanded
+// into targets of its DNS SRV records, which are resolved again each time seeds is called.
 func seeds(seedstr string) []string {
 	if stringutils.IsEmpty(seedstr) {
 		return nil
 	}
-	s := strings.Split(seedstr, ",")
-	for i, seed := range s {
-		li := strings.LastIndex(seed, ":")
-		if li < 0 {
-			s[i] = fmt.Sprintf("%s:%d", seed, config.DefaultGddMemPort)
+	var s []string
+	for _, seed := range strings.Split(seedstr, ",") {
+		if strings.HasPrefix(seed, srvSeedPrefix) {
+			s = append(s, srvSeeds(strings.TrimPrefix(seed, srvSeedPrefix))...)
 			continue
 		}
-		if len(seed) > li+1 {
-			if port, err := cast.ToIntE(seed[li+1:]); err != nil {
-				s[i] = fmt.Sprintf("%s:%d", seed[:li], config.DefaultGddMemPort)
-			} else {
-				s[i] = fmt.Sprintf("%s:%d", seed[:li], port)
-			}
-		}
+		s = append(s, seedAddr(seed))
 	}
 	return s
 }
 
+// seedAddr normalizes seed into host:port. Port is DefaultGddMemPort if seed has no port or the port is invalid.
+// IPv6 literal may be either bracketed or not, e.g. [fe80::1]:7946, [fe80::1] or fe80::1.
+func seedAddr(seed string) string {
+	host, port, err := net.SplitHostPort(seed)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(seed, "["), "]")
+		return net.JoinHostPort(host, strconv.Itoa(config.DefaultGddMemPort))
+	}
+	p, err := cast.ToIntE(port)
+	if err != nil {
+		p = config.DefaultGddMemPort
+	}
+	return net.JoinHostPort(host, strconv.Itoa(p))
+}
+
+// joinSleep is replaced in tests
+var joinSleep = time.Sleep
+
+// join joins cluster by seeds, retrying GddMemJoinRetries times at GddMemJoinInterval if failed or no seed resolved.
+// Callers must hold nodeLock.
 func join() error {
 	assertMlistNotNil()
 	seed := config.DefaultGddMemSeed
 	if stringutils.IsNotEmpty(config.GddMemSeed.Load()) {
 		seed = config.GddMemSeed.Load()
 	}
-	s := seeds(seed)
-	if len(s) == 0 {
+	retries := cast.ToIntOrDefault(config.GddMemJoinRetries.Load(), config.DefaultGddMemJoinRetries)
+	if retries < 0 {
+		retries = 0
+	}
+	interval, err := time.ParseDuration(config.GddMemJoinInterval.LoadOrDefault(config.DefaultGddMemJoinInterval))
+	if err != nil {
+		interval, _ = time.ParseDuration(config.DefaultGddMemJoinInterval)
+	}
+	if stringutils.IsEmpty(seed) {
 		logger.Warn().Msg("No seed found")
 		return nil
 	}
-	_, err := mlist.Join(s)
-	if err != nil {
-		return errors.Wrap(err, "[go-doudou] Failed to join cluster")
+	for attempt := 1; ; attempt++ {
+		// SRV records may be empty until peers are up, which is a failed attempt rather than running alone
+		if s := seeds(seed); len(s) == 0 {
+			err = errors.Errorf("no seed resolved from %s", seed)
+		} else if _, err = mlist.Join(s); err == nil {
+			break
+		}
+		if attempt > retries {
+			return errors.Wrapf(err, "[go-doudou] Failed to join cluster after %d attempts", attempt)
+		}
+		logger.Warn().Err(err).Msgf("[go-doudou] attempt %d to join cluster failed, retry in %s", attempt, interval)
+		joinSleep(interval)
 	}
 	logger.Info().Msgf("Node %s joined cluster successfully", mlist.LocalNode().FullAddress())
 	return nil
 }
 
-// AllNodes return all memberlist nodes except dead and left nodes
+// AllNodes return all memberlist nodes except dead and left nodes, including nodes not ready to serve traffic yet.
+// Use ParseMeta and NodeMeta.Ready to skip them.
 func AllNodes() ([]*memberlist.Node, error) {
-	assertMlistNotNil()
 	var nodes []*memberlist.Node
-	for _, node := range mlist.Members() {
+	for _, node := range mustLocalList().Members() {
 		nodes = append(nodes, node)
 	}
 	return nodes, nil
 }
 
+// NodeInfos returns NodeInfo of all memberlist nodes except dead and left nodes. Nodes not supplying service named svc
+// are skipped if svc is not empty. Unlike AllNodes, it returns ErrNodeNotCreated rather than panicking if local node
+// has not been created.
+func NodeInfos(svc string) ([]NodeInfo, error) {
+	_, nodes, ok := members()
+	if !ok {
+		return nil, ErrNodeNotCreated
+	}
+	infos := make([]NodeInfo, 0, len(nodes))
+	for _, node := range nodes {
+		info := Info(node)
+		if stringutils.IsNotEmpty(svc) && !info.SupplyService(svc) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// NodesBySvc returns alive memberlist nodes supplying service named svcName, skipping dead, left and suspect nodes.
+// It returns ErrNodeNotCreated if local node has not been created.
+func NodesBySvc(svcName string) ([]*memberlist.Node, error) {
+	_, all, ok := members()
+	if !ok {
+		return nil, ErrNodeNotCreated
+	}
+	var nodes []*memberlist.Node
+	for _, node := range all {
+		if node.State != memberlist.StateAlive {
+			continue
+		}
+		if Info(node).SupplyService(svcName) {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// Services returns sorted distinct names of services supplied by alive memberlist nodes.
+// It returns ErrNodeNotCreated if local node has not been created.
+func Services() ([]string, error) {
+	_, all, ok := members()
+	if !ok {
+		return nil, ErrNodeNotCreated
+	}
+	set := make(map[string]struct{})
+	for _, node := range all {
+		if node.State != memberlist.StateAlive {
+			continue
+		}
+		for _, service := range Info(node).Meta.Services {
+			set[service.Name] = struct{}{}
+		}
+	}
+	services := make([]string, 0, len(set))
+	for name := range set {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
 func ParseMeta(node *memberlist.Node) (NodeMeta, error) {
 	var mm NodeMeta
 	if len(node.Meta) > 0 {
 		r := bytes.NewReader(node.Meta)
-		dec := codec.NewDecoder(r, &codec.MsgpackHandle{})
+		handle := &codec.MsgpackHandle{}
+		// decode strings in Service.Data as string rather than []byte
+		handle.RawToString = true
+		dec := codec.NewDecoder(r, handle)
 		if err := dec.Decode(&mm); err != nil {
 			logger.Panic().Err(errors.Wrap(err, "[go-doudou] parse node meta data error")).Msg("")
 		}
@@ -164,26 +339,22 @@ func newConf() *memberlist.Config {
 		}
 	}
 	setGddMemIndirectChecks(cfg)
-	minLevel := config.DefaultGddLogLevel
-	if stringutils.IsNotEmpty(config.GddLogLevel.Load()) {
-		minLevel = strings.ToUpper(config.GddLogLevel.Load())
-		if minLevel == "ERROR" {
-			minLevel = "ERR"
-		} else if minLevel == "WARNING" {
-			minLevel = "WARN"
-		}
-	}
 	lf := &logutils.LevelFilter{
-		Levels:   []logutils.LogLevel{"DEBUG", "WARN", "ERR", "INFO"},
-		MinLevel: logutils.LogLevel(minLevel),
+		Levels: []logutils.LogLevel{"DEBUG", "WARN", "ERR", "INFO"},
 	}
 	disable := cast.ToBoolOrDefault(config.GddMemLogDisable.Load(), config.DefaultGddMemLogDisable)
 	if disable {
 		lf.Writer = ioutil.Discard
 	} else {
+		// logger.Logger writes to GddLogFile with rotation if configured
 		lf.Writer = logger.Logger
 	}
-	cfg.LogOutput = lf
+	cfg.LogOutput = &gossipLogWriter{
+		filter:    lf,
+		subsystem: logger.NewSubsystem(config.LogSubsystemMemberlist),
+	}
+	cfg.CompressUserState = cast.ToBoolOrDefault(config.GddMemStateCompression.Load(), config.DefaultGddMemStateCompression)
+	cfg.MetricSink = pushPullSink()
 	setGddMemDeadTimeout(cfg)
 	setGddMemSyncInterval(cfg)
 	setGddMemReclaimTimeout(cfg)
@@ -193,18 +364,8 @@ func newConf() *memberlist.Config {
 	setGddMemRetransmitMult(cfg)
 	setGddMemGossipNodes(cfg)
 	setGddMemGossipInterval(cfg)
-	// if env GDD_MEM_WEIGHT is set to > 0, then disable weight calculation, client will always use the same weight
-	weight := config.DefaultGddWeight
-	if stringutils.IsNotEmpty(config.GddWeight.Load()) {
-		if w, err := cast.ToIntE(config.GddWeight.Load()); err == nil {
-			weight = w
-		}
-	} else if stringutils.IsNotEmpty(config.GddMemWeight.Load()) {
-		if w, err := cast.ToIntE(config.GddMemWeight.Load()); err == nil {
-			weight = w
-		}
-	}
-	if weight > 0 {
+	// static positive weight disables weight calculation, client will always use the same weight from node meta
+	if staticWeight() > 0 {
 		cfg.WeightInterval = 0
 	} else {
 		cfg.WeightInterval = config.DefaultGddMemWeightInterval
@@ -218,6 +379,13 @@ func newConf() *memberlist.Config {
 				}
 			}
 		}
+		if cfg.WeightInterval <= 0 {
+			// weight in node meta is 0, so weight must be calculated, otherwise local node would never be selected
+			logger.Warn().Msgf("[go-doudou] weight calculation is enabled by non-positive weight but %s is not positive, use %s instead",
+				string(config.GddMemWeightInterval), fallbackWeightInterval)
+			cfg.WeightInterval = fallbackWeightInterval
+		}
+		cfg.WeightFunc = weightFunc
 	}
 	cfg.TCPTimeout, _ = time.ParseDuration(config.DefaultGddMemTCPTimeout)
 	tcpTimeoutStr := config.GddMemTCPTimeout.Load()
@@ -239,30 +407,79 @@ func newConf() *memberlist.Config {
 	}
 	cfg.BindPort = memport
 	cfg.AdvertisePort = memport
-	memhost := config.GddMemHost.Load()
-	if stringutils.IsNotEmpty(memhost) {
-		if strings.HasPrefix(memhost, ".") {
-			hostname, _ := os.Hostname()
-			cfg.AdvertiseAddr = hostname + memhost
-		} else {
-			cfg.AdvertiseAddr = memhost
+	setGddMemAdvertise(cfg)
+	return cfg
+}
+
+// fallbackWeightInterval is used as weight calculation interval if weight calculation is enabled
+// but GddMemWeightInterval is not set to a positive value
+const fallbackWeightInterval = 5 * time.Second
+
+// weightFunc calculates weight of local node instead of the default formula of memberlist if not nil
+var weightFunc func() int
+
+// SetWeightFunc makes local node calculate its weight by f every GddMemWeightInterval from live signals,
+// e.g. inflight requests, cpu usage or free memory. Non-positive results are clamped to 1. The weight is gossiped
+// to other nodes as node weight, which service providers use in place of weight in node meta. It takes effect only
+// if weight calculation is enabled by non-positive GddWeight, and must be called before NewNode.
+func SetWeightFunc(f func() int) {
+	weightFunc = f
+}
+
+// staticWeight returns static weight of local node from GddWeight, or GddMemWeight if GddWeight is not set.
+// A positive static weight is put into node meta and disables weight calculation, while a non-positive weight
+// enables calculating weight every GddMemWeightInterval and 0 is put into node meta, so that service providers
+// always use the latest calculated weight broadcast by local node.
+func staticWeight() int {
+	weight := config.DefaultGddWeight
+	if stringutils.IsNotEmpty(config.GddWeight.Load()) {
+		if w, err := cast.ToIntE(config.GddWeight.Load()); err == nil {
+			weight = w
+		}
+	} else if stringutils.IsNotEmpty(config.GddMemWeight.Load()) {
+		if w, err := cast.ToIntE(config.GddMemWeight.Load()); err == nil {
+			weight = w
 		}
 	}
-	return cfg
+	if weight < 0 {
+		weight = 0
+	}
+	return weight
 }
 
-var createMemberlist = memberlist.Create
+var createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+	m, err := memberlist.Create(conf)
+	// a free port is not retried if advertise port differs from bind port, because NAT forwards to the configured one
+	if err != nil && conf.BindPort != 0 && conf.AdvertisePort == conf.BindPort && ip.IsAddrInUse(err) &&
+		cast.ToBoolOrDefault(config.GddBindRetry.Load(), config.DefaultGddBindRetry) {
+		// zero bind port makes memberlist pick a free port and retry binding on conflicts by itself
+		logger.Warn().Err(err).Msgf("[go-doudou] memberlist port %d is in use, retry with a free port", conf.BindPort)
+		conf.BindPort = 0
+		m, err = memberlist.Create(conf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
 
 func numNodes() int {
-	assertMlistNotNil()
-	return mlist.NumMembers()
+	if f, _ := numMembers.Load().(func() int); f != nil {
+		return f()
+	}
+	// only local node is known before memberlist is created
+	return 1
 }
 
 func retransmitMultGetter() int {
 	return mconf.RetransmitMult
 }
 
-func NewRest(data ...map[string]interface{}) {
+// NewRest registers rest service to memberlist with optional custom data. It returns error and leaves node meta
+// unchanged if node meta with data encoded exceeds memberlist.MetaMaxSize bytes.
+func NewRest(data ...map[string]interface{}) error {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
 	assertMlistNotNil()
 	service := config.GetServiceName() + "_" + string(cons.REST_TYPE)
 	httpPort := config.GetPort()
@@ -280,14 +497,21 @@ func NewRest(data ...map[string]interface{}) {
 	if len(data) > 0 {
 		si.Data = data[0]
 	}
-	delegator.AddService(si)
+	if err := delegator.AddService(si, memberlist.MetaMaxSize); err != nil {
+		return errors.Wrapf(err, "[go-doudou] failed to register %s service to memberlist", service)
+	}
 	if err := mlist.UpdateNode(mlist.Config().TCPTimeout); err != nil {
-		panic(errors.Wrapf(err, "[go-doudou] failed to register %s service to memberlist", service))
+		return errors.Wrapf(err, "[go-doudou] failed to register %s service to memberlist", service)
 	}
 	logger.Info().Msgf("[go-doudou] registered %s service to memberlist successfully", service)
+	return nil
 }
 
-func NewGrpc(data ...map[string]interface{}) {
+// NewGrpc registers grpc service to memberlist with optional custom data. It returns error and leaves node meta
+// unchanged if node meta with data encoded exceeds memberlist.MetaMaxSize bytes.
+func NewGrpc(data ...map[string]interface{}) error {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
 	assertMlistNotNil()
 	service := config.GetServiceName() + "_" + string(cons.GRPC_TYPE)
 	grpcPort := config.GetGrpcPort()
@@ -300,11 +524,33 @@ func NewGrpc(data ...map[string]interface{}) {
 	if len(data) > 0 {
 		si.Data = data[0]
 	}
-	delegator.AddService(si)
+	if err := delegator.AddService(si, memberlist.MetaMaxSize); err != nil {
+		return errors.Wrapf(err, "[go-doudou] failed to register %s service to memberlist", service)
+	}
 	if err := mlist.UpdateNode(mlist.Config().TCPTimeout); err != nil {
-		panic(errors.Wrapf(err, "[go-doudou] failed to register %s service to memberlist", service))
+		return errors.Wrapf(err, "[go-doudou] failed to register %s service to memberlist", service)
 	}
 	logger.Info().Msgf("[go-doudou] registered %s service to memberlist successfully", service)
+	return nil
+}
+
+// UpdateData replaces custom data of services registered by local node by NewRest or NewGrpc, e.g. to publish dynamic
+// status or shard assignments, and propagates it to other nodes by gossip as part of node meta. Different from SetData,
+// it is small and visible in Service.Data. It returns error and leaves node meta unchanged if encoded node meta would
+// exceed memberlist.MetaMaxSize bytes.
+func UpdateData(data map[string]interface{}) error {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if mlist == nil {
+		return errors.New("[go-doudou] create memberlist first")
+	}
+	if err := delegator.SetServiceData(data, memberlist.MetaMaxSize); err != nil {
+		return err
+	}
+	if err := mlist.UpdateNode(mlist.Config().TCPTimeout); err != nil {
+		return errors.Wrap(err, "[go-doudou] failed to propagate node meta data")
+	}
+	return nil
 }
 
 type memConfigListener struct {
@@ -359,6 +605,12 @@ func CallbackOnChange(listener *memConfigListener) func(event *configmgr.NacosCh
 }
 
 func registerConfigListener(memConf *memberlist.Config) {
+	if configListener != nil {
+		configListener.Lock.Lock()
+		configListener.memConf = memConf
+		configListener.Lock.Unlock()
+		return
+	}
 	listener := &memConfigListener{
 		memConf: memConf,
 	}
@@ -381,29 +633,37 @@ func registerConfigListener(memConf *memberlist.Config) {
 	default:
 		panic(fmt.Errorf("[go-doudou] from registry pkg: unknown config type: %s\n", configType))
 	}
+	configListener = listener
 }
 
+// Shutdown shuts down local memberlist node. It is safe to be called more than once,
+// and NewNode can be called again after it returns.
 func Shutdown() {
-	shutdownOnce.Do(func() {
-		if mlist != nil {
-			_ = mlist.Shutdown()
-			mlist = nil
-			logger.Info().Msg("memberlist shutdown")
-		}
-	})
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if readinessStop != nil {
+		close(readinessStop)
+		readinessStop = nil
+	}
+	if mlist != nil {
+		_ = mlist.Shutdown()
+		mlist = nil
+		numMembers.Store((func() int)(nil))
+		logger.Info().Msg("memberlist shutdown")
+	}
 }
 
 // Leave leaves the cluster on purpose
 func Leave(timeout time.Duration) {
-	if mlist != nil {
-		_ = mlist.Leave(timeout)
+	if m := localList(); m != nil {
+		_ = m.Leave(timeout)
 		logger.Info().Msg("local node left the cluster")
 	}
 }
 
 func RegisterServiceProvider(sp IMemberlistServiceProvider) {
-	if mlist != nil {
-		for _, node := range mlist.Members() {
+	if m := localList(); m != nil {
+		for _, node := range m.Members() {
 			sp.AddNode(node)
 		}
 	}
@@ -411,6 +671,15 @@ func RegisterServiceProvider(sp IMemberlistServiceProvider) {
 }
 
 func LocalNode() *memberlist.Node {
-	assertMlistNotNil()
-	return mlist.LocalNode()
+	return mustLocalList().LocalNode()
+}
+
+// LocalNodeState returns gossip state of local node, e.g. memberlist.StateSuspect if other members suspect it failed,
+// or ErrNodeNotCreated if local node has not been created. Unlike LocalNode, it doesn't panic.
+func LocalNodeState() (memberlist.NodeStateType, error) {
+	local, _, ok := members()
+	if !ok {
+		return memberlist.StateDead, ErrNodeNotCreated
+	}
+	return local.State, nil
 }
@@ -0,0 +1,516 @@
+package memberlist
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/buildinfo"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist/mock"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func mockCreateMemberlist(t *testing.T) func() {
+	ctrl := gomock.NewController(t)
+	origin := createMemberlist
+	createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+		m := mock.NewMockIMemberlist(ctrl)
+		m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+		m.EXPECT().Shutdown().AnyTimes().Return(nil)
+		return m, nil
+	}
+	return func() {
+		createMemberlist = origin
+		ctrl.Finish()
+	}
+}
+
+func TestNewNode_Shutdown_NewNode(t *testing.T) {
+	defer mockCreateMemberlist(t)()
+	defer Shutdown()
+
+	require.NoError(t, NewNode())
+	first := mlist
+	require.NotNil(t, first)
+	require.ErrorIs(t, NewNode(), ErrNodeExists)
+	require.Equal(t, first, mlist)
+
+	Shutdown()
+	require.Nil(t, mlist)
+	Shutdown()
+
+	require.NoError(t, NewNode())
+	require.NotNil(t, mlist)
+	require.True(t, first != mlist)
+}
+
+func TestNewNode_Weight(t *testing.T) {
+	tests := []struct {
+		name           string
+		weight         string
+		memWeight      string
+		weightInterval string
+		wantWeight     int
+		wantInterval   time.Duration
+	}{
+		{
+			name:         "static positive weight disables calculation",
+			weight:       "8",
+			wantWeight:   8,
+			wantInterval: 0,
+		},
+		{
+			name:           "static positive GddMemWeight disables calculation",
+			memWeight:      "3",
+			weightInterval: "2s",
+			wantWeight:     3,
+			wantInterval:   0,
+		},
+		{
+			name:           "zero weight enables calculation",
+			weight:         "0",
+			weightInterval: "2s",
+			wantWeight:     0,
+			wantInterval:   2 * time.Second,
+		},
+		{
+			name:         "negative weight enables calculation with fallback interval",
+			memWeight:    "-1",
+			wantWeight:   0,
+			wantInterval: fallbackWeightInterval,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Unsetenv(string(config.GddWeight))
+			os.Unsetenv(string(config.GddMemWeight))
+			os.Unsetenv(string(config.GddMemWeightInterval))
+			defer func() {
+				os.Unsetenv(string(config.GddWeight))
+				os.Unsetenv(string(config.GddMemWeight))
+				os.Unsetenv(string(config.GddMemWeightInterval))
+			}()
+			if tt.weight != "" {
+				config.GddWeight.Write(tt.weight)
+			}
+			if tt.memWeight != "" {
+				config.GddMemWeight.Write(tt.memWeight)
+			}
+			if tt.weightInterval != "" {
+				config.GddMemWeightInterval.Write(tt.weightInterval)
+			}
+			defer mockCreateMemberlist(t)()
+			defer Shutdown()
+
+			require.NoError(t, NewNode())
+			require.Equal(t, tt.wantWeight, delegator.meta.Weight)
+			require.Equal(t, tt.wantInterval, mconf.WeightInterval)
+		})
+	}
+}
+
+func TestSetWeightFunc(t *testing.T) {
+	SetWeightFunc(func() int {
+		return 42
+	})
+	defer SetWeightFunc(nil)
+	defer os.Unsetenv(string(config.GddWeight))
+
+	config.GddWeight.Write("0")
+	conf := newConf()
+	require.NotNil(t, conf.WeightFunc)
+	require.Equal(t, 42, conf.WeightFunc())
+
+	config.GddWeight.Write("8")
+	require.Nil(t, newConf().WeightFunc)
+}
+
+func TestNewConf_StateCompression(t *testing.T) {
+	require.False(t, newConf().CompressUserState)
+
+	os.Setenv(string(config.GddMemStateCompression), "true")
+	defer os.Unsetenv(string(config.GddMemStateCompression))
+	require.True(t, newConf().CompressUserState)
+}
+
+func TestUpdateData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	origin := createMemberlist
+	createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+		m := mock.NewMockIMemberlist(ctrl)
+		m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+		m.EXPECT().Config().AnyTimes().Return(&memberlist.Config{TCPTimeout: time.Second})
+		m.EXPECT().UpdateNode(time.Second).AnyTimes().Return(nil)
+		m.EXPECT().Shutdown().AnyTimes().Return(nil)
+		return m, nil
+	}
+	defer func() {
+		createMemberlist = origin
+	}()
+	defer Shutdown()
+
+	require.NoError(t, NewNode())
+	require.NoError(t, delegator.AddService(Service{
+		Name: "usersvc_rest",
+		Host: "localhost",
+		Port: 6060,
+		Type: "rest",
+	}, memberlist.MetaMaxSize))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			require.NoError(t, UpdateData(map[string]interface{}{
+				"shard": i,
+			}))
+		}(i)
+		go func() {
+			defer wg.Done()
+			Info(&memberlist.Node{Meta: delegator.NodeMeta(memberlist.MetaMaxSize)})
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, UpdateData(map[string]interface{}{
+		"status": "draining",
+	}))
+	info := Info(&memberlist.Node{Meta: delegator.NodeMeta(memberlist.MetaMaxSize)})
+	require.Equal(t, "draining", info.Meta.Services[0].Data["status"])
+
+	err := UpdateData(map[string]interface{}{
+		"shards": strings.Repeat("x", 1024),
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exceeds length limit of 512 bytes")
+	info = Info(&memberlist.Node{Meta: delegator.NodeMeta(memberlist.MetaMaxSize)})
+	require.Equal(t, "draining", info.Meta.Services[0].Data["status"])
+}
+
+func TestUpdateData_NoNode(t *testing.T) {
+	require.Error(t, UpdateData(map[string]interface{}{}))
+}
+
+func TestNewNode_Shutdown_ConcurrentReaders(t *testing.T) {
+	config.GddServiceName.Write("usersvc")
+	defer os.Unsetenv(string(config.GddServiceName))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	origin := createMemberlist
+	createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+		m := mock.NewMockIMemberlist(ctrl)
+		local := &memberlist.Node{Name: "local", Port: 7946}
+		m.EXPECT().LocalNode().AnyTimes().Return(local)
+		m.EXPECT().Members().AnyTimes().Return([]*memberlist.Node{local})
+		m.EXPECT().NumMembers().AnyTimes().Return(1)
+		m.EXPECT().AdvertiseAddr().AnyTimes().Return("127.0.0.1")
+		m.EXPECT().Config().AnyTimes().Return(&memberlist.Config{TCPTimeout: time.Second})
+		m.EXPECT().UpdateNode(time.Second).AnyTimes().Return(nil)
+		m.EXPECT().Leave(gomock.Any()).AnyTimes().Return(nil)
+		m.EXPECT().Shutdown().AnyTimes().Return(nil)
+		return m, nil
+	}
+	providers := events.ServiceProviders
+	defer func() {
+		createMemberlist = origin
+		events.ServiceProviders = providers
+	}()
+	defer Shutdown()
+
+	// readers panic if local node has not been created, which is expected while racing with Shutdown
+	read := func(fn func()) {
+		defer func() {
+			_ = recover()
+		}()
+		fn()
+	}
+	readers := []func(){
+		func() { _, _ = AllNodes() },
+		func() { numNodes() },
+		func() { _ = NewRest() },
+		func() { _ = NewGrpc() },
+		func() { _ = UpdateData(map[string]interface{}{"shard": 1}) },
+		func() { Leave(time.Millisecond) },
+		func() { LocalNode() },
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2 + len(readers))
+		go func() {
+			defer wg.Done()
+			_ = NewNode()
+		}()
+		go func() {
+			defer wg.Done()
+			Shutdown()
+		}()
+		for _, fn := range readers {
+			go func(fn func()) {
+				defer wg.Done()
+				read(fn)
+			}(fn)
+		}
+	}
+	// RegisterServiceProvider appends to service providers without lock, so it is called from one goroutine only
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			RegisterServiceProvider(&mockServiceProvider{serverMap: make(map[string]*memberlist.Node)})
+		}
+	}()
+	wg.Wait()
+}
+
+func TestNewNode_MetaTooLarge(t *testing.T) {
+	defer mockCreateMemberlist(t)()
+	origin := buildinfo.BuildUser
+	buildinfo.BuildUser = strings.Repeat("x", 1024)
+	defer func() {
+		buildinfo.BuildUser = origin
+	}()
+
+	err := NewNode()
+	require.Error(t, err)
+	require.Regexp(t, `node meta data is \d+ bytes, exceeds length limit of 512 bytes`, err.Error())
+	require.Nil(t, mlist)
+}
+
+func TestNewRest_DataTooLarge(t *testing.T) {
+	config.GddServiceName.Write("usersvc")
+	defer os.Unsetenv(string(config.GddServiceName))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	origin := createMemberlist
+	createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+		m := mock.NewMockIMemberlist(ctrl)
+		m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+		m.EXPECT().AdvertiseAddr().AnyTimes().Return("localhost")
+		m.EXPECT().Shutdown().AnyTimes().Return(nil)
+		return m, nil
+	}
+	defer func() {
+		createMemberlist = origin
+	}()
+	defer Shutdown()
+
+	require.NoError(t, NewNode())
+	err := NewRest(map[string]interface{}{
+		"shards": strings.Repeat("x", 1024),
+	})
+	require.Error(t, err)
+	require.Regexp(t, `node meta data is \d+ bytes, exceeds length limit of 512 bytes`, err.Error())
+	require.Empty(t, delegator.meta.Services)
+}
+
+func TestJoin_Retry(t *testing.T) {
+	config.GddMemSeed.Write("seed1:7946")
+	config.GddMemJoinRetries.Write("2")
+	config.GddMemJoinInterval.Write("2s")
+	defer os.Unsetenv(string(config.GddMemSeed))
+	defer os.Unsetenv(string(config.GddMemJoinRetries))
+	defer os.Unsetenv(string(config.GddMemJoinInterval))
+	var slept []time.Duration
+	origin := joinSleep
+	joinSleep = func(d time.Duration) {
+		slept = append(slept, d)
+	}
+	defer func() {
+		joinSleep = origin
+		mlist = nil
+	}()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mock.NewMockIMemberlist(ctrl)
+	m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+	gomock.InOrder(
+		m.EXPECT().Join([]string{"seed1:7946"}).Return(0, errors.New("connection refused")),
+		m.EXPECT().Join([]string{"seed1:7946"}).Return(0, errors.New("connection refused")),
+		m.EXPECT().Join([]string{"seed1:7946"}).Return(1, nil),
+	)
+	mlist = m
+	require.NoError(t, join())
+	require.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second}, slept)
+
+	slept = nil
+	m.EXPECT().Join([]string{"seed1:7946"}).Times(3).Return(0, errors.New("connection refused"))
+	err := join()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "after 3 attempts")
+	require.Len(t, slept, 2)
+}
+
+func TestJoin_RetryEmptySRV(t *testing.T) {
+	config.GddMemSeed.Write("srv+_gossip._tcp.usersvc.default.svc.cluster.local")
+	config.GddMemJoinRetries.Write("2")
+	config.GddMemJoinInterval.Write("2s")
+	defer os.Unsetenv(string(config.GddMemSeed))
+	defer os.Unsetenv(string(config.GddMemJoinRetries))
+	defer os.Unsetenv(string(config.GddMemJoinInterval))
+	r := &fakeSRVResolver{}
+	defer mockSeedResolver(r)()
+	var slept []time.Duration
+	origin := joinSleep
+	joinSleep = func(d time.Duration) {
+		slept = append(slept, d)
+		if len(slept) == 2 {
+			r.addrs = []*net.SRV{{Target: "usersvc-0.usersvc.default.svc.cluster.local.", Port: 7946}}
+		}
+	}
+	defer func() {
+		joinSleep = origin
+		mlist = nil
+	}()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mock.NewMockIMemberlist(ctrl)
+	m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+	m.EXPECT().Join([]string{"usersvc-0.usersvc.default.svc.cluster.local:7946"}).Return(1, nil)
+	mlist = m
+	require.NoError(t, join())
+	require.Equal(t, 3, r.calls)
+	require.Len(t, slept, 2)
+
+	r.addrs = nil
+	err := join()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no seed resolved")
+}
+
+func TestNodeInfos(t *testing.T) {
+	_, err := NodeInfos("")
+	require.ErrorIs(t, err, ErrNodeNotCreated)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	usersvc := &delegate{meta: NodeMeta{Services: []Service{{Name: "usersvc_rest", Host: "10.0.0.1", Port: 6060}}}}
+	ordersvc := &delegate{meta: NodeMeta{Services: []Service{{Name: "ordersvc_rest", Host: "10.0.0.2", Port: 6060}}}}
+	m := mock.NewMockIMemberlist(ctrl)
+	m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "node1"})
+	m.EXPECT().Members().AnyTimes().Return([]*memberlist.Node{
+		{Name: "node1", Meta: usersvc.NodeMeta(memberlist.MetaMaxSize)},
+		{Name: "node2", Meta: ordersvc.NodeMeta(memberlist.MetaMaxSize)},
+	})
+	nodeLock.Lock()
+	mlist = m
+	nodeLock.Unlock()
+	defer func() {
+		nodeLock.Lock()
+		mlist = nil
+		nodeLock.Unlock()
+	}()
+
+	infos, err := NodeInfos("")
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+
+	infos, err = NodeInfos("ordersvc_rest")
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, "node2", infos[0].Name)
+	require.Equal(t, "10.0.0.2", infos[0].Meta.Services[0].Host)
+
+	infos, err = NodeInfos("paymentsvc_rest")
+	require.NoError(t, err)
+	require.Empty(t, infos)
+}
+
+func TestLocalNodeState(t *testing.T) {
+	_, err := LocalNodeState()
+	require.ErrorIs(t, err, ErrNodeNotCreated)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	m := mock.NewMockIMemberlist(ctrl)
+	m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "node1", State: memberlist.StateSuspect})
+	m.EXPECT().Members().AnyTimes().Return(nil)
+	nodeLock.Lock()
+	mlist = m
+	nodeLock.Unlock()
+	defer func() {
+		nodeLock.Lock()
+		mlist = nil
+		nodeLock.Unlock()
+	}()
+
+	state, err := LocalNodeState()
+	require.NoError(t, err)
+	require.Equal(t, memberlist.StateSuspect, state)
+}
+
+func TestSeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		seed string
+		want []string
+	}{
+		{name: "ipv4 with port", seed: "10.0.0.1:56199", want: []string{"10.0.0.1:56199"}},
+		{name: "ipv4 without port", seed: "10.0.0.1", want: []string{"10.0.0.1:7946"}},
+		{name: "bracketed ipv6 with port", seed: "[fe80::1]:56199", want: []string{"[fe80::1]:56199"}},
+		{name: "bracketed ipv6 without port", seed: "[fe80::1]", want: []string{"[fe80::1]:7946"}},
+		{name: "bare ipv6", seed: "fe80::1", want: []string{"[fe80::1]:7946"}},
+		{name: "hostname with port", seed: "usersvc.default.svc:56199", want: []string{"usersvc.default.svc:56199"}},
+		{name: "hostname without port", seed: "usersvc.default.svc", want: []string{"usersvc.default.svc:7946"}},
+		{name: "invalid port", seed: "localhost:abc", want: []string{"localhost:7946"}},
+		{name: "multiple", seed: "localhost,[::1]:56199", want: []string{"localhost:7946", "[::1]:56199"}},
+		{name: "empty", seed: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, seeds(tt.seed))
+		})
+	}
+}
+
+func TestNodesBySvc_Services(t *testing.T) {
+	_, err := NodesBySvc("usersvc_rest")
+	require.ErrorIs(t, err, ErrNodeNotCreated)
+	_, err = Services()
+	require.ErrorIs(t, err, ErrNodeNotCreated)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	usersvc := &delegate{meta: NodeMeta{Services: []Service{{Name: "usersvc_rest"}, {Name: "usersvc_grpc"}}}}
+	ordersvc := &delegate{meta: NodeMeta{Services: []Service{{Name: "ordersvc_rest"}}}}
+	paymentsvc := &delegate{meta: NodeMeta{Services: []Service{{Name: "paymentsvc_rest"}}}}
+	m := mock.NewMockIMemberlist(ctrl)
+	m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "node1"})
+	m.EXPECT().Members().AnyTimes().Return([]*memberlist.Node{
+		{Name: "node1", State: memberlist.StateAlive, Meta: usersvc.NodeMeta(memberlist.MetaMaxSize)},
+		{Name: "node2", State: memberlist.StateAlive, Meta: ordersvc.NodeMeta(memberlist.MetaMaxSize)},
+		{Name: "node3", State: memberlist.StateSuspect, Meta: usersvc.NodeMeta(memberlist.MetaMaxSize)},
+		{Name: "node4", State: memberlist.StateAlive, Meta: usersvc.NodeMeta(memberlist.MetaMaxSize)},
+		{Name: "node5", State: memberlist.StateDead, Meta: paymentsvc.NodeMeta(memberlist.MetaMaxSize)},
+		{Name: "node6", State: memberlist.StateLeft, Meta: paymentsvc.NodeMeta(memberlist.MetaMaxSize)},
+	})
+	nodeLock.Lock()
+	mlist = m
+	nodeLock.Unlock()
+	defer func() {
+		nodeLock.Lock()
+		mlist = nil
+		nodeLock.Unlock()
+	}()
+
+	nodes, err := NodesBySvc("usersvc_grpc")
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+	require.Equal(t, "node1", nodes[0].Name)
+	require.Equal(t, "node4", nodes[1].Name)
+
+	nodes, err = NodesBySvc("paymentsvc_rest")
+	require.NoError(t, err)
+	require.Empty(t, nodes)
+
+	services, err := Services()
+	require.NoError(t, err)
+	require.Equal(t, []string{"ordersvc_rest", "usersvc_grpc", "usersvc_rest"}, services)
+}
@@ -0,0 +1,113 @@
+package memberlist
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"math"
+)
+
+// DataString returns value of key in Data as string. Numbers and bools are formatted, and it returns false
+// if key is absent or the value is of other types.
+func (n NodeInfo) DataString(key string) (string, bool) {
+	switch v := n.Data[key].(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// DataInt returns value of key in Data as int. Strings are parsed by cast.ToIntE, and it returns false
+// if key is absent or the value can't be converted to int without losing precision.
+func (n NodeInfo) DataInt(key string) (int, bool) {
+	switch v := n.Data[key].(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), int64(int(v)) == v
+	case uint:
+		return int(v), v <= math.MaxInt
+	case uint8:
+		return int(v), true
+	case uint16:
+		return int(v), true
+	case uint32:
+		return int(v), uint64(v) <= math.MaxInt
+	case uint64:
+		return int(v), v <= math.MaxInt
+	case float32:
+		return int(v), float32(int(v)) == v
+	case float64:
+		return int(v), float64(int(v)) == v
+	case string, []byte:
+		s, _ := n.DataString(key)
+		i, err := cast.ToIntE(s)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// DataBool returns value of key in Data as bool. Strings are parsed by cast.ToBoolE, and it returns false
+// if key is absent or the value is of other types.
+func (n NodeInfo) DataBool(key string) (bool, bool) {
+	switch v := n.Data[key].(type) {
+	case bool:
+		return v, true
+	case string, []byte:
+		s, _ := n.DataString(key)
+		b, err := cast.ToBoolE(s)
+		return b, err == nil
+	}
+	return false, false
+}
+
+// UnmarshalData decodes Data into v by json, so v is usually a pointer to struct with json tags
+func (n NodeInfo) UnmarshalData(v interface{}) error {
+	raw, err := json.Marshal(jsonCompatible(n.Data))
+	if err != nil {
+		return errors.Wrap(err, "[go-doudou] failed to marshal node data")
+	}
+	if err = json.Unmarshal(raw, v); err != nil {
+		return errors.Wrap(err, "[go-doudou] failed to unmarshal node data")
+	}
+	return nil
+}
+
+// jsonCompatible converts nested maps with interface{} keys decoded from msgpack to maps with string keys,
+// and []byte to string, which json can't marshal as is
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = jsonCompatible(item)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(jsonCompatible(k))] = jsonCompatible(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, item := range v {
+			s[i] = jsonCompatible(item)
+		}
+		return s
+	case []byte:
+		return string(v)
+	}
+	return value
+}
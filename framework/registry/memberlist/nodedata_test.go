@@ -0,0 +1,68 @@
+package memberlist
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"testing"
+)
+
+func TestNodeInfo_Data(t *testing.T) {
+	d := &delegate{meta: NodeMeta{Services: []Service{{Name: "usersvc_rest", Host: "10.0.0.1", Port: 6060}}}}
+	require.NoError(t, d.SetServiceData(map[string]interface{}{
+		"zone":    "cn-east-1a",
+		"shard":   3,
+		"primary": true,
+		"replica": "2",
+		"canary":  "false",
+		"limits": map[string]interface{}{
+			"qps": 100,
+		},
+	}, memberlist.MetaMaxSize))
+	info := Info(&memberlist.Node{Name: "node1", Meta: d.NodeMeta(memberlist.MetaMaxSize)})
+
+	zone, ok := info.DataString("zone")
+	require.True(t, ok)
+	require.Equal(t, "cn-east-1a", zone)
+	shard, ok := info.DataString("shard")
+	require.True(t, ok)
+	require.Equal(t, "3", shard)
+	_, ok = info.DataString("limits")
+	require.False(t, ok)
+
+	n, ok := info.DataInt("shard")
+	require.True(t, ok)
+	require.Equal(t, 3, n)
+	n, ok = info.DataInt("replica")
+	require.True(t, ok)
+	require.Equal(t, 2, n)
+	_, ok = info.DataInt("zone")
+	require.False(t, ok)
+	_, ok = info.DataInt("missing")
+	require.False(t, ok)
+
+	b, ok := info.DataBool("primary")
+	require.True(t, ok)
+	require.True(t, b)
+	b, ok = info.DataBool("canary")
+	require.True(t, ok)
+	require.False(t, b)
+	_, ok = info.DataBool("shard")
+	require.False(t, ok)
+
+	var data struct {
+		Zone   string `json:"zone"`
+		Shard  int    `json:"shard"`
+		Limits struct {
+			QPS int `json:"qps"`
+		} `json:"limits"`
+	}
+	require.NoError(t, info.UnmarshalData(&data))
+	require.Equal(t, "cn-east-1a", data.Zone)
+	require.Equal(t, 3, data.Shard)
+	require.Equal(t, 100, data.Limits.QPS)
+
+	var mismatch struct {
+		Zone int `json:"zone"`
+	}
+	require.Error(t, info.UnmarshalData(&mismatch))
+}
@@ -0,0 +1,136 @@
+package memberlist
+
+import (
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"sync"
+	"time"
+)
+
+const (
+	// NodeReady means node is ready to serve traffic
+	NodeReady = "ready"
+	// NodeNotReady means node has joined the cluster but not all readiness checks passed yet,
+	// so service providers skip it
+	NodeNotReady = "notReady"
+)
+
+// ReadinessCheck returns nil if the component it checks is ready, e.g. caches are warmed up or migrations finished
+type ReadinessCheck func() error
+
+type namedReadinessCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+var readinessLock sync.Mutex
+var readinessChecks []namedReadinessCheck
+var readinessStop chan struct{}
+
+// RegisterReadinessCheck registers a readiness check. It should be called before local node created. If any readiness
+// check registered, local node joins the cluster in NodeNotReady status, runs all checks every GddMemReadinessInterval,
+// and switches to NodeReady by updating node meta once all of them passed.
+func RegisterReadinessCheck(name string, check ReadinessCheck) {
+	readinessLock.Lock()
+	defer readinessLock.Unlock()
+	readinessChecks = append(readinessChecks, namedReadinessCheck{
+		name:  name,
+		check: check,
+	})
+}
+
+func hasReadinessChecks() bool {
+	readinessLock.Lock()
+	defer readinessLock.Unlock()
+	return len(readinessChecks) > 0
+}
+
+func runReadinessChecks() error {
+	readinessLock.Lock()
+	checks := make([]namedReadinessCheck, len(readinessChecks))
+	copy(checks, readinessChecks)
+	readinessLock.Unlock()
+	for _, item := range checks {
+		if err := item.check(); err != nil {
+			return errors.Wrapf(err, "[go-doudou] readiness check %s failed", item.name)
+		}
+	}
+	return nil
+}
+
+// Ready returns false if the node is in NodeNotReady status or draining. Nodes of old versions without status are ready.
+func (m NodeMeta) Ready() bool {
+	return m.Status != NodeNotReady && !m.Draining
+}
+
+// waitReady runs readiness checks every GddMemReadinessInterval until all of them passed or stop is closed
+func waitReady(stop <-chan struct{}) {
+	interval, err := time.ParseDuration(config.GddMemReadinessInterval.Load())
+	if err != nil || interval <= 0 {
+		interval, _ = time.ParseDuration(config.DefaultGddMemReadinessInterval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := runReadinessChecks()
+		if err == nil {
+			markReady()
+			return
+		}
+		logger.Debug().Err(err).Msg("[go-doudou] local node is not ready")
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func markReady() {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if mlist == nil {
+		return
+	}
+	delegator.SetStatus(NodeReady)
+	if err := mlist.UpdateNode(mlist.Config().TCPTimeout); err != nil {
+		logger.Error().Err(err).Msg("[go-doudou] failed to update node status to ready")
+		return
+	}
+	logger.Info().Msg("[go-doudou] local node is ready to serve traffic")
+}
+
+// Drain marks local node as draining by updating node meta, so that service providers of other nodes stop selecting it
+// for new requests, while it keeps serving in-flight ones. It does nothing if local node has not been created.
+func Drain() error {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if mlist == nil {
+		return nil
+	}
+	delegator.SetDraining(true)
+	if err := mlist.UpdateNode(mlist.Config().TCPTimeout); err != nil {
+		return errors.Wrap(err, "[go-doudou] failed to update node status to draining")
+	}
+	return nil
+}
+
+// MarkNotReady switches local node to NodeNotReady status by updating node meta and stops pending readiness checks,
+// so that it won't become ready again. It does nothing if local node has not been created.
+func MarkNotReady() error {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if readinessStop != nil {
+		close(readinessStop)
+		readinessStop = nil
+	}
+	if mlist == nil {
+		return nil
+	}
+	delegator.SetStatus(NodeNotReady)
+	if err := mlist.UpdateNode(mlist.Config().TCPTimeout); err != nil {
+		return errors.Wrap(err, "[go-doudou] failed to update node status to not ready")
+	}
+	return nil
+}
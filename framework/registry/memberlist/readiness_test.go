@@ -0,0 +1,135 @@
+package memberlist
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist/mock"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewNode_WaitReady(t *testing.T) {
+	config.GddMemReadinessInterval.Write("10ms")
+	defer config.GddMemReadinessInterval.Write(config.DefaultGddMemReadinessInterval)
+	defer func() {
+		readinessChecks = nil
+	}()
+	var calls int32
+	RegisterReadinessCheck("cache", func() error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("cache is cold")
+		}
+		return nil
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	updated := make(chan struct{})
+	origin := createMemberlist
+	createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+		m := mock.NewMockIMemberlist(ctrl)
+		m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+		m.EXPECT().Config().AnyTimes().Return(&memberlist.Config{TCPTimeout: time.Second})
+		m.EXPECT().UpdateNode(time.Second).Times(1).DoAndReturn(func(time.Duration) error {
+			close(updated)
+			return nil
+		})
+		m.EXPECT().Shutdown().AnyTimes().Return(nil)
+		return m, nil
+	}
+	defer func() {
+		createMemberlist = origin
+	}()
+	defer Shutdown()
+
+	require.NoError(t, NewNode())
+	delegator.lock.Lock()
+	require.Equal(t, NodeNotReady, delegator.meta.Status)
+	delegator.lock.Unlock()
+
+	select {
+	case <-updated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("local node did not become ready")
+	}
+	delegator.lock.Lock()
+	require.Equal(t, NodeReady, delegator.meta.Status)
+	delegator.lock.Unlock()
+	require.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(3))
+}
+
+func TestNewNode_ReadyWithoutChecks(t *testing.T) {
+	defer mockCreateMemberlist(t)()
+	defer Shutdown()
+
+	require.NoError(t, NewNode())
+	require.Equal(t, NodeReady, delegator.meta.Status)
+}
+
+func TestBase_AddNode_NotReady(t *testing.T) {
+	d := &delegate{
+		meta: NodeMeta{
+			Services: []Service{
+				{
+					Name: "usersvc_rest",
+					Host: "localhost",
+					Port: 6060,
+					Type: "rest",
+				},
+			},
+			Weight: 1,
+			Status: NodeNotReady,
+		},
+	}
+	node := &memberlist.Node{Name: "node1", Meta: d.NodeMeta(512)}
+	b := base{
+		name:    "usersvc_rest",
+		nodeMap: make(map[string]*server),
+	}
+	b.AddNode(node)
+	require.Empty(t, b.nodes)
+
+	d.SetStatus(NodeReady)
+	node.Meta = d.NodeMeta(512)
+	b.AddNode(node)
+	require.Len(t, b.nodes, 1)
+
+	d.SetStatus(NodeNotReady)
+	node.Meta = d.NodeMeta(512)
+	b.AddNode(node)
+	require.Empty(t, b.nodes)
+}
+
+func TestDrain_MarkNotReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	origin := createMemberlist
+	createMemberlist = func(conf *memberlist.Config) (memberlist.IMemberlist, error) {
+		m := mock.NewMockIMemberlist(ctrl)
+		m.EXPECT().LocalNode().AnyTimes().Return(&memberlist.Node{Name: "local", Port: 7946})
+		m.EXPECT().Config().AnyTimes().Return(&memberlist.Config{TCPTimeout: time.Second})
+		m.EXPECT().UpdateNode(time.Second).Times(2).Return(nil)
+		m.EXPECT().Shutdown().AnyTimes().Return(nil)
+		return m, nil
+	}
+	defer func() {
+		createMemberlist = origin
+	}()
+	defer Shutdown()
+
+	require.NoError(t, NewNode())
+	require.NoError(t, Drain())
+	require.True(t, delegator.meta.Draining)
+	require.False(t, delegator.meta.Ready())
+	require.NoError(t, MarkNotReady())
+	require.Equal(t, NodeNotReady, delegator.meta.Status)
+}
+
+func TestDrain_NoNode(t *testing.T) {
+	require.NoError(t, Drain())
+	require.NoError(t, MarkNotReady())
+}
@@ -53,8 +53,17 @@ func (m *base) AddNode(node *memberlist.Node) {
 	if stringutils.IsEmpty(service.Name) {
 		return
 	}
+	if !meta.Ready() {
+		// node is still warming up, remove it from load balancer in case it was ready before
+		m.RemoveNode(node)
+		return
+	}
 	baseUrl := service.BaseUrl()
 	weight := meta.Weight
+	if weight <= 0 {
+		// weight is calculated by the node itself and broadcast
+		weight = node.Weight
+	}
 	if s, exists := m.nodeMap[node.Name]; !exists {
 		s = &server{
 			service:       m.name,
@@ -175,11 +184,16 @@ var _ IMemberlistServiceProvider = (*SWRRServiceProvider)(nil)
 
 // SWRRServiceProvider is a smooth weighted round-robin algo implementation for IMemberlistServiceProvider
 // https://github.com/nginx/nginx/commit/52327e0627f49dbda1e8db695e63a4b0af4448b1
+// Nodes are selected in proportion to their weights, and nodes of zero weight are skipped.
+// It degrades to plain round-robin if all nodes have the same weight.
 type SWRRServiceProvider struct {
 	base base
-	lock sync.RWMutex
+	lock sync.Mutex
 }
 
+// SmoothWeightedRoundRobinServiceProvider is an alias of SWRRServiceProvider
+type SmoothWeightedRoundRobinServiceProvider = SWRRServiceProvider
+
 func (m *SWRRServiceProvider) AddNode(node *memberlist.Node) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -201,23 +215,27 @@ func (m *SWRRServiceProvider) RemoveNode(node *memberlist.Node) {
 	m.base.RemoveNode(node)
 }
 
-// SelectServer selects a node which is supplying service specified by name property from cluster
+// SelectServer selects a node which is supplying service specified by name property from cluster.
+// It returns empty string if no node has positive weight.
 func (m *SWRRServiceProvider) SelectServer() string {
-	m.lock.RLock()
-	defer m.lock.RUnlock()
-	if len(m.base.nodes) == 0 {
-		return ""
-	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	var selected *server
 	total := 0
 	for i := 0; i < len(m.base.nodes); i++ {
 		s := m.base.nodes[i]
+		if s.weight <= 0 {
+			continue
+		}
 		s.currentWeight += s.weight
 		total += s.weight
 		if selected == nil || s.currentWeight > selected.currentWeight {
 			selected = s
 		}
 	}
+	if selected == nil {
+		return ""
+	}
 	selected.currentWeight -= total
 	return selected.baseUrl
 }
@@ -237,6 +255,11 @@ func NewSWRRServiceProvider(name string) *SWRRServiceProvider {
 	return sp
 }
 
+// NewSmoothWeightedRoundRobinServiceProvider is an alias of NewSWRRServiceProvider
+func NewSmoothWeightedRoundRobinServiceProvider(name string) *SmoothWeightedRoundRobinServiceProvider {
+	return NewSWRRServiceProvider(name)
+}
+
 func NewSWRRGrpcClientConn(service string, dialOptions ...grpc.DialOption) *grpc.ClientConn {
 	return NewGrpcClientConn(service, "memberlist_weight_balancer", dialOptions...)
 }
@@ -1,8 +1,11 @@
 package memberlist
 
 import (
+	"fmt"
+	"github.com/stretchr/testify/require"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
 	"sync"
+	"testing"
 )
 
 type mockServiceProvider struct {
@@ -53,3 +56,95 @@ func newMockServiceProvider(name string) *mockServiceProvider {
 		name:      name,
 	}
 }
+
+func newWeightedNode(name string, port, weight int) *memberlist.Node {
+	d := &delegate{
+		meta: NodeMeta{
+			Services: []Service{
+				{
+					Name: "usersvc_rest",
+					Host: "localhost",
+					Port: port,
+					Type: "rest",
+				},
+			},
+			Weight: weight,
+		},
+	}
+	return &memberlist.Node{
+		Name: name,
+		Meta: d.NodeMeta(512),
+	}
+}
+
+func newSWRRServiceProvider(weights ...int) *SmoothWeightedRoundRobinServiceProvider {
+	sp := &SmoothWeightedRoundRobinServiceProvider{
+		base: base{
+			name:    "usersvc_rest",
+			nodeMap: make(map[string]*server),
+		},
+	}
+	for i, weight := range weights {
+		sp.AddNode(newWeightedNode(fmt.Sprintf("node%d", i), 6060+i, weight))
+	}
+	return sp
+}
+
+func TestSWRRServiceProvider_Distribution(t *testing.T) {
+	sp := newSWRRServiceProvider(5, 3, 2)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[sp.SelectServer()]++
+	}
+	expected := map[string]int{
+		"http://localhost:6060": 500,
+		"http://localhost:6061": 300,
+		"http://localhost:6062": 200,
+	}
+	require.Len(t, counts, len(expected))
+	for baseUrl, count := range expected {
+		require.InDelta(t, count, counts[baseUrl], 10, baseUrl)
+	}
+}
+
+func TestSWRRServiceProvider_EqualWeights(t *testing.T) {
+	sp := newSWRRServiceProvider(2, 2, 2)
+	var selected []string
+	for i := 0; i < 6; i++ {
+		selected = append(selected, sp.SelectServer())
+	}
+	require.Equal(t, selected[:3], selected[3:])
+	require.ElementsMatch(t, []string{"http://localhost:6060", "http://localhost:6061", "http://localhost:6062"}, selected[:3])
+}
+
+func TestSWRRServiceProvider_ZeroWeight(t *testing.T) {
+	sp := newSWRRServiceProvider(1, 0, 1)
+	for i := 0; i < 100; i++ {
+		require.NotEqual(t, "http://localhost:6061", sp.SelectServer())
+	}
+	sp.RemoveNode(newWeightedNode("node0", 6060, 1))
+	sp.RemoveNode(newWeightedNode("node2", 6062, 1))
+	require.Equal(t, "", sp.SelectServer())
+}
+
+func TestSWRRServiceProvider_Concurrent(t *testing.T) {
+	sp := newSWRRServiceProvider(3, 1)
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	counts := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				selected := sp.SelectServer()
+				lock.Lock()
+				counts[selected]++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 750, counts["http://localhost:6060"])
+	require.Equal(t, 250, counts["http://localhost:6061"])
+}
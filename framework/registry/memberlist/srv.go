@@ -0,0 +1,41 @@
+package memberlist
+
+import (
+	"context"
+	"fmt"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net"
+	"strings"
+	"time"
+)
+
+// srvSeedPrefix marks a seed in GddMemSeed as a DNS SRV record name, e.g. srv+_gossip._tcp.myservice.default.svc.cluster.local,
+// which is useful to discover peers by headless service in Kubernetes
+const srvSeedPrefix = "srv+"
+
+// srvLookupTimeout is the timeout of looking up SRV records of a seed
+const srvLookupTimeout = 5 * time.Second
+
+// srvResolver looks up DNS SRV records. *net.Resolver implements it.
+type srvResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+var seedResolver srvResolver = net.DefaultResolver
+
+// srvSeeds looks up SRV records of name and returns their targets as host:port pairs. It logs a warning and returns nil
+// if the lookup fails, so that a node started before its peers doesn't crash.
+func srvSeeds(name string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), srvLookupTimeout)
+	defer cancel()
+	_, addrs, err := seedResolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		logger.Warn().Err(err).Msgf("[go-doudou] failed to look up SRV records of seed %s", name)
+		return nil
+	}
+	var s []string
+	for _, addr := range addrs {
+		s = append(s, net.JoinHostPort(strings.TrimSuffix(addr.Target, "."), fmt.Sprint(addr.Port)))
+	}
+	return s
+}
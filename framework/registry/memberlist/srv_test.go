@@ -0,0 +1,59 @@
+package memberlist
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+)
+
+type fakeSRVResolver struct {
+	calls int
+	addrs []*net.SRV
+	err   error
+}
+
+func (f *fakeSRVResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.calls++
+	return name, f.addrs, f.err
+}
+
+func mockSeedResolver(r srvResolver) func() {
+	origin := seedResolver
+	seedResolver = r
+	return func() {
+		seedResolver = origin
+	}
+}
+
+func TestSeeds_SRV(t *testing.T) {
+	r := &fakeSRVResolver{
+		addrs: []*net.SRV{
+			{Target: "usersvc-0.usersvc.default.svc.cluster.local.", Port: 7946},
+			{Target: "usersvc-1.usersvc.default.svc.cluster.local.", Port: 7947},
+		},
+	}
+	defer mockSeedResolver(r)()
+
+	s := seeds("localhost:56199,srv+_gossip._tcp.usersvc.default.svc.cluster.local")
+	require.Equal(t, []string{
+		"localhost:56199",
+		"usersvc-0.usersvc.default.svc.cluster.local:7946",
+		"usersvc-1.usersvc.default.svc.cluster.local:7947",
+	}, s)
+
+	r.addrs = r.addrs[:1]
+	s = seeds("srv+_gossip._tcp.usersvc.default.svc.cluster.local")
+	require.Equal(t, []string{"usersvc-0.usersvc.default.svc.cluster.local:7946"}, s)
+	require.Equal(t, 2, r.calls)
+}
+
+func TestSeeds_SRVLookupFailed(t *testing.T) {
+	defer mockSeedResolver(&fakeSRVResolver{
+		err: errors.New("no such host"),
+	})()
+
+	require.Nil(t, seeds("srv+_gossip._tcp.usersvc.default.svc.cluster.local"))
+	require.Equal(t, []string{"localhost:7946"}, seeds("localhost,srv+_gossip._tcp.usersvc.default.svc.cluster.local"))
+}
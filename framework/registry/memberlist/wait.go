@@ -0,0 +1,101 @@
+package memberlist
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"time"
+)
+
+// waitPollInterval is the interval of polling members by WaitForMembers and WaitForNode
+var waitPollInterval = 100 * time.Millisecond
+
+// ErrNodeNotCreated is returned by WaitForMembers and WaitForNode if local memberlist node has not been created
+var ErrNodeNotCreated = errors.New("[go-doudou] memberlist node not created, call NewNode first")
+
+func members() (*memberlist.Node, []*memberlist.Node, bool) {
+	nodeLock.Lock()
+	defer nodeLock.Unlock()
+	if mlist == nil {
+		return nil, nil, false
+	}
+	return mlist.LocalNode(), mlist.Members(), true
+}
+
+func waitFor(ctx context.Context, cond func(local *memberlist.Node, nodes []*memberlist.Node) bool) error {
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	for {
+		local, nodes, ok := members()
+		if !ok {
+			return ErrNodeNotCreated
+		}
+		if cond(local, nodes) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForMembers blocks until local node has discovered at least minCount alive peers excluding itself,
+// or ctx is done. The returned error wraps ctx.Err() if ctx is done first. It is useful for integration tests
+// and startup sequences which need peers before proceeding.
+func WaitForMembers(ctx context.Context, minCount int) error {
+	err := waitFor(ctx, func(local *memberlist.Node, nodes []*memberlist.Node) bool {
+		var peers int
+		for _, node := range nodes {
+			if node.Name != local.Name {
+				peers++
+			}
+		}
+		return peers >= minCount
+	})
+	return errors.Wrapf(err, "[go-doudou] failed to wait for %d members", minCount)
+}
+
+// WaitForMinMembers blocks until the cluster has at least n alive members including local node, or timeout elapses.
+// It is WaitForMembers with n-1 peers, and returns error if timeout elapses first.
+func WaitForMinMembers(n int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return WaitForMembers(ctx, n-1)
+}
+
+// waitForMinMembersFromConfig waits for GddMemMinMembers members, and logs a warning rather than failing on timeout
+func waitForMinMembersFromConfig() {
+	n := cast.ToIntOrDefault(config.GddMemMinMembers.Load(), config.DefaultGddMemMinMembers)
+	if n <= 0 {
+		return
+	}
+	timeout, err := time.ParseDuration(config.GddMemMinMembersTimeout.LoadOrDefault(config.DefaultGddMemMinMembersTimeout))
+	if err != nil {
+		timeout, _ = time.ParseDuration(config.DefaultGddMemMinMembersTimeout)
+	}
+	logger.Info().Msgf("[go-doudou] waiting for cluster of %d members up to %s", n, timeout)
+	if err = WaitForMinMembers(n, timeout); err != nil {
+		logger.Warn().Err(err).Msg("[go-doudou] proceed without enough members")
+		return
+	}
+	logger.Info().Msgf("[go-doudou] cluster has %d members at least", n)
+}
+
+// WaitForNode blocks until local node has discovered the alive node with name, or ctx is done.
+// The returned error wraps ctx.Err() if ctx is done first.
+func WaitForNode(ctx context.Context, name string) error {
+	err := waitFor(ctx, func(local *memberlist.Node, nodes []*memberlist.Node) bool {
+		for _, node := range nodes {
+			if node.Name == name {
+				return true
+			}
+		}
+		return false
+	})
+	return errors.Wrapf(err, "[go-doudou] failed to wait for node %s", name)
+}
@@ -0,0 +1,108 @@
+package memberlist
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist/mock"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mockGrowingMembers(t *testing.T) func() {
+	ctrl := gomock.NewController(t)
+	local := &memberlist.Node{Name: "local", Port: 7946}
+	var calls int32
+	m := mock.NewMockIMemberlist(ctrl)
+	m.EXPECT().LocalNode().AnyTimes().Return(local)
+	// one more peer is discovered on every call
+	m.EXPECT().Members().AnyTimes().DoAndReturn(func() []*memberlist.Node {
+		n := atomic.AddInt32(&calls, 1)
+		nodes := []*memberlist.Node{local}
+		for i := int32(1); i < n; i++ {
+			nodes = append(nodes, &memberlist.Node{Name: fmt.Sprintf("peer%d", i)})
+		}
+		return nodes
+	})
+	nodeLock.Lock()
+	origin := mlist
+	mlist = m
+	nodeLock.Unlock()
+	origInterval := waitPollInterval
+	waitPollInterval = time.Millisecond
+	return func() {
+		nodeLock.Lock()
+		mlist = origin
+		nodeLock.Unlock()
+		waitPollInterval = origInterval
+		ctrl.Finish()
+	}
+}
+
+func TestWaitForMembers(t *testing.T) {
+	defer mockGrowingMembers(t)()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, WaitForMembers(ctx, 3))
+}
+
+func TestWaitForMembers_Timeout(t *testing.T) {
+	defer mockGrowingMembers(t)()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, WaitForMembers(ctx, 1000), context.DeadlineExceeded)
+}
+
+func TestWaitForNode(t *testing.T) {
+	defer mockGrowingMembers(t)()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, WaitForNode(ctx, "peer5"))
+}
+
+func TestWaitForNode_NotCreated(t *testing.T) {
+	require.ErrorIs(t, WaitForNode(context.Background(), "peer1"), ErrNodeNotCreated)
+}
+
+func TestNewNode_MinMembers(t *testing.T) {
+	conf := memberlist.DefaultLANConfig()
+	conf.Name = "peer"
+	conf.BindAddr = "127.0.0.1"
+	conf.BindPort = 0
+	conf.LogOutput = ioutil.Discard
+	peer, err := memberlist.Create(conf)
+	require.NoError(t, err)
+	defer peer.Shutdown()
+
+	config.GddMemName.Write("local")
+	config.GddMemPort.Write("0")
+	config.GddMemSeed.Write(peer.LocalNode().FullAddress().Addr)
+	config.GddMemMinMembers.Write("2")
+	config.GddMemMinMembersTimeout.Write("5s")
+	defer func() {
+		os.Unsetenv(string(config.GddMemName))
+		os.Unsetenv(string(config.GddMemPort))
+		os.Unsetenv(string(config.GddMemSeed))
+		os.Unsetenv(string(config.GddMemMinMembers))
+		os.Unsetenv(string(config.GddMemMinMembersTimeout))
+	}()
+	defer Shutdown()
+	require.NoError(t, NewNode())
+	require.Equal(t, 2, mlist.NumMembers())
+	require.Error(t, WaitForMinMembers(3, 50*time.Millisecond))
+
+	// a missing member only delays startup
+	Shutdown()
+	config.GddMemMinMembers.Write("3")
+	config.GddMemMinMembersTimeout.Write("200ms")
+	start := time.Now()
+	require.NoError(t, NewNode())
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.Equal(t, 2, mlist.NumMembers())
+}
@@ -15,6 +15,7 @@ import (
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
 	"github.com/wubin1989/nacos-sdk-go/v2/clients"
 	"github.com/wubin1989/nacos-sdk-go/v2/clients/naming_client"
+	nacosconst "github.com/wubin1989/nacos-sdk-go/v2/common/constant"
 	"github.com/wubin1989/nacos-sdk-go/v2/model"
 	"github.com/wubin1989/nacos-sdk-go/v2/vo"
 	"google.golang.org/grpc"
@@ -38,6 +39,20 @@ func InitialiseNacosNamingClient() {
 	}
 }
 
+// setHeartbeat sets preserved metadata keys to let nacos server check heartbeats of the instance by GddNacosHeartbeatInterval
+func setHeartbeat(metadata map[string]string) {
+	interval, err := time.ParseDuration(config.GddNacosHeartbeatInterval.LoadOrDefault(config.DefaultGddNacosHeartbeatInterval))
+	if err != nil || interval <= 0 {
+		logger.Warn().Msgf("[go-doudou] invalid %s %s, use default %s instead", string(config.GddNacosHeartbeatInterval),
+			config.GddNacosHeartbeatInterval.Load(), config.DefaultGddNacosHeartbeatInterval)
+		interval, _ = time.ParseDuration(config.DefaultGddNacosHeartbeatInterval)
+	}
+	ms := interval.Milliseconds()
+	metadata[nacosconst.HEART_BEAT_INTERVAL] = strconv.FormatInt(ms, 10)
+	metadata[nacosconst.HEART_BEAT_TIMEOUT] = strconv.FormatInt(ms*3, 10)
+	metadata[nacosconst.IP_DELETE_TIMEOUT] = strconv.FormatInt(ms*6, 10)
+}
+
 func NewRest(data ...map[string]interface{}) {
 	onceNacos.Do(func() {
 		InitialiseNacosNamingClient()
@@ -68,6 +83,7 @@ func NewRest(data ...map[string]interface{}) {
 	metadata["buildUser"] = buildinfo.BuildUser
 	metadata["buildTime"] = buildTime
 	metadata["weight"] = strconv.Itoa(weight)
+	setHeartbeat(metadata)
 	metadata["rootPath"] = rr
 	for _, item := range data {
 		for k, v := range item {
@@ -120,6 +136,7 @@ func NewGrpc(data ...map[string]interface{}) {
 	metadata["buildUser"] = buildinfo.BuildUser
 	metadata["buildTime"] = buildTime
 	metadata["weight"] = strconv.Itoa(weight)
+	setHeartbeat(metadata)
 	for _, item := range data {
 		for k, v := range item {
 			metadata[k] = fmt.Sprint(v)
@@ -153,7 +170,9 @@ func ShutdownRest() {
 		success, err := NamingClient.DeregisterInstance(vo.DeregisterInstanceParam{
 			Ip:          registerHost,
 			Port:        httpPort,
+			Cluster:     config.GddNacosClusterName.LoadOrDefault(config.DefaultGddNacosClusterName),
 			ServiceName: service,
+			GroupName:   config.GddNacosGroupName.LoadOrDefault(config.DefaultGddNacosGroupName),
 			Ephemeral:   true,
 		})
 		if err != nil {
@@ -176,7 +195,9 @@ func ShutdownGrpc() {
 		success, err := NamingClient.DeregisterInstance(vo.DeregisterInstanceParam{
 			Ip:          registerHost,
 			Port:        grpcPort,
+			Cluster:     config.GddNacosClusterName.LoadOrDefault(config.DefaultGddNacosClusterName),
 			ServiceName: service,
+			GroupName:   config.GddNacosGroupName.LoadOrDefault(config.DefaultGddNacosGroupName),
 			Ephemeral:   true,
 		})
 		if err != nil {
@@ -363,6 +384,126 @@ func NewWRRServiceProvider(serviceName string, opts ...NacosProviderOption) *WRR
 	return provider
 }
 
+// SWRRServiceProvider is a smooth weighted round-robin load balance implementation for IServiceProvider.
+// It subscribes to instance changes of the service from nacos server and keeps healthy instances in memory,
+// so weight changes of instances, e.g. by nacos console, take effect without querying nacos server on every call.
+type SWRRServiceProvider struct {
+	nacosBase
+	subscribed *vo.SubscribeParam
+	instances  []*weightedInstance
+}
+
+type weightedInstance struct {
+	addr          string
+	weight        int
+	currentWeight int
+}
+
+// update replaces instances in memory, keeping current weights of instances still available
+func (n *SWRRServiceProvider) update(instances []model.Instance) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	current := make(map[string]int)
+	for _, item := range n.instances {
+		current[item.addr] = item.currentWeight
+	}
+	instances = append([]model.Instance(nil), instances...)
+	sort.Sort(instance(instances))
+	n.instances = n.instances[:0:0]
+	for _, item := range instances {
+		weight := int(item.Weight)
+		if !item.Healthy || !item.Enable || weight <= 0 {
+			continue
+		}
+		addr := fmt.Sprintf("http://%s:%d%s", item.Ip, item.Port, item.Metadata["rootPath"])
+		n.instances = append(n.instances, &weightedInstance{
+			addr:          addr,
+			weight:        weight,
+			currentWeight: current[addr],
+		})
+	}
+}
+
+// SelectServer selects a healthy instance in proportion to its weight
+func (n *SWRRServiceProvider) SelectServer() string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if len(n.instances) == 0 {
+		logger.Error().Msgf("[go-doudou] %s server not found", n.serviceName)
+		return ""
+	}
+	var selected *weightedInstance
+	total := 0
+	for _, item := range n.instances {
+		item.currentWeight += item.weight
+		total += item.weight
+		if selected == nil || item.currentWeight > selected.currentWeight {
+			selected = item
+		}
+	}
+	selected.currentWeight -= total
+	return selected.addr
+}
+
+// Close unsubscribes from instance changes of the service
+func (n *SWRRServiceProvider) Close() {
+	if n.subscribed == nil || n.namingClient == nil {
+		return
+	}
+	if err := n.namingClient.Unsubscribe(n.subscribed); err != nil {
+		logger.Error().Err(err).Msgf("[go-doudou] failed to unsubscribe from %s", n.serviceName)
+	}
+	n.subscribed = nil
+}
+
+// NewSWRRServiceProvider creates new SWRRServiceProvider instance
+func NewSWRRServiceProvider(serviceName string, opts ...NacosProviderOption) *SWRRServiceProvider {
+	onceNacos.Do(func() {
+		InitialiseNacosNamingClient()
+	})
+	provider := &SWRRServiceProvider{
+		nacosBase: nacosBase{
+			serviceName:  serviceName,
+			namingClient: NamingClient,
+		},
+	}
+	for _, opt := range opts {
+		opt(provider)
+	}
+	if provider.namingClient == nil {
+		logger.Error().Msg("[go-doudou] nacos discovery client has not been initialized")
+		return provider
+	}
+	instances, err := provider.namingClient.SelectInstances(vo.SelectInstancesParam{
+		Clusters:    provider.clusters,
+		ServiceName: provider.serviceName,
+		GroupName:   provider.groupName,
+		HealthyOnly: true,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msgf("[go-doudou] failed to get %s instances from nacos server", serviceName)
+	}
+	provider.update(instances)
+	subscribed := &vo.SubscribeParam{
+		ServiceName: provider.serviceName,
+		Clusters:    provider.clusters,
+		GroupName:   provider.groupName,
+		SubscribeCallback: func(services []model.Instance, err error) {
+			if err != nil {
+				logger.Error().Err(err).Msgf("[go-doudou] failed to watch %s instances from nacos server", serviceName)
+				return
+			}
+			provider.update(services)
+		},
+	}
+	if err = provider.namingClient.Subscribe(subscribed); err != nil {
+		logger.Error().Err(err).Msgf("[go-doudou] failed to subscribe to %s", serviceName)
+		return provider
+	}
+	provider.subscribed = subscribed
+	return provider
+}
+
 type NacosConfig struct {
 	ServiceName string
 	Clusters    []string
@@ -11,8 +11,10 @@ import (
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/nacos"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/nacos/mock"
 	"github.com/wubin1989/nacos-sdk-go/v2/clients/naming_client"
+	"github.com/wubin1989/nacos-sdk-go/v2/common/constant"
 	"github.com/wubin1989/nacos-sdk-go/v2/model"
 	"github.com/wubin1989/nacos-sdk-go/v2/vo"
+	"os"
 	"testing"
 )
 
@@ -297,3 +299,96 @@ func TestNacosWRRServiceProvider_SelectServer(t *testing.T) {
 	got := n.SelectServer()
 	require.Equal(t, got, "http://10.10.10.10:80/api")
 }
+
+func TestShutdownRest(t *testing.T) {
+	setup()
+	_ = config.GddNacosGroupName.Write("mygroup")
+	_ = config.GddNacosHeartbeatInterval.Write("2s")
+	defer os.Unsetenv(string(config.GddNacosGroupName))
+	defer os.Unsetenv(string(config.GddNacosHeartbeatInterval))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	namingClient := mock.NewMockINamingClient(ctrl)
+	namingClient.
+		EXPECT().
+		RegisterInstance(gomock.Any()).
+		DoAndReturn(func(param vo.RegisterInstanceParam) (bool, error) {
+			require.Equal(t, float64(5), param.Weight)
+			require.Equal(t, "mygroup", param.GroupName)
+			require.Equal(t, "2000", param.Metadata[constant.HEART_BEAT_INTERVAL])
+			require.Equal(t, "6000", param.Metadata[constant.HEART_BEAT_TIMEOUT])
+			require.Equal(t, "12000", param.Metadata[constant.IP_DELETE_TIMEOUT])
+			return true, nil
+		})
+	namingClient.
+		EXPECT().
+		DeregisterInstance(gomock.Any()).
+		DoAndReturn(func(param vo.DeregisterInstanceParam) (bool, error) {
+			require.Equal(t, "seed_rest", param.ServiceName)
+			require.Equal(t, "mygroup", param.GroupName)
+			require.Equal(t, "DEFAULT", param.Cluster)
+			return true, nil
+		})
+	nacos.NewNamingClient = func(param vo.NacosClientParam) (iClient naming_client.INamingClient, err error) {
+		return namingClient, nil
+	}
+	nacos.NamingClient = namingClient
+
+	nacos.NewRest()
+	nacos.ShutdownRest()
+}
+
+func TestNacosSWRRServiceProvider_SelectServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	namingClient := mock.NewMockINamingClient(ctrl)
+	namingClient.
+		EXPECT().
+		SelectInstances(vo.SelectInstancesParam{
+			Clusters:    []string{"a"},
+			ServiceName: "testsvc",
+			HealthyOnly: true,
+		}).
+		Return(services.Hosts, nil)
+	var subscribed *vo.SubscribeParam
+	namingClient.
+		EXPECT().
+		Subscribe(gomock.Any()).
+		DoAndReturn(func(param *vo.SubscribeParam) error {
+			subscribed = param
+			return nil
+		})
+
+	n := nacos.NewSWRRServiceProvider("testsvc",
+		nacos.WithNacosNamingClient(namingClient),
+		nacos.WithNacosClusters([]string{"a"}))
+	require.NotNil(t, subscribed)
+	require.Equal(t, "testsvc", subscribed.ServiceName)
+
+	counts := make(map[string]int)
+	for i := 0; i < 250; i++ {
+		counts[n.SelectServer()]++
+	}
+	require.Equal(t, 100, counts["http://10.10.10.10:80/api"])
+	require.Equal(t, 90, counts["http://10.10.10.11:80"])
+	require.Equal(t, 60, counts["http://10.10.10.14:80"])
+
+	updated := append([]model.Instance(nil), services.Hosts[:2]...)
+	updated[0].Weight = 0
+	subscribed.SubscribeCallback(updated, nil)
+	for i := 0; i < 10; i++ {
+		require.Equal(t, "http://10.10.10.11:80", n.SelectServer())
+	}
+
+	subscribed.SubscribeCallback(nil, nil)
+	require.Equal(t, "", n.SelectServer())
+
+	namingClient.
+		EXPECT().
+		Unsubscribe(subscribed).
+		Return(nil)
+	n.Close()
+	n.Close()
+}
@@ -1,21 +1,36 @@
 package registry
 
 import (
+	"context"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/consul"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/etcd"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/memberlist"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/nacos"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry/zk"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"time"
 )
 
+// memberlistLeaveTimeout returns the max time to wait for leave message to be broadcast to other nodes.
+// It is GddGraceTimeout, as leaving cluster is part of graceful shutdown.
+func memberlistLeaveTimeout() time.Duration {
+	timeout, err := time.ParseDuration(config.GddGraceTimeout.LoadOrDefault(config.DefaultGddGraceTimeout))
+	if err != nil || timeout <= 0 {
+		timeout, _ = time.ParseDuration(config.DefaultGddGraceTimeout)
+	}
+	return timeout
+}
+
 type IServiceProvider interface {
 	SelectServer() string
 	Close()
 }
 
-func NewRest(data ...map[string]interface{}) {
+// NewRest registers rest service to all configured service discovery modes. It returns error if registering to
+// memberlist failed, e.g. custom data is too large to fit in node meta.
+func NewRest(data ...map[string]interface{}) error {
 	for mode, _ := range config.ServiceDiscoveryMap() {
 		switch mode {
 		case constants.SD_NACOS:
@@ -23,16 +38,23 @@ func NewRest(data ...map[string]interface{}) {
 		case constants.SD_ETCD:
 			etcd.NewRest(data...)
 		case constants.SD_MEMBERLIST:
-			memberlist.NewRest(data...)
+			if err := memberlist.NewRest(data...); err != nil {
+				return err
+			}
 		case constants.SD_ZK:
 			zk.NewRest(data...)
+		case constants.SD_CONSUL:
+			consul.NewRest(data...)
 		default:
 			logger.Warn().Msgf("[go-doudou] unknown service discovery mode: %s", mode)
 		}
 	}
+	return nil
 }
 
-func NewGrpc(data ...map[string]interface{}) {
+// NewGrpc registers grpc service to all configured service discovery modes. It returns error if registering to
+// memberlist failed, e.g. custom data is too large to fit in node meta.
+func NewGrpc(data ...map[string]interface{}) error {
 	for mode, _ := range config.ServiceDiscoveryMap() {
 		switch mode {
 		case constants.SD_NACOS:
@@ -40,13 +62,18 @@ func NewGrpc(data ...map[string]interface{}) {
 		case constants.SD_ETCD:
 			etcd.NewGrpc(data...)
 		case constants.SD_MEMBERLIST:
-			memberlist.NewGrpc(data...)
+			if err := memberlist.NewGrpc(data...); err != nil {
+				return err
+			}
 		case constants.SD_ZK:
 			zk.NewGrpc(data...)
+		case constants.SD_CONSUL:
+			consul.NewGrpc(data...)
 		default:
 			logger.Warn().Msgf("[go-doudou] unknown service discovery mode: %s", mode)
 		}
 	}
+	return nil
 }
 
 func ShutdownRest() {
@@ -57,9 +84,12 @@ func ShutdownRest() {
 		case constants.SD_ETCD:
 			etcd.ShutdownRest()
 		case constants.SD_MEMBERLIST:
+			memberlist.Leave(memberlistLeaveTimeout())
 			memberlist.Shutdown()
 		case constants.SD_ZK:
 			zk.ShutdownRest()
+		case constants.SD_CONSUL:
+			consul.ShutdownRest()
 		default:
 			logger.Warn().Msgf("[go-doudou] unknown service discovery mode: %s", mode)
 		}
@@ -74,11 +104,70 @@ func ShutdownGrpc() {
 		case constants.SD_ETCD:
 			etcd.ShutdownGrpc()
 		case constants.SD_MEMBERLIST:
+			memberlist.Leave(memberlistLeaveTimeout())
 			memberlist.Shutdown()
 		case constants.SD_ZK:
 			zk.ShutdownGrpc()
+		case constants.SD_CONSUL:
+			consul.ShutdownGrpc()
 		default:
 			logger.Warn().Msgf("[go-doudou] unknown service discovery mode: %s", mode)
 		}
 	}
 }
+
+// ShutdownContext returns a context expiring after GddShutdownTimeout, which bounds the whole shutdown sequence of
+// rest and grpc servers. Each step of the sequence should give up once the context is done.
+func ShutdownContext() (context.Context, context.CancelFunc) {
+	budget, err := time.ParseDuration(config.GddShutdownTimeout.LoadOrDefault(config.DefaultGddShutdownTimeout))
+	if err != nil || budget <= 0 {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed or not positive, use default %s instead.\n", string(config.GddShutdownTimeout),
+			config.GddShutdownTimeout.Load(), config.DefaultGddShutdownTimeout)
+		budget, _ = time.ParseDuration(config.DefaultGddShutdownTimeout)
+	}
+	logger.Info().Msgf("[go-doudou] shutdown: shutting down in %s", budget)
+	return context.WithTimeout(context.Background(), budget)
+}
+
+// preStopWait returns how long to keep serving after leaving service registries, which is the longer of
+// GddRegistryLeaveWait if any service discovery mode is enabled and GddPreStopDelay
+func preStopWait() time.Duration {
+	var wait time.Duration
+	if len(config.ServiceDiscoveryMap()) > 0 {
+		leaveWait, err := time.ParseDuration(config.GddRegistryLeaveWait.LoadOrDefault(config.DefaultGddRegistryLeaveWait))
+		if err != nil {
+			logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddRegistryLeaveWait),
+				config.GddRegistryLeaveWait.Load(), err.Error(), config.DefaultGddRegistryLeaveWait)
+			leaveWait, _ = time.ParseDuration(config.DefaultGddRegistryLeaveWait)
+		}
+		wait = leaveWait
+	}
+	delay, err := time.ParseDuration(config.GddPreStopDelay.LoadOrDefault(config.DefaultGddPreStopDelay))
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddPreStopDelay),
+			config.GddPreStopDelay.Load(), err.Error(), config.DefaultGddPreStopDelay)
+		delay, _ = time.ParseDuration(config.DefaultGddPreStopDelay)
+	}
+	if delay > wait {
+		wait = delay
+	}
+	return wait
+}
+
+// WaitLeavePropagation keeps server serving for the longer of GddRegistryLeaveWait and GddPreStopDelay, or until ctx
+// is done. It should be called after ShutdownRest or ShutdownGrpc and before shutting down server, so that service
+// discovery of other nodes and load balancers stop targeting this node before it stops accepting new connections.
+func WaitLeavePropagation(ctx context.Context) {
+	wait := preStopWait()
+	if wait <= 0 {
+		return
+	}
+	logger.Info().Msgf("[go-doudou] shutdown: waiting %s for leaving service registries and load balancers to propagate", wait)
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		logger.Warn().Msgf("[go-doudou] shutdown: %s ran out while waiting for leave to propagate", string(config.GddShutdownTimeout))
+	case <-timer.C:
+	}
+}
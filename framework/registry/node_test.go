@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"github.com/apolloconfig/agollo/v4"
 	"github.com/apolloconfig/agollo/v4/agcache/memory"
 	apolloConfig "github.com/apolloconfig/agollo/v4/env/config"
@@ -16,6 +17,7 @@ import (
 	"github.com/wubin1989/nacos-sdk-go/v2/clients/naming_client"
 	"github.com/wubin1989/nacos-sdk-go/v2/vo"
 	"testing"
+	"time"
 )
 
 func setup() {
@@ -168,3 +170,46 @@ func TestNewNode_InvalidServiceDiscoveryMode(t *testing.T) {
 		}, ShouldNotPanic)
 	})
 }
+
+func TestWaitLeavePropagation(t *testing.T) {
+	Convey("Should wait GddRegistryLeaveWait only if service discovery is enabled", t, func() {
+		_ = config.GddRegistryLeaveWait.Write("50ms")
+		defer config.GddRegistryLeaveWait.Write("")
+		_ = config.GddServiceDiscoveryMode.Write("")
+		start := time.Now()
+		WaitLeavePropagation(context.Background())
+		So(time.Since(start), ShouldBeLessThan, 50*time.Millisecond)
+
+		_ = config.GddServiceDiscoveryMode.Write("nacos")
+		defer config.GddServiceDiscoveryMode.Write("")
+		start = time.Now()
+		WaitLeavePropagation(context.Background())
+		So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+	})
+
+	Convey("Should wait the longer of GddRegistryLeaveWait and GddPreStopDelay in one phase", t, func() {
+		_ = config.GddRegistryLeaveWait.Write("50ms")
+		defer config.GddRegistryLeaveWait.Write("")
+		_ = config.GddPreStopDelay.Write("150ms")
+		defer config.GddPreStopDelay.Write("")
+		_ = config.GddServiceDiscoveryMode.Write("nacos")
+		defer config.GddServiceDiscoveryMode.Write("")
+		start := time.Now()
+		WaitLeavePropagation(context.Background())
+		elapsed := time.Since(start)
+		So(elapsed, ShouldBeGreaterThanOrEqualTo, 150*time.Millisecond)
+		So(elapsed, ShouldBeLessThan, 200*time.Millisecond)
+	})
+
+	Convey("Should give up once shutdown budget runs out", t, func() {
+		_ = config.GddPreStopDelay.Write("10s")
+		defer config.GddPreStopDelay.Write("")
+		_ = config.GddShutdownTimeout.Write("100ms")
+		defer config.GddShutdownTimeout.Write("")
+		ctx, cancel := ShutdownContext()
+		defer cancel()
+		start := time.Now()
+		WaitLeavePropagation(ctx)
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+	})
+}
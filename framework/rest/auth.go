@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+)
+
+// Authenticator authenticates requests, e.g. by bearer token, api key or session cookie
+type Authenticator interface {
+	// Authenticate returns principal of the request, e.g. user or claims of token, or error if authentication failed
+	Authenticate(r *http.Request) (principal interface{}, err error)
+}
+
+// AuthenticatorFunc allows an ordinary function to be used as Authenticator
+type AuthenticatorFunc func(r *http.Request) (interface{}, error)
+
+// Authenticate calls f(r)
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (interface{}, error) {
+	return f(r)
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying principal
+func ContextWithPrincipal(ctx context.Context, principal interface{}) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns principal put into ctx by Authenticate middleware, e.g. jwt.MapClaims for JWTAuthenticator
+func PrincipalFromContext(ctx context.Context) (interface{}, bool) {
+	principal := ctx.Value(principalKey{})
+	return principal, principal != nil
+}
+
+// Authenticate returns a middleware authenticating requests by authenticator. It responds 401 if authentication failed,
+// otherwise stores principal into request context, which can be read by PrincipalFromContext. It is not added by
+// default, add it by RestServer.AddMiddleware to protect biz routes, or by AddRouteWithMiddleware for some routes only.
+func Authenticate(authenticator Authenticator) func(inner http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil {
+				writeErrorResponse(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+			inner.ServeHTTP(w, r.WithContext(ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package rest_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func authenticated(t *testing.T, authenticator rest.Authenticator, token string) (int, jwt.MapClaims) {
+	var claims jwt.MapClaims
+	handler := rest.Authenticate(authenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := rest.PrincipalFromContext(r.Context())
+		require.True(t, ok)
+		claims = principal.(jwt.MapClaims)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr.Code, claims
+}
+
+func signHS256(t *testing.T, key []byte, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTAuthenticator_SigningKey(t *testing.T) {
+	key := []byte("secret")
+	authenticator, err := rest.NewJWTAuthenticator(rest.WithJWTSigningKey(key))
+	require.NoError(t, err)
+
+	code, claims := authenticated(t, authenticator, signHS256(t, key, jwt.MapClaims{
+		"sub": "jack",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "jack", claims["sub"])
+
+	code, _ = authenticated(t, authenticator, "")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = authenticated(t, authenticator, "not.a.token")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = authenticated(t, authenticator, signHS256(t, []byte("another"), jwt.MapClaims{"sub": "jack"}))
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = authenticated(t, authenticator, signHS256(t, key, jwt.MapClaims{
+		"sub": "jack",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	}))
+	require.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestJWTAuthenticator_JWKS(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var fetches int
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key1",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	defer jwksServer.Close()
+	authenticator, err := rest.NewJWTAuthenticator(rest.WithJWKSURL(jwksServer.URL))
+	require.NoError(t, err)
+
+	sign := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(privateKey)
+		require.NoError(t, err)
+		return s
+	}
+
+	code, claims := authenticated(t, authenticator, sign("key1", jwt.MapClaims{"sub": "jack"}))
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "jack", claims["sub"])
+	code, _ = authenticated(t, authenticator, sign("key1", jwt.MapClaims{"sub": "rose"}))
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 1, fetches)
+
+	code, _ = authenticated(t, authenticator, sign("key1", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	// jwks is not refetched within a minute
+	code, _ = authenticated(t, authenticator, sign("key2", jwt.MapClaims{"sub": "jack"}))
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.Equal(t, 1, fetches)
+
+	// tokens signed by HMAC are rejected even if signed with the public key
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "jack"})
+	hmacToken.Header["kid"] = "key1"
+	s, err := hmacToken.SignedString(privateKey.N.Bytes())
+	require.NoError(t, err)
+	code, _ = authenticated(t, authenticator, s)
+	require.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestJWTAuthenticatorFromConfig(t *testing.T) {
+	_, err := rest.JWTAuthenticatorFromConfig()
+	require.Error(t, err)
+
+	config.GddJwtSigningKey.Write("secret")
+	defer os.Unsetenv(string(config.GddJwtSigningKey))
+	authenticator, err := rest.JWTAuthenticatorFromConfig()
+	require.NoError(t, err)
+	code, _ := authenticated(t, authenticator, signHS256(t, []byte("secret"), jwt.MapClaims{"sub": "jack"}))
+	require.Equal(t, http.StatusOK, code)
+
+	config.GddJwtRequireExp.Write("true")
+	defer os.Unsetenv(string(config.GddJwtRequireExp))
+	authenticator, err = rest.JWTAuthenticatorFromConfig()
+	require.NoError(t, err)
+	code, _ = authenticated(t, authenticator, signHS256(t, []byte("secret"), jwt.MapClaims{"sub": "jack"}))
+	require.Equal(t, http.StatusUnauthorized, code)
+	code, _ = authenticated(t, authenticator, signHS256(t, []byte("secret"), jwt.MapClaims{
+		"sub": "jack",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}))
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestJWTAuthenticator_ConcurrentFetch(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var fetches int32
+	release := make(chan struct{})
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key1",
+					"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+				},
+			},
+		})
+	}))
+	defer jwksServer.Close()
+	authenticator, err := rest.NewJWTAuthenticator(rest.WithJWKSURL(jwksServer.URL))
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "jack"})
+	token.Header["kid"] = "key1"
+	s, err := token.SignedString(privateKey)
+	require.NoError(t, err)
+
+	// requests arriving while jwks is being fetched share the fetch
+	codes := make(chan int, 5)
+	for i := 0; i < cap(codes); i++ {
+		go func() {
+			code, _ := authenticated(t, authenticator, s)
+			codes <- code
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	for i := 0; i < cap(codes); i++ {
+		require.Equal(t, http.StatusOK, <-codes)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+}
+
+func TestNewJWTAuthenticator_NoKey(t *testing.T) {
+	_, err := rest.NewJWTAuthenticator()
+	require.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+)
+
+// BatchItem is an invocation of a service method bundled in batch request
+type BatchItem struct {
+	// Method is the name of service interface method
+	Method string `json:"method"`
+	// Params is a json object whose keys are parameter names of the method
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// BatchResult is the result of a BatchItem. Results are returned in the same order as items.
+type BatchResult struct {
+	Method string `json:"method"`
+	// StatusCode is the http status code the method would have responded if called alone
+	StatusCode int `json:"statusCode"`
+	// Result is a json object whose keys are result names of the method, absent if the call failed
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is absent if the call succeeded
+	Error *ErrorResponse `json:"error,omitempty"`
+}
+
+// BatchMethod calls a service method with params of a BatchItem, and returns value marshalled into Result of BatchResult
+type BatchMethod func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// DecodeBatchParams decodes params of BatchItem into v. It returns BizError with 400 status code if params are malformed.
+func DecodeBatchParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return NewBizError(errors.Wrap(err, "invalid params"), WithStatusCode(http.StatusBadRequest))
+	}
+	return nil
+}
+
+type batchRoutesKey struct{}
+
+// withBatchRoutes puts biz routes keyed by name with patterns prefixed by route root path into request context,
+// so that BatchHandler can dispatch items through routes of the called methods
+func withBatchRoutes(inner http.Handler, routes map[string]Route) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), batchRoutesKey{}, routes)))
+	})
+}
+
+func callBatchMethod(r *http.Request, methods map[string]BatchMethod, item BatchItem) (result BatchResult) {
+	result.Method = item.Method
+	defer func() {
+		if e := recover(); e != nil {
+			statusCode, resp := NewErrorResponse(e)
+			panicLog(r, statusCode).Str("batchMethod", item.Method).
+				Msgf("panic: %+v\n\nstacktrace from panic: %s\n", e, string(debug.Stack()))
+			result.StatusCode, result.Error, result.Result = statusCode, &resp, nil
+		}
+	}()
+	method, ok := methods[item.Method]
+	if !ok {
+		panic(NewBizError(fmt.Errorf("method %s not found", item.Method), WithStatusCode(http.StatusNotFound)))
+	}
+	if routes, ok := r.Context().Value(batchRoutesKey{}).(map[string]Route); ok {
+		route, ok := routes[item.Method]
+		if !ok {
+			// not exposed by the server, so it mustn't be reachable from batch endpoint either
+			panic(NewBizError(fmt.Errorf("method %s not found", item.Method), WithStatusCode(http.StatusNotFound)))
+		}
+		return dispatchBatchItem(r, route, method, item)
+	}
+	// not served by RestServer, e.g. in unit tests
+	value, err := method(r.Context(), item.Params)
+	if err != nil {
+		panic(err)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(NewBizError(err))
+	}
+	result.StatusCode = http.StatusOK
+	result.Result = data
+	return
+}
+
+// dispatchBatchItem calls method through the handler chain of its route as if it were requested alone, with params
+// as request body. Middlewares of the route like Authenticate, MaxBodySize and Timeout of the route apply to the call.
+// Global middlewares are not applied again, as they have handled the batch request. Panics are recovered by
+// callBatchMethod.
+func dispatchBatchItem(r *http.Request, route Route, method BatchMethod, item BatchItem) BatchResult {
+	route.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		params, err := io.ReadAll(r.Body)
+		if err != nil {
+			panic(err)
+		}
+		value, err := method(r.Context(), params)
+		if err != nil {
+			panic(err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			panic(NewBizError(err))
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}
+	limit := route.MaxBodySize
+	if limit == 0 {
+		limit = maxBodySize()
+	}
+	timeout := route.Timeout
+	if timeout == 0 {
+		timeout = defaultHandlerTimeout()
+	}
+	h := withHandlerTimeout(withBodyLimit(MaxBodySize(HandlerTimeout(route.Handler())), limit), timeout)
+
+	sub := r.Clone(ContextWithRoutePattern(r.Context(), route.Pattern))
+	sub.Method = route.Method
+	sub.URL.Path = route.Pattern
+	sub.URL.RawPath = ""
+	sub.URL.RawQuery = ""
+	sub.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	sub.Body = io.NopCloser(bytes.NewReader(item.Params))
+	sub.ContentLength = int64(len(item.Params))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, sub)
+
+	result := BatchResult{
+		Method:     item.Method,
+		StatusCode: rec.Code,
+	}
+	if rec.Code < http.StatusBadRequest {
+		result.Result = rec.Body.Bytes()
+		return result
+	}
+	// responded by middlewares, e.g. 401 by Authenticate or 503 by HandlerTimeout
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Message == "" {
+		resp = ErrorResponse{
+			Code:    1,
+			Message: http.StatusText(rec.Code),
+		}
+	}
+	result.Error = &resp
+	return result
+}
+
+// BatchHandler returns handler of batch endpoint generated by go-doudou svc http --batch. Request body is a json array of
+// BatchItem, and response body is a json array of BatchResult. Items are executed one by one in order with context of
+// the batch request. If served by RestServer, each item is dispatched through the registered route of the same name
+// as its method, so middlewares of the route like Authenticate, and MaxBodySize and Timeout of the route apply to it
+// the same as calling the method alone, and methods without registered routes are not found. A failed item doesn't
+// fail the whole batch, its error is returned in Error of its BatchResult, so the batch request itself always responds
+// 200 unless the request body is malformed, larger than GddMaxBodySize or has more items than GddBatchMaxItems.
+func BatchHandler(methods map[string]BatchMethod) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var items []BatchItem
+		if err := DecodeJSON(r, &items); err != nil && err != io.EOF {
+			statusCode := http.StatusBadRequest
+			var bizError BizError
+			if errors.As(err, &bizError) {
+				statusCode = bizError.StatusCode
+			}
+			writeErrorResponse(w, statusCode, err.Error())
+			return
+		}
+		maxItems := cast.ToIntOrDefault(config.GddBatchMaxItems.Load(), config.DefaultGddBatchMaxItems)
+		if maxItems > 0 && len(items) > maxItems {
+			writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("too many items in batch, max %d", maxItems))
+			return
+		}
+		results := make([]BatchResult, 0, len(items))
+		for _, item := range items {
+			results = append(results, callBatchMethod(r, methods, item))
+		}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			logger.Error().Err(err).Msg("[go-doudou] failed to write batch results")
+		}
+	}
+}
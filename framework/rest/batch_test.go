@@ -0,0 +1,159 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchHandler(t *testing.T) {
+	handler := rest.BatchHandler(map[string]rest.BatchMethod{
+		"Echo": func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			var p struct {
+				Msg string `json:"msg"`
+			}
+			if err := rest.DecodeBatchParams(params, &p); err != nil {
+				return nil, err
+			}
+			return p, nil
+		},
+		"Panic": func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+			panic("boom")
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/batch",
+		strings.NewReader(`[{"method":"Echo","params":{"msg":"hi"}},{"method":"Panic"},{"method":"Echo","params":{"msg":1}}]`)))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var results []rest.BatchResult
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&results))
+	require.Len(t, results, 3)
+	require.Equal(t, http.StatusOK, results[0].StatusCode)
+	require.JSONEq(t, `{"msg":"hi"}`, string(results[0].Result))
+	require.Nil(t, results[0].Error)
+	require.Equal(t, http.StatusInternalServerError, results[1].StatusCode)
+	require.Equal(t, "boom", results[1].Error.Message)
+	require.Equal(t, http.StatusBadRequest, results[2].StatusCode)
+	require.Empty(t, results[2].Result)
+
+	config.GddBatchMaxItems.Write("1")
+	defer os.Unsetenv(string(config.GddBatchMaxItems))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[{"method":"Echo"},{"method":"Echo"}]`)))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestBatchHandler_TooLarge(t *testing.T) {
+	config.GddMaxBodySize.Write("16")
+	defer os.Unsetenv(string(config.GddMaxBodySize))
+	handler := rest.BatchHandler(map[string]rest.BatchMethod{})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/batch",
+		strings.NewReader(`[{"method":"Echo","params":{"msg":"hi"}}]`)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	require.Contains(t, rr.Body.String(), "request body must not be larger than 16 bytes")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(`[{"method":1}]`)))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestBatchHandler_RouteChain(t *testing.T) {
+	config.GddPort.Write("6083")
+	defer config.GddPort.Write(strconv.Itoa(config.DefaultGddPort))
+
+	echo := func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		principal, _ := rest.PrincipalFromContext(ctx)
+		return map[string]interface{}{"principal": principal, "params": params}, nil
+	}
+	slow := func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+		return "slow", nil
+	}
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:        "Secret",
+		Method:      http.MethodPost,
+		Pattern:     "/secret",
+		HandlerFunc: notFound,
+		Middlewares: []rest.MiddlewareFunc{
+			rest.Authenticate(rest.AuthenticatorFunc(func(r *http.Request) (interface{}, error) {
+				if r.Header.Get("X-Token") != "secret" {
+					return nil, errors.New("invalid token")
+				}
+				return "admin", nil
+			})),
+		},
+	}, rest.Route{
+		Name:        "Small",
+		Method:      http.MethodPost,
+		Pattern:     "/small",
+		HandlerFunc: notFound,
+		MaxBodySize: 8,
+	}, rest.Route{
+		Name:        "Slow",
+		Method:      http.MethodPost,
+		Pattern:     "/slow",
+		HandlerFunc: notFound,
+		Timeout:     50 * time.Millisecond,
+	}, rest.Route{
+		Name:    "Batch",
+		Method:  http.MethodPost,
+		Pattern: "/batch",
+		HandlerFunc: rest.BatchHandler(map[string]rest.BatchMethod{
+			"Secret":     echo,
+			"Small":      echo,
+			"Slow":       slow,
+			"Unexported": echo,
+		}).ServeHTTP,
+	})
+	go srv.Run()
+	time.Sleep(10 * time.Millisecond)
+
+	post := func(token string) []rest.BatchResult {
+		req, err := http.NewRequest(http.MethodPost, "http://localhost:6083/batch", strings.NewReader(
+			`[{"method":"Secret","params":{"a":1}},{"method":"Small","params":{"a":"123456789"}},{"method":"Small","params":1},{"method":"Slow"},{"method":"Unexported"}]`))
+		require.NoError(t, err)
+		req.Header.Set("X-Token", token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var results []rest.BatchResult
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&results))
+		require.Len(t, results, 5)
+		return results
+	}
+
+	results := post("")
+	require.Equal(t, http.StatusUnauthorized, results[0].StatusCode)
+	require.Equal(t, "invalid token", results[0].Error.Message)
+	require.Equal(t, http.StatusRequestEntityTooLarge, results[1].StatusCode)
+	require.Equal(t, http.StatusOK, results[2].StatusCode)
+	require.JSONEq(t, `{"principal":null,"params":1}`, string(results[2].Result))
+	require.Equal(t, http.StatusServiceUnavailable, results[3].StatusCode)
+	require.NotNil(t, results[3].Error)
+	require.Equal(t, http.StatusNotFound, results[4].StatusCode)
+
+	results = post("secret")
+	require.Equal(t, http.StatusOK, results[0].StatusCode)
+	require.JSONEq(t, `{"principal":"admin","params":{"a":1}}`, string(results[0].Result))
+}
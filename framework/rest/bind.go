@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+)
+
+// errBodyTooLarge is the message of error responses to requests with body larger than limit
+var errBodyTooLarge = errors.New("request body too large")
+
+// maxBytesReaderErrMsg is message of error returned by reader of http.MaxBytesReader when limit exceeded,
+// which has no exported type before go 1.19
+const maxBytesReaderErrMsg = "http: request body too large"
+
+// isBodyTooLarge reports whether err is caused by reading request body beyond limit of http.MaxBytesReader
+func isBodyTooLarge(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if err.Error() == maxBytesReaderErrMsg {
+			return true
+		}
+	}
+	return false
+}
+
+func maxBodySize() int64 {
+	size, err := cast.ToInt64E(config.GddMaxBodySize.Load())
+	if err != nil {
+		return config.DefaultGddMaxBodySize
+	}
+	return size
+}
+
+// DecodeJSON decodes json request body into v. It returns io.EOF if body is empty. Other errors are BizError
+// with 400 status code for malformed json, which tells the offending field or position if possible,
+// and 413 status code for body larger than GddMaxBodySize or MaxBodySize of the matched route.
+func DecodeJSON(r *http.Request, v interface{}) error {
+	var body io.Reader = r.Body
+	limit := bodyLimit(r)
+	if limit > 0 && r.Body != nil {
+		// no ResponseWriter here, MaxBodySize middleware tells server to close the connection if limit exceeded
+		body = http.MaxBytesReader(nil, r.Body, limit)
+	}
+	err := json.NewDecoder(body).Decode(v)
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return NewBizError(err, WithStatusCode(http.StatusBadRequest), func(bz *BizError) {
+		bz.ErrMsg = bindErrMsg(err, limit)
+		if isBodyTooLarge(err) {
+			bz.StatusCode = http.StatusRequestEntityTooLarge
+		}
+	})
+}
+
+func bindErrMsg(err error, limit int64) string {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		invalidUnmar *json.InvalidUnmarshalError
+	)
+	switch {
+	case isBodyTooLarge(err):
+		return fmt.Sprintf("request body must not be larger than %d bytes", limit)
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("malformed json at position %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "malformed json: unexpected end of request body"
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if typeErr.Struct != "" && field != "" {
+			field = strings.Join([]string{typeErr.Struct, field}, ".")
+		}
+		if field == "" {
+			return fmt.Sprintf("invalid value %s at position %d: expected %s", typeErr.Value, typeErr.Offset, typeErr.Type)
+		}
+		return fmt.Sprintf("invalid value %s for field %s at position %d: expected %s", typeErr.Value, field, typeErr.Offset, typeErr.Type)
+	case errors.As(err, &invalidUnmar):
+		return invalidUnmar.Error()
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,110 @@
+package rest_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+type bindBase struct {
+	ID int `json:"id"`
+}
+
+type bindPayload struct {
+	bindBase
+	Name   string            `json:"name"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+	Items  []bindBase        `json:"items"`
+}
+
+func newJSONRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(body))
+}
+
+func TestDecodeJSON(t *testing.T) {
+	var payload bindPayload
+	err := rest.DecodeJSON(newJSONRequest(`{"id":1,"name":"go-doudou","tags":["a","b"],"labels":{"k":"v"},"items":[{"id":2}]}`), &payload)
+	require.NoError(t, err)
+	require.Equal(t, 1, payload.ID)
+	require.Equal(t, []string{"a", "b"}, payload.Tags)
+	require.Equal(t, map[string]string{"k": "v"}, payload.Labels)
+	require.Equal(t, 2, payload.Items[0].ID)
+}
+
+func TestDecodeJSON_Empty(t *testing.T) {
+	var payload bindPayload
+	require.Equal(t, io.EOF, rest.DecodeJSON(newJSONRequest(""), &payload))
+}
+
+func TestDecodeJSON_Malformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{
+			name:    "syntax",
+			body:    `{"id":1,}`,
+			message: "malformed json at position 9",
+		},
+		{
+			name:    "truncated",
+			body:    `{"id":1`,
+			message: "malformed json: unexpected end of request body",
+		},
+		{
+			name:    "type",
+			body:    `{"items":[{"id":"2"}]}`,
+			message: "for field bindPayload.items",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload bindPayload
+			err := rest.DecodeJSON(newJSONRequest(tt.body), &payload)
+			bz, ok := err.(rest.BizError)
+			require.True(t, ok)
+			require.Equal(t, http.StatusBadRequest, bz.StatusCode)
+			require.Contains(t, bz.ErrMsg, tt.message)
+		})
+	}
+}
+
+func TestDecodeJSON_TooLarge(t *testing.T) {
+	_ = config.GddMaxBodySize.Write("16")
+	defer config.GddMaxBodySize.Write("")
+	var payload bindPayload
+	err := rest.DecodeJSON(newJSONRequest(`{"name":"`+strings.Repeat("a", 32)+`"}`), &payload)
+	bz, ok := err.(rest.BizError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusRequestEntityTooLarge, bz.StatusCode)
+
+	require.PanicsWithValue(t, bz, func() {
+		rest.HandleBadRequestErr(err)
+	})
+}
+
+func TestDecodeJSON_ExactLimit(t *testing.T) {
+	_ = config.GddMaxBodySize.Write("16")
+	defer config.GddMaxBodySize.Write("")
+	var payload bindPayload
+	require.NoError(t, rest.DecodeJSON(newJSONRequest(`{"name":"aaaaa"}`), &payload))
+	require.Equal(t, "aaaaa", payload.Name)
+
+	// number at top level is only complete at end of body
+	var number int64
+	require.NoError(t, rest.DecodeJSON(newJSONRequest("1234567890123456"), &number))
+	require.Equal(t, int64(1234567890123456), number)
+
+	err := rest.DecodeJSON(newJSONRequest("12345678901234567"), &number)
+	bz, ok := err.(rest.BizError)
+	require.True(t, ok)
+	require.Equal(t, http.StatusRequestEntityTooLarge, bz.StatusCode)
+}
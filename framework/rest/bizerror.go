@@ -1,19 +1,28 @@
 package rest
 
 import (
+	"context"
 	"fmt"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"net/http"
+	"os"
+	"runtime/debug"
 )
 
 // BizError is used for business error implemented error interface
 // StatusCode will be set to http response status code
 // ErrCode is used for business error code
 // ErrMsg is custom error message
+// Details is error chain with stack trace decoded from ErrorResponse, only available if server returns it
 type BizError struct {
 	StatusCode int
 	ErrCode    int
 	ErrMsg     string
 	Cause      error
+	Details    string
 }
 
 type BizErrorOption func(bizError *BizError)
@@ -63,9 +72,79 @@ func (b BizError) Error() string {
 }
 
 func HandleBadRequestErr(err error) {
+	if bz, ok := err.(BizError); ok {
+		panic(bz)
+	}
 	panic(NewBizError(err, WithStatusCode(http.StatusBadRequest)))
 }
 
 func HandleInternalServerError(err error) {
 	panic(NewBizError(err))
 }
+
+// ErrorResponse is the wire format of errors between generated http handlers and clients
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Details is error chain with stack trace, only returned if GDD_ENV is dev or GddErrorDetailsEnable is true
+	Details string `json:"details,omitempty"`
+}
+
+// BizError reconstructs BizError from ErrorResponse
+func (e ErrorResponse) BizError(statusCode int) BizError {
+	return BizError{
+		StatusCode: statusCode,
+		ErrCode:    e.Code,
+		ErrMsg:     e.Message,
+		Details:    e.Details,
+	}
+}
+
+// errorDetailsEnabled reports whether error details should be returned to clients. Unlike framework.CheckDev,
+// unset GDD_ENV doesn't count as dev, so that stack traces won't leak from production deployments lacking it.
+func errorDetailsEnabled() bool {
+	return os.Getenv("GDD_ENV") == "dev" || cast.ToBoolOrDefault(config.GddErrorDetailsEnable.Load(), config.DefaultGddErrorDetailsEnable)
+}
+
+// NewErrorResponse converts recovered panic value e to http status code and ErrorResponse
+func NewErrorResponse(e interface{}) (int, ErrorResponse) {
+	statusCode := http.StatusInternalServerError
+	resp := ErrorResponse{
+		Code:    1, // 1 indicates there is an error
+		Message: fmt.Sprintf("%v", e),
+	}
+	cause := e
+	if err, ok := e.(error); ok {
+		switch {
+		case errors.Is(err, context.Canceled):
+			statusCode = http.StatusBadRequest
+		case isBodyTooLarge(err):
+			// plain envelope without details, as stack trace of the sentinel error tells nothing
+			return http.StatusRequestEntityTooLarge, ErrorResponse{
+				Code:    1,
+				Message: errBodyTooLarge.Error(),
+			}
+		default:
+			var bizError BizError
+			if errors.As(err, &bizError) {
+				statusCode = bizError.StatusCode
+				resp.Code = bizError.ErrCode
+				resp.Message = bizError.Error()
+				if bizError.Cause != nil {
+					cause = bizError.Cause
+				}
+			}
+		}
+	}
+	if stringutils.IsEmpty(resp.Message) {
+		resp.Message = http.StatusText(statusCode)
+	}
+	if errorDetailsEnabled() {
+		if _, ok := cause.(interface{ StackTrace() errors.StackTrace }); ok {
+			resp.Details = fmt.Sprintf("%+v", cause)
+		} else {
+			resp.Details = fmt.Sprintf("%v\n\n%s", cause, debug.Stack())
+		}
+	}
+	return statusCode, resp
+}
@@ -3,7 +3,9 @@ package rest_test
 import (
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"os"
 	"testing"
 )
 
@@ -30,3 +32,43 @@ func TestWithErrCode(t *testing.T) {
 		})
 	})
 }
+
+func TestNewErrorResponse_Details(t *testing.T) {
+	Convey("Error details should only be returned if enabled explicitly", t, func() {
+		env, hasEnv := os.LookupEnv("GDD_ENV")
+		defer func() {
+			if hasEnv {
+				os.Setenv("GDD_ENV", env)
+			} else {
+				os.Unsetenv("GDD_ENV")
+			}
+		}()
+		defer os.Unsetenv(string(config.GddErrorDetailsEnable))
+		err := errors.New("boom")
+
+		Convey("Should not return details if GDD_ENV is not set", func() {
+			os.Unsetenv("GDD_ENV")
+			_, resp := rest.NewErrorResponse(err)
+			So(resp.Details, ShouldBeEmpty)
+		})
+
+		Convey("Should not return details if GDD_ENV is prod", func() {
+			os.Setenv("GDD_ENV", "prod")
+			_, resp := rest.NewErrorResponse(err)
+			So(resp.Details, ShouldBeEmpty)
+		})
+
+		Convey("Should return details if GDD_ENV is dev", func() {
+			os.Setenv("GDD_ENV", "dev")
+			_, resp := rest.NewErrorResponse(err)
+			So(resp.Details, ShouldContainSubstring, "boom")
+		})
+
+		Convey("Should return details if GddErrorDetailsEnable is true", func() {
+			os.Unsetenv("GDD_ENV")
+			config.GddErrorDetailsEnable.Write("true")
+			_, resp := rest.NewErrorResponse(err)
+			So(resp.Details, ShouldContainSubstring, "boom")
+		})
+	})
+}
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type bodyLimitKey struct{}
+
+// withBodyLimit overrides GddMaxBodySize for requests handled by inner
+func withBodyLimit(inner http.Handler, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bodyLimitKey{}, limit)))
+	})
+}
+
+// bodyLimit returns max size in bytes of request body, which is MaxBodySize of the matched route if set,
+// otherwise GddMaxBodySize. Non-positive value means unlimited.
+func bodyLimit(r *http.Request) int64 {
+	if limit, ok := r.Context().Value(bodyLimitKey{}).(int64); ok {
+		return limit
+	}
+	return maxBodySize()
+}
+
+// MaxBodySize rejects requests whose Content-Length is larger than GddMaxBodySize with 413 status code, and limits
+// reading request body of the other requests to GddMaxBodySize by http.MaxBytesReader, e.g. requests in chunked
+// encoding. Reading more returns an error converted to 413 response if handlers panic with it, and the connection
+// is closed after the response. Set MaxBodySize of a Route to override it.
+func MaxBodySize(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := bodyLimit(r)
+		if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		if r.ContentLength > limit {
+			writeErrorResponse(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", limit))
+			return
+		}
+		r2 := *r
+		r2.Body = http.MaxBytesReader(w, r.Body, limit)
+		inner.ServeHTTP(w, &r2)
+	})
+}
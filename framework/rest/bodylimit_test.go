@@ -0,0 +1,108 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	_ = config.GddMaxBodySize.Write("16")
+	defer config.GddMaxBodySize.Write("")
+	handler := rest.Recovery(rest.MaxBodySize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			panic(err)
+		}
+		w.Write(body)
+	})))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("hello")))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "hello", w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("a", 32))))
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	var resp rest.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, rest.ErrorResponse{Code: 1, Message: "request body must not be larger than 16 bytes"}, resp)
+
+	// unknown content length, e.g. chunked encoding
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("a", 32)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	resp = rest.ErrorResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, rest.ErrorResponse{Code: 1, Message: "request body too large"}, resp)
+
+	// exactly the limit
+	req = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("a", 16)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, strings.Repeat("a", 16), w.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("a", 17)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestRestServer_RouteMaxBodySize(t *testing.T) {
+	_ = config.GddMaxBodySize.Write("16")
+	defer config.GddMaxBodySize.Write("")
+	config.GddPort.Write("6075")
+	echo := func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := rest.DecodeJSON(r, &payload); err != nil {
+			panic(err)
+		}
+		w.Write([]byte(payload["name"]))
+	}
+	go func() {
+		srv := rest.NewRestServer()
+		srv.AddRoute(rest.Route{
+			Name:        "Small",
+			Method:      http.MethodPost,
+			Pattern:     "/small",
+			HandlerFunc: echo,
+		}, rest.Route{
+			Name:        "Large",
+			Method:      http.MethodPost,
+			Pattern:     "/large",
+			HandlerFunc: echo,
+			MaxBodySize: 64,
+		})
+		srv.Run()
+	}()
+
+	body := `{"name":"` + strings.Repeat("a", 32) + `"}`
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = http.Post("http://localhost:6075/large", "application/json", strings.NewReader(body))
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, strings.Repeat("a", 32), string(data))
+
+	resp, err := http.Post("http://localhost:6075/small", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
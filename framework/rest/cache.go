@@ -0,0 +1,249 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/unionj-cloud/go-doudou/v2/framework/kvstore"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var CacheRoutes = cacheRoutes
+
+// cachePrefix prefixes keys of cached responses to separate them from other states in the same store
+const cachePrefix = "gdd:cache:"
+
+var cacheStoresLock sync.Mutex
+
+// cacheStores collects stores used by Cache middlewares for invalidation from management endpoint
+var cacheStores []kvstore.Store
+
+func registerCacheStore(store kvstore.Store) {
+	cacheStoresLock.Lock()
+	defer cacheStoresLock.Unlock()
+	for _, item := range cacheStores {
+		if item == store {
+			return
+		}
+	}
+	cacheStores = append(cacheStores, store)
+}
+
+type cachedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"storedAt"`
+}
+
+type cacheOptions struct {
+	headers []string
+}
+
+// CacheOption configures Cache middleware
+type CacheOption func(*cacheOptions)
+
+// WithCacheKeyHeaders adds values of request headers to cache key, e.g. Accept-Language
+func WithCacheKeyHeaders(headers ...string) CacheOption {
+	return func(options *cacheOptions) {
+		options.headers = append(options.headers, headers...)
+	}
+}
+
+// uncachedHeaders identify a single request, so they are never replayed from cached responses
+var uncachedHeaders = []string{CorrelationIDHeader, CausationIDHeader, HeaderXRequestID}
+
+// handlerHeader returns headers in header which were added or changed since before was taken, i.e. headers set by
+// the cached handler itself rather than by outer middlewares, excluding uncachedHeaders
+func handlerHeader(before, header http.Header) http.Header {
+	ret := make(http.Header)
+	for k, v := range header {
+		if prev, ok := before[k]; ok && equalValues(prev, v) {
+			continue
+		}
+		ret[k] = append([]string(nil), v...)
+	}
+	for _, k := range uncachedHeaders {
+		ret.Del(k)
+	}
+	return ret
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CacheKey returns cache key of r in the form of "METHOD /path?query", query parameters are sorted by key.
+// Values of headers are appended as "|Header=value" in the given order.
+func CacheKey(r *http.Request, headers ...string) string {
+	var sb strings.Builder
+	sb.WriteString(r.Method)
+	sb.WriteString(" ")
+	sb.WriteString(r.URL.Path)
+	if query := r.URL.Query().Encode(); query != "" {
+		sb.WriteString("?")
+		sb.WriteString(query)
+	}
+	for _, header := range headers {
+		sb.WriteString("|")
+		sb.WriteString(http.CanonicalHeaderKey(header))
+		sb.WriteString("=")
+		sb.WriteString(r.Header.Get(header))
+	}
+	return sb.String()
+}
+
+type cacheResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *cacheResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *cacheResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Cache caches 2xx responses of GET and HEAD requests in store for ttl, keyed by CacheKey. It is meant to be
+// attached to expensive read routes one by one, e.g. HandlerFunc: rest.Cache(store, time.Minute)(handler).ServeHTTP.
+// Requests carrying X-Tenant-Id header are keyed by it as well, so that tenants never share cached responses.
+// Only headers set by the handler are cached, headers set by outer middlewares and correlation and request id
+// headers are not replayed. Cached responses are served with Age header in seconds, and can be invalidated by key
+// pattern from DELETE /go-doudou/cache?pattern=GET%20/users* if store implements kvstore.Scanner.
+func Cache(store kvstore.Store, ttl time.Duration, opts ...CacheOption) func(inner http.Handler) http.Handler {
+	var options cacheOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	registerCacheStore(store)
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				inner.ServeHTTP(w, r)
+				return
+			}
+			headers := options.headers
+			if r.Header.Get(TenantHeader) != "" && !containsHeader(headers, TenantHeader) {
+				headers = append(headers[:len(headers):len(headers)], TenantHeader)
+			}
+			key := cachePrefix + CacheKey(r, headers...)
+			if value, ok, err := store.Get(r.Context(), key); err != nil {
+				logger.Error().Err(err).Msgf("failed to get cached response of %s", key)
+			} else if ok {
+				var cached cachedResponse
+				if err = json.Unmarshal(value, &cached); err == nil {
+					for k, v := range cached.Header {
+						w.Header()[k] = v
+					}
+					w.Header().Set("Age", strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+					w.WriteHeader(cached.StatusCode)
+					w.Write(cached.Body)
+					return
+				}
+				logger.Error().Err(err).Msgf("failed to decode cached response of %s", key)
+			}
+			before := w.Header().Clone()
+			rw := &cacheResponseWriter{ResponseWriter: w}
+			inner.ServeHTTP(rw, r)
+			if rw.statusCode < 200 || rw.statusCode >= 300 {
+				return
+			}
+			value, _ := json.Marshal(cachedResponse{
+				StatusCode: rw.statusCode,
+				Header:     handlerHeader(before, w.Header()),
+				Body:       rw.body.Bytes(),
+				StoredAt:   time.Now(),
+			})
+			if err := store.Set(r.Context(), key, value, ttl); err != nil {
+				logger.Error().Err(err).Msgf("failed to cache response of %s", key)
+			}
+		})
+	}
+}
+
+func containsHeader(headers []string, header string) bool {
+	for _, item := range headers {
+		if http.CanonicalHeaderKey(item) == header {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidateCache deletes cached responses whose CacheKey matches glob-style pattern from all stores used by
+// Cache middlewares, and returns the number of deleted responses. Stores not implementing kvstore.Scanner are skipped.
+func InvalidateCache(ctx context.Context, pattern string) (int, error) {
+	cacheStoresLock.Lock()
+	stores := make([]kvstore.Store, len(cacheStores))
+	copy(stores, cacheStores)
+	cacheStoresLock.Unlock()
+	var deleted int
+	for _, store := range stores {
+		scanner, ok := store.(kvstore.Scanner)
+		if !ok {
+			continue
+		}
+		keys, err := scanner.Keys(ctx, cachePrefix+pattern)
+		if err != nil {
+			return deleted, err
+		}
+		for _, key := range keys {
+			if err = store.Del(ctx, key); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func cacheRoutes() []Route {
+	return []Route{
+		{
+			Name:    "DeleteCache",
+			Method:  http.MethodDelete,
+			Pattern: "/go-doudou/cache",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				pattern := _req.FormValue("pattern")
+				if pattern == "" {
+					http.Error(_writer, "missing pattern", http.StatusBadRequest)
+					return
+				}
+				deleted, err := InvalidateCache(_req.Context(), pattern)
+				if err != nil {
+					http.Error(_writer, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				_writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				json.NewEncoder(_writer).Encode(struct {
+					Deleted int `json:"deleted"`
+				}{
+					Deleted: deleted,
+				})
+			},
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package rest_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/kvstore"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var calls int
+	handler := rest.Cache(kvstore.NewMemoryStore(), time.Minute, rest.WithCacheKeyHeaders("Accept-Language"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.URL.Query().Get("fail") != "" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprintf(w, "%s %d", r.Header.Get("Accept-Language"), calls)
+		}))
+	do := func(method, target, lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "/users?b=2&a=1", "en")
+	require.Equal(t, "en 1", w.Body.String())
+	require.Empty(t, w.Header().Get("Age"))
+
+	// query parameters in different order hit the same cache
+	w = do(http.MethodGet, "/users?a=1&b=2", "en")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "en 1", w.Body.String())
+	require.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	require.Equal(t, "0", w.Header().Get("Age"))
+
+	// selected headers are part of cache key
+	w = do(http.MethodGet, "/users?a=1&b=2", "zh")
+	require.Equal(t, "zh 2", w.Body.String())
+
+	// non 2xx responses are not cached
+	require.Equal(t, http.StatusInternalServerError, do(http.MethodGet, "/users?fail=1", "en").Code)
+	require.Equal(t, http.StatusInternalServerError, do(http.MethodGet, "/users?fail=1", "en").Code)
+	require.Equal(t, 4, calls)
+
+	// unsafe methods are never cached
+	require.Equal(t, "en 5", do(http.MethodPost, "/users?a=1&b=2", "en").Body.String())
+	require.Equal(t, "en 6", do(http.MethodPost, "/users?a=1&b=2", "en").Body.String())
+
+	deleted, err := rest.InvalidateCache(context.Background(), "GET /users*Accept-Language=en")
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	require.Equal(t, "en 7", do(http.MethodGet, "/users?a=1&b=2", "en").Body.String())
+	require.Equal(t, "zh 2", do(http.MethodGet, "/users?a=1&b=2", "zh").Body.String())
+}
+
+func TestCacheRoutes(t *testing.T) {
+	store := kvstore.NewMemoryStore()
+	handler := rest.Cache(store, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders/2", nil))
+
+	route := rest.CacheRoutes()[0]
+	w := httptest.NewRecorder()
+	route.HandlerFunc(w, httptest.NewRequest(http.MethodDelete, "/go-doudou/cache", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	route.HandlerFunc(w, httptest.NewRequest(http.MethodDelete, "/go-doudou/cache?pattern=GET%20/orders/*", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"deleted":2}`, w.Body.String())
+}
+
+func TestCache_Headers(t *testing.T) {
+	var calls int
+	handler := rest.Correlation(rest.Cache(kvstore.NewMemoryStore(), time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Request-ID", fmt.Sprint(calls))
+			fmt.Fprintf(w, "%d", calls)
+		})))
+	do := func(correlationID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set(rest.CorrelationIDHeader, correlationID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("first")
+	require.Equal(t, "1", w.Body.String())
+	require.Equal(t, "first", w.Header().Get(rest.CorrelationIDHeader))
+
+	// headers set by outer middlewares and request ids are not replayed from cache
+	w = do("second")
+	require.Equal(t, "1", w.Body.String())
+	require.Equal(t, "0", w.Header().Get("Age"))
+	require.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	require.Equal(t, "second", w.Header().Get(rest.CorrelationIDHeader))
+	require.Empty(t, w.Header().Get("X-Request-ID"))
+}
+
+func TestCache_Tenant(t *testing.T) {
+	handler := rest.Tenant(nil)(rest.Cache(kvstore.NewMemoryStore(), time.Minute)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, _ := rest.TenantFromContext(r.Context())
+			w.Write([]byte(tenantID))
+		})))
+	do := func(tenantID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set(rest.TenantHeader, tenantID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, "acme", do("acme").Body.String())
+	w := do("globex")
+	require.Equal(t, "globex", w.Body.String())
+	require.Empty(t, w.Header().Get("Age"))
+	w = do("acme")
+	require.Equal(t, "acme", w.Body.String())
+	require.Equal(t, "0", w.Header().Get("Age"))
+
+	deleted, err := rest.InvalidateCache(context.Background(), "GET /orders|X-Tenant-Id=globex")
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"context"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type peerAddrKey struct{}
+
+// ConnContext stores remote address of c into context of requests from it, which is kept even if r.RemoteAddr is
+// rewritten by handlers.ProxyHeaders. Set it to http.Server.ConnContext.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, peerAddrKey{}, c.RemoteAddr().String())
+}
+
+// peerIP returns ip of the peer sending r, falling back to r.RemoteAddr if ConnContext is not set
+func peerIP(r *http.Request) string {
+	addr, ok := r.Context().Value(peerAddrKey{}).(string)
+	if !ok {
+		addr = r.RemoteAddr
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+var trustedProxiesCache struct {
+	sync.Mutex
+	value string
+	nets  []*net.IPNet
+}
+
+// trustedProxies returns parsed GddTrustedProxies. Malformed value trusts no proxy.
+func trustedProxies() []*net.IPNet {
+	value := config.GddTrustedProxies.LoadOrDefault(config.DefaultGddTrustedProxies)
+	trustedProxiesCache.Lock()
+	defer trustedProxiesCache.Unlock()
+	if value == trustedProxiesCache.value {
+		return trustedProxiesCache.nets
+	}
+	nets, err := parseCIDRs(value)
+	if err != nil {
+		logger.Error().Err(err).Msgf("[go-doudou] invalid %s, no proxy is trusted", string(config.GddTrustedProxies))
+		nets = nil
+	}
+	trustedProxiesCache.value, trustedProxiesCache.nets = value, nets
+	return nets
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns ip of the client sending r. It is the ip of the peer connected to the server, unless the peer is
+// one of GddTrustedProxies, then X-Forwarded-For is walked from right to left skipping trusted proxies, or X-Real-IP
+// is used if X-Forwarded-For is absent. Forwarded headers from untrusted peers are ignored, so that clients cannot
+// spoof their ip.
+func ClientIP(r *http.Request) string {
+	peer := peerIP(r)
+	nets := trustedProxies()
+	ip := net.ParseIP(peer)
+	if ip == nil || !containsIP(nets, ip) {
+		return peer
+	}
+	if values := r.Header.Values(HeaderXForwardedFor); len(values) > 0 {
+		hops := strings.Split(strings.Join(values, ","), ",")
+		client := peer
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				break
+			}
+			client = hop.String()
+			if !containsIP(nets, hop) {
+				break
+			}
+		}
+		return client
+	}
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get(HeaderXRealIP))); realIP != nil {
+		return realIP.String()
+	}
+	return peer
+}
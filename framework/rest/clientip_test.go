@@ -0,0 +1,62 @@
+package rest_test
+
+import (
+	"github.com/gorilla/handlers"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	newRequest := func(remoteAddr string, headers map[string]string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return r
+	}
+
+	// no proxy is trusted by default
+	require.Equal(t, "203.0.113.9", rest.ClientIP(newRequest("203.0.113.9:1234", map[string]string{
+		"X-Forwarded-For": "198.51.100.1",
+		"X-Real-IP":       "198.51.100.2",
+	})))
+
+	config.GddTrustedProxies.Write("10.0.0.0/8,192.168.1.1")
+	defer os.Unsetenv(string(config.GddTrustedProxies))
+	require.Equal(t, "198.51.100.1", rest.ClientIP(newRequest("10.0.0.1:1234", map[string]string{
+		"X-Forwarded-For": "6.6.6.6, 198.51.100.1, 192.168.1.1",
+	})))
+	require.Equal(t, "198.51.100.2", rest.ClientIP(newRequest("10.0.0.1:1234", map[string]string{
+		"X-Real-IP": "198.51.100.2",
+	})))
+	require.Equal(t, "10.0.0.1", rest.ClientIP(newRequest("10.0.0.1:1234", nil)))
+	// spoofed by untrusted peer
+	require.Equal(t, "203.0.113.9", rest.ClientIP(newRequest("203.0.113.9:1234", map[string]string{
+		"X-Forwarded-For": "10.0.0.2",
+	})))
+}
+
+func TestClientIP_ProxyHeaders(t *testing.T) {
+	ts := httptest.NewUnstartedServer(handlers.ProxyHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rest.RateLimitByIP(r)))
+	})))
+	ts.Config.ConnContext = rest.ConnContext
+	ts.Start()
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	require.Equal(t, "127.0.0.1", string(body))
+}
@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"context"
+	"github.com/ascarter/requestid"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+const (
+	// CorrelationIDHeader is the http header carrying correlation id, which is constant across the whole flow
+	CorrelationIDHeader = "X-Correlation-Id"
+	// CausationIDHeader is the http header carrying causation id, which is the id of the immediate parent hop
+	CausationIDHeader = "X-Causation-Id"
+)
+
+// CorrelationIDs identifies a hop in an event-driven or multi-hop request flow
+type CorrelationIDs struct {
+	// CorrelationID is constant across the whole flow
+	CorrelationID string
+	// CausationID is the ID of the immediate parent hop, empty for the first hop of the flow
+	CausationID string
+	// ID is the id of current hop, which will be the causation id of downstream hops.
+	// Correlation middleware uses request id as ID.
+	ID string
+}
+
+type correlationKey struct{}
+
+// NewCorrelationContext returns a copy of ctx carrying ids, which will be propagated to downstream services
+// by generated http clients. Handlers can call it to start a new flow or to continue a flow from consumed events.
+func NewCorrelationContext(ctx context.Context, ids CorrelationIDs) context.Context {
+	return context.WithValue(ctx, correlationKey{}, ids)
+}
+
+// CorrelationFromContext returns ids put into ctx by Correlation middleware or NewCorrelationContext
+func CorrelationFromContext(ctx context.Context) (CorrelationIDs, bool) {
+	ids, ok := ctx.Value(correlationKey{}).(CorrelationIDs)
+	return ids, ok && ids.CorrelationID != ""
+}
+
+// Correlation reads X-Correlation-Id and X-Causation-Id headers and puts them into request context together with
+// request id of current hop. A new correlation id is generated if the request doesn't carry one, so that every
+// request belongs to a flow. The correlation id is also set to response header.
+func Correlation(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids := CorrelationIDs{
+			CorrelationID: r.Header.Get(CorrelationIDHeader),
+			CausationID:   r.Header.Get(CausationIDHeader),
+		}
+		if ids.CorrelationID == "" {
+			ids.CorrelationID = uuid.New().String()
+		}
+		if rid, ok := requestid.FromContext(r.Context()); ok {
+			ids.ID = rid
+		} else if rid = r.Header.Get("X-Request-ID"); rid != "" {
+			ids.ID = rid
+		} else {
+			ids.ID = uuid.New().String()
+		}
+		w.Header().Set(CorrelationIDHeader, ids.CorrelationID)
+		inner.ServeHTTP(w, r.WithContext(NewCorrelationContext(r.Context(), ids)))
+	})
+}
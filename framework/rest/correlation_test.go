@@ -0,0 +1,47 @@
+package rest_test
+
+import (
+	"context"
+	"github.com/ascarter/requestid"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorrelation(t *testing.T) {
+	var ids rest.CorrelationIDs
+	handler := requestid.RequestIDHandler(rest.Correlation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids, _ = rest.CorrelationFromContext(r.Context())
+	})))
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(rest.CorrelationIDHeader, "flow-1")
+	req.Header.Set(rest.CausationIDHeader, "parent-1")
+	req.Header.Set("X-Request-ID", "rid-1")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, rest.CorrelationIDs{
+		CorrelationID: "flow-1",
+		CausationID:   "parent-1",
+		ID:            "rid-1",
+	}, ids)
+	require.Equal(t, "flow-1", rr.Header().Get(rest.CorrelationIDHeader))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	require.NotEmpty(t, ids.CorrelationID)
+	require.Empty(t, ids.CausationID)
+	require.NotEmpty(t, ids.ID)
+	require.Equal(t, ids.CorrelationID, rr.Header().Get(rest.CorrelationIDHeader))
+}
+
+func TestCorrelationFromContext(t *testing.T) {
+	_, ok := rest.CorrelationFromContext(context.Background())
+	require.False(t, ok)
+	ctx := rest.NewCorrelationContext(context.Background(), rest.CorrelationIDs{CorrelationID: "flow-1", ID: "event-1"})
+	ids, ok := rest.CorrelationFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "event-1", ids.ID)
+}
@@ -2,15 +2,54 @@ package rest
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"html/template"
 	"net/http"
+	"path"
 )
 
 // Oas store OpenAPI3.0 description json string
 var Oas string
 var DocRoutes = docRoutes
 
+// externalBasePath returns base path prefixed by reverse proxy from GddExternalBasePath config
+// or X-Forwarded-Prefix request header
+func externalBasePath(r *http.Request) string {
+	basePath := config.GddExternalBasePath.LoadOrDefault(config.DefaultGddExternalBasePath)
+	if stringutils.IsEmpty(basePath) {
+		basePath = r.Header.Get("X-Forwarded-Prefix")
+	}
+	if stringutils.IsEmpty(basePath) {
+		return ""
+	}
+	return path.Join("/", basePath)
+}
+
+// oasBehindProxy rewrites servers url of OpenAPI3.0 description json string to external base path
+func oasBehindProxy(basePath string) string {
+	if stringutils.IsEmpty(basePath) || stringutils.IsEmpty(Oas) {
+		return Oas
+	}
+	var api map[string]interface{}
+	if err := json.Unmarshal([]byte(Oas), &api); err != nil {
+		return Oas
+	}
+	rr := config.GddRouteRootPath.LoadOrDefault(config.DefaultGddRouteRootPath)
+	api["servers"] = []map[string]string{
+		{
+			"url": path.Join(basePath, "/", rr),
+		},
+	}
+	data, err := json.Marshal(api)
+	if err != nil {
+		return Oas
+	}
+	return string(data)
+}
+
 func docRoutes() []Route {
 	return []Route{
 		{
@@ -23,6 +62,7 @@ func docRoutes() []Route {
 					err    error
 					buf    bytes.Buffer
 					scheme string
+					docUrl string
 				)
 				if tpl, err = template.New("onlinedoc.tmpl").Parse(onlinedocTmpl); err != nil {
 					panic(err)
@@ -32,8 +72,14 @@ func docRoutes() []Route {
 				} else {
 					scheme = "https"
 				}
-				doc := Oas
-				docUrl := fmt.Sprintf("%s://%s/go-doudou/openapi.json", scheme, _req.Host)
+				basePath := externalBasePath(_req)
+				doc := oasBehindProxy(basePath)
+				if stringutils.IsNotEmpty(basePath) {
+					// relative to host, so that scheme and host rewritten by reverse proxy are kept
+					docUrl = path.Join(basePath, "/go-doudou/openapi.json")
+				} else {
+					docUrl = fmt.Sprintf("%s://%s/go-doudou/openapi.json", scheme, _req.Host)
+				}
 				if err = tpl.Execute(&buf, struct {
 					Doc    string
 					DocUrl string
@@ -52,7 +98,7 @@ func docRoutes() []Route {
 			Method:  "GET",
 			Pattern: "/go-doudou/openapi.json",
 			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
-				_writer.Write([]byte(Oas))
+				_writer.Write([]byte(oasBehindProxy(externalBasePath(_req))))
 			},
 		},
 	}
@@ -0,0 +1,61 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func docHandler(t *testing.T, name string) http.HandlerFunc {
+	for _, item := range rest.DocRoutes() {
+		if item.Name == name {
+			return item.HandlerFunc
+		}
+	}
+	t.Fatalf("doc route %s not found", name)
+	return nil
+}
+
+func TestDocRoutes_BehindProxy(t *testing.T) {
+	oas := rest.Oas
+	defer func() {
+		rest.Oas = oas
+	}()
+	rest.Oas = `{"openapi":"3.0.2","servers":[{"url":"http://localhost:6060"}]}`
+	config.GddRouteRootPath.Write("/api")
+	defer os.Unsetenv(string(config.GddRouteRootPath))
+
+	servers := func(req *http.Request) string {
+		rec := httptest.NewRecorder()
+		docHandler(t, "GetOpenAPI")(rec, req)
+		var api struct {
+			Servers []struct {
+				URL string `json:"url"`
+			} `json:"servers"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &api))
+		require.Len(t, api.Servers, 1)
+		return api.Servers[0].URL
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/go-doudou/openapi.json", nil)
+	require.Equal(t, "http://localhost:6060", servers(req))
+
+	req = httptest.NewRequest(http.MethodGet, "/go-doudou/openapi.json", nil)
+	req.Header.Set("X-Forwarded-Prefix", "/user-svc/")
+	require.Equal(t, "/user-svc/api", servers(req))
+
+	config.GddExternalBasePath.Write("gateway/user")
+	defer os.Unsetenv(string(config.GddExternalBasePath))
+	require.Equal(t, "/gateway/user/api", servers(req))
+
+	rec := httptest.NewRecorder()
+	docHandler(t, "GetDoc")(rec, httptest.NewRequest(http.MethodGet, "/go-doudou/doc", nil))
+	// escaped in javascript string by html/template
+	require.Contains(t, rec.Body.String(), `window.docUrl = "\/gateway\/user\/go-doudou\/openapi.json"`)
+}
@@ -0,0 +1,229 @@
+package rest
+
+import (
+	"context"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	cons "github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/memberlist"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// drainPollInterval is the interval for checking whether all connections have been closed during draining
+const drainPollInterval = 100 * time.Millisecond
+
+// ConnTracker wraps net.Listener to track all accepted connections. Different from http.Server, it keeps
+// tracking hijacked connections such as WebSocket, so that they can be forcibly closed after draining.
+type ConnTracker struct {
+	net.Listener
+	mu    sync.Mutex
+	conns map[*trackedConn]struct{}
+}
+
+// NewConnTracker creates a ConnTracker from l
+func NewConnTracker(l net.Listener) *ConnTracker {
+	return &ConnTracker{
+		Listener: l,
+		conns:    make(map[*trackedConn]struct{}),
+	}
+}
+
+// Accept waits for and returns the next connection to the listener and starts tracking it
+func (t *ConnTracker) Accept() (net.Conn, error) {
+	conn, err := t.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tc := &trackedConn{Conn: conn, tracker: t}
+	t.mu.Lock()
+	t.conns[tc] = struct{}{}
+	t.mu.Unlock()
+	return tc, nil
+}
+
+// Len returns count of connections which have not been closed yet
+func (t *ConnTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.conns)
+}
+
+// CloseAll forcibly closes all connections which have not been closed yet and returns the count
+func (t *ConnTracker) CloseAll() int {
+	t.mu.Lock()
+	conns := make([]*trackedConn, 0, len(t.conns))
+	for conn := range t.conns {
+		conns = append(conns, conn)
+	}
+	t.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+	return len(conns)
+}
+
+func (t *ConnTracker) remove(conn *trackedConn) {
+	t.mu.Lock()
+	delete(t.conns, conn)
+	t.mu.Unlock()
+}
+
+type trackedConn struct {
+	net.Conn
+	tracker *ConnTracker
+	once    sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.tracker.remove(c)
+	})
+	return c.Conn.Close()
+}
+
+// GracefulShutdown shuts down httpServer gracefully within GddGraceTimeout, then waits at most GddDrainTimeout
+// for remaining long-lived connections tracked by tracker, finally forcibly closes them and logs the count.
+// Both steps give up once ctx is done, which carries the budget of the whole shutdown sequence, see
+// registry.ShutdownContext. tracker can be nil, then only graceful shutdown will be performed.
+func GracefulShutdown(ctx context.Context, httpServer *http.Server, tracker *ConnTracker) {
+	grace, err := time.ParseDuration(config.GddGraceTimeout.Load())
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddGraceTimeout),
+			config.GddGraceTimeout.Load(), err.Error(), config.DefaultGddGraceTimeout)
+		grace, _ = time.ParseDuration(config.DefaultGddGraceTimeout)
+	}
+	logger.Info().Msgf("Http server is gracefully shutting down in %s", grace)
+
+	graceCtx, cancel := context.WithTimeout(ctx, grace)
+	defer cancel()
+	// Doesn't block if no connections, but will otherwise wait
+	// until the timeout deadline.
+	httpServer.Shutdown(graceCtx)
+	if tracker == nil || tracker.Len() == 0 {
+		return
+	}
+
+	drain, err := time.ParseDuration(config.GddDrainTimeout.Load())
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddDrainTimeout),
+			config.GddDrainTimeout.Load(), err.Error(), config.DefaultGddDrainTimeout)
+		drain, _ = time.ParseDuration(config.DefaultGddDrainTimeout)
+	}
+	if drain > 0 {
+		logger.Info().Msgf("Http server is draining %d long-lived connections in %s", tracker.Len(), drain)
+		deadline := time.NewTimer(drain)
+		defer deadline.Stop()
+		ticker := time.NewTicker(drainPollInterval)
+		defer ticker.Stop()
+	L:
+		for tracker.Len() > 0 {
+			select {
+			case <-ctx.Done():
+				break L
+			case <-deadline.C:
+				break L
+			case <-ticker.C:
+			}
+		}
+	}
+	if n := tracker.CloseAll(); n > 0 {
+		logger.Warn().Msgf("Http server forcibly closed %d connections after drain timeout", n)
+	}
+}
+
+var inFlightRequests int64
+
+// TrackInFlight counts requests being processed by inner, see InFlightRequests. It is added to middleware chain of
+// business routes by default.
+func TrackInFlight(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&inFlightRequests, 1)
+		defer atomic.AddInt64(&inFlightRequests, -1)
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// InFlightRequests returns count of business requests being processed
+func InFlightRequests() int64 {
+	return atomic.LoadInt64(&inFlightRequests)
+}
+
+func drainDuration(name, value, defaultValue string) time.Duration {
+	if value == "" {
+		value = defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", name,
+			value, err.Error(), defaultValue)
+		d, _ = time.ParseDuration(defaultValue)
+	}
+	return d
+}
+
+// sleepContext blocks for d or until ctx is done, and returns ctx.Err() in the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// DrainAndLeave migrates traffic away from local node for zero-drop deploys. It marks local memberlist node as draining,
+// waits GddDrainPropagationWait for the status to be gossiped, flips readiness of local node to false, waits at most
+// GddDrainInFlightTimeout for in-flight requests to complete, then leaves the memberlist cluster within
+// GddDrainLeaveTimeout and returns, so that the caller can shut down http server. Memberlist steps are skipped if
+// memberlist service discovery mode is not enabled. It returns ctx.Err() if ctx is done before all steps finished.
+func DrainAndLeave(ctx context.Context) error {
+	_, mem := config.ServiceDiscoveryMap()[cons.SD_MEMBERLIST]
+	if mem {
+		logger.Info().Msg("[go-doudou] draining: marking local node as draining")
+		if err := memberlist.Drain(); err != nil {
+			logger.Error().Err(err).Msg("")
+		}
+		wait := drainDuration(string(config.GddDrainPropagationWait), config.GddDrainPropagationWait.Load(), config.DefaultGddDrainPropagationWait)
+		logger.Info().Msgf("[go-doudou] draining: waiting %s for draining status to propagate", wait)
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+		logger.Info().Msg("[go-doudou] draining: flipping readiness of local node to false")
+		if err := memberlist.MarkNotReady(); err != nil {
+			logger.Error().Err(err).Msg("")
+		}
+	}
+	timeout := drainDuration(string(config.GddDrainInFlightTimeout), config.GddDrainInFlightTimeout.Load(), config.DefaultGddDrainInFlightTimeout)
+	logger.Info().Msgf("[go-doudou] draining: waiting at most %s for %d in-flight requests to complete", timeout, InFlightRequests())
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+L:
+	for InFlightRequests() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline.C:
+			logger.Warn().Msgf("[go-doudou] draining: %d requests are still in flight after %s", InFlightRequests(), timeout)
+			break L
+		case <-ticker.C:
+		}
+	}
+	if mem {
+		leave := drainDuration(string(config.GddDrainLeaveTimeout), config.GddDrainLeaveTimeout.Load(), config.DefaultGddDrainLeaveTimeout)
+		logger.Info().Msgf("[go-doudou] draining: leaving memberlist cluster in %s", leave)
+		memberlist.Leave(leave)
+	}
+	logger.Info().Msg("[go-doudou] draining: done, http server is ready to shut down")
+	return nil
+}
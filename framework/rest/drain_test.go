@@ -0,0 +1,204 @@
+package rest_test
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGracefulShutdown_ForceCloseHijacked(t *testing.T) {
+	config.GddGraceTimeout.Write("100ms")
+	config.GddDrainTimeout.Write("200ms")
+	defer func() {
+		config.GddGraceTimeout.Write(config.DefaultGddGraceTimeout)
+		config.GddDrainTimeout.Write(config.DefaultGddDrainTimeout)
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tracker := rest.NewConnTracker(ln)
+	hijacked := make(chan struct{})
+	httpServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// simulate a stuck stream which never ends by itself
+			_, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			buf.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\n")
+			buf.Flush()
+			close(hijacked)
+		}),
+	}
+	go httpServer.Serve(tracker)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	<-hijacked
+	require.Equal(t, 1, tracker.Len())
+
+	start := time.Now()
+	rest.GracefulShutdown(context.Background(), httpServer, tracker)
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.Equal(t, 0, tracker.Len())
+
+	reader := bufio.NewReader(conn)
+	_, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	_, err = io.ReadAll(reader)
+	require.NoError(t, err)
+}
+
+func TestGracefulShutdown_DrainBeforeTimeout(t *testing.T) {
+	config.GddGraceTimeout.Write("50ms")
+	config.GddDrainTimeout.Write("10s")
+	defer func() {
+		config.GddGraceTimeout.Write(config.DefaultGddGraceTimeout)
+		config.GddDrainTimeout.Write(config.DefaultGddDrainTimeout)
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tracker := rest.NewConnTracker(ln)
+	hijacked := make(chan struct{})
+	httpServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			close(hijacked)
+			go func() {
+				time.Sleep(200 * time.Millisecond)
+				conn.Close()
+			}()
+		}),
+	}
+	go httpServer.Serve(tracker)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	<-hijacked
+
+	start := time.Now()
+	rest.GracefulShutdown(context.Background(), httpServer, tracker)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, 0, tracker.Len())
+}
+
+func TestDrainAndLeave_WaitInFlight(t *testing.T) {
+	config.GddDrainInFlightTimeout.Write("5s")
+	defer config.GddDrainInFlightTimeout.Write(config.DefaultGddDrainInFlightTimeout)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := rest.TrackInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		close(done)
+	}()
+	<-started
+	require.Equal(t, int64(1), rest.InFlightRequests())
+
+	time.AfterFunc(200*time.Millisecond, func() {
+		close(release)
+	})
+	start := time.Now()
+	require.NoError(t, rest.DrainAndLeave(context.Background()))
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	<-done
+	require.Equal(t, int64(0), rest.InFlightRequests())
+}
+
+func TestDrainAndLeave_InFlightTimeout(t *testing.T) {
+	config.GddDrainInFlightTimeout.Write("200ms")
+	defer config.GddDrainInFlightTimeout.Write(config.DefaultGddDrainInFlightTimeout)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := rest.TrackInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-started
+
+	start := time.Now()
+	require.NoError(t, rest.DrainAndLeave(context.Background()))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, int64(1), rest.InFlightRequests())
+}
+
+func TestDrainAndLeave_ContextDone(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := rest.TrackInFlight(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, rest.DrainAndLeave(ctx), context.DeadlineExceeded)
+}
+
+func TestGracefulShutdown_ShutdownBudget(t *testing.T) {
+	config.GddGraceTimeout.Write("100ms")
+	config.GddDrainTimeout.Write("10s")
+	defer func() {
+		config.GddGraceTimeout.Write(config.DefaultGddGraceTimeout)
+		config.GddDrainTimeout.Write(config.DefaultGddDrainTimeout)
+	}()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tracker := rest.NewConnTracker(ln)
+	hijacked := make(chan struct{})
+	httpServer := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// simulate a stuck stream which never ends by itself
+			if _, _, err := w.(http.Hijacker).Hijack(); err != nil {
+				return
+			}
+			close(hijacked)
+		}),
+	}
+	go httpServer.Serve(tracker)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	<-hijacked
+
+	// drain timeout is cut short by what is left of the shutdown budget
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	rest.GracefulShutdown(ctx, httpServer, tracker)
+	require.Less(t, time.Since(start), 2*time.Second)
+	require.Equal(t, 0, tracker.Len())
+}
@@ -1,24 +1,23 @@
 package gorilla
 
 import (
-	"context"
 	"fmt"
 	"github.com/arl/statsviz"
 	"github.com/ascarter/requestid"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
-	"github.com/klauspost/compress/gzhttp"
 	"github.com/olekukonko/tablewriter"
 	"github.com/rs/cors"
 	"github.com/unionj-cloud/go-doudou/v2/framework"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/banner"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	register "github.com/unionj-cloud/go-doudou/v2/framework/registry"
-	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
 	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/timeutils"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
@@ -26,6 +25,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -48,87 +48,69 @@ type RestServer struct {
 	*mux.Router
 	rootRouter *mux.Router
 	common
+	// notFound and methodNotAllowed default to rest.NotFound and rest.MethodNotAllowed
+	notFound         http.Handler
+	methodNotAllowed http.Handler
 }
 
 const gddPathPrefix = "/go-doudou/"
 const debugPathPrefix = "/debug/"
 
-var contentTypeShouldbeGzip []string
-
-func init() {
-	contentTypeShouldbeGzip = []string{
-		"text/html",
-		"text/css",
-		"text/plain",
-		"text/xml",
-		"text/x-component",
-		"text/javascript",
-		"application/x-javascript",
-		"application/javascript",
-		"application/json",
-		"application/manifest+json",
-		"application/vnd.api+json",
-		"application/xml",
-		"application/xhtml+xml",
-		"application/rss+xml",
-		"application/atom+xml",
-		"application/vnd.ms-fontobject",
-		"application/x-font-ttf",
-		"application/x-font-opentype",
-		"application/x-font-truetype",
-		"image/svg+xml",
-		"image/x-icon",
-		"image/vnd.microsoft.icon",
-		"font/ttf",
-		"font/eot",
-		"font/otf",
-		"font/opentype",
-	}
-}
-
-// mux.MiddlewareFunc is alias for func(http.Handler) http.Handler
-func toMiddlewareFunc(m func(http.Handler) http.HandlerFunc) mux.MiddlewareFunc {
-	return func(handler http.Handler) http.Handler {
-		return m(handler)
-	}
-}
-
 // NewRestServer create a RestServer instance
 func NewRestServer(data ...map[string]interface{}) *RestServer {
-	rr := config.DefaultGddRouteRootPath
-	if stringutils.IsNotEmpty(config.GddRouteRootPath.Load()) {
-		rr = config.GddRouteRootPath.Load()
-	}
+	rr := rest.RouteRootPath()
 	rootRouter := mux.NewRouter().StrictSlash(true)
 	srv := &RestServer{
 		Router:     rootRouter.PathPrefix(rr).Subrouter().StrictSlash(true),
 		rootRouter: rootRouter,
 	}
 	srv.Middlewares = append(srv.Middlewares,
-		rest.Tracing,
+		routePattern,
+		rest.TracingFromConfig(),
 		rest.Metrics,
 	)
+	if recentRequests, ok := rest.RecentRequestsFromConfig(); ok {
+		srv.Middlewares = append(srv.Middlewares, recentRequests)
+	}
+	if slowRequests, ok := rest.SlowRequestsFromConfig(); ok {
+		srv.Middlewares = append(srv.Middlewares, slowRequests)
+	}
 	if cast.ToBoolOrDefault(config.GddEnableResponseGzip.Load(), config.DefaultGddEnableResponseGzip) {
-		gzipMiddleware, err := gzhttp.NewWrapper(gzhttp.ContentTypes(contentTypeShouldbeGzip))
+		gzipMiddleware, err := rest.GzipFromConfig()
 		if err != nil {
 			panic(err)
 		}
-		srv.Middlewares = append(srv.Middlewares, toMiddlewareFunc(gzipMiddleware))
+		srv.Middlewares = append(srv.Middlewares, gzipMiddleware)
 	}
 	if cast.ToBoolOrDefault(config.GddLogReqEnable.Load(), config.DefaultGddLogReqEnable) {
-		srv.Middlewares = append(srv.Middlewares, rest.Log)
+		srv.Middlewares = append(srv.Middlewares, rest.LogFromConfig())
 	}
 	srv.Middlewares = append(srv.Middlewares,
 		requestid.RequestIDHandler,
 		handlers.ProxyHeaders,
 		rest.FallbackContentType(config.GddFallbackContentType.LoadOrDefault(config.DefaultGddFallbackContentType)),
 	)
+	if rateLimit, ok := rest.RateLimitFromConfig(); ok {
+		srv.Middlewares = append(srv.Middlewares, rateLimit)
+	}
 	if len(data) > 0 {
 		srv.data = data[0]
 	}
 	return srv
 }
 
+// routePattern puts path template of the matched route into request context by rest.ContextWithRoutePattern
+func routePattern(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil {
+			if pattern, err := route.GetPathTemplate(); err == nil {
+				r = r.WithContext(rest.ContextWithRoutePattern(r.Context(), pattern))
+			}
+		}
+		inner.ServeHTTP(w, r)
+	})
+}
+
 // AddRoute adds routes to router
 func (srv *RestServer) AddRoute(route ...rest.Route) {
 	srv.bizRoutes = append(srv.bizRoutes, route...)
@@ -140,10 +122,7 @@ func (srv *common) printRoutes() {
 	}
 	logger.Info().Msg("================ Registered Routes ================")
 	data := [][]string{}
-	rr := config.DefaultGddRouteRootPath
-	if stringutils.IsNotEmpty(config.GddRouteRootPath.Load()) {
-		rr = config.GddRouteRootPath.Load()
-	}
+	rr := rest.NormalizeRootPath(config.GddRouteRootPath.LoadOrDefault(config.DefaultGddRouteRootPath))
 	var all []rest.Route
 	all = append(all, srv.bizRoutes...)
 	all = append(all, srv.gddRoutes...)
@@ -170,6 +149,27 @@ func (srv *common) printRoutes() {
 	logger.Info().Msg("===================================================")
 }
 
+// AddRouteWithMiddleware adds a route with middlewares applying to it only. They are called after global middlewares
+// added by AddMiddleware and PreMiddleware, so per-route middlewares are innermost.
+func (srv *RestServer) AddRouteWithMiddleware(route rest.Route, mwf ...func(http.Handler) http.Handler) {
+	for _, item := range mwf {
+		route.Middlewares = append(route.Middlewares, item)
+	}
+	srv.bizRoutes = append(srv.bizRoutes, route)
+}
+
+// SetNotFoundHandler replaces rest.NotFound as handler for requests not matching any route. It is wrapped by
+// middlewares the same as rest.NotFound. It should be called before Run.
+func (srv *RestServer) SetNotFoundHandler(handler http.Handler) {
+	srv.notFound = handler
+}
+
+// SetMethodNotAllowedHandler replaces rest.MethodNotAllowed as handler for requests matching a route but not its method.
+// It is wrapped by middlewares the same as rest.MethodNotAllowed. It should be called before Run.
+func (srv *RestServer) SetMethodNotAllowedHandler(handler http.Handler) {
+	srv.methodNotAllowed = handler
+}
+
 // AddMiddleware adds middlewares to the end of chain
 func (srv *RestServer) AddMiddleware(mwf ...func(http.Handler) http.Handler) {
 	for _, item := range mwf {
@@ -186,7 +186,7 @@ func (srv *RestServer) PreMiddleware(mwf ...func(http.Handler) http.Handler) {
 	srv.Middlewares = append(middlewares, srv.Middlewares...)
 }
 
-func (srv *RestServer) newHttpServer() *http.Server {
+func (srv *RestServer) newHttpServer(ln net.Listener) (*http.Server, *rest.ConnTracker) {
 	write, err := time.ParseDuration(config.GddWriteTimeout.Load())
 	if err != nil {
 		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddWriteTimeout),
@@ -222,25 +222,59 @@ func (srv *RestServer) newHttpServer() *http.Server {
 		WriteTimeout: write,
 		ReadTimeout:  read,
 		IdleTimeout:  idle,
-		Handler:      srv.rootRouter, // Pass our instance of gorilla/mux in.
+		// Wrap the whole router with recovery as the outermost layer, so that panics raised by any middleware
+		// are recovered too.
+		Handler: rest.Recovery(srv.rootRouter),
+		// keep peer address of connections for rest.ClientIP, as r.RemoteAddr is rewritten by handlers.ProxyHeaders
+		ConnContext: rest.ConnContext,
+	}
+	certFile := config.GddTLSCert.LoadOrDefault(config.DefaultGddTLSCert)
+	keyFile := config.GddTLSKey.LoadOrDefault(config.DefaultGddTLSKey)
+	useTLS := rest.TLSEnabled()
+	if err = rest.EnableH2C(httpServer); err != nil {
+		panic(err)
 	}
 
+	if ln == nil {
+		return httpServer, nil
+	}
+	tracker := rest.NewConnTracker(ln)
+
 	// Run our server in a goroutine so that it doesn't block.
 	go func() {
 		logger.Info().Msgf("Http server is listening at %v", httpServer.Addr)
 		logger.Info().Msgf("Http server started in %s", time.Since(startAt))
-		if err := httpServer.ListenAndServe(); err != nil {
+		var err error
+		if useTLS {
+			err = httpServer.ServeTLS(tracker, certFile, keyFile)
+		} else {
+			err = httpServer.Serve(tracker)
+		}
+		if err != nil {
 			logger.Error().Err(err).Msg("")
 		}
 	}()
 
-	return httpServer
+	return httpServer, tracker
 }
 
 // Run runs http server
 func (srv *RestServer) Run() {
 	banner.Print()
-	register.NewRest(srv.data)
+	// check tls files before registering service, otherwise clients may be routed to a node failing every request
+	if err := rest.CheckTLS(); err != nil {
+		logger.Panic().Err(err).Msg("")
+	}
+	// bind port before registering service, as the port may be changed if GddBindRetry is enabled
+	ln, err := rest.Listen()
+	if err != nil {
+		// nothing can be served without a listener, and service must not be registered
+		logger.Panic().Err(err).Msg("")
+	}
+	if err = register.NewRest(srv.data); err != nil {
+		ln.Close()
+		logger.Panic().Err(err).Msg("")
+	}
 	manage := cast.ToBoolOrDefault(config.GddManage.Load(), config.DefaultGddManage)
 	if manage {
 		srv.Middlewares = append([]mux.MiddlewareFunc{rest.PrometheusMiddleware}, srv.Middlewares...)
@@ -267,15 +301,15 @@ func (srv *RestServer) Run() {
 				return false
 			},
 		})
+		allowIPs, err := rest.ManageAllowIPsFromConfig()
+		if err != nil {
+			logger.Panic().Err(err).Msg("")
+		}
 		gddRouter.Use(rest.Metrics)
 		gddRouter.Use(corsOpts.Handler)
+		gddRouter.Use(allowIPs)
 		gddRouter.Use(rest.BasicAuth())
-		srv.gddRoutes = append(srv.gddRoutes, rest.DocRoutes()...)
-		srv.gddRoutes = append(srv.gddRoutes, rest.PromRoutes()...)
-		srv.gddRoutes = append(srv.gddRoutes, rest.ConfigRoutes()...)
-		if _, ok := config.ServiceDiscoveryMap()[constants.SD_MEMBERLIST]; ok {
-			srv.gddRoutes = append(srv.gddRoutes, rest.MemberlistUIRoutes()...)
-		}
+		srv.gddRoutes = append(srv.gddRoutes, rest.ManageRoutes()...)
 		for _, item := range srv.gddRoutes {
 			gddRouter.
 				Methods(item.Method, http.MethodOptions).
@@ -289,107 +323,119 @@ func (srv *RestServer) Run() {
 				config.GddStatsFreq.Load(), err.Error(), config.DefaultGddStatsFreq)
 			freq, _ = time.ParseDuration(config.DefaultGddStatsFreq)
 		}
-		srv.gddRoutes = append(srv.gddRoutes, []rest.Route{
-			{
-				Name:    "GetStatsvizWs",
-				Method:  "GET",
-				Pattern: gddPathPrefix + "statsviz/ws",
-			},
-			{
-				Name:    "GetStatsviz",
-				Method:  "GET",
-				Pattern: gddPathPrefix + "statsviz/",
-			},
-		}...)
-		gddRouter.
-			Methods(http.MethodGet).
-			Path("/statsviz/ws").
-			Name("GetStatsvizWs").
-			HandlerFunc(statsviz.NewWsHandler(freq))
-		gddRouter.
-			Methods(http.MethodGet).
-			PathPrefix("/statsviz/").
-			Name("GetStatsviz").
-			Handler(statsviz.IndexAtRoot(gddPathPrefix + "statsviz/"))
-		srv.debugRoutes = append(srv.debugRoutes, []rest.Route{
-			{
-				Name:    "GetDebugPprofCmdline",
-				Method:  "GET",
-				Pattern: debugPathPrefix + "pprof/cmdline",
-			},
-			{
-				Name:    "GetDebugPprofProfile",
-				Method:  "GET",
-				Pattern: debugPathPrefix + "pprof/profile",
-			},
-			{
-				Name:    "GetDebugPprofSymbol",
-				Method:  "GET",
-				Pattern: debugPathPrefix + "pprof/symbol",
-			},
-			{
-				Name:    "GetDebugPprofTrace",
-				Method:  "GET",
-				Pattern: debugPathPrefix + "pprof/trace",
-			},
-			{
-				Name:    "GetDebugPprofIndex",
-				Method:  "GET",
-				Pattern: debugPathPrefix + "pprof/",
-			},
-		}...)
-		debugRouter := srv.rootRouter.PathPrefix(debugPathPrefix).Subrouter().StrictSlash(true)
-		debugRouter.Use(rest.Metrics)
-		debugRouter.Use(corsOpts.Handler)
-		debugRouter.Use(rest.BasicAuth())
-		debugRouter.Methods(http.MethodGet).Path("/pprof/cmdline").Name("GetDebugPprofCmdline").HandlerFunc(pprof.Cmdline)
-		debugRouter.Methods(http.MethodGet).Path("/pprof/profile").Name("GetDebugPprofProfile").HandlerFunc(pprof.Profile)
-		debugRouter.Methods(http.MethodGet).Path("/pprof/symbol").Name("GetDebugPprofSymbol").HandlerFunc(pprof.Symbol)
-		debugRouter.Methods(http.MethodGet).Path("/pprof/trace").Name("GetDebugPprofTrace").HandlerFunc(pprof.Trace)
-		debugRouter.Methods(http.MethodGet).PathPrefix("/pprof/").Name("GetDebugPprofIndex").HandlerFunc(pprof.Index)
+		if rest.ManageStatsvizEnabled() {
+			srv.gddRoutes = append(srv.gddRoutes, []rest.Route{
+				{
+					Name:    "GetStatsvizWs",
+					Method:  "GET",
+					Pattern: gddPathPrefix + "statsviz/ws",
+				},
+				{
+					Name:    "GetStatsviz",
+					Method:  "GET",
+					Pattern: gddPathPrefix + "statsviz/",
+				},
+			}...)
+			gddRouter.
+				Methods(http.MethodGet).
+				Path("/statsviz/ws").
+				Name("GetStatsvizWs").
+				HandlerFunc(statsviz.NewWsHandler(freq))
+			gddRouter.
+				Methods(http.MethodGet).
+				PathPrefix("/statsviz/").
+				Name("GetStatsviz").
+				Handler(statsviz.IndexAtRoot(gddPathPrefix + "statsviz/"))
+		}
+		if rest.ManagePprofEnabled() {
+			srv.debugRoutes = append(srv.debugRoutes, []rest.Route{
+				{
+					Name:    "GetDebugPprofCmdline",
+					Method:  "GET",
+					Pattern: debugPathPrefix + "pprof/cmdline",
+				},
+				{
+					Name:    "GetDebugPprofProfile",
+					Method:  "GET",
+					Pattern: debugPathPrefix + "pprof/profile",
+				},
+				{
+					Name:    "GetDebugPprofSymbol",
+					Method:  "GET",
+					Pattern: debugPathPrefix + "pprof/symbol",
+				},
+				{
+					Name:    "GetDebugPprofTrace",
+					Method:  "GET",
+					Pattern: debugPathPrefix + "pprof/trace",
+				},
+				{
+					Name:    "GetDebugPprofIndex",
+					Method:  "GET",
+					Pattern: debugPathPrefix + "pprof/",
+				},
+			}...)
+			debugRouter := srv.rootRouter.PathPrefix(debugPathPrefix).Subrouter().StrictSlash(true)
+			debugRouter.Use(rest.Metrics)
+			debugRouter.Use(corsOpts.Handler)
+			debugRouter.Use(allowIPs)
+			debugRouter.Use(rest.BasicAuth())
+			debugRouter.Methods(http.MethodGet).Path("/pprof/cmdline").Name("GetDebugPprofCmdline").HandlerFunc(pprof.Cmdline)
+			debugRouter.Methods(http.MethodGet).Path("/pprof/profile").Name("GetDebugPprofProfile").HandlerFunc(pprof.Profile)
+			debugRouter.Methods(http.MethodGet).Path("/pprof/symbol").Name("GetDebugPprofSymbol").HandlerFunc(pprof.Symbol)
+			debugRouter.Methods(http.MethodGet).Path("/pprof/trace").Name("GetDebugPprofTrace").HandlerFunc(pprof.Trace)
+			debugRouter.Methods(http.MethodGet).PathPrefix("/pprof/").Name("GetDebugPprofIndex").HandlerFunc(pprof.Index)
+		}
 	}
-	srv.Middlewares = append(srv.Middlewares, rest.Recovery)
+	srv.Middlewares = append(srv.Middlewares, rest.TrackInFlight, rest.Maintenance, rest.Recovery)
 	srv.Use(srv.Middlewares...)
 	for _, item := range srv.bizRoutes {
 		srv.
 			Methods(item.Method, http.MethodOptions).
 			Path(item.Pattern).
 			Name(item.Name).
-			Handler(item.HandlerFunc)
+			Handler(item.Handler())
+	}
+	srv.rootRouter.NotFoundHandler = srv.rootRouter.NewRoute().BuildOnly().HandlerFunc(rest.NotFound).GetHandler()
+	srv.rootRouter.MethodNotAllowedHandler = srv.rootRouter.NewRoute().BuildOnly().HandlerFunc(rest.MethodNotAllowed).GetHandler()
+	if srv.notFound != nil {
+		srv.rootRouter.NotFoundHandler = srv.rootRouter.NewRoute().BuildOnly().Handler(srv.notFound).GetHandler()
+	}
+	if srv.methodNotAllowed != nil {
+		srv.rootRouter.MethodNotAllowedHandler = srv.rootRouter.NewRoute().BuildOnly().Handler(srv.methodNotAllowed).GetHandler()
 	}
-	srv.rootRouter.NotFoundHandler = srv.rootRouter.NewRoute().BuildOnly().HandlerFunc(http.NotFound).GetHandler()
-	srv.rootRouter.MethodNotAllowedHandler = srv.rootRouter.NewRoute().BuildOnly().HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		w.Write([]byte("405 method not allowed"))
-	}).GetHandler()
 	for i := len(srv.Middlewares) - 1; i >= 0; i-- {
 		srv.rootRouter.NotFoundHandler = srv.Middlewares[i].Middleware(srv.rootRouter.NotFoundHandler)
 		srv.rootRouter.MethodNotAllowedHandler = srv.Middlewares[i].Middleware(srv.rootRouter.MethodNotAllowedHandler)
 	}
 	srv.printRoutes()
-	httpServer := srv.newHttpServer()
+	httpServer, tracker := srv.newHttpServer(ln)
 	defer func() {
-		register.ShutdownRest()
-		grace, err := time.ParseDuration(config.GddGraceTimeout.Load())
-		if err != nil {
-			logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddGraceTimeout),
-				config.GddGraceTimeout.Load(), err.Error(), config.DefaultGddGraceTimeout)
-			grace, _ = time.ParseDuration(config.DefaultGddGraceTimeout)
-		}
-		logger.Info().Msgf("Http server is gracefully shutting down in %s", grace)
-
-		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		ctx, cancel := register.ShutdownContext()
 		defer cancel()
-		// Doesn't block if no connections, but will otherwise wait
-		// until the timeout deadline.
-		httpServer.Shutdown(ctx)
+		logger.Info().Msg("[go-doudou] shutdown: leaving service registries")
+		if err := timeutils.CallWithCtx(ctx, func() struct{} {
+			register.ShutdownRest()
+			return struct{}{}
+		}); err != nil {
+			logger.Error().Err(err).Msg("[go-doudou] shutdown: leaving service registries timed out")
+		}
+		register.WaitLeavePropagation(ctx)
+		rest.GracefulShutdown(ctx, httpServer, tracker)
 	}()
 
 	c := make(chan os.Signal, 1)
-	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-	signal.Notify(c, os.Interrupt)
+	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C) or SIGTERM (e.g. sent by kubernetes).
+	// SIGKILL or SIGQUIT (Ctrl+/) will not be caught.
+	// SIGHUP reloads hot-reloadable config without restarting.
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Block until we receive our signal.
-	<-c
+	for sig := range c {
+		if sig == syscall.SIGHUP {
+			rest.ReloadConfig()
+			continue
+		}
+		break
+	}
 }
@@ -1,7 +1,16 @@
 package gorilla
 
 import (
+	"context"
+	"crypto/tls"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"golang.org/x/net/http2"
+	"io"
+	"net"
+	"net/http"
+	"os"
 	"testing"
 )
 
@@ -21,3 +30,38 @@ func TestDefaultHttpSrv_printRoutes(t *testing.T) {
 	}...)
 	srv.printRoutes()
 }
+
+func TestRestServer_H2C(t *testing.T) {
+	config.GddEnableH2C.Write("true")
+	defer os.Unsetenv(string(config.GddEnableH2C))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := NewRestServer()
+	srv.rootRouter.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	httpServer, _ := srv.newHttpServer(ln)
+	defer httpServer.Close()
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	url := "http://" + ln.Addr().String() + "/hello"
+	resp, err := client.Get(url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, "HTTP/2.0", string(body))
+
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, "HTTP/1.1", string(body))
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"github.com/klauspost/compress/gzhttp"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// parseContentTypes parses comma separated content types, each must be a valid media type like application/x-ndjson
+func parseContentTypes(value string) ([]string, error) {
+	var contentTypes []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if stringutils.IsEmpty(item) {
+			continue
+		}
+		mediaType, _, err := mime.ParseMediaType(item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid content type %q", item)
+		}
+		if !strings.Contains(mediaType, "/") {
+			return nil, errors.Errorf("invalid content type %q", item)
+		}
+		contentTypes = append(contentTypes, item)
+	}
+	return contentTypes, nil
+}
+
+// GzipFromConfig returns response gzip middleware compressing responses whose content type is in GddGzipContentTypes
+// if set, otherwise in the built-in list, and whose size is not less than GddGzipMinLength.
+// It returns error if any content type is malformed.
+func GzipFromConfig() (func(inner http.Handler) http.Handler, error) {
+	contentTypes := contentTypeShouldbeGzip
+	if value := config.GddGzipContentTypes.LoadOrDefault(config.DefaultGddGzipContentTypes); stringutils.IsNotEmpty(strings.TrimSpace(value)) {
+		parsed, err := parseContentTypes(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", string(config.GddGzipContentTypes))
+		}
+		contentTypes = parsed
+	}
+	minLength := cast.ToIntOrDefault(config.GddGzipMinLength.Load(), config.DefaultGddGzipMinLength)
+	if minLength < 0 {
+		minLength = config.DefaultGddGzipMinLength
+	}
+	wrapper, err := gzhttp.NewWrapper(gzhttp.ContentTypes(contentTypes), gzhttp.MinSize(minLength))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	logger.Info().Msgf("[go-doudou] gzip responses of content types %s not less than %d bytes", strings.Join(contentTypes, ","), minLength)
+	return func(inner http.Handler) http.Handler {
+		return wrapper(inner)
+	}, nil
+}
@@ -0,0 +1,59 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func gzipResponse(t *testing.T, contentType string, size int) string {
+	mw, err := rest.GzipFromConfig()
+	require.NoError(t, err)
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(strings.Repeat("a", size)))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr.Header().Get("Content-Encoding")
+}
+
+func TestGzipFromConfig(t *testing.T) {
+	defer os.Unsetenv(string(config.GddGzipContentTypes))
+	defer os.Unsetenv(string(config.GddGzipMinLength))
+
+	t.Run("default", func(t *testing.T) {
+		require.Equal(t, "gzip", gzipResponse(t, "application/json", 2048))
+		require.Empty(t, gzipResponse(t, "application/json", 100))
+		require.Empty(t, gzipResponse(t, "application/x-ndjson", 2048))
+	})
+
+	t.Run("content types", func(t *testing.T) {
+		config.GddGzipContentTypes.Write("application/x-ndjson, application/vnd.example+json")
+		defer os.Unsetenv(string(config.GddGzipContentTypes))
+		require.Equal(t, "gzip", gzipResponse(t, "application/x-ndjson", 2048))
+		require.Equal(t, "gzip", gzipResponse(t, "application/vnd.example+json; charset=utf-8", 2048))
+		require.Empty(t, gzipResponse(t, "application/json", 2048))
+	})
+
+	t.Run("min length", func(t *testing.T) {
+		config.GddGzipMinLength.Write("64")
+		defer os.Unsetenv(string(config.GddGzipMinLength))
+		require.Equal(t, "gzip", gzipResponse(t, "application/json", 100))
+		require.Empty(t, gzipResponse(t, "application/json", 32))
+	})
+
+	t.Run("invalid content type", func(t *testing.T) {
+		config.GddGzipContentTypes.Write("application/json,ndjson")
+		defer os.Unsetenv(string(config.GddGzipContentTypes))
+		_, err := rest.GzipFromConfig()
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net/http"
+)
+
+// h2cMaxConcurrentStreams limits concurrent streams of each h2c connection, the same as default of http2.Server
+const h2cMaxConcurrentStreams = 250
+
+func h2cEnabled() bool {
+	return cast.ToBoolOrDefault(config.GddEnableH2C.Load(), config.DefaultGddEnableH2C)
+}
+
+// EnableH2C wraps handler of httpServer to serve HTTP/2 over cleartext if GddEnableH2C is true and https is not enabled.
+// HTTP/2 connections are registered to httpServer, so that they are closed gracefully by httpServer.Shutdown too.
+// It must be called before httpServer starts serving.
+func EnableH2C(httpServer *http.Server) error {
+	if !h2cEnabled() {
+		return nil
+	}
+	if TLSEnabled() {
+		logger.Warn().Msgf("[go-doudou] %s is ignored as https is enabled", string(config.GddEnableH2C))
+		return nil
+	}
+	h2s := &http2.Server{
+		MaxConcurrentStreams: h2cMaxConcurrentStreams,
+		IdleTimeout:          httpServer.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(httpServer, h2s); err != nil {
+		return err
+	}
+	httpServer.Handler = h2c.NewHandler(httpServer.Handler, h2s)
+	return nil
+}
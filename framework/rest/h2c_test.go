@@ -0,0 +1,51 @@
+package rest_test
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRestServer_H2C(t *testing.T) {
+	config.GddPort.Write("6073")
+	config.GddEnableH2C.Write("true")
+	defer os.Unsetenv(string(config.GddEnableH2C))
+	go func() {
+		srv := rest.NewRestServer()
+		srv.AddRoute(Routes(NewMocksvcHandler())...)
+		srv.Run()
+	}()
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		var err error
+		resp, err = client.Get("http://localhost:6073/user")
+		return err == nil
+	}, 5*time.Second, 20*time.Millisecond)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 2, resp.ProtoMajor)
+
+	resp, err := http.Get("http://localhost:6073/user")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 1, resp.ProtoMajor)
+}
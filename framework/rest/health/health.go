@@ -0,0 +1,208 @@
+// Package health provides a health check endpoint reporting status of dependencies like database, redis and
+// elasticsearch by registered HealthChecker, together with gossip status of local memberlist node.
+//
+// Mount it by RestServer.AddRoute(health.Routes()...), it works no matter GddManage is enabled or not.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	registry "github.com/unionj-cloud/go-doudou/v2/framework/registry/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// StatusUp means the dependency is healthy
+	StatusUp = "up"
+	// StatusDown means the dependency is unhealthy
+	StatusDown = "down"
+	// StatusSuspect means local memberlist node is suspected failed by other members
+	StatusSuspect = "suspect"
+)
+
+// HealthChecker checks health of a dependency
+type HealthChecker interface {
+	// Name identifies the dependency in response body
+	Name() string
+	// Check returns nil if the dependency is healthy. ctx is cancelled after GddHealthCheckTimeout.
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string {
+	return c.name
+}
+
+func (c checkerFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// NewChecker creates a HealthChecker named name from function check, e.g. health.NewChecker("mysql", db.PingContext)
+func NewChecker(name string, check func(ctx context.Context) error) HealthChecker {
+	return checkerFunc{name: name, check: check}
+}
+
+var (
+	checkersMu sync.RWMutex
+	checkers   []HealthChecker
+)
+
+// Register adds checkers to be run by health check endpoint
+func Register(checker ...HealthChecker) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+	checkers = append(checkers, checker...)
+}
+
+// errSuspect is returned by gossip checker if local node is suspected failed
+var errSuspect = errors.New("local node is suspected failed by other members")
+
+type gossipChecker struct{}
+
+func (gossipChecker) Name() string {
+	return "memberlist"
+}
+
+func (gossipChecker) Check(_ context.Context) error {
+	state, err := registry.LocalNodeState()
+	if err != nil {
+		return err
+	}
+	switch state {
+	case memberlist.StateAlive:
+		return nil
+	case memberlist.StateSuspect:
+		return errSuspect
+	default:
+		return errors.Errorf("local node is in state %d", state)
+	}
+}
+
+// builtinCheckers returns gossip checker if memberlist is one of service discovery modes
+func builtinCheckers() []HealthChecker {
+	if _, ok := config.ServiceDiscoveryMap()[constants.SD_MEMBERLIST]; ok {
+		return []HealthChecker{gossipChecker{}}
+	}
+	return nil
+}
+
+// CheckResult is health status of a dependency
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is response body of health check endpoint. Status is up only if all checks are up.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+func checkTimeout() time.Duration {
+	timeout, err := time.ParseDuration(config.GddHealthCheckTimeout.LoadOrDefault(config.DefaultGddHealthCheckTimeout))
+	if err != nil || timeout <= 0 {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed, use default %s instead.", string(config.GddHealthCheckTimeout),
+			config.GddHealthCheckTimeout.Load(), config.DefaultGddHealthCheckTimeout)
+		timeout, _ = time.ParseDuration(config.DefaultGddHealthCheckTimeout)
+	}
+	return timeout
+}
+
+func runCheck(ctx context.Context, checker HealthChecker, timeout time.Duration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.Wrap(ctx.Err(), "health check timeout")
+	}
+	result := CheckResult{
+		Name:     checker.Name(),
+		Status:   StatusUp,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+		if errors.Is(err, errSuspect) {
+			result.Status = StatusSuspect
+		}
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Check runs built-in and registered checkers concurrently, each within GddHealthCheckTimeout
+func Check(ctx context.Context) Report {
+	checkersMu.RLock()
+	all := append(builtinCheckers(), checkers...)
+	checkersMu.RUnlock()
+	timeout := checkTimeout()
+	report := Report{
+		Status: StatusUp,
+		Checks: make([]CheckResult, len(all)),
+	}
+	var wg sync.WaitGroup
+	for i, checker := range all {
+		wg.Add(1)
+		go func(i int, checker HealthChecker) {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, checker, timeout)
+		}(i, checker)
+	}
+	wg.Wait()
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+			break
+		}
+	}
+	return report
+}
+
+// Routes returns health check endpoint /go-doudou/health, which responds 200 if all checks are up, otherwise 503,
+// with Report as json body
+func Routes() []rest.Route {
+	return []rest.Route{
+		{
+			Name:    "GetHealth",
+			Method:  http.MethodGet,
+			Pattern: "/go-doudou/health",
+			HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
+				report := Check(request.Context())
+				writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				if report.Status != StatusUp {
+					writer.WriteHeader(http.StatusServiceUnavailable)
+				}
+				if err := json.NewEncoder(writer).Encode(report); err != nil {
+					logger.Error().Err(err).Msg("[go-doudou] failed to write health report")
+				}
+			},
+		},
+	}
+}
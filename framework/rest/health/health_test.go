@@ -0,0 +1,69 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest/health"
+)
+
+func serve(t *testing.T) (int, health.Report) {
+	rr := httptest.NewRecorder()
+	health.Routes()[0].HandlerFunc(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/health", nil))
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	return rr.Code, report
+}
+
+func TestRoutes(t *testing.T) {
+	config.GddHealthCheckTimeout.Write("100ms")
+	defer os.Unsetenv(string(config.GddHealthCheckTimeout))
+
+	health.Register(health.NewChecker("mysql", func(ctx context.Context) error {
+		return nil
+	}))
+	code, report := serve(t)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, health.StatusUp, report.Status)
+	require.Len(t, report.Checks, 1)
+	require.Equal(t, "mysql", report.Checks[0].Name)
+	require.Equal(t, health.StatusUp, report.Checks[0].Status)
+
+	health.Register(health.NewChecker("redis", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}), health.NewChecker("es", func(ctx context.Context) error {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+		return nil
+	}))
+	start := time.Now()
+	code, report = serve(t)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, health.StatusDown, report.Status)
+	require.Len(t, report.Checks, 3)
+	require.Equal(t, health.StatusUp, report.Checks[0].Status)
+	require.Equal(t, health.StatusDown, report.Checks[1].Status)
+	require.Equal(t, "connection refused", report.Checks[1].Error)
+	require.Equal(t, health.StatusDown, report.Checks[2].Status)
+	require.Contains(t, report.Checks[2].Error, "timeout")
+}
+
+func TestCheck_Memberlist(t *testing.T) {
+	config.GddServiceDiscoveryMode.Write("memberlist")
+	defer os.Unsetenv(string(config.GddServiceDiscoveryMode))
+	report := health.Check(context.Background())
+	require.Equal(t, health.StatusDown, report.Status)
+	require.Equal(t, "memberlist", report.Checks[0].Name)
+	require.Equal(t, health.StatusDown, report.Checks[0].Status)
+}
@@ -0,0 +1,143 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTP3Server is the subset of methods of *http3.Server from github.com/quic-go/quic-go/http3 used by go-doudou.
+// HTTP/3 support is experimental.
+type HTTP3Server interface {
+	// ListenAndServe listens on udp address and serves HTTP/3 requests
+	ListenAndServe() error
+	// SetQUICHeaders adds Alt-Svc header advertising HTTP/3 to hdr
+	SetQUICHeaders(hdr http.Header) error
+	// Close closes the server immediately
+	Close() error
+}
+
+// HTTP3ServerFactory creates HTTP3Server listening on udp addr, serving handler with tlsConfig. go-doudou doesn't
+// depend on quic-go, so applications enabling HTTP/3 register a factory by RegisterHTTP3ServerFactory, e.g.
+//
+//	rest.RegisterHTTP3ServerFactory(func(addr string, tlsConfig *tls.Config, handler http.Handler) rest.HTTP3Server {
+//		return &http3.Server{
+//			Addr:      addr,
+//			TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+//			Handler:   handler,
+//		}
+//	})
+type HTTP3ServerFactory func(addr string, tlsConfig *tls.Config, handler http.Handler) HTTP3Server
+
+var http3Lock sync.Mutex
+var http3Factory HTTP3ServerFactory
+
+// RegisterHTTP3ServerFactory registers factory used to create HTTP/3 server if GddHttp3Enable is true.
+// It should be called before RestServer runs.
+func RegisterHTTP3ServerFactory(factory HTTP3ServerFactory) {
+	http3Lock.Lock()
+	defer http3Lock.Unlock()
+	http3Factory = factory
+}
+
+func getHTTP3ServerFactory() HTTP3ServerFactory {
+	http3Lock.Lock()
+	defer http3Lock.Unlock()
+	return http3Factory
+}
+
+func http3Enabled() bool {
+	return cast.ToBoolOrDefault(config.GddHttp3Enable.Load(), config.DefaultGddHttp3Enable)
+}
+
+// NewHTTP3Server creates HTTP3Server listening on the same port as httpServer but over udp, serving the same handler,
+// and wraps handler of httpServer to advertise HTTP/3 by Alt-Svc header. It returns nil if GddHttp3Enable is not true.
+// TLS is required by HTTP/3, so it returns error if GddTLSCert or GddTLSKey is not set, or no HTTP3ServerFactory registered.
+// It must be called before httpServer starts serving.
+func NewHTTP3Server(httpServer *http.Server) (HTTP3Server, error) {
+	if !http3Enabled() {
+		return nil, nil
+	}
+	if !TLSEnabled() {
+		return nil, errors.New("[go-doudou] HTTP/3 requires TLS, please set GDD_TLS_CERT and GDD_TLS_KEY")
+	}
+	factory := getHTTP3ServerFactory()
+	if factory == nil {
+		return nil, errors.New("[go-doudou] no HTTP/3 server factory registered, please call rest.RegisterHTTP3ServerFactory")
+	}
+	cert, err := tls.LoadX509KeyPair(config.GddTLSCert.LoadOrDefault(config.DefaultGddTLSCert),
+		config.GddTLSKey.LoadOrDefault(config.DefaultGddTLSKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "[go-doudou] failed to load TLS certificate for HTTP/3")
+	}
+	tlsConfig := &tls.Config{}
+	if httpServer.TLSConfig != nil {
+		tlsConfig = httpServer.TLSConfig.Clone()
+	}
+	tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	h3 := factory(httpServer.Addr, tlsConfig, httpServer.Handler)
+	httpServer.Handler = AltSvc(h3)(httpServer.Handler)
+	return h3, nil
+}
+
+// AltSvc advertises HTTP/3 by adding Alt-Svc header to responses of HTTP/1.1 and HTTP/2 requests
+func AltSvc(h3 HTTP3Server) func(inner http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor < 3 {
+				if err := h3.SetQUICHeaders(w.Header()); err != nil {
+					logger.Debug().Err(err).Msg("[go-doudou] failed to set Alt-Svc header")
+				}
+			}
+			inner.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ServeHTTP3 runs h3 in a new goroutine
+func ServeHTTP3(h3 HTTP3Server) {
+	go func() {
+		logger.Info().Msg("[go-doudou] experimental HTTP/3 server is listening")
+		if err := h3.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error().Err(err).Msg("[go-doudou] HTTP/3 server stopped")
+		}
+	}()
+}
+
+// GracefulShutdownHTTP3 shuts down h3 in a new goroutine and returns a channel closed once it is done.
+// If h3 supports graceful shutdown by a Shutdown(ctx context.Context) error method like *http3.Server of
+// recent quic-go versions, it is given GddGraceTimeout to finish in-flight requests, otherwise it is closed immediately.
+// It is closed once ctx is done too. h3 can be nil.
+func GracefulShutdownHTTP3(ctx context.Context, h3 HTTP3Server) <-chan struct{} {
+	done := make(chan struct{})
+	if h3 == nil {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		if s, ok := h3.(interface {
+			Shutdown(ctx context.Context) error
+		}); ok {
+			grace, err := time.ParseDuration(config.GddGraceTimeout.Load())
+			if err != nil {
+				grace, _ = time.ParseDuration(config.DefaultGddGraceTimeout)
+			}
+			graceCtx, cancel := context.WithTimeout(ctx, grace)
+			defer cancel()
+			if err = s.Shutdown(graceCtx); err != nil {
+				logger.Debug().Err(err).Msg("[go-doudou] HTTP/3 server graceful shutdown failed")
+			}
+		}
+		if err := h3.Close(); err != nil {
+			logger.Debug().Err(err).Msg("[go-doudou] failed to close HTTP/3 server")
+		}
+	}()
+	return done
+}
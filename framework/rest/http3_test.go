@@ -0,0 +1,130 @@
+package rest_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeHTTP3Server struct {
+	addr      string
+	tlsConfig *tls.Config
+	handler   http.Handler
+	shutdown  bool
+	closed    bool
+}
+
+func (f *fakeHTTP3Server) ListenAndServe() error {
+	return nil
+}
+
+func (f *fakeHTTP3Server) SetQUICHeaders(hdr http.Header) error {
+	hdr.Add("Alt-Svc", `h3=":6060"; ma=2592000`)
+	return nil
+}
+
+func (f *fakeHTTP3Server) Shutdown(ctx context.Context) error {
+	f.shutdown = true
+	return nil
+}
+
+func (f *fakeHTTP3Server) Close() error {
+	f.closed = true
+	return nil
+}
+
+func writeSelfSignedCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestNewHTTP3Server(t *testing.T) {
+	httpServer := &http.Server{
+		Addr: ":6060",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		}),
+	}
+	h3, err := rest.NewHTTP3Server(httpServer)
+	require.NoError(t, err)
+	require.Nil(t, h3)
+
+	config.GddHttp3Enable.Write("true")
+	defer os.Unsetenv(string(config.GddHttp3Enable))
+	_, err = rest.NewHTTP3Server(httpServer)
+	require.Error(t, err)
+
+	certFile, keyFile := writeSelfSignedCert(t)
+	config.GddTLSCert.Write(certFile)
+	config.GddTLSKey.Write(keyFile)
+	defer func() {
+		os.Unsetenv(string(config.GddTLSCert))
+		os.Unsetenv(string(config.GddTLSKey))
+	}()
+	_, err = rest.NewHTTP3Server(httpServer)
+	require.Error(t, err)
+
+	var fake *fakeHTTP3Server
+	rest.RegisterHTTP3ServerFactory(func(addr string, tlsConfig *tls.Config, handler http.Handler) rest.HTTP3Server {
+		fake = &fakeHTTP3Server{
+			addr:      addr,
+			tlsConfig: tlsConfig,
+			handler:   handler,
+		}
+		return fake
+	})
+	defer rest.RegisterHTTP3ServerFactory(nil)
+	h3, err = rest.NewHTTP3Server(httpServer)
+	require.NoError(t, err)
+	require.Equal(t, fake, h3)
+	require.Equal(t, ":6060", fake.addr)
+	require.Len(t, fake.tlsConfig.Certificates, 1)
+
+	rr := httptest.NewRecorder()
+	httpServer.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, `h3=":6060"; ma=2592000`, rr.Header().Get("Alt-Svc"))
+	require.Equal(t, "OK", rr.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.ProtoMajor = 3
+	rr = httptest.NewRecorder()
+	fake.handler.ServeHTTP(rr, req)
+	require.Empty(t, rr.Header().Get("Alt-Svc"))
+
+	<-rest.GracefulShutdownHTTP3(context.Background(), h3)
+	require.True(t, fake.shutdown)
+	require.True(t, fake.closed)
+	<-rest.GracefulShutdownHTTP3(context.Background(), nil)
+}
@@ -0,0 +1,240 @@
+package rest
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"golang.org/x/sync/singleflight"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrMissingToken is returned by JWTAuthenticator if request has no bearer token
+	ErrMissingToken = errors.New("missing bearer token")
+	// ErrUnknownKey is returned by JWTAuthenticator if no key in JWKS matches kid of token
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrMissingExp is returned by JWTAuthenticator requiring exp claim if token has no exp claim
+	ErrMissingExp = errors.New("missing exp claim")
+)
+
+const (
+	// jwksRefreshInterval is min interval of refetching JWKS for tokens signed by unknown key
+	jwksRefreshInterval = time.Minute
+	// jwksTimeout is timeout of fetching JWKS
+	jwksTimeout = 10 * time.Second
+)
+
+// JWTAuthenticator authenticates requests by bearer token in Authorization header, whose signature is verified either
+// by a HMAC signing key or by RSA public keys from a JWKS endpoint. Claims of valid token are returned as principal
+// in type jwt.MapClaims. Expiration, not before and issued at claims are validated if present, tokens without
+// expiration claim can be rejected by WithJWTRequireExp.
+type JWTAuthenticator struct {
+	signingKey []byte
+	jwksURL    string
+	client     *http.Client
+	requireExp bool
+
+	lock      sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+	fetch     singleflight.Group
+}
+
+// JWTOption configures JWTAuthenticator
+type JWTOption func(*JWTAuthenticator)
+
+// WithJWTSigningKey verifies tokens signed by HS256, HS384 or HS512 with key
+func WithJWTSigningKey(key []byte) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.signingKey = key
+	}
+}
+
+// WithJWKSURL verifies tokens signed by RS256, RS384 or RS512 with RSA public keys served by JWKS endpoint url.
+// Keys are fetched on first use, and fetched again for tokens signed by unknown key at most once per minute.
+func WithJWKSURL(url string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.jwksURL = url
+	}
+}
+
+// WithJWKSClient replaces http client fetching JWKS
+func WithJWKSClient(client *http.Client) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.client = client
+	}
+}
+
+// WithJWTRequireExp rejects tokens without exp claim, which would be valid forever otherwise
+func WithJWTRequireExp(require bool) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.requireExp = require
+	}
+}
+
+// NewJWTAuthenticator creates a JWTAuthenticator. Either WithJWTSigningKey or WithJWKSURL must be given.
+func NewJWTAuthenticator(opts ...JWTOption) (*JWTAuthenticator, error) {
+	a := &JWTAuthenticator{
+		client: &http.Client{Timeout: jwksTimeout},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if len(a.signingKey) == 0 && stringutils.IsEmpty(a.jwksURL) {
+		return nil, errors.New("either signing key or jwks url is required")
+	}
+	return a, nil
+}
+
+// JWTAuthenticatorFromConfig creates a JWTAuthenticator from GddJwtSigningKey or GddJwtJwksURL, and GddJwtRequireExp.
+// It returns error if neither GddJwtSigningKey nor GddJwtJwksURL is set.
+func JWTAuthenticatorFromConfig() (*JWTAuthenticator, error) {
+	requireExp := WithJWTRequireExp(cast.ToBoolOrDefault(config.GddJwtRequireExp.Load(), config.DefaultGddJwtRequireExp))
+	if key := config.GddJwtSigningKey.LoadOrDefault(config.DefaultGddJwtSigningKey); stringutils.IsNotEmpty(key) {
+		return NewJWTAuthenticator(WithJWTSigningKey([]byte(key)), requireExp)
+	}
+	if url := config.GddJwtJwksURL.LoadOrDefault(config.DefaultGddJwtJwksURL); stringutils.IsNotEmpty(url) {
+		return NewJWTAuthenticator(WithJWKSURL(url), requireExp)
+	}
+	return nil, errors.Errorf("either %s or %s is required", string(config.GddJwtSigningKey), string(config.GddJwtJwksURL))
+}
+
+// bearerToken returns token from Authorization header in form of Bearer <token>
+func bearerToken(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return ""
+}
+
+// Authenticate implements Authenticator
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (interface{}, error) {
+	tokenString := bearerToken(r)
+	if stringutils.IsEmpty(tokenString) {
+		return nil, ErrMissingToken
+	}
+	parser := &jwt.Parser{
+		ValidMethods: []string{"RS256", "RS384", "RS512"},
+	}
+	if len(a.signingKey) > 0 {
+		parser.ValidMethods = []string{"HS256", "HS384", "HS512"}
+	}
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(tokenString, claims, a.keyFunc); err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+		return nil, errors.Wrap(err, "invalid token")
+	}
+	if _, ok := claims["exp"]; a.requireExp && !ok {
+		return nil, ErrMissingExp
+	}
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	if len(a.signingKey) > 0 {
+		return a.signingKey, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	return a.publicKey(kid)
+}
+
+// publicKey returns RSA public key of kid from JWKS, refetching JWKS if kid is unknown. Token without kid can be
+// verified only if JWKS has a single key. JWKS is fetched without holding the lock, so that requests with known kid
+// are not blocked, and concurrent requests with unknown kid share a single fetch.
+func (a *JWTAuthenticator) publicKey(kid string) (*rsa.PublicKey, error) {
+	a.lock.Lock()
+	key, ok := a.lookup(kid)
+	recent := !a.fetchedAt.IsZero() && time.Since(a.fetchedAt) < jwksRefreshInterval
+	a.lock.Unlock()
+	if ok {
+		return key, nil
+	}
+	if recent {
+		return nil, ErrUnknownKey
+	}
+	if _, err, _ := a.fetch.Do(a.jwksURL, func() (interface{}, error) {
+		keys, err := a.fetchKeys()
+		a.lock.Lock()
+		defer a.lock.Unlock()
+		a.fetchedAt = time.Now()
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+		return nil, nil
+	}); err != nil {
+		return nil, err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if key, ok = a.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (a *JWTAuthenticator) lookup(kid string) (*rsa.PublicKey, bool) {
+	if stringutils.IsEmpty(kid) && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, true
+		}
+	}
+	key, ok := a.keys[kid]
+	return key, ok
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (a *JWTAuthenticator) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	resp, err := a.client.Get(a.jwksURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch jwks failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetch jwks failed: status code %d", resp.StatusCode)
+	}
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, errors.Wrap(err, "decode jwks failed")
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, item := range jwks.Keys {
+		if item.Kty != "RSA" || (stringutils.IsNotEmpty(item.Use) && item.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(item.N)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode modulus of key %s failed", item.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(item.E)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode exponent of key %s failed", item.Kid)
+		}
+		keys[item.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/ip"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net"
+	"strconv"
+)
+
+func httpHost() string {
+	if stringutils.IsNotEmpty(config.GddHost.Load()) {
+		return config.GddHost.Load()
+	}
+	return config.DefaultGddHost
+}
+
+// Listen binds the address of http server configured by GddHost and GddPort. If GddPort is 0, or the port is
+// already in use and GddBindRetry is true, it binds a free port picked by kernel instead. The actual port is written
+// back to GddPort, so Listen must be called before registering the service to service registry.
+func Listen() (net.Listener, error) {
+	host := httpHost()
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.FormatUint(config.GetPort(), 10)))
+	if err != nil && ip.IsAddrInUse(err) && cast.ToBoolOrDefault(config.GddBindRetry.Load(), config.DefaultGddBindRetry) {
+		logger.Warn().Err(err).Msg("Http port is in use, retry with a free port")
+		ln, err = net.Listen("tcp", net.JoinHostPort(host, "0"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if port := uint64(ln.Addr().(*net.TCPAddr).Port); port != config.GetPort() {
+		_ = config.GddPort.Write(strconv.FormatUint(port, 10))
+		logger.Info().Msgf("Http server bound to free port %d", port)
+	}
+	return ln, nil
+}
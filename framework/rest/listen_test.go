@@ -0,0 +1,53 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestListen_BindRetry(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupied.Close()
+	port := occupied.Addr().(*net.TCPAddr).Port
+
+	config.GddHost.Write("127.0.0.1")
+	config.GddPort.Write(strconv.Itoa(port))
+	defer func() {
+		config.GddHost.Write(config.DefaultGddHost)
+		config.GddPort.Write(strconv.Itoa(config.DefaultGddPort))
+		config.GddBindRetry.Write("false")
+	}()
+
+	_, err = rest.Listen()
+	require.Error(t, err)
+
+	config.GddBindRetry.Write("true")
+	ln, err := rest.Listen()
+	require.NoError(t, err)
+	defer ln.Close()
+	actual := ln.Addr().(*net.TCPAddr).Port
+	require.NotEqual(t, port, actual)
+	require.Equal(t, uint64(actual), config.GetPort())
+}
+
+func TestListen_FreePort(t *testing.T) {
+	config.GddHost.Write("127.0.0.1")
+	config.GddPort.Write("0")
+	defer func() {
+		config.GddHost.Write(config.DefaultGddHost)
+		config.GddPort.Write(strconv.Itoa(config.DefaultGddPort))
+	}()
+
+	ln, err := rest.Listen()
+	require.NoError(t, err)
+	defer ln.Close()
+	actual := ln.Addr().(*net.TCPAddr).Port
+	require.NotZero(t, actual)
+	require.Equal(t, uint64(actual), config.GetPort())
+	require.Equal(t, strconv.Itoa(actual), config.GddPort.Load())
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"strings"
+)
+
+// LogLevelRoutes returns routes for listing and adjusting runtime log levels. PUT /go-doudou/loglevel accepts subsystem
+// and level either as form values or as json body like {"level":"debug"}, subsystem defaults to application logs.
+var LogLevelRoutes = logLevelRoutes
+
+// LogSubsystemApp is name of application logs in log level management endpoint, which is the global logger
+const LogSubsystemApp = "app"
+
+// accessLogger logs http access logs, whose level is set by GddLogAccessLevel
+var accessLogger = logger.NewSubsystem(config.LogSubsystemAccess)
+
+// LogLevel is level of a log subsystem
+type LogLevel struct {
+	Subsystem string `json:"subsystem"`
+	Level     string `json:"level"`
+	// Inherited is true if the subsystem follows level of application logs
+	Inherited bool `json:"inherited"`
+}
+
+func logLevels() []LogLevel {
+	levels := []LogLevel{
+		{
+			Subsystem: LogSubsystemApp,
+			Level:     logger.GetLevel().String(),
+		},
+	}
+	for _, item := range logger.Subsystems() {
+		level, ok := item.Level()
+		levels = append(levels, LogLevel{
+			Subsystem: item.Name(),
+			Level:     level.String(),
+			Inherited: !ok,
+		})
+	}
+	return levels
+}
+
+func logLevelRoutes() []Route {
+	return []Route{
+		{
+			Name:    "GetLogLevel",
+			Method:  http.MethodGet,
+			Pattern: "/go-doudou/loglevel",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				_writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				json.NewEncoder(_writer).Encode(logLevels())
+			},
+		},
+		{
+			Name:    "PutLogLevel",
+			Method:  http.MethodPut,
+			Pattern: "/go-doudou/loglevel",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				name := _req.FormValue("subsystem")
+				levelStr := _req.FormValue("level")
+				if strings.HasPrefix(_req.Header.Get("Content-Type"), "application/json") {
+					var body LogLevel
+					if err := json.NewDecoder(_req.Body).Decode(&body); err != nil {
+						writeErrorResponse(_writer, http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err))
+						return
+					}
+					name, levelStr = body.Subsystem, body.Level
+				}
+				if name == "" {
+					name = LogSubsystemApp
+				}
+				levelStr = strings.ToLower(levelStr)
+				if name == LogSubsystemApp {
+					if err := logger.SetLevel(levelStr); err != nil {
+						writeErrorResponse(_writer, http.StatusBadRequest, err.Error())
+						return
+					}
+				} else {
+					subsystem, ok := logger.GetSubsystem(name)
+					if !ok {
+						writeErrorResponse(_writer, http.StatusNotFound, fmt.Sprintf("log subsystem %s not found", name))
+						return
+					}
+					if levelStr == "" || levelStr == "inherit" {
+						subsystem.ResetLevel()
+					} else {
+						level, err := zerolog.ParseLevel(levelStr)
+						if err != nil {
+							writeErrorResponse(_writer, http.StatusBadRequest, fmt.Sprintf("invalid log level %q", levelStr))
+							return
+						}
+						subsystem.SetLevel(level)
+					}
+				}
+				logger.Info().Msgf("[go-doudou] log level of %s is set to %q", name, levelStr)
+				_writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				json.NewEncoder(_writer).Encode(logLevels())
+			},
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLogLevelRoutes(t *testing.T) {
+	routes := rest.LogLevelRoutes()
+	get, put := routes[0], routes[1]
+
+	rr := httptest.NewRecorder()
+	put.HandlerFunc(rr, httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel?subsystem=memberlist&level=debug", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	subsystem, ok := logger.GetSubsystem(config.LogSubsystemMemberlist)
+	require.True(t, ok)
+	defer subsystem.ResetLevel()
+	level, ok := subsystem.Level()
+	require.True(t, ok)
+	require.Equal(t, zerolog.DebugLevel, level)
+
+	rr = httptest.NewRecorder()
+	get.HandlerFunc(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/loglevel", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var levels []rest.LogLevel
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&levels))
+	require.Equal(t, rest.LogSubsystemApp, levels[0].Subsystem)
+	found := false
+	for _, item := range levels {
+		switch item.Subsystem {
+		case config.LogSubsystemMemberlist:
+			found = true
+			require.Equal(t, "debug", item.Level)
+			require.False(t, item.Inherited)
+		case config.LogSubsystemAccess:
+			require.True(t, item.Inherited)
+		}
+	}
+	require.True(t, found)
+
+	rr = httptest.NewRecorder()
+	put.HandlerFunc(rr, httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel?subsystem=memberlist&level=inherit", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	_, ok = subsystem.Level()
+	require.False(t, ok)
+
+	rr = httptest.NewRecorder()
+	put.HandlerFunc(rr, httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel?subsystem=unknown&level=debug", nil))
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	put.HandlerFunc(rr, httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel?subsystem=access&level=verbose", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestLogLevelRoutes_JSON(t *testing.T) {
+	origin := logger.GetLevel()
+	defer logger.SetLevel(origin.String())
+	require.NoError(t, logger.SetLevel("info"))
+	put := rest.LogLevelRoutes()[1]
+
+	req := httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	put.HandlerFunc(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, zerolog.DebugLevel, logger.GetLevel())
+
+	req = httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel", strings.NewReader(`{"level":"verbose"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	put.HandlerFunc(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, zerolog.DebugLevel, logger.GetLevel())
+
+	req = httptest.NewRequest(http.MethodPut, "/go-doudou/loglevel", strings.NewReader(`{"level":`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	put.HandlerFunc(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestSetLevel(t *testing.T) {
+	originLevel := logger.GetLevel()
+	origin := logger.Logger
+	defer func() {
+		logger.Logger = origin
+		logger.SetLevel(originLevel.String())
+	}()
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	// copies of the global Logger follow level too
+	copied := logger.Logger
+
+	require.NoError(t, logger.SetLevel(" WARN "))
+	require.Equal(t, zerolog.WarnLevel, logger.GetLevel())
+	require.Error(t, logger.SetLevel(""))
+	require.Error(t, logger.SetLevel("verbose"))
+	require.Equal(t, zerolog.WarnLevel, logger.GetLevel())
+	logger.Info().Msg("info from package")
+	copied.Info().Msg("info from copy")
+	copied.Warn().Msg("warn from copy")
+	require.NotContains(t, buf.String(), "info from")
+	require.Contains(t, buf.String(), "warn from copy")
+
+	// subsystem can log below level of application logs
+	subsystem := logger.NewSubsystem("test")
+	subsystem.SetLevel(zerolog.DebugLevel)
+	subsystem.Debug().Msg("debug from subsystem")
+	require.Contains(t, buf.String(), `{"level":"debug","time"`)
+	require.Contains(t, buf.String(), "debug from subsystem")
+
+	require.NoError(t, logger.SetLevel("debug"))
+	copied.Debug().Msg("debug from copy")
+	require.Contains(t, buf.String(), "debug from copy")
+}
+
+func TestSetLevel_Concurrent(t *testing.T) {
+	originLevel := logger.GetLevel()
+	origin := logger.Logger
+	defer func() {
+		logger.Logger = origin
+		logger.SetLevel(originLevel.String())
+	}()
+	logger.SetOutput(io.Discard)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Debug().Int("j", j).Msg("")
+			}
+		}()
+	}
+	for _, level := range []string{"info", "debug", "warn", "debug"} {
+		require.NoError(t, logger.SetLevel(level))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+)
+
+var MaintenanceRoutes = maintenanceRoutes
+
+// MaintenanceStatus describes whether the service is in maintenance mode
+type MaintenanceStatus struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message,omitempty"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+}
+
+var maintenanceState atomic.Value
+
+func init() {
+	maintenanceState.Store(MaintenanceStatus{})
+}
+
+// EnableMaintenance turns on maintenance mode. All business routes will respond 503 with message and
+// Retry-After header in seconds, while management routes keep working.
+// Empty message and non-positive retryAfter fall back to GddMaintenanceMessage and GddMaintenanceRetryAfter.
+func EnableMaintenance(message string, retryAfter time.Duration) {
+	if stringutils.IsEmpty(message) {
+		message = config.GddMaintenanceMessage.LoadOrDefault(config.DefaultGddMaintenanceMessage)
+	}
+	if retryAfter <= 0 {
+		var err error
+		if retryAfter, err = time.ParseDuration(config.GddMaintenanceRetryAfter.Load()); err != nil {
+			retryAfter, _ = time.ParseDuration(config.DefaultGddMaintenanceRetryAfter)
+		}
+	}
+	maintenanceState.Store(MaintenanceStatus{
+		Enabled:    true,
+		Message:    message,
+		RetryAfter: int(retryAfter.Seconds()),
+	})
+	logger.Warn().Msgf("Maintenance mode enabled: %s", message)
+}
+
+// DisableMaintenance turns off maintenance mode
+func DisableMaintenance() {
+	maintenanceState.Store(MaintenanceStatus{})
+	logger.Info().Msg("Maintenance mode disabled")
+}
+
+// GetMaintenanceStatus returns current maintenance mode status
+func GetMaintenanceStatus() MaintenanceStatus {
+	return maintenanceState.Load().(MaintenanceStatus)
+}
+
+// Maintenance rejects requests with 503 status code when maintenance mode is enabled
+func Maintenance(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := GetMaintenanceStatus()
+		if !status.Enabled {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		if status.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(status.RetryAfter))
+		}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}{
+			Code:    1,
+			Message: status.Message,
+		})
+	})
+}
+
+func maintenanceRoutes() []Route {
+	writeStatus := func(_writer http.ResponseWriter) {
+		_writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		json.NewEncoder(_writer).Encode(GetMaintenanceStatus())
+	}
+	return []Route{
+		{
+			Name:    "GetMaintenance",
+			Method:  http.MethodGet,
+			Pattern: "/go-doudou/maintenance",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				writeStatus(_writer)
+			},
+		},
+		{
+			Name:    "PutMaintenance",
+			Method:  http.MethodPut,
+			Pattern: "/go-doudou/maintenance",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				var retryAfter time.Duration
+				if ra := _req.FormValue("retryAfter"); stringutils.IsNotEmpty(ra) {
+					var err error
+					if retryAfter, err = time.ParseDuration(ra); err != nil {
+						http.Error(_writer, "invalid retryAfter: "+err.Error(), http.StatusBadRequest)
+						return
+					}
+				}
+				EnableMaintenance(_req.FormValue("message"), retryAfter)
+				writeStatus(_writer)
+			},
+		},
+		{
+			Name:    "DeleteMaintenance",
+			Method:  http.MethodDelete,
+			Pattern: "/go-doudou/maintenance",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				DisableMaintenance()
+				writeStatus(_writer)
+			},
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+func TestMaintenance(t *testing.T) {
+	defer rest.DisableMaintenance()
+	handler := rest.Maintenance(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rest.EnableMaintenance("upgrading database", 2*time.Minute)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "120", rr.Header().Get("Retry-After"))
+	require.Contains(t, rr.Body.String(), "upgrading database")
+
+	rest.DisableMaintenance()
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestMaintenanceRoutes(t *testing.T) {
+	defer rest.DisableMaintenance()
+	routes := make(map[string]rest.Route)
+	for _, item := range rest.MaintenanceRoutes() {
+		routes[item.Method] = item
+	}
+
+	rr := httptest.NewRecorder()
+	routes[http.MethodPut].HandlerFunc(rr, httptest.NewRequest(http.MethodPut, "/go-doudou/maintenance?retryAfter=30s", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	status := rest.GetMaintenanceStatus()
+	require.True(t, status.Enabled)
+	require.Equal(t, 30, status.RetryAfter)
+	require.NotEmpty(t, status.Message)
+
+	rr = httptest.NewRecorder()
+	routes[http.MethodPut].HandlerFunc(rr, httptest.NewRequest(http.MethodPut, "/go-doudou/maintenance?retryAfter=abc", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = httptest.NewRecorder()
+	routes[http.MethodDelete].HandlerFunc(rr, httptest.NewRequest(http.MethodDelete, "/go-doudou/maintenance", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.False(t, rest.GetMaintenanceStatus().Enabled)
+}
@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ManageRoutes is exported for gorilla RestServer
+var ManageRoutes = manageRoutes
+
+// ManageStatsvizEnabled reports whether statsviz routes should be mounted along with management routes
+func ManageStatsvizEnabled() bool {
+	return cast.ToBoolOrDefault(config.GddManageStatsviz.Load(), config.DefaultGddManageStatsviz)
+}
+
+// ManagePprofEnabled reports whether /debug/pprof routes should be mounted along with management routes
+func ManagePprofEnabled() bool {
+	return cast.ToBoolOrDefault(config.GddManagePprof.Load(), config.DefaultGddManagePprof)
+}
+
+// manageRoutes returns management routes with /go-doudou/ prefix mounted if GddManage is enabled. Online document,
+// config and profile routes can be turned off by GddManageDoc, GddManageConfigUI and GddManagePprof respectively.
+// Statsviz and /debug/pprof routes are not included as they are mounted by each server in its own way.
+func manageRoutes() []Route {
+	var routes []Route
+	if cast.ToBoolOrDefault(config.GddManageDoc.Load(), config.DefaultGddManageDoc) {
+		routes = append(routes, docRoutes()...)
+	}
+	routes = append(routes, promRoutes()...)
+	if cast.ToBoolOrDefault(config.GddManageConfigUI.Load(), config.DefaultGddManageConfigUI) {
+		routes = append(routes, configRoutes()...)
+	}
+	routes = append(routes, maintenanceRoutes()...)
+	if ManagePprofEnabled() {
+		routes = append(routes, profileRoutes()...)
+	}
+	routes = append(routes, cacheRoutes()...)
+	routes = append(routes, recentRequestsRoutes()...)
+	routes = append(routes, logLevelRoutes()...)
+	if _, ok := config.ServiceDiscoveryMap()[constants.SD_MEMBERLIST]; ok {
+		routes = append(routes, MemberlistUIRoutes()...)
+		routes = append(routes, PromSDRoutes()...)
+	}
+	return routes
+}
+
+// parseCIDRs parses comma separated CIDRs, single ip without prefix length is taken as a range of only itself
+func parseCIDRs(value string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if stringutils.IsEmpty(item) {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, errors.Errorf("invalid ip %q", item)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid cidr %q", item)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// AllowIPs returns a middleware responding 403 to requests whose client ip is not within any of cidrs.
+// Client ip is resolved by ClientIP, so forwarded headers are honored only from GddTrustedProxies.
+func AllowIPs(cidrs ...string) (func(inner http.Handler) http.Handler, error) {
+	nets, err := parseCIDRs(strings.Join(cidrs, ","))
+	if err != nil {
+		return nil, err
+	}
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := net.ParseIP(ClientIP(r)); ip != nil && containsIP(nets, ip) {
+				inner.ServeHTTP(w, r)
+				return
+			}
+			writeErrorResponse(w, http.StatusForbidden, "client ip is not allowed")
+		})
+	}, nil
+}
+
+// ManageAllowIPsFromConfig returns AllowIPs middleware from GddManageAllowedIPs for management routes,
+// or a no-op middleware if it is not set. It returns error if any cidr is malformed.
+func ManageAllowIPsFromConfig() (func(inner http.Handler) http.Handler, error) {
+	value := config.GddManageAllowedIPs.LoadOrDefault(config.DefaultGddManageAllowedIPs)
+	if stringutils.IsEmpty(strings.TrimSpace(value)) {
+		return func(inner http.Handler) http.Handler {
+			return inner
+		}, nil
+	}
+	allowIPs, err := AllowIPs(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", string(config.GddManageAllowedIPs))
+	}
+	return allowIPs, nil
+}
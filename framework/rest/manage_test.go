@@ -0,0 +1,120 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gorilla/handlers"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+func routeNames(routes []rest.Route) []string {
+	var names []string
+	for _, r := range routes {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestManageRoutes(t *testing.T) {
+	names := routeNames(rest.ManageRoutes())
+	require.Contains(t, names, "GetDoc")
+	require.Contains(t, names, "GetConfig")
+	require.Contains(t, names, "Prometheus")
+	require.True(t, rest.ManageStatsvizEnabled())
+	require.True(t, rest.ManagePprofEnabled())
+
+	config.GddManageDoc.Write("false")
+	config.GddManageConfigUI.Write("false")
+	config.GddManagePprof.Write("false")
+	config.GddManageStatsviz.Write("false")
+	defer func() {
+		os.Unsetenv(string(config.GddManageDoc))
+		os.Unsetenv(string(config.GddManageConfigUI))
+		os.Unsetenv(string(config.GddManagePprof))
+		os.Unsetenv(string(config.GddManageStatsviz))
+	}()
+	names = routeNames(rest.ManageRoutes())
+	require.NotContains(t, names, "GetDoc")
+	require.NotContains(t, names, "GetOpenAPI")
+	require.NotContains(t, names, "GetConfig")
+	require.NotContains(t, names, "GetProfile")
+	require.Contains(t, names, "Prometheus")
+	require.False(t, rest.ManageStatsvizEnabled())
+	require.False(t, rest.ManagePprofEnabled())
+}
+
+func TestManageAllowIPsFromConfig(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	serve := func(handler http.Handler, remoteAddr string, header map[string]string) int {
+		req := httptest.NewRequest(http.MethodGet, "/go-doudou/config", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	allowIPs, err := rest.ManageAllowIPsFromConfig()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, serve(allowIPs(ok), "8.8.8.8:1234", nil))
+
+	config.GddManageAllowedIPs.Write("10.0.0.0/8, 192.168.1.10,fd00::/8")
+	defer os.Unsetenv(string(config.GddManageAllowedIPs))
+	allowIPs, err = rest.ManageAllowIPsFromConfig()
+	require.NoError(t, err)
+	handler := allowIPs(ok)
+	require.Equal(t, http.StatusOK, serve(handler, "10.1.2.3:1234", nil))
+	require.Equal(t, http.StatusOK, serve(handler, "192.168.1.10:1234", nil))
+	require.Equal(t, http.StatusForbidden, serve(handler, "192.168.1.11:1234", nil))
+	require.Equal(t, http.StatusOK, serve(handler, "[fd12::1]:1234", nil))
+	require.Equal(t, http.StatusForbidden, serve(handler, "[2001:db8::1]:1234", nil))
+	require.Equal(t, http.StatusForbidden, serve(handler, "8.8.8.8:1234", nil))
+	// spoofed forwarded headers from untrusted peer are ignored
+	require.Equal(t, http.StatusForbidden, serve(handler, "8.8.8.8:1234", map[string]string{"X-Forwarded-For": "10.0.0.1"}))
+	require.Equal(t, http.StatusForbidden, serve(handler, "8.8.8.8:1234", map[string]string{"X-Real-IP": "fd00::2"}))
+	require.Equal(t, http.StatusForbidden, serve(handler, "8.8.8.8:1234", map[string]string{"Forwarded": "for=10.0.0.1"}))
+
+	// forwarded headers from trusted proxy are honored
+	config.GddTrustedProxies.Write("172.16.0.0/12")
+	defer os.Unsetenv(string(config.GddTrustedProxies))
+	require.Equal(t, http.StatusOK, serve(handler, "172.16.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.1"}))
+	require.Equal(t, http.StatusOK, serve(handler, "172.16.0.1:1234", map[string]string{"X-Real-IP": "fd00::2"}))
+	require.Equal(t, http.StatusForbidden, serve(handler, "172.16.0.1:1234", map[string]string{"X-Forwarded-For": "8.8.8.8"}))
+	require.Equal(t, http.StatusForbidden, serve(handler, "172.16.0.1:1234", nil))
+
+	config.GddManageAllowedIPs.Write("10.0.0.0/8,10.0.0.0/33")
+	_, err = rest.ManageAllowIPsFromConfig()
+	require.Error(t, err)
+	config.GddManageAllowedIPs.Write("not-an-ip")
+	_, err = rest.ManageAllowIPsFromConfig()
+	require.Error(t, err)
+}
+
+func TestAllowIPs_ProxyHeaders(t *testing.T) {
+	allowIPs, err := rest.AllowIPs("10.0.0.0/8")
+	require.NoError(t, err)
+	// handlers.ProxyHeaders runs before management middlewares and rewrites r.RemoteAddr from spoofed headers
+	ts := httptest.NewUnstartedServer(handlers.ProxyHeaders(allowIPs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))))
+	ts.Config.ConnContext = rest.ConnContext
+	ts.Start()
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
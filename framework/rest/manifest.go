@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"io"
+	"os"
+)
+
+// RouteManifestEntry is a route in route manifest
+type RouteManifestEntry struct {
+	Name   string `json:"name"`
+	Method string `json:"method"`
+	// Pattern is resolved pattern including route root path, the same as what clients request
+	Pattern string `json:"pattern"`
+}
+
+// WriteRouteManifest writes routes returned by Routes to w as json array of RouteManifestEntry. Management and
+// debug routes are included only after Run.
+func (srv *RestServer) WriteRouteManifest(w io.Writer) error {
+	routes := srv.Routes()
+	manifest := make([]RouteManifestEntry, 0, len(routes))
+	for _, item := range routes {
+		manifest = append(manifest, RouteManifestEntry{
+			Name:    item.Name,
+			Method:  item.Method,
+			Pattern: item.Pattern,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.WithStack(enc.Encode(manifest))
+}
+
+// writeRouteManifestFile writes route manifest to file at GddRouteManifest if set. It is called by Run right after
+// routes table is printed.
+func (srv *RestServer) writeRouteManifestFile() {
+	file := config.GddRouteManifest.LoadOrDefault(config.DefaultGddRouteManifest)
+	if stringutils.IsEmpty(file) {
+		return
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		logger.Error().Err(err).Msgf("create route manifest %s failed", file)
+		return
+	}
+	defer f.Close()
+	if err = srv.WriteRouteManifest(f); err != nil {
+		logger.Error().Err(err).Msgf("write route manifest %s failed", file)
+		return
+	}
+	logger.Info().Msgf("route manifest is written to %s", file)
+}
@@ -0,0 +1,70 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWriteRouteManifest(t *testing.T) {
+	config.GddRouteRootPath.Write("/api/")
+	defer os.Unsetenv(string(config.GddRouteRootPath))
+
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:    "GetHello",
+		Method:  http.MethodGet,
+		Pattern: "/hello",
+	})
+	srv.Mount("/webhooks", http.NotFoundHandler())
+	var buf bytes.Buffer
+	require.NoError(t, srv.WriteRouteManifest(&buf))
+	var manifest []rest.RouteManifestEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &manifest))
+	require.Equal(t, []rest.RouteManifestEntry{
+		{Name: "GetHello", Method: http.MethodGet, Pattern: "/api/hello"},
+		{Name: "Mount/webhooks", Method: "*", Pattern: "/api/webhooks/*"},
+	}, manifest)
+}
+
+func TestRouteManifestFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "routes.json")
+	config.GddPort.Write("6080")
+	defer config.GddPort.Write(strconv.Itoa(config.DefaultGddPort))
+	config.GddRouteRootPath.Write("/api")
+	config.GddRouteManifest.Write(file)
+	defer os.Unsetenv(string(config.GddRouteRootPath))
+	defer os.Unsetenv(string(config.GddRouteManifest))
+
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:    "GetHello",
+		Method:  http.MethodGet,
+		Pattern: "/hello",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		},
+	})
+	go srv.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	var manifest []rest.RouteManifestEntry
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Equal(t, rest.RouteManifestEntry{Name: "GetHello", Method: http.MethodGet, Pattern: "/api/hello"}, manifest[0])
+	require.Contains(t, manifest, rest.RouteManifestEntry{Name: "GetDoc", Method: http.MethodGet, Pattern: "/go-doudou/doc"})
+
+	resp, err := http.Get("http://localhost:6080" + manifest[0].Pattern)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -11,7 +11,7 @@ import (
 	"github.com/klauspost/compress/gzip"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
 	"github.com/opentracing/opentracing-go"
-	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"github.com/slok/goresilience"
 	"github.com/slok/goresilience/bulkhead"
 	"github.com/uber/jaeger-client-go"
@@ -19,6 +19,7 @@ import (
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"go.opentelemetry.io/otel/trace"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -33,6 +34,7 @@ var (
 	Tracing             = tracing
 	Metrics             = metrics
 	Log                 = log
+	JSONLog             = jsonLog
 	FallbackContentType = fallbackContentType
 	BasicAuth           = basicAuth
 	Recovery            = recovery
@@ -109,7 +111,7 @@ func init() {
 func metrics(inner http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		m := httpsnoop.CaptureMetrics(inner, w, r)
-		logger.Info().
+		accessLogger.Info().
 			Msgf("%s\t%s\t%s\t%d\t%d\t%s", r.RemoteAddr,
 				r.Method,
 				r.URL,
@@ -120,7 +122,49 @@ func metrics(inner http.Handler) http.Handler {
 	})
 }
 
-// log logs http request body and response body for debugging
+// logRecorder buffers response for logging until handler flushes it. Once flushed, e.g. by NDJSONWriter or
+// server-sent events, the buffered part is sent to client and the rest is written through, so streaming
+// responses are neither held in memory nor delayed.
+type logRecorder struct {
+	*httptest.ResponseRecorder
+	w         http.ResponseWriter
+	streaming bool
+	streamed  int
+}
+
+func (lr *logRecorder) Write(p []byte) (int, error) {
+	if lr.streaming {
+		n, err := lr.w.Write(p)
+		lr.streamed += n
+		return n, err
+	}
+	return lr.ResponseRecorder.Write(p)
+}
+
+func (lr *logRecorder) WriteString(str string) (int, error) {
+	return lr.Write([]byte(str))
+}
+
+func (lr *logRecorder) Flush() {
+	if !lr.streaming {
+		lr.ResponseRecorder.Flush()
+		lr.streaming = true
+		result := lr.Result()
+		for k, v := range result.Header {
+			lr.w.Header()[k] = v
+		}
+		lr.w.WriteHeader(result.StatusCode)
+		n, _ := lr.w.Write(lr.Body.Bytes())
+		lr.streamed += n
+		lr.Body.Reset()
+	}
+	if f, ok := lr.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// log logs http request body and response body for debugging. Body of streaming response, which is flushed
+// by handler, is not logged.
 func log(inner http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var (
@@ -132,17 +176,20 @@ func log(inner http.Handler) http.Handler {
 			logger.Error().Err(err).Msg("call copyReqBody(r.Body) error")
 		}
 
-		rec := httptest.NewRecorder()
+		rec := &logRecorder{ResponseRecorder: httptest.NewRecorder(), w: w}
 		start := time.Now()
 		inner.ServeHTTP(rec, r)
 		elapsed := time.Since(start)
 		reqBody := GetReqBody(reqBodyCopy, r)
 		rid, _ := requestid.FromContext(r.Context())
 		span := opentracing.SpanFromContext(r.Context())
-		if jspan, ok := span.(*jaeger.Span); ok {
-			traceId = jspan.SpanContext().TraceID().String()
+		traceId = traceIDFromContext(r.Context())
+		var respBody string
+		respContentLength := rec.streamed
+		if !rec.streaming {
+			respBody = GetRespBody(rec.ResponseRecorder)
+			respContentLength = rec.Body.Len()
 		}
-		respBody := GetRespBody(rec)
 		reqQuery := r.URL.RawQuery
 		if unescape, err := url.QueryUnescape(reqQuery); err == nil {
 			reqQuery = unescape
@@ -161,7 +208,7 @@ func log(inner http.Handler) http.Handler {
 			"respBody":          respBody,
 			"statusCode":        rec.Result().StatusCode,
 			"respHeader":        rec.Result().Header,
-			"respContentLength": rec.Body.Len(),
+			"respContentLength": respContentLength,
 			"elapsedTime":       elapsed.String(),
 			"elapsed":           elapsed.Milliseconds(),
 			"span":              span,
@@ -171,7 +218,10 @@ func log(inner http.Handler) http.Handler {
 		if reqLog, err = JsonMarshalIndent(fields, "", "    ", true); err != nil {
 			reqLog = fmt.Sprintf("call jsonMarshalIndent(fields, \"\", \"    \", true) error: %s", err)
 		}
-		logger.Info().Fields(fields).Msg(reqLog)
+		accessLogger.Info().Fields(fields).Msg(reqLog)
+		if rec.streaming {
+			return
+		}
 		header := rec.Result().Header
 		for k, v := range header {
 			w.Header()[k] = v
@@ -181,6 +231,34 @@ func log(inner http.Handler) http.Handler {
 	})
 }
 
+// jsonLog logs one json line per request with method, path, status code, bytes written, remote address,
+// request id and duration. Unlike log, it doesn't buffer response, status code and bytes written are captured
+// by wrapping http.ResponseWriter.
+func jsonLog(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := httpsnoop.CaptureMetrics(inner, w, r)
+		accessLogger.Info().
+			Str("httpMethod", r.Method).
+			Str("path", r.URL.Path).
+			Int("statusCode", m.Code).
+			Int64("bytesWritten", m.Written).
+			Str("remoteAddr", r.RemoteAddr).
+			Str("requestId", requestIDFromRequest(r)).
+			Str("traceId", traceIDFromContext(r.Context())).
+			Str("elapsedTime", m.Duration.String()).
+			Int64("elapsed", m.Duration.Milliseconds()).
+			Msgf("%s %s %d", r.Method, r.URL.Path, m.Code)
+	})
+}
+
+// LogFromConfig returns request logging middleware in format set by GddLogReqFormat, Log by default
+func LogFromConfig() func(inner http.Handler) http.Handler {
+	if strings.ToLower(config.GddLogReqFormat.LoadOrDefault(config.DefaultGddLogReqFormat)) == config.LogReqFormatJSON {
+		return jsonLog
+	}
+	return log
+}
+
 // rest set Content-Type to application/json
 func rest(inner http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -227,39 +305,35 @@ func basicAuth() func(inner http.Handler) http.Handler {
 	}
 }
 
+// requestIDFromRequest returns request id put into request context by requestid middleware, or X-Request-ID header
+// if requestid middleware hasn't run, e.g. panic recovered outside of middleware chain
+func requestIDFromRequest(r *http.Request) string {
+	if rid, ok := requestid.FromContext(r.Context()); ok {
+		return rid
+	}
+	return r.Header.Get("X-Request-ID")
+}
+
+// panicLog returns an error level log event for panic recovered from processing r with statusCode responded,
+// attaching request id and trace id as structured fields for correlation
+func panicLog(r *http.Request, statusCode int) *zerolog.Event {
+	return logger.Error().
+		Str("requestId", requestIDFromRequest(r)).
+		Str("traceId", traceIDFromContext(r.Context())).
+		Str("httpMethod", r.Method).
+		Str("path", r.URL.Path).
+		Int("statusCode", statusCode)
+}
+
 // recovery handles panic from processing incoming http request
 func recovery(inner http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if e := recover(); e != nil {
-				statusCode := http.StatusInternalServerError
-				errCode := 1 // 1 indicates there is an error
-				message := fmt.Sprintf("%v", e)
-				if err, ok := e.(error); ok {
-					switch {
-					case errors.Is(err, context.Canceled):
-						statusCode = http.StatusBadRequest
-					default:
-						var bizError BizError
-						if errors.As(err, &bizError) {
-							statusCode = bizError.StatusCode
-							errCode = bizError.ErrCode
-							message = bizError.Error()
-						}
-					}
-				}
+				statusCode, resp := NewErrorResponse(e)
 				w.WriteHeader(statusCode)
-				if stringutils.IsEmpty(message) {
-					message = http.StatusText(statusCode)
-				}
-				logger.Error().Msgf("panic: %+v\n\nstacktrace from panic: %s\n", e, string(debug.Stack()))
-				if _err := json.NewEncoder(w).Encode(struct {
-					Code    int    `json:"code"`
-					Message string `json:"message"`
-				}{
-					Code:    errCode,
-					Message: message,
-				}); _err != nil {
+				panicLog(r, statusCode).Msgf("panic: %+v\n\nstacktrace from panic: %s\n", e, string(debug.Stack()))
+				if _err := json.NewEncoder(w).Encode(resp); _err != nil {
 					http.Error(w, _err.Error(), http.StatusInternalServerError)
 					return
 				}
@@ -286,11 +360,26 @@ func gzipBody(inner http.Handler) http.Handler {
 	})
 }
 
+// traceIDFromContext returns jaeger or OpenTelemetry trace id of the span from ctx
+func traceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String()
+	}
+	span := opentracing.SpanFromContext(ctx)
+	if jspan, ok := span.(*jaeger.Span); ok {
+		return jspan.SpanContext().TraceID().String()
+	}
+	return ""
+}
+
 // tracing add jaeger tracing middleware
 func tracing(inner http.Handler) http.Handler {
 	return nethttp.Middleware(
 		opentracing.GlobalTracer(),
-		inner,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recordTraceID(r.Context())
+			inner.ServeHTTP(w, r)
+		}),
 		nethttp.OperationNameFunc(func(r *http.Request) string {
 			return fmt.Sprintf("HTTP %s: %s", r.Method, r.URL.Path)
 		}))
@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"github.com/ascarter/requestid"
+	"github.com/gorilla/handlers"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"net/http"
+)
+
+// Names of built-in middlewares, which can be used as anchors of InsertMiddlewareBefore and InsertMiddlewareAfter.
+// Listed in default order from outermost to innermost. Middlewares added by AddMiddleware are placed between
+// MiddlewareHandlerTimeout and MiddlewareMutualTLS, those added by PreMiddleware before MiddlewareTracing.
+const (
+	// MiddlewarePrometheus is added by Run if GddManage is enabled
+	MiddlewarePrometheus = "prometheus"
+	MiddlewareTracing    = "tracing"
+	MiddlewareMetrics    = "metrics"
+	// MiddlewareRecentRequests is added if GddRecentRequestsEnable is enabled
+	MiddlewareRecentRequests = "recentrequests"
+	// MiddlewareSlowRequests is added if GddSlowRequestThreshold is positive
+	MiddlewareSlowRequests = "slowrequests"
+	// MiddlewareGzipBody decompresses gzip encoded request body
+	MiddlewareGzipBody = "gzipbody"
+	// MiddlewareGzip compresses response body, it is added if GddEnableResponseGzip is enabled
+	MiddlewareGzip = "gzip"
+	// MiddlewareLog is added if GddLogReqEnable is enabled
+	MiddlewareLog                 = "log"
+	MiddlewareRequestID           = "requestid"
+	MiddlewareProxyHeaders        = "proxyheaders"
+	MiddlewareFallbackContentType = "fallbackcontenttype"
+	MiddlewareMaxBodySize         = "maxbodysize"
+	// MiddlewareRateLimit is added if GddRateLimitEnable is enabled
+	MiddlewareRateLimit = "ratelimit"
+	// MiddlewareHandlerTimeout is no-op unless GddHandlerTimeout or Timeout of the matched route is positive
+	MiddlewareHandlerTimeout = "timeout"
+	// MiddlewareMutualTLS is added by Run if TLS is enabled and GddClientCAFile is set
+	MiddlewareMutualTLS = "mtls"
+	// MiddlewareTrackInFlight is added by Run
+	MiddlewareTrackInFlight = "inflight"
+	// MiddlewareMaintenance is added by Run
+	MiddlewareMaintenance = "maintenance"
+	// MiddlewareRecovery is the panic handler added by Run, either recovery or the one set by WithPanicHandler
+	MiddlewareRecovery = "recovery"
+)
+
+// ErrMiddlewareNotFound is returned by InsertMiddlewareBefore and InsertMiddlewareAfter if the anchor middleware
+// is unknown or not enabled
+var ErrMiddlewareNotFound = errors.New("middleware not found")
+
+// middlewareInsertion is an insertion relative to a middleware added by Run, applied when Run assembles the chain
+type middlewareInsertion struct {
+	anchor string
+	after  bool
+	mwf    []MiddlewareFunc
+}
+
+// use appends mw named name to the end of chain, name is empty for user middlewares
+func (srv *RestServer) use(name string, mw ...MiddlewareFunc) {
+	for _, item := range mw {
+		srv.middlewares = append(srv.middlewares, item)
+		srv.middlewareNames = append(srv.middlewareNames, name)
+	}
+}
+
+// useBuiltinMiddlewares appends built-in middlewares enabled by config
+func (srv *RestServer) useBuiltinMiddlewares() {
+	srv.use(MiddlewareTracing, TracingFromConfig())
+	srv.use(MiddlewareMetrics, metrics)
+	if recentRequests, ok := RecentRequestsFromConfig(); ok {
+		srv.use(MiddlewareRecentRequests, recentRequests)
+	}
+	if slowRequests, ok := SlowRequestsFromConfig(); ok {
+		srv.use(MiddlewareSlowRequests, slowRequests)
+	}
+	srv.use(MiddlewareGzipBody, gzipBody)
+	if cast.ToBoolOrDefault(config.GddEnableResponseGzip.Load(), config.DefaultGddEnableResponseGzip) {
+		gzipMiddleware, err := GzipFromConfig()
+		if err != nil {
+			panic(err)
+		}
+		srv.use(MiddlewareGzip, gzipMiddleware)
+	}
+	if cast.ToBoolOrDefault(config.GddLogReqEnable.Load(), config.DefaultGddLogReqEnable) {
+		srv.use(MiddlewareLog, LogFromConfig())
+	}
+	srv.use(MiddlewareRequestID, requestid.RequestIDHandler)
+	srv.use(MiddlewareProxyHeaders, handlers.ProxyHeaders)
+	srv.use(MiddlewareFallbackContentType, fallbackContentType(config.GddFallbackContentType.LoadOrDefault(config.DefaultGddFallbackContentType)))
+	srv.use(MiddlewareMaxBodySize, MaxBodySize)
+	if rateLimit, ok := RateLimitFromConfig(); ok {
+		srv.use(MiddlewareRateLimit, rateLimit)
+	}
+	srv.use(MiddlewareHandlerTimeout, HandlerTimeout)
+}
+
+// manageEnabled reports whether management routes and MiddlewarePrometheus are added by Run
+func manageEnabled() bool {
+	return cast.ToBoolOrDefault(config.GddManage.Load(), config.DefaultGddManage)
+}
+
+// mutualTLSEnabled reports whether MiddlewareMutualTLS is added by Run
+func mutualTLSEnabled() bool {
+	return TLSEnabled() && stringutils.IsNotEmpty(config.GddClientCAFile.LoadOrDefault(config.DefaultGddClientCAFile))
+}
+
+// runtimeMiddlewareEnabled reports whether name is a middleware which will be added by Run
+func runtimeMiddlewareEnabled(name string) bool {
+	switch name {
+	case MiddlewarePrometheus:
+		return manageEnabled()
+	case MiddlewareMutualTLS:
+		return mutualTLSEnabled()
+	case MiddlewareTrackInFlight, MiddlewareMaintenance, MiddlewareRecovery:
+		return true
+	default:
+		return false
+	}
+}
+
+func (srv *RestServer) indexOfMiddleware(name string) int {
+	for i, item := range srv.middlewareNames {
+		if item == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertMiddleware inserts mwf right before or after the middleware named anchor
+func (srv *RestServer) insertMiddleware(anchor string, after bool, mwf []MiddlewareFunc) error {
+	i := srv.indexOfMiddleware(anchor)
+	if i < 0 {
+		return errors.Wrap(ErrMiddlewareNotFound, anchor)
+	}
+	if after {
+		i++
+	}
+	names := make([]string, len(mwf))
+	srv.middlewares = append(srv.middlewares[:i], append(mwf, srv.middlewares[i:]...)...)
+	srv.middlewareNames = append(srv.middlewareNames[:i], append(names, srv.middlewareNames[i:]...)...)
+	return nil
+}
+
+func (srv *RestServer) insertOrDefer(anchor string, after bool, mwf []func(http.Handler) http.Handler) error {
+	middlewares := make([]MiddlewareFunc, 0, len(mwf))
+	for _, item := range mwf {
+		middlewares = append(middlewares, item)
+	}
+	if srv.indexOfMiddleware(anchor) >= 0 {
+		return srv.insertMiddleware(anchor, after, middlewares)
+	}
+	if !runtimeMiddlewareEnabled(anchor) {
+		return errors.Wrap(ErrMiddlewareNotFound, anchor)
+	}
+	srv.insertions = append(srv.insertions, middlewareInsertion{anchor: anchor, after: after, mwf: middlewares})
+	return nil
+}
+
+// InsertMiddlewareBefore inserts middlewares right before the built-in middleware named name, so they are called
+// earlier than it, e.g. auth before MiddlewareMaxBodySize. Names and default order of built-in middlewares are listed
+// by MiddlewareTracing etc. It returns ErrMiddlewareNotFound if name is unknown or the middleware is not enabled.
+// It should be called before Run.
+func (srv *RestServer) InsertMiddlewareBefore(name string, mwf ...func(http.Handler) http.Handler) error {
+	return srv.insertOrDefer(name, false, mwf)
+}
+
+// InsertMiddlewareAfter inserts middlewares right after the built-in middleware named name, so they are called
+// later than it, e.g. after MiddlewareTracing to see the span in request context. It returns ErrMiddlewareNotFound
+// if name is unknown or the middleware is not enabled. It should be called before Run.
+func (srv *RestServer) InsertMiddlewareAfter(name string, mwf ...func(http.Handler) http.Handler) error {
+	return srv.insertOrDefer(name, true, mwf)
+}
+
+// applyInsertions applies insertions relative to middlewares added by Run
+func (srv *RestServer) applyInsertions() error {
+	for _, item := range srv.insertions {
+		if err := srv.insertMiddleware(item.anchor, item.after, item.mwf); err != nil {
+			return err
+		}
+	}
+	srv.insertions = nil
+	return nil
+}
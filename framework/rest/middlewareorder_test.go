@@ -0,0 +1,92 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInsertMiddleware(t *testing.T) {
+	config.GddPort.Write("6077")
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(name string) func(inner http.Handler) http.Handler {
+		return func(inner http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/order" {
+					mu.Lock()
+					calls = append(calls, name)
+					mu.Unlock()
+				}
+				inner.ServeHTTP(w, r)
+			})
+		}
+	}
+	auth := func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			inner.ServeHTTP(w, r)
+		})
+	}
+
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:        "Order",
+		Method:      http.MethodPost,
+		Pattern:     "/order",
+		MaxBodySize: 8,
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		},
+	})
+	srv.PreMiddleware(record("pre"))
+	srv.AddMiddleware(record("add"))
+	require.NoError(t, srv.InsertMiddlewareBefore(rest.MiddlewareMetrics, record("beforeMetrics")))
+	require.NoError(t, srv.InsertMiddlewareAfter(rest.MiddlewareTracing, record("afterTracing")))
+	require.NoError(t, srv.InsertMiddlewareBefore(rest.MiddlewareRecovery, record("beforeRecovery")))
+	require.NoError(t, srv.InsertMiddlewareBefore(rest.MiddlewareMaxBodySize, auth))
+
+	err := srv.InsertMiddlewareAfter("not-exist", auth)
+	require.ErrorIs(t, err, rest.ErrMiddlewareNotFound)
+	// rate limit is disabled by default
+	err = srv.InsertMiddlewareBefore(rest.MiddlewareRateLimit, auth)
+	require.ErrorIs(t, err, rest.ErrMiddlewareNotFound)
+
+	go srv.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:6077/order", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	mu.Lock()
+	require.Equal(t, []string{"pre", "afterTracing", "beforeMetrics", "add", "beforeRecovery"}, calls)
+	mu.Unlock()
+
+	// auth runs before body size limit, so oversized requests without credentials are rejected as unauthorized
+	req, _ = http.NewRequest(http.MethodPost, "http://localhost:6077/order", strings.NewReader(strings.Repeat("a", 64)))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPost, "http://localhost:6077/order", strings.NewReader(strings.Repeat("a", 64)))
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // Route wraps config for route
@@ -19,6 +20,22 @@ type Route struct {
 	Method      string
 	Pattern     string
 	HandlerFunc http.HandlerFunc
+	// Middlewares apply to this route only. They are composed inside global middlewares, the first one is the outermost.
+	Middlewares []MiddlewareFunc
+	// MaxBodySize overrides GddMaxBodySize for this route if not zero, negative value means unlimited
+	MaxBodySize int64
+	// Timeout overrides GddHandlerTimeout for this route if not zero, negative value means no timeout,
+	// e.g. for streaming responses
+	Timeout time.Duration
+}
+
+// Handler returns HandlerFunc wrapped by Middlewares of the route
+func (r Route) Handler() http.Handler {
+	h := http.Handler(r.HandlerFunc)
+	for i := len(r.Middlewares) - 1; i >= 0; i-- {
+		h = r.Middlewares[i].Middleware(h)
+	}
+	return h
 }
 
 // borrowed from httputil unexported function drainBody
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// mountMethods are http methods routed to mounted handlers
+var mountMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// mount is a handler mounted under prefix by Mount
+type mount struct {
+	prefix  string
+	handler http.Handler
+}
+
+// name of mounted handler in routes table and in request context if GddRouterSaveMatchedRoutePath is enabled
+func (m mount) name() string {
+	return "Mount" + m.prefix
+}
+
+// pattern matches all paths under prefix
+func (m mount) pattern() string {
+	return m.prefix + "/*"
+}
+
+// Mount registers handler, e.g. an existing http.ServeMux, for all methods of requests to prefix and all paths
+// under it, so it needn't be rewritten as routes. Like routes added by AddRoute, prefix is relative to
+// GddRouteRootPath, and requests are wrapped by the global middleware chain. Both GddRouteRootPath and prefix are
+// stripped from request path before passed to handler, e.g. with GddRouteRootPath /api, handler mounted under
+// /webhooks sees /github for request to /api/webhooks/github, and / for request to /api/webhooks. It is printed
+// as a single route with method * in routes table. Routes with static patterns like /webhooks/health always win
+// over mounted handler, even under / which mounts handler for all unmatched paths. But if prefix overlaps dynamic
+// patterns like /webhooks/:id, which one matches a request is picked at random, so avoid that. It should be
+// called before Run.
+func (srv *RestServer) Mount(prefix string, handler http.Handler) {
+	srv.mounts = append(srv.mounts, mount{
+		prefix:  strings.TrimSuffix(path.Clean("/"+prefix), "/"),
+		handler: handler,
+	})
+}
+
+// mountRoutes returns a route of each mounted handler for printing routes table
+func (srv *RestServer) mountRoutes() []Route {
+	var routes []Route
+	for _, item := range srv.mounts {
+		routes = append(routes, Route{
+			Name:    item.name(),
+			Method:  "*",
+			Pattern: item.pattern(),
+		})
+	}
+	return routes
+}
+
+// registerMounts registers mounted handlers to bizRouter wrapped by middlewares. rr is GddRouteRootPath.
+func (srv *RestServer) registerMounts(rr string) {
+	for _, item := range srv.mounts {
+		// stripped is empty if handler is mounted under / without GddRouteRootPath, then request path is passed as is
+		stripped := strings.TrimSuffix(path.Clean("/"+rr+item.prefix), "/")
+		var h http.Handler = item.handler
+		if stripped != "" {
+			inner := item.handler
+			h = http.StripPrefix(stripped, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "" {
+					r.URL.Path = "/"
+				}
+				inner.ServeHTTP(w, r)
+			}))
+		}
+		for i := len(srv.middlewares) - 1; i >= 0; i-- {
+			h = srv.middlewares[i].Middleware(h)
+		}
+		h = withRoutePattern(h, stripped+"/*")
+		exact := item.prefix
+		if exact == "" {
+			exact = "/"
+		}
+		for _, method := range mountMethods {
+			srv.bizRouter.Handler(method, exact, h, item.name())
+			srv.bizRouter.Handler(method, item.pattern(), h, item.name())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMount(t *testing.T) {
+	config.GddPort.Write("6078")
+	config.GddRouteRootPath.Write("/api")
+	defer os.Unsetenv(string(config.GddRouteRootPath))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("index " + r.Method + " " + r.URL.Path))
+	})
+	mux.HandleFunc("/github/push", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("push " + r.Method + " " + r.URL.Path))
+	})
+
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:    "GetHello",
+		Method:  http.MethodGet,
+		Pattern: "/hello",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		},
+	})
+	srv.Mount("/webhooks/", mux)
+	srv.AddMiddleware(func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Mounted", "true")
+			inner.ServeHTTP(w, r)
+		})
+	})
+	go srv.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	do := func(method, url string) (*http.Response, string) {
+		req, _ := http.NewRequest(method, url, strings.NewReader("{}"))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, body := do(http.MethodPost, "http://localhost:6078/api/webhooks/github/push")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "push POST /github/push", body)
+	require.Equal(t, "true", resp.Header.Get("X-Mounted"))
+
+	resp, body = do(http.MethodDelete, "http://localhost:6078/api/webhooks")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "index DELETE /", body)
+
+	resp, body = do(http.MethodGet, "http://localhost:6078/api/hello")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "hello", body)
+
+	resp, _ = do(http.MethodGet, "http://localhost:6078/webhooks/github/push")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMount_Root(t *testing.T) {
+	config.GddPort.Write("6084")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("index " + r.URL.Path))
+	})
+
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:    "GetHello",
+		Method:  http.MethodGet,
+		Pattern: "/hello",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		},
+	})
+	srv.Mount("/", mux)
+	go srv.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	get := func(url string) (int, string) {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("http://localhost:6084/")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "index /", body)
+
+	code, body = get("http://localhost:6084/github")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "index /github", body)
+
+	// static routes win over mounted handler
+	code, body = get("http://localhost:6084/hello")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "hello", body)
+}
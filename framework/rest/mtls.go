@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+type peerCertificateKey struct{}
+
+// ClientIdentity wraps verified client certificate subject info for authorization
+type ClientIdentity struct {
+	CommonName     string
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+	IPAddresses    []string
+}
+
+// PeerCertificateFromContext returns verified client certificate put into context by MutualTLS middleware
+func PeerCertificateFromContext(ctx context.Context) (*x509.Certificate, bool) {
+	cert, ok := ctx.Value(peerCertificateKey{}).(*x509.Certificate)
+	return cert, ok && cert != nil
+}
+
+// ClientIdentityFromContext returns CN and SANs of verified client certificate put into context by MutualTLS middleware
+func ClientIdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	cert, ok := PeerCertificateFromContext(ctx)
+	if !ok {
+		return ClientIdentity{}, false
+	}
+	identity := ClientIdentity{
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+	}
+	for _, item := range cert.URIs {
+		identity.URIs = append(identity.URIs, item.String())
+	}
+	for _, item := range cert.IPAddresses {
+		identity.IPAddresses = append(identity.IPAddresses, item.String())
+	}
+	return identity, true
+}
+
+// MutualTLS puts verified client certificate into request context. If require is true, requests without
+// verified client certificate will be rejected with 403 status code, otherwise they will be passed through.
+// Client certificates are verified by tls handshake against GddClientCAFile, so this middleware only checks
+// whether there is a verified chain.
+func MutualTLS(require bool) func(inner http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+				ctx := context.WithValue(r.Context(), peerCertificateKey{}, r.TLS.VerifiedChains[0][0])
+				inner.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			if require {
+				http.Error(w, "client certificate required", http.StatusForbidden)
+				return
+			}
+			inner.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TLSEnabled returns GddTLSEnable if it is set, otherwise returns true if both certificate file and private key file
+// are configured
+func TLSEnabled() bool {
+	if enable := config.GddTLSEnable.Load(); stringutils.IsNotEmpty(enable) {
+		return cast.ToBoolOrDefault(enable, false)
+	}
+	return stringutils.IsNotEmpty(config.GddTLSCert.LoadOrDefault(config.DefaultGddTLSCert)) &&
+		stringutils.IsNotEmpty(config.GddTLSKey.LoadOrDefault(config.DefaultGddTLSKey))
+}
+
+// CheckTLS returns error if https is enabled but certificate file or private key file is not set, not readable,
+// or they don't match each other. It is called before http server starts to fail fast.
+func CheckTLS() error {
+	if !TLSEnabled() {
+		return nil
+	}
+	certFile := config.GddTLSCert.LoadOrDefault(config.DefaultGddTLSCert)
+	keyFile := config.GddTLSKey.LoadOrDefault(config.DefaultGddTLSKey)
+	if stringutils.IsEmpty(certFile) || stringutils.IsEmpty(keyFile) {
+		return errors.Errorf("[go-doudou] https is enabled, but %s or %s is not set", string(config.GddTLSCert), string(config.GddTLSKey))
+	}
+	for _, file := range []string{certFile, keyFile} {
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrapf(err, "[go-doudou] failed to read tls file %s", file)
+		}
+		f.Close()
+	}
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		return errors.Wrapf(err, "[go-doudou] failed to load tls certificate %s and private key %s", certFile, keyFile)
+	}
+	return nil
+}
+
+func clientAuthRequired() bool {
+	return config.GddClientAuthMode.LoadOrDefault(config.DefaultGddClientAuthMode) != config.ClientAuthVerifyIfGiven
+}
+
+// newTLSConfig creates tls config for http server. It returns nil if mutual TLS is not configured.
+func newTLSConfig() (*tls.Config, error) {
+	caFile := config.GddClientCAFile.LoadOrDefault(config.DefaultGddClientCAFile)
+	if stringutils.IsEmpty(caFile) {
+		return nil, nil
+	}
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "[go-doudou] failed to read client CA file %s", caFile)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("[go-doudou] no valid certificate found in client CA file %s", caFile)
+	}
+	clientAuth := tls.RequireAndVerifyClientCert
+	if !clientAuthRequired() {
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+	return &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: clientAuth,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package rest_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+func TestMutualTLS(t *testing.T) {
+	var identity rest.ClientIdentity
+	handler := rest.MutualTLS(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ = rest.ClientIdentityFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "ordersvc"},
+		DNSNames: []string{"ordersvc.default.svc"},
+	}
+	req = httptest.NewRequest(http.MethodGet, "/hello", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{cert}},
+	}
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "ordersvc", identity.CommonName)
+	require.Equal(t, []string{"ordersvc.default.svc"}, identity.DNSNames)
+}
+
+func TestMutualTLS_VerifyIfGiven(t *testing.T) {
+	handler := rest.MutualTLS(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := rest.PeerCertificateFromContext(r.Context())
+		require.False(t, ok)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestCheckTLS(t *testing.T) {
+	defer os.Unsetenv(string(config.GddTLSEnable))
+	defer os.Unsetenv(string(config.GddTLSCert))
+	defer os.Unsetenv(string(config.GddTLSKey))
+	certFile, keyFile := writeSelfSignedCert(t)
+	dir := filepath.Dir(keyFile)
+
+	require.False(t, rest.TLSEnabled())
+	require.NoError(t, rest.CheckTLS())
+
+	config.GddTLSEnable.Write("true")
+	require.True(t, rest.TLSEnabled())
+	require.ErrorContains(t, rest.CheckTLS(), "GDD_TLS_CERT or GDD_TLS_KEY is not set")
+
+	config.GddTLSCert.Write(certFile)
+	config.GddTLSKey.Write(filepath.Join(dir, "not-exist.pem"))
+	require.ErrorContains(t, rest.CheckTLS(), "failed to read tls file")
+
+	config.GddTLSKey.Write(certFile)
+	require.ErrorContains(t, rest.CheckTLS(), "failed to load tls certificate")
+
+	config.GddTLSKey.Write(keyFile)
+	require.NoError(t, rest.CheckTLS())
+
+	config.GddTLSEnable.Write("false")
+	require.False(t, rest.TLSEnabled())
+
+	os.Unsetenv(string(config.GddTLSEnable))
+	require.True(t, rest.TLSEnabled())
+}
+
+func TestRestServer_TLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	config.GddPort.Write("6072")
+	config.GddTLSEnable.Write("true")
+	config.GddTLSCert.Write(certFile)
+	config.GddTLSKey.Write(keyFile)
+	go func() {
+		srv := rest.NewRestServer()
+		srv.AddRoute(Routes(NewMocksvcHandler())...)
+		srv.Run()
+	}()
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("https://localhost:6072/user")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.TLS != nil && resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+	os.Unsetenv(string(config.GddTLSEnable))
+	os.Unsetenv(string(config.GddTLSCert))
+	os.Unsetenv(string(config.GddTLSKey))
+}
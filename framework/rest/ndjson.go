@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NDJSONContentType is the content type of newline-delimited JSON stream
+const NDJSONContentType = "application/x-ndjson"
+
+// NDJSONWriter streams values to http response as newline-delimited JSON, one value per line.
+// Each line is flushed immediately, so memory usage stays flat no matter how many values are written.
+// NDJSONContentType is not in gzip content type list, so the response won't be buffered by gzip middleware.
+type NDJSONWriter struct {
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	flusher http.Flusher
+}
+
+// NewNDJSONWriter sets response headers for streaming and creates a NDJSONWriter instance.
+// It should be called before writing anything else to w.
+func NewNDJSONWriter(w http.ResponseWriter) *NDJSONWriter {
+	w.Header().Set("Content-Type", NDJSONContentType)
+	// disable response buffering of nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher, _ := w.(http.Flusher)
+	return &NDJSONWriter{
+		w:       w,
+		enc:     json.NewEncoder(w),
+		flusher: flusher,
+	}
+}
+
+// Write encodes v as one line of json and flushes it to client
+func (n *NDJSONWriter) Write(v interface{}) error {
+	// json.Encoder appends a newline after each value
+	if err := n.enc.Encode(v); err != nil {
+		return err
+	}
+	if n.flusher != nil {
+		n.flusher.Flush()
+	}
+	return nil
+}
+
+// StreamNDJSON writes all values received from ch to w as newline-delimited JSON until ch is closed
+// or client goes away
+func StreamNDJSON(w http.ResponseWriter, r *http.Request, ch <-chan interface{}) error {
+	nw := NewNDJSONWriter(w)
+	for {
+		select {
+		case <-r.Context().Done():
+			return r.Context().Err()
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := nw.Write(v); err != nil {
+				return err
+			}
+		}
+	}
+}
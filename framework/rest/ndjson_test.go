@@ -0,0 +1,82 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"github.com/unionj-cloud/go-doudou/v2/framework/restclient"
+)
+
+type ndjsonItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ch := make(chan interface{})
+		go func() {
+			defer close(ch)
+			for i := 0; i < 1000; i++ {
+				ch <- ndjsonItem{ID: i, Name: "go-doudou"}
+			}
+		}()
+		require.NoError(t, rest.StreamNDJSON(w, r, ch))
+	}))
+	defer ts.Close()
+
+	resp, err := resty.New().R().SetDoNotParseResponse(true).Get(ts.URL)
+	require.NoError(t, err)
+	require.Equal(t, rest.NDJSONContentType, resp.Header().Get("Content-Type"))
+
+	iter := restclient.NewNDJSONIterator(resp.RawBody())
+	defer iter.Close()
+	var count int
+	for iter.Next() {
+		var item ndjsonItem
+		require.NoError(t, iter.Decode(&item))
+		require.Equal(t, count, item.ID)
+		count++
+	}
+	require.NoError(t, iter.Err())
+	require.Equal(t, 1000, count)
+}
+
+func TestStreamNDJSON_Log(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(rest.Log(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nw := rest.NewNDJSONWriter(w)
+		require.NoError(t, nw.Write(ndjsonItem{ID: 0, Name: "go-doudou"}))
+		// the first line must reach client before handler returns
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+		require.NoError(t, nw.Write(ndjsonItem{ID: 1, Name: "go-doudou"}))
+	})))
+	defer ts.Close()
+
+	start := time.Now()
+	resp, err := resty.New().R().SetDoNotParseResponse(true).Get(ts.URL)
+	require.NoError(t, err)
+	require.Equal(t, rest.NDJSONContentType, resp.Header().Get("Content-Type"))
+
+	iter := restclient.NewNDJSONIterator(resp.RawBody())
+	defer iter.Close()
+	require.True(t, iter.Next())
+	require.Less(t, time.Since(start), 3*time.Second)
+	close(release)
+	var item ndjsonItem
+	require.NoError(t, iter.Decode(&item))
+	require.Equal(t, 0, item.ID)
+	require.True(t, iter.Next())
+	require.NoError(t, iter.Decode(&item))
+	require.Equal(t, 1, item.ID)
+	require.False(t, iter.Next())
+	require.NoError(t, iter.Err())
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	registry "github.com/unionj-cloud/go-doudou/v2/framework/registry/memberlist"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+)
+
+// NodesRoutes exposes memberlist cluster membership as a json array of registry.NodeInfo at /nodes, optionally filtered
+// by service name with svc query parameter, e.g. /nodes?svc=usersvc_rest. Unlike MemberlistUIRoutes, it is not mounted
+// by GddManage and has no basic auth, so add it to your own routes by RestServer.AddRoute if needed.
+// It responds 503 if local memberlist node has not been created, to tell from an empty cluster.
+func NodesRoutes() []Route {
+	return []Route{
+		{
+			Name:    "GetNodes",
+			Method:  http.MethodGet,
+			Pattern: "/nodes",
+			HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
+				nodes, err := registry.NodeInfos(request.URL.Query().Get("svc"))
+				if err != nil {
+					if errors.Is(err, registry.ErrNodeNotCreated) {
+						writeErrorResponse(writer, http.StatusServiceUnavailable, "memberlist is not enabled or local node is not created")
+						return
+					}
+					writeErrorResponse(writer, http.StatusInternalServerError, err.Error())
+					return
+				}
+				writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				if err = json.NewEncoder(writer).Encode(nodes); err != nil {
+					logger.Error().Err(err).Msg("[go-doudou] failed to write nodes")
+				}
+			},
+		},
+	}
+}
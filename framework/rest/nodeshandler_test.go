@@ -0,0 +1,19 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNodesRoutes_NotClustered(t *testing.T) {
+	routes := rest.NodesRoutes()
+	require.Len(t, routes, 1)
+	require.Equal(t, "/nodes", routes[0].Pattern)
+	w := httptest.NewRecorder()
+	routes[0].HandlerFunc(w, httptest.NewRequest(http.MethodGet, "/nodes?svc=usersvc_rest", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Contains(t, w.Body.String(), "memberlist is not enabled")
+}
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"net/http"
+)
+
+func restAppType() bool {
+	return config.GddAppType.LoadOrDefault(config.DefaultGddAppType) == "rest"
+}
+
+func writeErrorResponse(w http.ResponseWriter, statusCode int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Code:    1,
+		Message: msg,
+	})
+}
+
+// NotFound is the default handler for requests not matching any route. It responds 404 status code
+// with ErrorResponse json body if GddAppType is rest, otherwise plain text as http.NotFound does.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	if restAppType() {
+		writeErrorResponse(w, http.StatusNotFound, "404 page not found")
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// MethodNotAllowed is the default handler for requests matching a route but not its method. It responds 405
+// status code with ErrorResponse json body if GddAppType is rest, otherwise plain text.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	if restAppType() {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "405 method not allowed")
+		return
+	}
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	w.Write([]byte("405 method not allowed"))
+}
@@ -0,0 +1,112 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotFound_Rest(t *testing.T) {
+	w := httptest.NewRecorder()
+	rest.NotFound(w, httptest.NewRequest(http.MethodGet, "/not-exist", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "application/json; charset=UTF-8", w.Header().Get("Content-Type"))
+	var resp rest.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, rest.ErrorResponse{Code: 1, Message: "404 page not found"}, resp)
+}
+
+func TestMethodNotAllowed_Rest(t *testing.T) {
+	w := httptest.NewRecorder()
+	rest.MethodNotAllowed(w, httptest.NewRequest(http.MethodGet, "/sign/up", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	require.Equal(t, "application/json; charset=UTF-8", w.Header().Get("Content-Type"))
+	var resp rest.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, rest.ErrorResponse{Code: 1, Message: "405 method not allowed"}, resp)
+}
+
+func TestNotFound_NotRest(t *testing.T) {
+	config.GddAppType.Write("grpc")
+	defer config.GddAppType.Write(config.DefaultGddAppType)
+
+	w := httptest.NewRecorder()
+	rest.NotFound(w, httptest.NewRequest(http.MethodGet, "/not-exist", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "404 page not found\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	rest.MethodNotAllowed(w, httptest.NewRequest(http.MethodGet, "/sign/up", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	require.Equal(t, "405 method not allowed", w.Body.String())
+}
+
+func TestRestServer_NotFound_MethodNotAllowed(t *testing.T) {
+	config.GddPort.Write("6071")
+	go func() {
+		srv := rest.NewRestServer()
+		srv.SetNotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("custom not found"))
+		}))
+		srv.AddRoute(Routes(NewMocksvcHandler())...)
+		srv.Run()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	resp, err := http.Get("http://localhost:6071/sign/up")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	var errResp rest.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	require.Equal(t, "405 method not allowed", errResp.Message)
+
+	resp, err = http.Get("http://localhost:6071/not-exist")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "custom not found", string(body))
+}
+
+func TestRestServer_SetNotFoundHandler_SetMethodNotAllowedHandler(t *testing.T) {
+	config.GddPort.Write("6076")
+	go func() {
+		srv := rest.NewRestServer()
+		srv.SetNotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("custom not found"))
+		}))
+		srv.SetMethodNotAllowedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			w.Write([]byte("custom method not allowed"))
+		}))
+		srv.AddRoute(Routes(NewMocksvcHandler())...)
+		srv.Run()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	resp, err := http.Get("http://localhost:6076/sign/up")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "custom method not allowed", string(body))
+
+	resp, err = http.Get("http://localhost:6076/not-exist")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "custom not found", string(body))
+}
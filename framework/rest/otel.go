@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"context"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
+	"net/http"
+	"strings"
+)
+
+// OtelPropagator extracts and injects W3C traceparent, tracestate and baggage headers.
+// It is used by otel tracing middleware and restclient.PropagateTraceContext.
+var OtelPropagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+type routePatternKey struct{}
+
+// ContextWithRoutePattern returns a copy of ctx carrying pattern of the route matching current request.
+// RestServer puts it for each route, the otel tracing middleware names server spans by it and
+// PrometheusMiddleware labels metrics by it.
+func ContextWithRoutePattern(ctx context.Context, pattern string) context.Context {
+	if holder, ok := ctx.Value(routePatternHolderKey{}).(*routePatternHolder); ok {
+		holder.pattern = pattern
+	}
+	return context.WithValue(ctx, routePatternKey{}, pattern)
+}
+
+// RoutePatternFromContext returns route pattern put by ContextWithRoutePattern, or empty string if absent
+func RoutePatternFromContext(ctx context.Context) string {
+	pattern, _ := ctx.Value(routePatternKey{}).(string)
+	return pattern
+}
+
+func withRoutePattern(inner http.Handler, pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r.WithContext(ContextWithRoutePattern(r.Context(), pattern)))
+	})
+}
+
+// otelSpanName names server span by method and route pattern rather than raw path to keep span names
+// low-cardinality, e.g. GET /users/:id
+func otelSpanName(_ string, r *http.Request) string {
+	if pattern := RoutePatternFromContext(r.Context()); stringutils.IsNotEmpty(pattern) {
+		return r.Method + " " + pattern
+	}
+	return "HTTP " + r.Method
+}
+
+// otelTracing add OpenTelemetry tracing middleware. It starts a server span for each request as child of span
+// from W3C traceparent header if any, and puts it into request context, so that generated http clients propagate it
+// to downstream services.
+func otelTracing(inner http.Handler) http.Handler {
+	return otelhttp.NewHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recordTraceID(r.Context())
+			inner.ServeHTTP(w, r)
+		}),
+		"",
+		otelhttp.WithPropagators(OtelPropagator),
+		otelhttp.WithSpanNameFormatter(otelSpanName),
+	)
+}
+
+// TracingFromConfig returns tracing middleware selected by GddTracingProvider
+func TracingFromConfig() func(inner http.Handler) http.Handler {
+	if strings.ToLower(config.GddTracingProvider.LoadOrDefault(config.DefaultGddTracingProvider)) == config.TracingProviderOtel {
+		return otelTracing
+	}
+	return tracing
+}
@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+var ProfileRoutes = profileRoutes
+
+// defaultProfileSeconds is the default duration of cpu profile if seconds query parameter is not provided.
+// It is shortened to fit in WriteTimeout of the server by defaultProfileDuration.
+const defaultProfileSeconds = 30
+
+// profileWriteMargin is time reserved for writing profile to response before WriteTimeout of the server
+const profileWriteMargin = 2 * time.Second
+
+// defaultProfileDuration returns defaultProfileSeconds, or less than writeTimeout by profileWriteMargin if it
+// doesn't fit, e.g. 13s for default GddWriteTimeout 15s. Non-positive writeTimeout means no timeout.
+func defaultProfileDuration(writeTimeout time.Duration) time.Duration {
+	duration := time.Duration(defaultProfileSeconds) * time.Second
+	if writeTimeout <= 0 || duration < writeTimeout {
+		return duration
+	}
+	if writeTimeout > 2*profileWriteMargin {
+		return writeTimeout - profileWriteMargin
+	}
+	return writeTimeout / 2
+}
+
+func profileMaxDuration() time.Duration {
+	d, err := time.ParseDuration(config.GddProfileMaxDuration.Load())
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddProfileMaxDuration),
+			config.GddProfileMaxDuration.Load(), err.Error(), config.DefaultGddProfileMaxDuration)
+		d, _ = time.ParseDuration(config.DefaultGddProfileMaxDuration)
+	}
+	return d
+}
+
+func profileFilename(profile string) string {
+	service := config.GddServiceName.LoadOrDefault(config.DefaultGddServiceName)
+	if service == "" {
+		service = "go-doudou"
+	}
+	return fmt.Sprintf("%s-%s-%s.pprof", service, profile, time.Now().Format("20060102150405"))
+}
+
+func profileError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Del("Content-Disposition")
+	w.WriteHeader(status)
+	w.Write([]byte(msg))
+}
+
+// profileRoutes returns route for capturing a cpu profile or a snapshot of other runtime profiles such as heap on demand
+// and downloading it as a file, so that /debug/pprof endpoints don't need to be always exposed in production.
+// Query parameter type is cpu or name of a runtime profile like heap, allocs and goroutine, default is cpu.
+// Query parameter seconds is duration of cpu profile which is capped by GddProfileMaxDuration, default is 30, or
+// a bit less than WriteTimeout of the server if it doesn't fit.
+func profileRoutes() []Route {
+	return []Route{
+		{
+			Name:    "GetProfile",
+			Method:  http.MethodGet,
+			Pattern: "/go-doudou/profile",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				profile := _req.FormValue("type")
+				if profile == "" {
+					profile = "cpu"
+				}
+				_writer.Header().Set("Content-Type", "application/octet-stream")
+				_writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, profileFilename(profile)))
+				if profile != "cpu" {
+					p := pprof.Lookup(profile)
+					if p == nil {
+						profileError(_writer, http.StatusBadRequest, fmt.Sprintf("unknown profile type %s", profile))
+						return
+					}
+					if profile == "heap" && _req.FormValue("gc") != "" {
+						runtime.GC()
+					}
+					p.WriteTo(_writer, 0)
+					return
+				}
+				var writeTimeout time.Duration
+				if srv, ok := _req.Context().Value(http.ServerContextKey).(*http.Server); ok {
+					writeTimeout = srv.WriteTimeout
+				}
+				duration := defaultProfileDuration(writeTimeout)
+				if s := _req.FormValue("seconds"); s != "" {
+					seconds, err := cast.ToIntE(s)
+					if err != nil || seconds <= 0 {
+						profileError(_writer, http.StatusBadRequest, fmt.Sprintf("invalid seconds %s", s))
+						return
+					}
+					duration = time.Duration(seconds) * time.Second
+				}
+				if maxDuration := profileMaxDuration(); duration > maxDuration {
+					duration = maxDuration
+				}
+				if writeTimeout > 0 && duration >= writeTimeout {
+					profileError(_writer, http.StatusBadRequest, fmt.Sprintf("profile duration %s exceeds server's WriteTimeout %s", duration, writeTimeout))
+					return
+				}
+				if err := pprof.StartCPUProfile(_writer); err != nil {
+					profileError(_writer, http.StatusConflict, fmt.Sprintf("could not enable CPU profiling: %s", err))
+					return
+				}
+				select {
+				case <-time.After(duration):
+				case <-_req.Context().Done():
+				}
+				pprof.StopCPUProfile()
+			},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProfileRoutes(t *testing.T) {
+	config.GddProfileMaxDuration.Write("100ms")
+	defer config.GddProfileMaxDuration.Write(config.DefaultGddProfileMaxDuration)
+	handler := rest.ProfileRoutes()[0].HandlerFunc
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/profile?type=heap", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Regexp(t, `^attachment; filename=".+-heap-\d{14}\.pprof"$`, rr.Header().Get("Content-Disposition"))
+	require.NotZero(t, rr.Body.Len())
+
+	start := time.Now()
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/profile?type=cpu&seconds=30", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Contains(t, rr.Header().Get("Content-Disposition"), "-cpu-")
+	require.NotZero(t, rr.Body.Len())
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/profile?type=unknown", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Empty(t, rr.Header().Get("Content-Disposition"))
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/profile?seconds=abc", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestProfileRoutes_DefaultDuration(t *testing.T) {
+	handler := rest.ProfileRoutes()[0].HandlerFunc
+	ts := httptest.NewUnstartedServer(handler)
+	// like GddWriteTimeout, which is shorter than default duration of cpu profile
+	ts.Config.WriteTimeout = 3 * time.Second
+	ts.Start()
+	defer ts.Close()
+
+	start := time.Now()
+	resp, err := http.Get(ts.URL + "/go-doudou/profile")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, body)
+	require.Less(t, time.Since(start), ts.Config.WriteTimeout)
+}
@@ -1,7 +1,9 @@
 package rest
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
 )
 
 var PromRoutes = promRoutes
@@ -12,7 +14,19 @@ func promRoutes() []Route {
 			Name:        "Prometheus",
 			Method:      "GET",
 			Pattern:     "/go-doudou/prometheus",
-			HandlerFunc: promhttp.Handler().ServeHTTP,
+			HandlerFunc: promHandler().ServeHTTP,
 		},
 	}
 }
+
+// promHandler returns prometheus metrics handler. Exemplars are only exposed in OpenMetrics format,
+// so it is enabled together with GddPromExemplarEnable.
+func promHandler() http.Handler {
+	if !exemplarEnabled() {
+		return promhttp.Handler()
+	}
+	return promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}))
+}
@@ -4,8 +4,11 @@ package rest
 // Many thanks to TannerGabriel https://github.com/TannerGabriel
 // Post link https://gabrieltanner.org/blog/collecting-prometheus-metrics-in-golang written by TannerGabriel
 import (
+	"context"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/unionj-cloud/go-doudou/v2/framework/buildinfo"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/constants"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	"net/http"
@@ -30,6 +33,13 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implements http.Flusher interface so that streaming responses work behind PrometheusMiddleware
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 var countRequests = prometheus.NewCounterVec(
 	prometheus.CounterOpts{
 		Name: "go_doudou_http_request_count",
@@ -38,26 +48,87 @@ var countRequests = prometheus.NewCounterVec(
 	[]string{"path", "method", "status"},
 )
 
-var httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-	Name: "go_doudou_http_response_time_seconds",
-	Help: "Duration of HTTP requests.",
-}, []string{"path", "method"})
+var httpDuration = prometheus.NewHistogramVec(httpDurationOpts(), []string{"path", "method"})
+
+func exemplarEnabled() bool {
+	return cast.ToBoolOrDefault(config.GddPromExemplarEnable.Load(), config.DefaultGddPromExemplarEnable)
+}
+
+func httpDurationOpts() prometheus.HistogramOpts {
+	opts := prometheus.HistogramOpts{
+		Name: "go_doudou_http_response_time_seconds",
+		Help: "Duration of HTTP requests.",
+	}
+	if exemplarEnabled() {
+		// keep classic buckets and expose native histogram at the same time
+		opts.NativeHistogramBucketFactor = 1.1
+	}
+	return opts
+}
+
+// traceIDHolder is put into request context by PrometheusMiddleware, then filled by tracing middleware
+// which is executed later and creates the span
+type traceIDHolder struct {
+	traceID string
+}
+
+type traceIDHolderKey struct{}
+
+// recordTraceID fills traceIDHolder from context with trace id of current span
+func recordTraceID(ctx context.Context) {
+	holder, ok := ctx.Value(traceIDHolderKey{}).(*traceIDHolder)
+	if !ok {
+		return
+	}
+	holder.traceID = traceIDFromContext(ctx)
+}
+
+// routePatternHolder is put into request context by PrometheusMiddleware, then filled by ContextWithRoutePattern
+// in case the route is matched after PrometheusMiddleware, e.g. by gorilla mux
+type routePatternHolder struct {
+	pattern string
+}
 
-// PrometheusMiddleware returns http HandlerFunc for prometheus matrix
+type routePatternHolderKey struct{}
+
+// unmatchedRoute labels requests not matching any route, e.g. 404 responses to scanners probing random paths
+const unmatchedRoute = "unmatched"
+
+// PrometheusMiddleware returns http HandlerFunc for prometheus matrix. Requests are labelled by pattern of the matched
+// route like /users/:id rather than raw path to keep cardinality of metrics low. Requests with no known route pattern
+// are labelled by "unmatched" rather than raw path for the same reason.
 func PrometheusMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
 		method := r.Method
-		timer := prometheus.NewTimer(httpDuration.WithLabelValues(path, method))
+		routeHolder := &routePatternHolder{pattern: RoutePatternFromContext(r.Context())}
+		r = r.WithContext(context.WithValue(r.Context(), routePatternHolderKey{}, routeHolder))
+		var holder *traceIDHolder
+		if exemplarEnabled() {
+			holder = &traceIDHolder{}
+			r = r.WithContext(context.WithValue(r.Context(), traceIDHolderKey{}, holder))
+		}
+		start := time.Now()
 
 		rw := NewResponseWriter(w)
 		next.ServeHTTP(rw, r)
 
 		statusCode := rw.statusCode
+		path := routeHolder.pattern
+		if stringutils.IsEmpty(path) {
+			path = unmatchedRoute
+		}
 
 		countRequests.WithLabelValues(path, method, strconv.Itoa(statusCode)).Inc()
 
-		timer.ObserveDuration()
+		elapsed := time.Since(start).Seconds()
+		observer := httpDuration.WithLabelValues(path, method)
+		if holder != nil && stringutils.IsNotEmpty(holder.traceID) {
+			if eo, ok := observer.(prometheus.ExemplarObserver); ok {
+				eo.ObserveWithExemplar(elapsed, prometheus.Labels{"trace_id": holder.traceID})
+				return
+			}
+		}
+		observer.Observe(elapsed)
 	})
 }
 
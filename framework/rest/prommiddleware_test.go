@@ -0,0 +1,103 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+func TestPrometheusMiddleware_Exemplar(t *testing.T) {
+	_ = config.GddPromExemplarEnable.Write("true")
+	defer config.GddPromExemplarEnable.Write("false")
+	tracer, closer := jaeger.NewTracer("exemplar", jaeger.NewConstSampler(true), jaeger.NewNullReporter())
+	defer closer.Close()
+	origin := opentracing.GlobalTracer()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(origin)
+
+	handler := rest.PrometheusMiddleware(rest.Tracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/exemplar", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	var traceID string
+	for _, mf := range mfs {
+		if mf.GetName() != "go_doudou_http_response_time_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, b := range m.GetHistogram().GetBucket() {
+				if b.GetExemplar() == nil {
+					continue
+				}
+				for _, l := range b.GetExemplar().GetLabel() {
+					if l.GetName() == "trace_id" {
+						traceID = l.GetValue()
+					}
+				}
+			}
+		}
+	}
+	require.NotEmpty(t, traceID)
+}
+
+func requestCount(t *testing.T, path string) float64 {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	var count float64
+	for _, mf := range mfs {
+		if mf.GetName() != "go_doudou_http_request_count" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "path" && l.GetValue() == path {
+					count += m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return count
+}
+
+func TestPrometheusMiddleware_RoutePattern(t *testing.T) {
+	// route is matched after PrometheusMiddleware, e.g. by gorilla mux
+	handler := rest.PrometheusMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest.ContextWithRoutePattern(r.Context(), "/users/:id")
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, p := range []string{"/users/123", "/users/456"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, p, nil))
+	}
+	require.Equal(t, float64(2), requestCount(t, "/users/:id"))
+	require.Zero(t, requestCount(t, "/users/123"))
+	require.Zero(t, requestCount(t, "/users/456"))
+
+	// route is matched before PrometheusMiddleware, e.g. by RestServer
+	handler = rest.PrometheusMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(rest.ContextWithRoutePattern(req.Context(), "/books/:id")))
+	require.Equal(t, float64(1), requestCount(t, "/books/:id"))
+	require.Zero(t, requestCount(t, "/books/1"))
+
+	// no route matched
+	before := requestCount(t, "unmatched")
+	for _, p := range []string{"/wp-admin", "/.env"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, p, nil))
+	}
+	require.Equal(t, before+2, requestCount(t, "unmatched"))
+	require.Zero(t, requestCount(t, "/wp-admin"))
+}
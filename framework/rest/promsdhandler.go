@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	registry "github.com/unionj-cloud/go-doudou/v2/framework/registry/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// PromTargetGroup is a target group in Prometheus http_sd_config format
+type PromTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// PromSDTargets converts rest services of nodes to Prometheus target groups, one group for each service of each node.
+// Metrics path is /go-doudou/prometheus. Labels are service, node, zone from service data if any, status, and
+// build info from node meta.
+func PromSDTargets(nodes []*memberlist.Node) []PromTargetGroup {
+	groups := make([]PromTargetGroup, 0)
+	for _, node := range nodes {
+		meta, _ := registry.ParseMeta(node)
+		for _, service := range meta.Services {
+			if service.Type != constants.REST_TYPE {
+				continue
+			}
+			row := NewRow(0, service, "", meta, node)
+			labels := map[string]string{
+				"__metrics_path__": "/go-doudou/prometheus",
+				"service":          row.SvcName,
+				"node":             row.Hostname,
+				"status":           row.Status,
+				"go_ver":           row.GoVer,
+				"gdd_ver":          row.GddVer,
+				"build_user":       row.BuildUser,
+				"build_time":       row.BuildTime,
+			}
+			switch zone := row.Data["zone"].(type) {
+			case string:
+				labels["zone"] = zone
+			case []byte:
+				// strings in service data are decoded from msgpack as []byte
+				labels["zone"] = string(zone)
+			}
+			groups = append(groups, PromTargetGroup{
+				Targets: []string{net.JoinHostPort(row.Host, strconv.Itoa(row.SvcPort))},
+				Labels:  labels,
+			})
+		}
+	}
+	return groups
+}
+
+// PromSDRoutes exposes all rest services discovered by memberlist as Prometheus scrape targets,
+// to be used as url of http_sd_config
+func PromSDRoutes() []Route {
+	return []Route{
+		{
+			Name:    "GetPrometheusSD",
+			Method:  "GET",
+			Pattern: "/go-doudou/prometheus/sd",
+			HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
+				nodes, err := registry.AllNodes()
+				if err != nil {
+					http.Error(writer, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				json.NewEncoder(writer).Encode(PromSDTargets(nodes))
+			},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package rest_test
+
+import (
+	"bytes"
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
+	registry "github.com/unionj-cloud/go-doudou/v2/framework/registry/memberlist"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
+	"testing"
+)
+
+func encodeMeta(t *testing.T, meta registry.NodeMeta) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, codec.NewEncoder(&buf, &codec.MsgpackHandle{}).Encode(meta))
+	return buf.Bytes()
+}
+
+func TestPromSDTargets(t *testing.T) {
+	nodes := []*memberlist.Node{
+		{
+			Name:  "node1",
+			State: memberlist.StateAlive,
+			Meta: encodeMeta(t, registry.NodeMeta{
+				GddVer: "v2.0.0",
+				Services: []registry.Service{
+					{
+						Name: "usersvc_rest",
+						Host: "10.0.0.1",
+						Port: 6060,
+						Type: constants.REST_TYPE,
+						Data: map[string]interface{}{"zone": "cn-east-1a"},
+					},
+					{
+						Name: "usersvc_grpc",
+						Host: "10.0.0.1",
+						Port: 50051,
+						Type: constants.GRPC_TYPE,
+					},
+				},
+			}),
+		},
+		{
+			Name:  "node2",
+			State: memberlist.StateSuspect,
+			Meta: encodeMeta(t, registry.NodeMeta{
+				Services: []registry.Service{
+					{
+						Name: "ordersvc_rest",
+						Host: "10.0.0.2",
+						Port: 6061,
+						Type: constants.REST_TYPE,
+					},
+				},
+			}),
+		},
+	}
+	groups := rest.PromSDTargets(nodes)
+	require.Len(t, groups, 2)
+
+	require.Equal(t, []string{"10.0.0.1:6060"}, groups[0].Targets)
+	require.Equal(t, "/go-doudou/prometheus", groups[0].Labels["__metrics_path__"])
+	require.Equal(t, "usersvc_rest", groups[0].Labels["service"])
+	require.Equal(t, "node1", groups[0].Labels["node"])
+	require.Equal(t, "cn-east-1a", groups[0].Labels["zone"])
+	require.Equal(t, "up", groups[0].Labels["status"])
+	require.Equal(t, "v2.0.0", groups[0].Labels["gdd_ver"])
+
+	require.Equal(t, []string{"10.0.0.2:6061"}, groups[1].Targets)
+	require.Equal(t, "suspect", groups[1].Labels["status"])
+	_, ok := groups[1].Labels["zone"]
+	require.False(t, ok)
+
+	require.Empty(t, rest.PromSDTargets(nil))
+}
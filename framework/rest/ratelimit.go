@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/ratelimit"
+	"github.com/unionj-cloud/go-doudou/v2/framework/ratelimit/memrate"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultRateLimitMaxKeys is the max number of keys whose token buckets are kept in memory.
+	// The least recently used one is evicted when exceeded.
+	defaultRateLimitMaxKeys = 10000
+	// defaultRateLimitIdleTimeout evicts token bucket of a key not used for the duration
+	defaultRateLimitIdleTimeout = 5 * time.Minute
+)
+
+// RateLimitKeyFunc returns key of a request, requests of the same key share one token bucket
+type RateLimitKeyFunc func(r *http.Request) string
+
+// RateLimitByIP keys requests by client ip returned by ClientIP. Configure GddTrustedProxies if the service is
+// behind proxies, otherwise all requests through a proxy share one token bucket.
+func RateLimitByIP(r *http.Request) string {
+	return ClientIP(r)
+}
+
+// RateLimitByHeader keys requests by value of header, e.g. an api key header. Requests without the header are keyed
+// by client ip, so that clients cannot bypass rate limit by omitting it.
+func RateLimitByHeader(header string) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		if value := r.Header.Get(header); stringutils.IsNotEmpty(value) {
+			return header + ":" + value
+		}
+		return RateLimitByIP(r)
+	}
+}
+
+type rateLimitOptions struct {
+	keyFunc     RateLimitKeyFunc
+	maxKeys     int
+	idleTimeout time.Duration
+}
+
+type RateLimitOption func(*rateLimitOptions)
+
+// WithRateLimitKeyFunc sets key function, default is RateLimitByIP
+func WithRateLimitKeyFunc(keyFunc RateLimitKeyFunc) RateLimitOption {
+	return func(opts *rateLimitOptions) {
+		opts.keyFunc = keyFunc
+	}
+}
+
+// WithRateLimitMaxKeys sets max number of keys whose token buckets are kept in memory, default is 10000
+func WithRateLimitMaxKeys(maxKeys int) RateLimitOption {
+	return func(opts *rateLimitOptions) {
+		opts.maxKeys = maxKeys
+	}
+}
+
+// WithRateLimitIdleTimeout sets duration after which token bucket of an idle key is evicted, default is 5 minutes
+func WithRateLimitIdleTimeout(idleTimeout time.Duration) RateLimitOption {
+	return func(opts *rateLimitOptions) {
+		opts.idleTimeout = idleTimeout
+	}
+}
+
+// RateLimit limits requests of each key to rate per second with bursts of at most burst requests by token bucket
+// algorithm. Requests exceeding the limit are rejected with 429 status code and Retry-After header in seconds.
+// Memory usage is bounded as token buckets are evicted if idle or the number of keys exceeds max keys.
+func RateLimit(rate float64, burst int, opts ...RateLimitOption) func(inner http.Handler) http.Handler {
+	options := rateLimitOptions{
+		keyFunc:     RateLimitByIP,
+		maxKeys:     defaultRateLimitMaxKeys,
+		idleTimeout: defaultRateLimitIdleTimeout,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	store := memrate.NewMemoryStore(func(_ context.Context, store *memrate.MemoryStore, key string) ratelimit.Limiter {
+		return memrate.NewLimiter(memrate.Limit(rate), burst, memrate.WithTimer(options.idleTimeout, func() {
+			store.DeleteKey(key)
+		}))
+	}, memrate.WithMaxKeys(options.maxKeys))
+	retryAfter := 1
+	if rate > 0 {
+		retryAfter = int(math.Max(1, math.Ceil(1/rate)))
+	}
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !store.GetLimiter(options.keyFunc(r)).Allow() {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				writeErrorResponse(w, http.StatusTooManyRequests, "too many requests")
+				return
+			}
+			inner.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitFromConfig returns RateLimit middleware keyed by client ip if GddRateLimitEnable is true
+func RateLimitFromConfig() (func(inner http.Handler) http.Handler, bool) {
+	if !cast.ToBoolOrDefault(config.GddRateLimitEnable.Load(), config.DefaultGddRateLimitEnable) {
+		return nil, false
+	}
+	rate, err := cast.ToFloat64E(config.GddRateLimitRate.Load())
+	if err != nil {
+		rate = config.DefaultGddRateLimitRate
+	}
+	burst := cast.ToIntOrDefault(config.GddRateLimitBurst.Load(), config.DefaultGddRateLimitBurst)
+	return RateLimit(rate, burst), true
+}
@@ -0,0 +1,55 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func rateLimitDo(handler http.Handler, remoteAddr string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := rest.RateLimit(0.001, 2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1235", nil).Code)
+	w := rateLimitDo(handler, "10.0.0.1:1236", nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1000", w.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.2:1234", nil).Code)
+}
+
+func TestRateLimit_ByHeader(t *testing.T) {
+	handler := rest.RateLimit(0.001, 1, rest.WithRateLimitKeyFunc(rest.RateLimitByHeader("X-Api-Key")))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", map[string]string{"X-Api-Key": "a"}).Code)
+	require.Equal(t, http.StatusTooManyRequests, rateLimitDo(handler, "10.0.0.2:1234", map[string]string{"X-Api-Key": "a"}).Code)
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", map[string]string{"X-Api-Key": "b"}).Code)
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+}
+
+func TestRateLimit_Evict(t *testing.T) {
+	handler := rest.RateLimit(0.001, 1, rest.WithRateLimitMaxKeys(1))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.2:1234", nil).Code)
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+
+	handler = rest.RateLimit(0.001, 1, rest.WithRateLimitIdleTimeout(50*time.Millisecond))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+	time.Sleep(200 * time.Millisecond)
+	require.Equal(t, http.StatusOK, rateLimitDo(handler, "10.0.0.1:1234", nil).Code)
+}
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"encoding/json"
+	"github.com/felixge/httpsnoop"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+var RecentRequestsRoutes = recentRequestsRoutes
+
+// maxRecentRequests bounds memory used by recent requests ring buffer
+const maxRecentRequests = 10000
+
+// RequestSummary summarizes a request for live debugging
+type RequestSummary struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	Duration  string    `json:"duration"`
+	Elapsed   int64     `json:"elapsed"`
+	RequestID string    `json:"requestId,omitempty"`
+	TraceID   string    `json:"traceId,omitempty"`
+}
+
+// requestRing is a fixed size ring buffer. Writers only do an atomic increment and an atomic store,
+// so they never block each other.
+type requestRing struct {
+	next  uint64
+	slots []atomic.Value
+}
+
+func newRequestRing(size int) *requestRing {
+	return &requestRing{
+		slots: make([]atomic.Value, size),
+	}
+}
+
+func (r *requestRing) add(summary RequestSummary) {
+	i := atomic.AddUint64(&r.next, 1) - 1
+	r.slots[i%uint64(len(r.slots))].Store(summary)
+}
+
+// snapshot returns summaries in the buffer, newest first
+func (r *requestRing) snapshot() []RequestSummary {
+	summaries := make([]RequestSummary, 0, len(r.slots))
+	for i := range r.slots {
+		if summary, ok := r.slots[i].Load().(RequestSummary); ok {
+			summaries = append(summaries, summary)
+		}
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Time.After(summaries[j].Time)
+	})
+	return summaries
+}
+
+// currentRing holds *requestRing of the latest RecentRequests middleware
+var currentRing atomic.Value
+
+// RecentRequests keeps summaries of the last size requests in memory, which are exposed by
+// GET /go-doudou/requests endpoint. Query strings are not kept as they may contain credentials.
+func RecentRequests(size int) func(inner http.Handler) http.Handler {
+	if size <= 0 {
+		size = config.DefaultGddRecentRequestsSize
+	}
+	if size > maxRecentRequests {
+		size = maxRecentRequests
+	}
+	ring := newRequestRing(size)
+	currentRing.Store(ring)
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			m := httpsnoop.CaptureMetrics(inner, w, r)
+			ring.add(RequestSummary{
+				Time:      start,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Status:    m.Code,
+				Duration:  m.Duration.String(),
+				Elapsed:   m.Duration.Milliseconds(),
+				RequestID: r.Header.Get("X-Request-ID"),
+				TraceID:   traceIDFromContext(r.Context()),
+			})
+		})
+	}
+}
+
+// RecentRequestsFromConfig returns RecentRequests middleware sized by GddRecentRequestsSize,
+// and false if GddRecentRequestsEnable is not true
+func RecentRequestsFromConfig() (func(inner http.Handler) http.Handler, bool) {
+	if !cast.ToBoolOrDefault(config.GddRecentRequestsEnable.Load(), config.DefaultGddRecentRequestsEnable) {
+		return nil, false
+	}
+	return RecentRequests(cast.ToIntOrDefault(config.GddRecentRequestsSize.Load(), config.DefaultGddRecentRequestsSize)), true
+}
+
+func recentRequestsRoutes() []Route {
+	return []Route{
+		{
+			Name:    "GetRecentRequests",
+			Method:  http.MethodGet,
+			Pattern: "/go-doudou/requests",
+			HandlerFunc: func(_writer http.ResponseWriter, _req *http.Request) {
+				ring, ok := currentRing.Load().(*requestRing)
+				if !ok {
+					writeErrorResponse(_writer, http.StatusNotFound, "recent requests are not enabled, set GDD_RECENT_REQUESTS_ENABLE=true")
+					return
+				}
+				summaries := ring.snapshot()
+				if limit, err := strconv.Atoi(_req.FormValue("limit")); err == nil && limit >= 0 && limit < len(summaries) {
+					summaries = summaries[:limit]
+				}
+				_writer.Header().Set("Content-Type", "application/json; charset=UTF-8")
+				json.NewEncoder(_writer).Encode(summaries)
+			},
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRecentRequests(t *testing.T) {
+	handler := rest.RecentRequests(3)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("OK"))
+	}))
+	for i := 0; i < 4; i++ {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d?token=secret", i), nil)
+		req.Header.Set("X-Request-ID", fmt.Sprintf("rid-%d", i))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/missing", nil))
+
+	route := rest.RecentRequestsRoutes()[0]
+	rr := httptest.NewRecorder()
+	route.HandlerFunc(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/requests", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var summaries []rest.RequestSummary
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 3)
+	require.Equal(t, http.MethodPost, summaries[0].Method)
+	require.Equal(t, "/missing", summaries[0].Path)
+	require.Equal(t, http.StatusNotFound, summaries[0].Status)
+	require.Equal(t, "/users/3", summaries[1].Path)
+	require.Equal(t, "rid-3", summaries[1].RequestID)
+	require.Equal(t, http.StatusOK, summaries[1].Status)
+	require.Equal(t, "/users/2", summaries[2].Path)
+
+	rr = httptest.NewRecorder()
+	route.HandlerFunc(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/requests?limit=1", nil))
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 1)
+}
+
+func TestRecentRequests_Concurrent(t *testing.T) {
+	handler := rest.RecentRequests(10)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+			}
+		}()
+	}
+	wg.Wait()
+	rr := httptest.NewRecorder()
+	rest.RecentRequestsRoutes()[0].HandlerFunc(rr, httptest.NewRequest(http.MethodGet, "/go-doudou/requests", nil))
+	var summaries []rest.RequestSummary
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 10)
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+)
+
+// applyMaintenanceConfig turns maintenance mode on or off according to GddMaintenanceEnable
+func applyMaintenanceConfig() {
+	enable := cast.ToBoolOrDefault(config.GddMaintenanceEnable.Load(), config.DefaultGddMaintenanceEnable)
+	if enable {
+		EnableMaintenance("", 0)
+	} else if GetMaintenanceStatus().Enabled {
+		DisableMaintenance()
+	}
+}
+
+// ReloadConfig reloads hot-reloadable config from local config files and applies them to
+// logger and middleware state. It is called by Run on SIGHUP signal.
+func ReloadConfig() {
+	logger.Info().Msg("Reloading config")
+	for _, key := range config.ReloadLocal() {
+		switch key {
+		case config.GddLogLevel:
+			if err := logger.SetLevel(config.GddLogLevel.LoadOrDefault(config.DefaultGddLogLevel)); err != nil {
+				logger.Error().Err(err).Msgf("fail to reload %s", string(config.GddLogLevel))
+			}
+		case config.GddLogAccessLevel, config.GddMemLogLevel:
+			config.ApplyLogLevels()
+		case config.GddMaintenanceEnable, config.GddMaintenanceMessage, config.GddMaintenanceRetryAfter:
+			applyMaintenanceConfig()
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package rest
+
+import (
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"path"
+	"strings"
+)
+
+// NormalizeRootPath normalizes root path of routes into form of /api by adding leading slash, stripping trailing
+// slash and collapsing duplicate slashes, e.g. api, /api/ and //api all become /api. Empty string is returned for
+// empty or / root path, so it can always be prepended to route patterns.
+func NormalizeRootPath(rootPath string) string {
+	rootPath = strings.TrimSpace(rootPath)
+	if rootPath == "" {
+		return ""
+	}
+	rootPath = path.Clean("/" + rootPath)
+	if rootPath == "/" {
+		return ""
+	}
+	return rootPath
+}
+
+// RouteRootPath returns GddRouteRootPath normalized by NormalizeRootPath, logging the normalized value if it differs
+// from the configured one. Servers call it once at construction.
+func RouteRootPath() string {
+	raw := config.GddRouteRootPath.LoadOrDefault(config.DefaultGddRouteRootPath)
+	rr := NormalizeRootPath(raw)
+	if rr != raw {
+		logger.Info().Msgf("%s %q is normalized to %q", string(config.GddRouteRootPath), raw, rr)
+	}
+	return rr
+}
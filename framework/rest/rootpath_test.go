@@ -0,0 +1,67 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNormalizeRootPath(t *testing.T) {
+	cases := []struct {
+		rootPath string
+		want     string
+		pattern  string
+	}{
+		{rootPath: "api", want: "/api", pattern: "/api/hello"},
+		{rootPath: "/api/", want: "/api", pattern: "/api/hello"},
+		{rootPath: "//api", want: "/api", pattern: "/api/hello"},
+		{rootPath: "", want: "", pattern: "/hello"},
+		{rootPath: "/", want: "", pattern: "/hello"},
+		{rootPath: "api//v1/", want: "/api/v1", pattern: "/api/v1/hello"},
+	}
+	defer os.Unsetenv(string(config.GddRouteRootPath))
+	for _, c := range cases {
+		t.Run(c.rootPath, func(t *testing.T) {
+			require.Equal(t, c.want, rest.NormalizeRootPath(c.rootPath))
+
+			config.GddRouteRootPath.Write(c.rootPath)
+			require.Equal(t, c.want, rest.RouteRootPath())
+			srv := rest.NewRestServer()
+			srv.AddRoute(rest.Route{
+				Name:    "GetHello",
+				Method:  http.MethodGet,
+				Pattern: "/hello",
+			})
+			routes := srv.Routes()
+			require.Len(t, routes, 1)
+			require.Equal(t, c.pattern, routes[0].Pattern)
+		})
+	}
+}
+
+func TestRouteRootPath_WithoutLeadingSlash(t *testing.T) {
+	config.GddPort.Write("6079")
+	config.GddRouteRootPath.Write("api")
+	defer os.Unsetenv(string(config.GddRouteRootPath))
+
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:    "GetHello",
+		Method:  http.MethodGet,
+		Pattern: "/hello",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("hello"))
+		},
+	})
+	go srv.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	resp, err := http.Get("http://localhost:6079/api/hello")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
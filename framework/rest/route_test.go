@@ -0,0 +1,92 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestServer_AddRouteWithMiddleware(t *testing.T) {
+	config.GddPort.Write("6074")
+	var lock sync.Mutex
+	var called []string
+	record := func(name string) func(http.Handler) http.Handler {
+		return func(inner http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				called = append(called, name)
+				lock.Unlock()
+				inner.ServeHTTP(w, r)
+			})
+		}
+	}
+	go func() {
+		srv := rest.NewRestServer()
+		srv.AddMiddleware(record("global"))
+		srv.AddRoute(rest.Route{
+			Name:    "Public",
+			Method:  http.MethodGet,
+			Pattern: "/public",
+			HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("public"))
+			},
+		})
+		srv.AddRouteWithMiddleware(rest.Route{
+			Name:    "Private",
+			Method:  http.MethodGet,
+			Pattern: "/private",
+			HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("private"))
+			},
+		}, record("auth"), record("audit"))
+		srv.AddRouteWithMiddleware(rest.Route{
+			Name:    "Broken",
+			Method:  http.MethodGet,
+			Pattern: "/broken",
+			HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("broken"))
+			},
+		}, func(inner http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("broken middleware")
+			})
+		})
+		srv.Run()
+	}()
+
+	get := func(path string) (int, string) {
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			var err error
+			resp, err = http.Get("http://localhost:6074" + path)
+			return err == nil
+		}, 5*time.Second, 20*time.Millisecond)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	code, body := get("/private")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "private", body)
+	lock.Lock()
+	require.Equal(t, []string{"global", "auth", "audit"}, called)
+	called = nil
+	lock.Unlock()
+
+	code, body = get("/public")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "public", body)
+	lock.Lock()
+	require.Equal(t, []string{"global"}, called)
+	lock.Unlock()
+
+	code, _ = get("/broken")
+	require.Equal(t, http.StatusInternalServerError, code)
+}
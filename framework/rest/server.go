@@ -1,23 +1,20 @@
 package rest
 
 import (
-	"context"
 	"fmt"
 	"github.com/arl/statsviz"
-	"github.com/ascarter/requestid"
-	"github.com/gorilla/handlers"
-	"github.com/klauspost/compress/gzhttp"
 	"github.com/olekukonko/tablewriter"
 	"github.com/rs/cors"
 	"github.com/unionj-cloud/go-doudou/v2/framework"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/banner"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	register "github.com/unionj-cloud/go-doudou/v2/framework/registry"
-	"github.com/unionj-cloud/go-doudou/v2/framework/registry/constants"
 	"github.com/unionj-cloud/go-doudou/v2/framework/rest/httprouter"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/timeutils"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
@@ -25,6 +22,7 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -77,23 +75,43 @@ func init() {
 	}
 }
 
-// MiddlewareFunc is alias for func(http.Handler) http.Handler
-func toMiddlewareFunc(m func(http.Handler) http.HandlerFunc) MiddlewareFunc {
-	return func(handler http.Handler) http.Handler {
-		return m(handler)
-	}
-}
-
 // RestServer wraps httpRouter router
 type RestServer struct {
-	bizRouter    *httprouter.RouteGroup
-	rootRouter   *httprouter.Router
-	gddRoutes    []Route
-	debugRoutes  []Route
-	bizRoutes    []Route
-	middlewares  []MiddlewareFunc
+	bizRouter   *httprouter.RouteGroup
+	rootRouter  *httprouter.Router
+	gddRoutes   []Route
+	debugRoutes []Route
+	bizRoutes   []Route
+	middlewares []MiddlewareFunc
+	// middlewareNames holds names of middlewares at the same index, empty for user middlewares
+	middlewareNames []string
+	// insertions are applied by Run after middlewares added by Run
+	insertions []middlewareInsertion
+	// rootPath is GddRouteRootPath normalized by NormalizeRootPath
+	rootPath string
+	// mounts are handlers registered by Mount
+	mounts       []mount
 	data         map[string]interface{}
 	panicHandler func(inner http.Handler) http.Handler
+	// notFound and methodNotAllowed default to NotFound and MethodNotAllowed
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+}
+
+// Routes returns registered routes with patterns prefixed by route root path, as printed in routes table on startup.
+// Management and debug routes are included only after Run.
+func (srv *RestServer) Routes() []Route {
+	var all []Route
+	all = append(all, srv.bizRoutes...)
+	all = append(all, srv.mountRoutes()...)
+	routes := make([]Route, 0, len(all)+len(srv.gddRoutes)+len(srv.debugRoutes))
+	for _, r := range all {
+		r.Pattern = path.Clean(srv.rootPath + r.Pattern)
+		routes = append(routes, r)
+	}
+	routes = append(routes, srv.gddRoutes...)
+	routes = append(routes, srv.debugRoutes...)
+	return routes
 }
 
 func (srv *RestServer) printRoutes() {
@@ -102,20 +120,8 @@ func (srv *RestServer) printRoutes() {
 	}
 	logger.Info().Msg("================ Registered Routes ================")
 	data := [][]string{}
-	rr := config.DefaultGddRouteRootPath
-	if stringutils.IsNotEmpty(config.GddRouteRootPath.Load()) {
-		rr = config.GddRouteRootPath.Load()
-	}
-	var all []Route
-	all = append(all, srv.bizRoutes...)
-	all = append(all, srv.gddRoutes...)
-	all = append(all, srv.debugRoutes...)
-	for _, r := range all {
-		if strings.HasPrefix(r.Pattern, gddPathPrefix) || strings.HasPrefix(r.Pattern, debugPathPrefix) {
-			data = append(data, []string{r.Name, r.Method, r.Pattern})
-		} else {
-			data = append(data, []string{r.Name, r.Method, path.Clean(rr + r.Pattern)})
-		}
+	for _, r := range srv.Routes() {
+		data = append(data, []string{r.Name, r.Method, r.Pattern})
 	}
 
 	tableString := &strings.Builder{}
@@ -134,40 +140,16 @@ func (srv *RestServer) printRoutes() {
 
 // NewRestServer create a RestServer instance
 func NewRestServer(data ...map[string]interface{}) *RestServer {
-	rr := config.DefaultGddRouteRootPath
-	if stringutils.IsNotEmpty(config.GddRouteRootPath.Load()) {
-		rr = config.GddRouteRootPath.Load()
-	}
-	if stringutils.IsEmpty(rr) {
-		rr = "/"
-	}
+	rr := RouteRootPath()
 	rootRouter := httprouter.New()
 	rootRouter.SaveMatchedRoutePath = cast.ToBoolOrDefault(config.GddRouterSaveMatchedRoutePath.Load(), config.DefaultGddRouterSaveMatchedRoutePath)
 	srv := &RestServer{
-		bizRouter:    rootRouter.NewGroup(rr),
+		bizRouter:    rootRouter.NewGroup(rr + "/"),
 		rootRouter:   rootRouter,
+		rootPath:     rr,
 		panicHandler: recovery,
 	}
-	srv.middlewares = append(srv.middlewares,
-		tracing,
-		metrics,
-		gzipBody,
-	)
-	if cast.ToBoolOrDefault(config.GddEnableResponseGzip.Load(), config.DefaultGddEnableResponseGzip) {
-		gzipMiddleware, err := gzhttp.NewWrapper(gzhttp.ContentTypes(contentTypeShouldbeGzip))
-		if err != nil {
-			panic(err)
-		}
-		srv.middlewares = append(srv.middlewares, toMiddlewareFunc(gzipMiddleware))
-	}
-	if cast.ToBoolOrDefault(config.GddLogReqEnable.Load(), config.DefaultGddLogReqEnable) {
-		srv.middlewares = append(srv.middlewares, log)
-	}
-	srv.middlewares = append(srv.middlewares,
-		requestid.RequestIDHandler,
-		handlers.ProxyHeaders,
-		fallbackContentType(config.GddFallbackContentType.LoadOrDefault(config.DefaultGddFallbackContentType)),
-	)
+	srv.useBuiltinMiddlewares()
 	if len(data) > 0 {
 		srv.data = data[0]
 	}
@@ -190,43 +172,19 @@ func WithUserData(userData map[string]interface{}) ServerOption {
 
 // NewRestServerWithOptions create a RestServer instance with options
 func NewRestServerWithOptions(options ...ServerOption) *RestServer {
-	rr := config.DefaultGddRouteRootPath
-	if stringutils.IsNotEmpty(config.GddRouteRootPath.Load()) {
-		rr = config.GddRouteRootPath.Load()
-	}
-	if stringutils.IsEmpty(rr) {
-		rr = "/"
-	}
+	rr := RouteRootPath()
 	rootRouter := httprouter.New()
 	rootRouter.SaveMatchedRoutePath = cast.ToBoolOrDefault(config.GddRouterSaveMatchedRoutePath.Load(), config.DefaultGddRouterSaveMatchedRoutePath)
 	srv := &RestServer{
-		bizRouter:    rootRouter.NewGroup(rr),
+		bizRouter:    rootRouter.NewGroup(rr + "/"),
 		rootRouter:   rootRouter,
+		rootPath:     rr,
 		panicHandler: recovery,
 	}
 	for _, fn := range options {
 		fn(srv)
 	}
-	srv.middlewares = append(srv.middlewares,
-		tracing,
-		metrics,
-		gzipBody,
-	)
-	if cast.ToBoolOrDefault(config.GddEnableResponseGzip.Load(), config.DefaultGddEnableResponseGzip) {
-		gzipMiddleware, err := gzhttp.NewWrapper(gzhttp.ContentTypes(contentTypeShouldbeGzip))
-		if err != nil {
-			panic(err)
-		}
-		srv.middlewares = append(srv.middlewares, toMiddlewareFunc(gzipMiddleware))
-	}
-	if cast.ToBoolOrDefault(config.GddLogReqEnable.Load(), config.DefaultGddLogReqEnable) {
-		srv.middlewares = append(srv.middlewares, log)
-	}
-	srv.middlewares = append(srv.middlewares,
-		requestid.RequestIDHandler,
-		handlers.ProxyHeaders,
-		fallbackContentType(config.GddFallbackContentType.LoadOrDefault(config.DefaultGddFallbackContentType)),
-	)
+	srv.useBuiltinMiddlewares()
 	return srv
 }
 
@@ -235,10 +193,33 @@ func (srv *RestServer) AddRoute(route ...Route) {
 	srv.bizRoutes = append(srv.bizRoutes, route...)
 }
 
+// AddRouteWithMiddleware adds a route with middlewares applying to it only. Middlewares of a request to the route are
+// called in order of recovery and built-in middlewares, global middlewares added by AddMiddleware and PreMiddleware,
+// then middlewares of the route, so per-route middlewares are innermost, e.g. they can read values put into request
+// context by global middlewares. Panics raised by them are recovered as well.
+func (srv *RestServer) AddRouteWithMiddleware(route Route, mwf ...func(http.Handler) http.Handler) {
+	for _, item := range mwf {
+		route.Middlewares = append(route.Middlewares, item)
+	}
+	srv.bizRoutes = append(srv.bizRoutes, route)
+}
+
+// SetNotFoundHandler replaces NotFound as handler for requests not matching any route. It is wrapped by middlewares
+// the same as NotFound. It should be called before Run.
+func (srv *RestServer) SetNotFoundHandler(handler http.Handler) {
+	srv.notFound = handler
+}
+
+// SetMethodNotAllowedHandler replaces MethodNotAllowed as handler for requests matching a route but not its method.
+// It is wrapped by middlewares the same as MethodNotAllowed. It should be called before Run.
+func (srv *RestServer) SetMethodNotAllowedHandler(handler http.Handler) {
+	srv.methodNotAllowed = handler
+}
+
 // AddMiddleware adds middlewares to the end of chain
 func (srv *RestServer) AddMiddleware(mwf ...func(http.Handler) http.Handler) {
 	for _, item := range mwf {
-		srv.middlewares = append(srv.middlewares, item)
+		srv.use("", item)
 	}
 }
 
@@ -249,9 +230,10 @@ func (srv *RestServer) PreMiddleware(mwf ...func(http.Handler) http.Handler) {
 		middlewares = append(middlewares, item)
 	}
 	srv.middlewares = append(middlewares, srv.middlewares...)
+	srv.middlewareNames = append(make([]string, len(middlewares)), srv.middlewareNames...)
 }
 
-func (srv *RestServer) newHttpServer() *http.Server {
+func (srv *RestServer) newHttpServer(ln net.Listener) (*http.Server, *ConnTracker, HTTP3Server) {
 	write, err := time.ParseDuration(config.GddWriteTimeout.Load())
 	if err != nil {
 		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddWriteTimeout),
@@ -287,28 +269,76 @@ func (srv *RestServer) newHttpServer() *http.Server {
 		WriteTimeout: write,
 		ReadTimeout:  read,
 		IdleTimeout:  idle,
-		Handler:      srv.rootRouter, // Pass our instance of httprouter.Router in.
+		// Wrap the whole router with panic handler as the outermost layer, so that panics raised by any middleware
+		// of biz routes, gdd routes, debug routes or not found and method not allowed handlers are recovered too.
+		Handler: srv.panicHandler(srv.rootRouter),
+		// keep peer address of connections for ClientIP, as r.RemoteAddr is rewritten by handlers.ProxyHeaders
+		ConnContext: ConnContext,
+	}
+	certFile := config.GddTLSCert.LoadOrDefault(config.DefaultGddTLSCert)
+	keyFile := config.GddTLSKey.LoadOrDefault(config.DefaultGddTLSKey)
+	useTLS := TLSEnabled()
+	if useTLS {
+		if httpServer.TLSConfig, err = newTLSConfig(); err != nil {
+			panic(err)
+		}
+	}
+	if err = EnableH2C(httpServer); err != nil {
+		panic(err)
 	}
 
+	if ln == nil {
+		return httpServer, nil, nil
+	}
+	h3, err := NewHTTP3Server(httpServer)
+	if err != nil {
+		logger.Error().Err(err).Msg("[go-doudou] experimental HTTP/3 server is not started")
+	}
+	if h3 != nil {
+		ServeHTTP3(h3)
+	}
+	tracker := NewConnTracker(ln)
+
 	// Run our server in a goroutine so that it doesn't block.
 	go func() {
 		logger.Info().Msgf("Http server is listening at %v", httpServer.Addr)
 		logger.Info().Msgf("Http server started in %s", time.Since(startAt))
-		if err := httpServer.ListenAndServe(); err != nil {
+		var err error
+		if useTLS {
+			err = httpServer.ServeTLS(tracker, certFile, keyFile)
+		} else {
+			err = httpServer.Serve(tracker)
+		}
+		if err != nil {
 			logger.Error().Err(err).Msg("")
 		}
 	}()
 
-	return httpServer
+	return httpServer, tracker, h3
 }
 
 // Run runs http server
 func (srv *RestServer) Run() {
 	banner.Print()
-	register.NewRest(srv.data)
-	manage := cast.ToBoolOrDefault(config.GddManage.Load(), config.DefaultGddManage)
+	// check tls files before registering service, otherwise clients may be routed to a node failing every request
+	if err := CheckTLS(); err != nil {
+		logger.Panic().Err(err).Msg("")
+	}
+	// bind port before registering service, as the port may be changed if GddBindRetry is enabled
+	ln, err := Listen()
+	if err != nil {
+		// nothing can be served without a listener, and service must not be registered
+		logger.Panic().Err(err).Msg("")
+	}
+	if err = register.NewRest(srv.data); err != nil {
+		ln.Close()
+		logger.Panic().Err(err).Msg("")
+	}
+	applyMaintenanceConfig()
+	manage := manageEnabled()
 	if manage {
 		srv.middlewares = append([]MiddlewareFunc{PrometheusMiddleware}, srv.middlewares...)
+		srv.middlewareNames = append([]string{MiddlewarePrometheus}, srv.middlewareNames...)
 		gddRouter := srv.rootRouter.NewGroup(gddPathPrefix)
 		corsOpts := cors.New(cors.Options{
 			AllowedMethods: []string{
@@ -332,14 +362,13 @@ func (srv *RestServer) Run() {
 				return false
 			},
 		})
-		basicAuthMiddle := MiddlewareFunc(basicAuth())
-		gddmiddlewares := []MiddlewareFunc{metrics, corsOpts.Handler, basicAuthMiddle}
-		srv.gddRoutes = append(srv.gddRoutes, docRoutes()...)
-		srv.gddRoutes = append(srv.gddRoutes, promRoutes()...)
-		srv.gddRoutes = append(srv.gddRoutes, configRoutes()...)
-		if _, ok := config.ServiceDiscoveryMap()[constants.SD_MEMBERLIST]; ok {
-			srv.gddRoutes = append(srv.gddRoutes, MemberlistUIRoutes()...)
+		allowIPs, err := ManageAllowIPsFromConfig()
+		if err != nil {
+			logger.Panic().Err(err).Msg("")
 		}
+		basicAuthMiddle := MiddlewareFunc(basicAuth())
+		gddmiddlewares := []MiddlewareFunc{metrics, corsOpts.Handler, allowIPs, basicAuthMiddle}
+		srv.gddRoutes = append(srv.gddRoutes, manageRoutes()...)
 		freq, err := time.ParseDuration(config.GddStatsFreq.Load())
 		if err != nil {
 			logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddStatsFreq),
@@ -347,25 +376,27 @@ func (srv *RestServer) Run() {
 			freq, _ = time.ParseDuration(config.DefaultGddStatsFreq)
 		}
 		_ = freq
-		srv.gddRoutes = append(srv.gddRoutes, []Route{
-			{
-				Name:    "GetStatsvizWs",
-				Method:  http.MethodGet,
-				Pattern: gddPathPrefix + "statsviz/ws",
-			},
-			{
-				Name:    "GetStatsviz",
-				Method:  http.MethodGet,
-				Pattern: gddPathPrefix + "statsviz/*",
-				HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
-					if strings.HasSuffix(request.URL.Path, "/ws") {
-						statsviz.Ws(writer, request)
-						return
-					}
-					statsviz.IndexAtRoot(gddPathPrefix+"statsviz/").ServeHTTP(writer, request)
+		if ManageStatsvizEnabled() {
+			srv.gddRoutes = append(srv.gddRoutes, []Route{
+				{
+					Name:    "GetStatsvizWs",
+					Method:  http.MethodGet,
+					Pattern: gddPathPrefix + "statsviz/ws",
 				},
-			},
-		}...)
+				{
+					Name:    "GetStatsviz",
+					Method:  http.MethodGet,
+					Pattern: gddPathPrefix + "statsviz/*",
+					HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
+						if strings.HasSuffix(request.URL.Path, "/ws") {
+							statsviz.Ws(writer, request)
+							return
+						}
+						statsviz.IndexAtRoot(gddPathPrefix+"statsviz/").ServeHTTP(writer, request)
+					},
+				},
+			}...)
+		}
 		for _, item := range srv.gddRoutes {
 			if item.HandlerFunc == nil {
 				continue
@@ -376,51 +407,53 @@ func (srv *RestServer) Run() {
 			}
 			gddRouter.Handler(item.Method, "/"+strings.TrimPrefix(item.Pattern, gddPathPrefix), h, item.Name)
 		}
-		srv.debugRoutes = append(srv.debugRoutes, []Route{
-			{
-				Name:    "GetDebugPprofCmdline",
-				Method:  http.MethodGet,
-				Pattern: debugPathPrefix + "pprof/cmdline",
-			},
-			{
-				Name:    "GetDebugPprofProfile",
-				Method:  http.MethodGet,
-				Pattern: debugPathPrefix + "pprof/profile",
-			},
-			{
-				Name:    "GetDebugPprofSymbol",
-				Method:  http.MethodGet,
-				Pattern: debugPathPrefix + "pprof/symbol",
-			},
-			{
-				Name:    "GetDebugPprofTrace",
-				Method:  http.MethodGet,
-				Pattern: debugPathPrefix + "pprof/trace",
-			},
-			{
-				Name:    "GetDebugPprofIndex",
-				Method:  http.MethodGet,
-				Pattern: debugPathPrefix + "pprof/*",
-				HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
-					lastSegment := request.URL.Path[strings.LastIndex(request.URL.Path, "/"):]
-					switch lastSegment {
-					case "/cmdline":
-						pprof.Cmdline(writer, request)
-						return
-					case "/profile":
-						pprof.Profile(writer, request)
-						return
-					case "/symbol":
-						pprof.Symbol(writer, request)
-						return
-					case "/trace":
-						pprof.Trace(writer, request)
-						return
-					}
-					pprof.Index(writer, request)
+		if ManagePprofEnabled() {
+			srv.debugRoutes = append(srv.debugRoutes, []Route{
+				{
+					Name:    "GetDebugPprofCmdline",
+					Method:  http.MethodGet,
+					Pattern: debugPathPrefix + "pprof/cmdline",
 				},
-			},
-		}...)
+				{
+					Name:    "GetDebugPprofProfile",
+					Method:  http.MethodGet,
+					Pattern: debugPathPrefix + "pprof/profile",
+				},
+				{
+					Name:    "GetDebugPprofSymbol",
+					Method:  http.MethodGet,
+					Pattern: debugPathPrefix + "pprof/symbol",
+				},
+				{
+					Name:    "GetDebugPprofTrace",
+					Method:  http.MethodGet,
+					Pattern: debugPathPrefix + "pprof/trace",
+				},
+				{
+					Name:    "GetDebugPprofIndex",
+					Method:  http.MethodGet,
+					Pattern: debugPathPrefix + "pprof/*",
+					HandlerFunc: func(writer http.ResponseWriter, request *http.Request) {
+						lastSegment := request.URL.Path[strings.LastIndex(request.URL.Path, "/"):]
+						switch lastSegment {
+						case "/cmdline":
+							pprof.Cmdline(writer, request)
+							return
+						case "/profile":
+							pprof.Profile(writer, request)
+							return
+						case "/symbol":
+							pprof.Symbol(writer, request)
+							return
+						case "/trace":
+							pprof.Trace(writer, request)
+							return
+						}
+						pprof.Index(writer, request)
+					},
+				},
+			}...)
+		}
 		debugRouter := srv.rootRouter.NewGroup(debugPathPrefix)
 		for _, item := range srv.debugRoutes {
 			if item.HandlerFunc == nil {
@@ -433,47 +466,80 @@ func (srv *RestServer) Run() {
 			debugRouter.Handler(item.Method, "/"+strings.TrimPrefix(item.Pattern, debugPathPrefix), h, item.Name)
 		}
 	}
-	srv.middlewares = append(srv.middlewares, srv.panicHandler)
+	if mutualTLSEnabled() {
+		srv.use(MiddlewareMutualTLS, MutualTLS(clientAuthRequired()))
+	}
+	srv.use(MiddlewareTrackInFlight, TrackInFlight)
+	srv.use(MiddlewareMaintenance, Maintenance)
+	srv.use(MiddlewareRecovery, srv.panicHandler)
+	if err := srv.applyInsertions(); err != nil {
+		logger.Panic().Err(err).Msg("")
+	}
+	rr := srv.rootPath
+	routes := make(map[string]Route, len(srv.bizRoutes))
+	for _, item := range srv.bizRoutes {
+		item.Pattern = path.Clean(rr + item.Pattern)
+		routes[item.Name] = item
+	}
 	for _, item := range srv.bizRoutes {
-		h := http.Handler(item.HandlerFunc)
+		h := item.Handler()
 		for i := len(srv.middlewares) - 1; i >= 0; i-- {
 			h = srv.middlewares[i].Middleware(h)
 		}
+		if item.MaxBodySize != 0 {
+			h = withBodyLimit(h, item.MaxBodySize)
+		}
+		if item.Timeout != 0 {
+			h = withHandlerTimeout(h, item.Timeout)
+		}
+		h = withRoutePattern(h, path.Clean(rr+item.Pattern))
+		h = withBatchRoutes(h, routes)
 		srv.bizRouter.Handler(item.Method, item.Pattern, h, item.Name)
 	}
-	srv.rootRouter.NotFound = http.HandlerFunc(http.NotFound)
-	srv.rootRouter.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		w.Write([]byte("405 method not allowed"))
-	})
+	srv.registerMounts(rr)
+	srv.rootRouter.NotFound = http.HandlerFunc(NotFound)
+	if srv.notFound != nil {
+		srv.rootRouter.NotFound = srv.notFound
+	}
+	srv.rootRouter.MethodNotAllowed = http.HandlerFunc(MethodNotAllowed)
+	if srv.methodNotAllowed != nil {
+		srv.rootRouter.MethodNotAllowed = srv.methodNotAllowed
+	}
 	for i := len(srv.middlewares) - 1; i >= 0; i-- {
 		srv.rootRouter.NotFound = srv.middlewares[i].Middleware(srv.rootRouter.NotFound)
 		srv.rootRouter.MethodNotAllowed = srv.middlewares[i].Middleware(srv.rootRouter.MethodNotAllowed)
 	}
 	srv.printRoutes()
-	httpServer := srv.newHttpServer()
+	srv.writeRouteManifestFile()
+	httpServer, tracker, h3 := srv.newHttpServer(ln)
 	defer func() {
-		register.ShutdownRest()
-		grace, err := time.ParseDuration(config.GddGraceTimeout.Load())
-		if err != nil {
-			logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddGraceTimeout),
-				config.GddGraceTimeout.Load(), err.Error(), config.DefaultGddGraceTimeout)
-			grace, _ = time.ParseDuration(config.DefaultGddGraceTimeout)
-		}
-		logger.Info().Msgf("Http server is gracefully shutting down in %s", grace)
-
-		ctx, cancel := context.WithTimeout(context.Background(), grace)
+		ctx, cancel := register.ShutdownContext()
 		defer cancel()
-		// Doesn't block if no connections, but will otherwise wait
-		// until the timeout deadline.
-		httpServer.Shutdown(ctx)
+		logger.Info().Msg("[go-doudou] shutdown: leaving service registries")
+		if err := timeutils.CallWithCtx(ctx, func() struct{} {
+			register.ShutdownRest()
+			return struct{}{}
+		}); err != nil {
+			logger.Error().Err(err).Msg("[go-doudou] shutdown: leaving service registries timed out")
+		}
+		register.WaitLeavePropagation(ctx)
+		h3Done := GracefulShutdownHTTP3(ctx, h3)
+		GracefulShutdown(ctx, httpServer, tracker)
+		<-h3Done
 	}()
 
 	c := make(chan os.Signal, 1)
-	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-	signal.Notify(c, os.Interrupt)
+	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C) or SIGTERM (e.g. sent by kubernetes).
+	// SIGKILL or SIGQUIT (Ctrl+/) will not be caught.
+	// SIGHUP reloads hot-reloadable config without restarting.
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	// Block until we receive our signal.
-	<-c
+	for sig := range c {
+		if sig == syscall.SIGHUP {
+			ReloadConfig()
+			continue
+		}
+		break
+	}
 }
@@ -0,0 +1,79 @@
+package rest_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestRestServer_SigtermHelper is not a real test. It runs a rest server in a child process started by TestRestServer_Sigterm.
+func TestRestServer_SigtermHelper(t *testing.T) {
+	if os.Getenv("GDD_TEST_SIGTERM_HELPER") != "1" {
+		t.Skip("helper process")
+	}
+	srv := rest.NewRestServer()
+	srv.AddRoute(Routes(NewMocksvcHandler())...)
+	srv.Run()
+	fmt.Println("server stopped")
+	os.Exit(0)
+}
+
+func TestRestServer_Sigterm(t *testing.T) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRestServer_SigtermHelper$")
+	// don't inherit config written by other tests
+	for _, item := range os.Environ() {
+		if !strings.HasPrefix(item, "GDD_") {
+			cmd.Env = append(cmd.Env, item)
+		}
+	}
+	cmd.Env = append(cmd.Env,
+		"GDD_TEST_SIGTERM_HELPER=1",
+		fmt.Sprintf("%s=%d", string(config.GddPort), port),
+		string(config.GddGraceTimeout)+"=1s",
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	require.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 10*time.Second, 20*time.Millisecond)
+	// give Run a moment to start listening to signals after the port is bound
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err = <-done:
+		require.NoError(t, err, output.String())
+		require.Contains(t, output.String(), "server stopped")
+	case <-time.After(10 * time.Second):
+		cmd.Process.Kill()
+		<-done
+		t.Fatalf("server didn't exit on SIGTERM: %s", output.String())
+	}
+}
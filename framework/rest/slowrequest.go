@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"github.com/felixge/httpsnoop"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SlowRequestDetail controls how much detail slow request log contains
+type SlowRequestDetail string
+
+const (
+	// SlowRequestBasic logs method, path, status code, duration, remote address, request id and trace id
+	SlowRequestBasic SlowRequestDetail = "basic"
+	// SlowRequestFull also logs query params, a subset of request headers, response size and duration breakdown
+	SlowRequestFull SlowRequestDetail = "full"
+)
+
+// SlowRequests logs requests taking longer than threshold at warn level, no matter whether request logging
+// is enabled. In SlowRequestFull detail level, only request headers listed in headers are logged,
+// so that credentials like Authorization or Cookie never leak into logs.
+func SlowRequests(threshold time.Duration, detail SlowRequestDetail, headers ...string) func(inner http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				once        sync.Once
+				firstByteAt time.Time
+			)
+			markFirstByte := func() {
+				once.Do(func() {
+					firstByteAt = time.Now()
+				})
+			}
+			start := time.Now()
+			m := httpsnoop.CaptureMetricsFn(w, func(ww http.ResponseWriter) {
+				inner.ServeHTTP(httpsnoop.Wrap(ww, httpsnoop.Hooks{
+					WriteHeader: func(next httpsnoop.WriteHeaderFunc) httpsnoop.WriteHeaderFunc {
+						return func(code int) {
+							markFirstByte()
+							next(code)
+						}
+					},
+					Write: func(next httpsnoop.WriteFunc) httpsnoop.WriteFunc {
+						return func(b []byte) (int, error) {
+							markFirstByte()
+							return next(b)
+						}
+					},
+				}), r)
+			})
+			if m.Duration < threshold {
+				return
+			}
+			event := accessLogger.Warn().
+				Str("remoteAddr", r.RemoteAddr).
+				Str("httpMethod", r.Method).
+				Str("path", r.URL.Path).
+				Int("statusCode", m.Code).
+				Str("elapsedTime", m.Duration.String()).
+				Int64("elapsed", m.Duration.Milliseconds()).
+				Str("requestId", r.Header.Get("X-Request-ID")).
+				Str("traceId", traceIDFromContext(r.Context()))
+			if detail == SlowRequestFull {
+				reqHeader := make(map[string]string)
+				for _, key := range headers {
+					if value := r.Header.Get(key); value != "" {
+						reqHeader[http.CanonicalHeaderKey(key)] = value
+					}
+				}
+				handleTime := m.Duration
+				if !firstByteAt.IsZero() {
+					handleTime = firstByteAt.Sub(start)
+				}
+				event = event.
+					Interface("reqQuery", r.URL.Query()).
+					Interface("reqHeader", reqHeader).
+					Int64("respContentLength", m.Written).
+					Str("handleTime", handleTime.String()).
+					Str("writeTime", (m.Duration - handleTime).String())
+			}
+			event.Msgf("[go-doudou] slow request %s %s took %s, exceeding threshold %s", r.Method, r.URL.Path, m.Duration, threshold)
+		})
+	}
+}
+
+// SlowRequestsFromConfig returns SlowRequests middleware configured by GddSlowRequestThreshold, GddSlowRequestDetail
+// and GddSlowRequestHeaders, and false if GddSlowRequestThreshold is not set to a positive duration
+func SlowRequestsFromConfig() (func(inner http.Handler) http.Handler, bool) {
+	threshold, err := time.ParseDuration(config.GddSlowRequestThreshold.LoadOrDefault(config.DefaultGddSlowRequestThreshold))
+	if err != nil || threshold <= 0 {
+		return nil, false
+	}
+	detail := SlowRequestDetail(strings.ToLower(config.GddSlowRequestDetail.LoadOrDefault(config.DefaultGddSlowRequestDetail)))
+	if detail != SlowRequestFull {
+		detail = SlowRequestBasic
+	}
+	var headers []string
+	for _, item := range strings.Split(config.GddSlowRequestHeaders.LoadOrDefault(config.DefaultGddSlowRequestHeaders), ",") {
+		if item = strings.TrimSpace(item); stringutils.IsNotEmpty(item) {
+			headers = append(headers, item)
+		}
+	}
+	return SlowRequests(threshold, detail, headers...), true
+}
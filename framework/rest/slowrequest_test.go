@@ -0,0 +1,69 @@
+package rest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlowRequests(t *testing.T) {
+	origin := logger.Logger
+	defer func() {
+		logger.Logger = origin
+	}()
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	handler := rest.SlowRequests(50*time.Millisecond, rest.SlowRequestFull, "User-Agent")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(60 * time.Millisecond)
+		}
+		w.Write([]byte("OK"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	require.Zero(t, buf.Len())
+
+	req := httptest.NewRequest(http.MethodGet, "/slow?name=jack", nil)
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "warn", entry["level"])
+	require.Equal(t, "/slow", entry["path"])
+	require.EqualValues(t, http.StatusOK, entry["statusCode"])
+	require.EqualValues(t, 2, entry["respContentLength"])
+	require.Equal(t, map[string]interface{}{"name": []interface{}{"jack"}}, entry["reqQuery"])
+	require.Equal(t, map[string]interface{}{"User-Agent": "test"}, entry["reqHeader"])
+	require.Contains(t, entry, "handleTime")
+	require.NotContains(t, buf.String(), "secret")
+}
+
+func TestSlowRequests_Basic(t *testing.T) {
+	origin := logger.Logger
+	defer func() {
+		logger.Logger = origin
+	}()
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	handler := rest.SlowRequests(0, rest.SlowRequestBasic, "User-Agent")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/users?name=jack", nil)
+	req.Header.Set("X-Request-ID", "rid")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.EqualValues(t, http.StatusNoContent, entry["statusCode"])
+	require.Equal(t, "rid", entry["requestId"])
+	require.NotContains(t, entry, "reqQuery")
+	require.NotContains(t, entry, "reqHeader")
+}
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// TenantHeader is the http header carrying tenant id
+const TenantHeader = "X-Tenant-Id"
+
+// DefaultTenantPattern is the default format of tenant id used by Tenant middleware
+var DefaultTenantPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type tenantKey struct{}
+
+// NewTenantContext returns a copy of ctx carrying tenantID, which will be propagated to downstream services
+// by generated http clients
+func NewTenantContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns tenant id put into ctx by Tenant middleware or NewTenantContext
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// pathMatcher returns a function reporting whether a path matches any of paths.
+// A path ending with * matches all paths with the prefix before *.
+func pathMatcher(paths []string) func(path string) bool {
+	return func(path string) bool {
+		for _, item := range paths {
+			if strings.HasSuffix(item, "*") {
+				if strings.HasPrefix(path, strings.TrimSuffix(item, "*")) {
+					return true
+				}
+				continue
+			}
+			if path == item {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Tenant validates X-Tenant-Id header against pattern and puts it into request context.
+// Requests without valid tenant id will be rejected with 400 status code except those to exemptPaths.
+// An exempt path ending with * matches all paths with the prefix before *, e.g. /public/*.
+// If pattern is nil, DefaultTenantPattern will be used.
+func Tenant(pattern *regexp.Regexp, exemptPaths ...string) func(inner http.Handler) http.Handler {
+	if pattern == nil {
+		pattern = DefaultTenantPattern
+	}
+	exempt := pathMatcher(exemptPaths)
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID := r.Header.Get(TenantHeader)
+			if tenantID != "" && pattern.MatchString(tenantID) {
+				inner.ServeHTTP(w, r.WithContext(NewTenantContext(r.Context(), tenantID)))
+				return
+			}
+			if exempt(r.URL.Path) {
+				inner.ServeHTTP(w, r)
+				return
+			}
+			msg := fmt.Sprintf("missing %s header", TenantHeader)
+			if tenantID != "" {
+				msg = fmt.Sprintf("invalid %s header", TenantHeader)
+			}
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Code:    1,
+				Message: msg,
+			})
+		})
+	}
+}
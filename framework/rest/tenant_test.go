@@ -0,0 +1,62 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	var tenantID string
+	handler := rest.Tenant(nil, "/health", "/public/*")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, _ = rest.TenantFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(rest.TenantHeader, "tenant-1")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "tenant-1", tenantID)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "missing X-Tenant-Id header")
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(rest.TenantHeader, "tenant 1;drop")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid X-Tenant-Id header")
+
+	for _, path := range []string{"/health", "/public/doc"} {
+		tenantID = "unchanged"
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "", tenantID)
+	}
+}
+
+func TestTenant_CustomPattern(t *testing.T) {
+	handler := rest.Tenant(regexp.MustCompile(`^t\d+$`))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(rest.TenantHeader, "t100")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(rest.TenantHeader, "tenant-1")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,136 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type handlerTimeoutKey struct{}
+
+// withHandlerTimeout overrides GddHandlerTimeout for requests handled by inner
+func withHandlerTimeout(inner http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), handlerTimeoutKey{}, timeout)))
+	})
+}
+
+// handlerTimeout returns max processing time of the request, which is Timeout of the matched route if set,
+// otherwise GddHandlerTimeout. Non-positive value means no timeout.
+func handlerTimeout(r *http.Request) time.Duration {
+	if timeout, ok := r.Context().Value(handlerTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return defaultHandlerTimeout()
+}
+
+// defaultHandlerTimeout returns GddHandlerTimeout
+func defaultHandlerTimeout() time.Duration {
+	timeout, err := time.ParseDuration(config.GddHandlerTimeout.LoadOrDefault(config.DefaultGddHandlerTimeout))
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as time.Duration failed: %s, use default %s instead.\n", string(config.GddHandlerTimeout),
+			config.GddHandlerTimeout.Load(), err.Error(), config.DefaultGddHandlerTimeout)
+		timeout, _ = time.ParseDuration(config.DefaultGddHandlerTimeout)
+	}
+	return timeout
+}
+
+// timeoutWriter buffers response of handler, so that it can be discarded once the request timed out
+type timeoutWriter struct {
+	mu          sync.Mutex
+	h           http.Header
+	buf         bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+// HandlerTimeout responds 503 with json body if handling a request takes longer than GddHandlerTimeout or Timeout
+// of the matched route, like http.TimeoutHandler. Context of the request is canceled on timeout, so that handlers
+// calling downstream services or databases with it can stop early, and writes after timeout return
+// http.ErrHandlerTimeout. Panics of handlers are still caught by the recovery middleware, which runs inside it.
+// Response is buffered until handler returns, so streaming routes like server-sent events should opt out
+// by negative Timeout. If the client disconnects or context of the request is done for other reasons before timeout,
+// nothing is written, as no one is waiting for the response.
+func HandlerTimeout(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := handlerTimeout(r)
+		if timeout <= 0 {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		parent := r.Context()
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+		done := make(chan struct{})
+		panicChan := make(chan interface{}, 1)
+		tw := &timeoutWriter{h: make(http.Header)}
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+			}()
+			inner.ServeHTTP(tw, r)
+			close(done)
+		}()
+		select {
+		case p := <-panicChan:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			dst := w.Header()
+			for k, vv := range tw.h {
+				dst[k] = vv
+			}
+			if !tw.wroteHeader {
+				tw.code = http.StatusOK
+			}
+			w.WriteHeader(tw.code)
+			w.Write(tw.buf.Bytes())
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			if ctx.Err() == context.DeadlineExceeded && parent.Err() == nil {
+				writeErrorResponse(w, http.StatusServiceUnavailable, "handler timeout")
+			}
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandlerTimeout(t *testing.T) {
+	config.GddPort.Write("6081")
+	defer config.GddPort.Write(strconv.Itoa(config.DefaultGddPort))
+	config.GddHandlerTimeout.Write("50ms")
+	defer os.Unsetenv(string(config.GddHandlerTimeout))
+
+	var canceled int32
+	srv := rest.NewRestServer()
+	srv.AddRoute(rest.Route{
+		Name:    "GetSlow",
+		Method:  http.MethodGet,
+		Pattern: "/slow",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+				atomic.StoreInt32(&canceled, 1)
+			case <-time.After(time.Second):
+			}
+			w.Write([]byte("slow"))
+		},
+	}, rest.Route{
+		Name:    "GetFast",
+		Method:  http.MethodGet,
+		Pattern: "/fast",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Fast", "true")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("fast"))
+		},
+	}, rest.Route{
+		Name:    "GetPanic",
+		Method:  http.MethodGet,
+		Pattern: "/panic",
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			panic("panic from handler")
+		},
+	}, rest.Route{
+		Name:    "GetOverride",
+		Method:  http.MethodGet,
+		Pattern: "/override",
+		Timeout: time.Second,
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("override"))
+		},
+	}, rest.Route{
+		Name:    "GetStream",
+		Method:  http.MethodGet,
+		Pattern: "/stream",
+		Timeout: -1,
+		HandlerFunc: func(w http.ResponseWriter, r *http.Request) {
+			_, ok := w.(http.Flusher)
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte(strconv.FormatBool(ok)))
+		},
+	})
+	go srv.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := http.Get("http://localhost:6081" + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	start := time.Now()
+	resp, body := get("/slow")
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+	var er rest.ErrorResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &er))
+	require.Equal(t, "handler timeout", er.Message)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&canceled) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	resp, body = get("/fast")
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get("X-Fast"))
+	require.Equal(t, "fast", body)
+
+	resp, _ = get("/panic")
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, body = get("/override")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "override", body)
+
+	resp, body = get("/stream")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "true", body)
+}
+
+func TestHandlerTimeout_ClientGone(t *testing.T) {
+	config.GddHandlerTimeout.Write("1s")
+	defer os.Unsetenv(string(config.GddHandlerTimeout))
+
+	handler := rest.HandlerTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	rr := httptest.NewRecorder()
+	start := time.Now()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+	require.Less(t, time.Since(start), time.Second)
+	require.False(t, rr.Flushed)
+	require.NotEqual(t, http.StatusServiceUnavailable, rr.Code)
+	require.Empty(t, rr.Body.String())
+}
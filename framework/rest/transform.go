@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseTransformer decides whether response body of r needs to be transformed. It returns nil if not,
+// so that the response is streamed to client without buffering, otherwise a function transforming the
+// whole marshalled body.
+type ResponseTransformer func(r *http.Request) func(body []byte) ([]byte, error)
+
+type transformResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *transformResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *transformResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// TransformResponse buffers JSON responses of requests for which transformer returns a non-nil function,
+// and writes transformed body instead. Only responses whose Content-Type contains json are transformed, responses
+// of other content types like files and empty responses are written as it is, so routes returning sensitive data
+// in other formats must not rely on it.
+// It is meant to be attached to routes one by one, e.g. HandlerFunc: rest.TransformResponse(transformer)(handler).ServeHTTP.
+// If transformation fails, e.g. masking fields of malformed json, the error is logged and 500 status code is
+// responded with ErrorResponse json body, the original body is never written.
+func TransformResponse(transformer ResponseTransformer) func(inner http.Handler) http.Handler {
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			transform := transformer(r)
+			if transform == nil {
+				inner.ServeHTTP(w, r)
+				return
+			}
+			tw := &transformResponseWriter{ResponseWriter: w}
+			inner.ServeHTTP(tw, r)
+			if tw.statusCode == 0 {
+				tw.statusCode = http.StatusOK
+			}
+			body := tw.body.Bytes()
+			if len(body) > 0 && strings.Contains(w.Header().Get("Content-Type"), "json") {
+				transformed, err := transform(body)
+				if err != nil {
+					logger.Error().Err(err).Msgf("[go-doudou] failed to transform response of %s %s", r.Method, r.URL.Path)
+					w.Header().Del("Content-Length")
+					writeErrorResponse(w, http.StatusInternalServerError, "failed to transform response")
+					return
+				}
+				body = transformed
+			}
+			if w.Header().Get("Content-Length") != "" {
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			}
+			w.WriteHeader(tw.statusCode)
+			w.Write(body)
+		})
+	}
+}
+
+// DefaultMaskValue replaces values of masked fields
+const DefaultMaskValue = "******"
+
+// MaskAnyRole is the key of mask rules applied to callers whose role has no rules of its own
+const MaskAnyRole = "*"
+
+// RoleFunc returns role of the authenticated caller from request context, e.g. put by an authentication middleware
+type RoleFunc func(ctx context.Context) string
+
+// MaskFields returns a ResponseTransformer masking fields of JSON responses by caller's role. rules maps roles to
+// field paths to mask, rules of MaskAnyRole apply to roles not listed, and a role mapped to no paths sees everything.
+// A field path is dot separated field names, e.g. user.email, and arrays are traversed transparently, so items.token
+// masks token field of every element of items array. Bodies are re-marshalled only if any field is actually masked.
+func MaskFields(role RoleFunc, rules map[string][]string) ResponseTransformer {
+	compiled := make(map[string][][]string, len(rules))
+	for key, paths := range rules {
+		compiled[key] = nil
+		for _, path := range paths {
+			if path = strings.TrimSpace(path); path != "" {
+				compiled[key] = append(compiled[key], strings.Split(path, "."))
+			}
+		}
+	}
+	return func(r *http.Request) func(body []byte) ([]byte, error) {
+		paths, ok := compiled[role(r.Context())]
+		if !ok {
+			paths = compiled[MaskAnyRole]
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		return func(body []byte) ([]byte, error) {
+			var data interface{}
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			if err := decoder.Decode(&data); err != nil {
+				return nil, err
+			}
+			masked := false
+			for _, path := range paths {
+				if maskPath(data, path) {
+					masked = true
+				}
+			}
+			if !masked {
+				return body, nil
+			}
+			return json.Marshal(data)
+		}
+	}
+}
+
+// maskPath replaces values at path in data with DefaultMaskValue and returns true if any value is replaced
+func maskPath(data interface{}, path []string) bool {
+	switch value := data.(type) {
+	case []interface{}:
+		masked := false
+		for _, item := range value {
+			if maskPath(item, path) {
+				masked = true
+			}
+		}
+		return masked
+	case map[string]interface{}:
+		child, ok := value[path[0]]
+		if !ok {
+			return false
+		}
+		if len(path) == 1 {
+			if child != nil {
+				value[path[0]] = DefaultMaskValue
+			}
+			return child != nil
+		}
+		return maskPath(child, path[1:])
+	}
+	return false
+}
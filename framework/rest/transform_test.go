@@ -0,0 +1,82 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type roleKey struct{}
+
+func TestMaskFields(t *testing.T) {
+	body := `{"id":12345678901234567890,"user":{"name":"jack","email":"jack@example.com"},"items":[{"token":"a"},{"token":"b"}]}`
+	transformer := rest.MaskFields(func(ctx context.Context) string {
+		role, _ := ctx.Value(roleKey{}).(string)
+		return role
+	}, map[string][]string{
+		"admin":          nil,
+		"auditor":        {"password"},
+		rest.MaskAnyRole: {"user.email", "items.token"},
+	})
+	handler := rest.TransformResponse(transformer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(body))
+	}))
+	serve := func(role string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		handler.ServeHTTP(rr, req.WithContext(context.WithValue(req.Context(), roleKey{}, role)))
+		return rr
+	}
+
+	rr := serve("guest")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.JSONEq(t, `{"id":12345678901234567890,"user":{"name":"jack","email":"******"},"items":[{"token":"******"},{"token":"******"}]}`, rr.Body.String())
+
+	rr = serve("admin")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, body, rr.Body.String())
+
+	rr = serve("auditor")
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, body, rr.Body.String())
+}
+
+func TestTransformResponse_NonJSON(t *testing.T) {
+	handler := rest.TransformResponse(func(r *http.Request) func(body []byte) ([]byte, error) {
+		return func(body []byte) ([]byte, error) {
+			return []byte("transformed"), nil
+		}
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("plain"))
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "plain", rr.Body.String())
+}
+
+func TestTransformResponse_Error(t *testing.T) {
+	handler := rest.TransformResponse(func(r *http.Request) func(body []byte) ([]byte, error) {
+		return func(body []byte) ([]byte, error) {
+			return nil, errors.New("malformed")
+		}
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.Write([]byte(`{"password":"secret"}`))
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.NotContains(t, rr.Body.String(), "secret")
+	var resp rest.ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	require.Equal(t, "failed to transform response", resp.Message)
+}
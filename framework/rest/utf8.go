@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// UTF8Mode decides how UTF8 middleware treats control characters
+type UTF8Mode int
+
+const (
+	// UTF8Reject rejects requests containing control characters with 400 status code
+	UTF8Reject UTF8Mode = iota
+	// UTF8Sanitize strips control characters from requests
+	UTF8Sanitize
+)
+
+// dangerousRune reports whether r is a control character other than tab, line feed and carriage return
+func dangerousRune(r rune) bool {
+	return unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r'
+}
+
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if dangerousRune(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// textual reports whether body of contentType should be checked. Requests without content type are checked too.
+func textual(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-www-form-urlencoded", "application/x-ndjson":
+		return true
+	}
+	return false
+}
+
+// UTF8 validates that query parameters and textual request bodies are valid UTF-8, and rejects or strips control
+// characters other than tab, line feed and carriage return according to mode. Requests with invalid UTF-8 are always
+// rejected with 400 status code. Bodies of non-textual content types such as multipart/form-data and
+// application/octet-stream are not checked, and requests to skipPaths are not checked at all. A skip path ending with *
+// matches all paths with the prefix before *. Control characters are checked in raw bytes, so escaped ones in json
+// strings such as \u0000 are left to handlers. Add it before any middleware or handler reading request body.
+func UTF8(mode UTF8Mode, skipPaths ...string) func(inner http.Handler) http.Handler {
+	skip := pathMatcher(skipPaths)
+	return func(inner http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip(r.URL.Path) {
+				inner.ServeHTTP(w, r)
+				return
+			}
+			if r.URL.RawQuery != "" {
+				query, err := url.ParseQuery(r.URL.RawQuery)
+				if err != nil {
+					writeErrorResponse(w, http.StatusBadRequest, "invalid query string")
+					return
+				}
+				sanitized := make(url.Values, len(query))
+				var changed bool
+				for key, values := range query {
+					if !utf8.ValidString(key) {
+						writeErrorResponse(w, http.StatusBadRequest, "invalid UTF-8 in query string")
+						return
+					}
+					cleanKey := stripControl(key)
+					for _, value := range values {
+						if !utf8.ValidString(value) {
+							writeErrorResponse(w, http.StatusBadRequest, "invalid UTF-8 in query string")
+							return
+						}
+						cleanValue := stripControl(value)
+						if cleanKey != key || cleanValue != value {
+							if mode == UTF8Reject {
+								writeErrorResponse(w, http.StatusBadRequest, "control characters in query string")
+								return
+							}
+							changed = true
+						}
+						sanitized[cleanKey] = append(sanitized[cleanKey], cleanValue)
+					}
+				}
+				if changed {
+					r.URL.RawQuery = sanitized.Encode()
+				}
+			}
+			if r.Body != nil && r.Body != http.NoBody && textual(r.Header.Get("Content-Type")) {
+				body, err := io.ReadAll(r.Body)
+				r.Body.Close()
+				if err != nil {
+					writeErrorResponse(w, http.StatusBadRequest, "failed to read request body")
+					return
+				}
+				if !utf8.Valid(body) {
+					writeErrorResponse(w, http.StatusBadRequest, "invalid UTF-8 in request body")
+					return
+				}
+				if bytes.IndexFunc(body, dangerousRune) >= 0 {
+					if mode == UTF8Reject {
+						writeErrorResponse(w, http.StatusBadRequest, "control characters in request body")
+						return
+					}
+					body = []byte(stripControl(string(body)))
+					r.ContentLength = int64(len(body))
+					r.Header.Del("Content-Length")
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			inner.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package rest_test
+
+import (
+	"github.com/stretchr/testify/require"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func utf8Handler(mode rest.UTF8Mode, got *string, gotQuery *string) http.Handler {
+	return rest.UTF8(mode, "/upload/*")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		*got = string(body)
+		*gotQuery = r.URL.Query().Get("name")
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestUTF8_Reject(t *testing.T) {
+	var got, gotQuery string
+	handler := utf8Handler(rest.UTF8Reject, &got, &gotQuery)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users?name=%E4%BD%A0%E5%A5%BD", strings.NewReader(`{"name":"jack\tma"}`))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, `{"name":"jack\tma"}`, got)
+	require.Equal(t, "你好", gotQuery)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("{\"name\":\"\xff\"}"))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid UTF-8 in request body")
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("{\"name\":\"jack\x00\"}"))
+	req.Header.Set("Content-Type", "application/json")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "control characters in request body")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users?name=%FF", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "invalid UTF-8 in query string")
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/users?name=jack%07", nil))
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "control characters in query string")
+}
+
+func TestUTF8_Sanitize(t *testing.T) {
+	var got, gotQuery string
+	handler := utf8Handler(rest.UTF8Sanitize, &got, &gotQuery)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users?name=jack%07%1Bma", strings.NewReader("name=jack\x00\x1bma\n"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "name=jackma\n", got)
+	require.Equal(t, "jackma", gotQuery)
+
+	// invalid UTF-8 is rejected even in sanitize mode
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("\xff"))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestUTF8_Binary(t *testing.T) {
+	var got, gotQuery string
+	handler := utf8Handler(rest.UTF8Reject, &got, &gotQuery)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("\xff\x00"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "\xff\x00", got)
+
+	rr = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/upload/avatar?name=%FF", strings.NewReader("\xff\x00"))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "\xff\x00", got)
+}
@@ -0,0 +1,98 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+// BatchCall is a call bundled in Batch. Its error is available after Do of the batch returned.
+type BatchCall struct {
+	method string
+	decode func(result json.RawMessage) error
+	done   bool
+	err    error
+}
+
+// Method returns name of the called service method
+func (c *BatchCall) Method() string {
+	return c.method
+}
+
+// Err returns error of the call, nil if it succeeded. It returns error if the batch hasn't been sent successfully.
+func (c *BatchCall) Err() error {
+	if !c.done {
+		return errors.New("[go-doudou] batch not sent")
+	}
+	return c.err
+}
+
+// Batch bundles multiple calls of service methods into one request to batch endpoint generated by
+// go-doudou svc http --batch. It is used by generated batch client code.
+type Batch struct {
+	items []rest.BatchItem
+	calls []*BatchCall
+}
+
+// Add adds a call of method with params, which must be marshalled into a json object whose keys are parameter names.
+// decode is called with result of the call if it succeeded.
+func (b *Batch) Add(method string, params interface{}, decode func(result json.RawMessage) error) *BatchCall {
+	call := &BatchCall{
+		method: method,
+		decode: decode,
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		call.done = true
+		call.err = errors.Wrap(err, "[go-doudou] failed to marshal params")
+	}
+	b.items = append(b.items, rest.BatchItem{
+		Method: method,
+		Params: data,
+	})
+	b.calls = append(b.calls, call)
+	return call
+}
+
+// Len returns number of calls in the batch
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// Do posts all calls to path by req, and dispatches results to calls. It returns error only if the batch request
+// failed as a whole, errors of calls are returned by Err of each BatchCall.
+func (b *Batch) Do(req *resty.Request, path string) error {
+	resp, err := req.SetBody(b.items).Post(path)
+	if err != nil {
+		return errors.Wrap(err, "error")
+	}
+	if resp.IsError() {
+		return DecodeError(resp)
+	}
+	var results []rest.BatchResult
+	if err = json.Unmarshal(resp.Body(), &results); err != nil {
+		return errors.Wrap(err, "error")
+	}
+	if len(results) != len(b.calls) {
+		return fmt.Errorf("[go-doudou] batch returned %d results for %d calls", len(results), len(b.calls))
+	}
+	for i, call := range b.calls {
+		if call.done {
+			continue
+		}
+		call.done = true
+		result := results[i]
+		if result.Error != nil {
+			call.err = result.Error.BizError(result.StatusCode)
+			continue
+		}
+		if call.decode != nil {
+			if err = call.decode(result.Result); err != nil {
+				call.err = errors.Wrap(err, "error")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package restclient
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/sony/gobreaker"
+	"net/http"
+	"time"
+)
+
+// ErrCircuitOpen is returned by generated clients without sending the request while circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerConfig configures circuit breaker of generated clients
+type BreakerConfig struct {
+	// Name identifies the circuit breaker in OnStateChange callback, usually the downstream service name
+	Name string
+	// FailureRatio trips the breaker open when ratio of failed requests reaches it, default 0.5
+	FailureRatio float64
+	// MinRequests is min count of requests in an interval before FailureRatio is checked, default 10
+	MinRequests uint32
+	// Interval is cyclic period of closed state to clear counts, 0 means never clear
+	Interval time.Duration
+	// Timeout is period of open state before switching to half-open, default 60s
+	Timeout time.Duration
+	// MaxRequests is max count of requests allowed to pass through in half-open state, default 1
+	MaxRequests uint32
+	// OnStateChange is called whenever state of the breaker changes, e.g. for metrics
+	OnStateChange func(name string, from, to gobreaker.State)
+}
+
+// CircuitBreaker stops sending requests to unhealthy downstream service. Only transport errors and 5xx responses
+// count as failures, 4xx responses are client errors and count as successes. Requests aborted because their context
+// is canceled or its deadline is exceeded say nothing about health of the downstream service, so they don't count
+// as failures either.
+type CircuitBreaker struct {
+	cb *gobreaker.TwoStepCircuitBreaker
+}
+
+// NewCircuitBreaker creates a CircuitBreaker instance from conf
+func NewCircuitBreaker(conf BreakerConfig) *CircuitBreaker {
+	ratio := conf.FailureRatio
+	if ratio <= 0 {
+		ratio = 0.5
+	}
+	minRequests := conf.MinRequests
+	if minRequests == 0 {
+		minRequests = 10
+	}
+	return &CircuitBreaker{
+		cb: gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+			Name:        conf.Name,
+			MaxRequests: conf.MaxRequests,
+			Interval:    conf.Interval,
+			Timeout:     conf.Timeout,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.Requests >= minRequests && float64(counts.TotalFailures)/float64(counts.Requests) >= ratio
+			},
+			OnStateChange: conf.OnStateChange,
+		}),
+	}
+}
+
+// State returns current state of the breaker
+func (b *CircuitBreaker) State() gobreaker.State {
+	return b.cb.State()
+}
+
+// Execute sends request by fn through the breaker, returns ErrCircuitOpen immediately if the breaker is open
+// or too many requests are in flight in half-open state. Nil breaker simply calls fn.
+func (b *CircuitBreaker) Execute(fn func() (*resty.Response, error)) (*resty.Response, error) {
+	if b == nil {
+		return fn()
+	}
+	done, err := b.cb.Allow()
+	if err != nil {
+		return nil, errors.Wrap(ErrCircuitOpen, err.Error())
+	}
+	resp, err := fn()
+	if err != nil {
+		done(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded))
+		return resp, err
+	}
+	done(resp == nil || resp.StatusCode() < http.StatusInternalServerError)
+	return resp, err
+}
+
+// WithCircuitBreaker makes generated client send requests through a circuit breaker created from conf
+func WithCircuitBreaker(conf BreakerConfig) RestClientOption {
+	return func(c RestClient) {
+		if r, ok := c.(interface{ SetBreaker(breaker *CircuitBreaker) }); ok {
+			r.SetBreaker(NewCircuitBreaker(conf))
+		}
+	}
+}
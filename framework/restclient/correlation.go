@@ -0,0 +1,26 @@
+package restclient
+
+import (
+	"github.com/go-resty/resty/v2"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+// PropagateCorrelation sets X-Correlation-Id header of request to correlation id and X-Causation-Id header to id of
+// current hop from ids in request context put by rest.Correlation middleware or rest.NewCorrelationContext,
+// unless the headers have been set explicitly. It is called by generated http clients.
+func PropagateCorrelation(request *resty.Request) {
+	ids, ok := rest.CorrelationFromContext(request.Context())
+	if !ok {
+		return
+	}
+	if request.Header.Get(rest.CorrelationIDHeader) == "" {
+		request.SetHeader(rest.CorrelationIDHeader, ids.CorrelationID)
+	}
+	causationID := ids.ID
+	if causationID == "" {
+		causationID = ids.CausationID
+	}
+	if request.Header.Get(rest.CausationIDHeader) == "" && causationID != "" {
+		request.SetHeader(rest.CausationIDHeader, causationID)
+	}
+}
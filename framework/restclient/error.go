@@ -0,0 +1,37 @@
+package restclient
+
+import (
+	"encoding/json"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+// DecodeError reconstructs rest.BizError from error response returned by go-doudou http server,
+// so that http status code, business error code and message are kept on client side.
+// If response body is not in rest.ErrorResponse format, the raw body will be used as error message.
+func DecodeError(resp *resty.Response) error {
+	var er rest.ErrorResponse
+	if err := json.Unmarshal(resp.Body(), &er); err != nil || er.Message == "" {
+		return rest.NewBizError(errors.New(resp.String()), rest.WithStatusCode(resp.StatusCode()))
+	}
+	return er.BizError(resp.StatusCode())
+}
+
+// ErrorDecoder converts error response to the error result of generated client methods. Returning nil makes
+// the response handled as a successful one, e.g. to decode body of 404 response into results.
+type ErrorDecoder func(resp *resty.Response) error
+
+// WithErrorDecoder replaces DecodeError, the default ErrorDecoder of generated clients, by decoder, e.g. to decode
+// error envelope of third-party services. It is ignored if decoder is nil.
+func WithErrorDecoder(decoder ErrorDecoder) RestClientOption {
+	return func(c RestClient) {
+		if decoder == nil {
+			return
+		}
+		if d, ok := c.(interface{ SetErrorDecoder(decoder ErrorDecoder) }); ok {
+			d.SetErrorDecoder(decoder)
+		}
+	}
+}
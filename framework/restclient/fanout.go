@@ -0,0 +1,90 @@
+package restclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FanOutError collects errors of failed backends keyed by backend name
+type FanOutError struct {
+	Errors map[string]error
+}
+
+func (e *FanOutError) Error() string {
+	backends := make([]string, 0, len(e.Errors))
+	for backend := range e.Errors {
+		backends = append(backends, backend)
+	}
+	sort.Strings(backends)
+	var msgs []string
+	for _, backend := range backends {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", backend, e.Errors[backend]))
+	}
+	return "[go-doudou] fan-out failed: " + strings.Join(msgs, "; ")
+}
+
+// Err returns error of backend, nil if the call to backend succeeded
+func (e *FanOutError) Err(backend string) error {
+	return e.Errors[backend]
+}
+
+// FanOut calls several backends concurrently and collects errors per backend, so that results of succeeded
+// backends are still available when some backends failed. It is used by generated aggregator client code.
+type FanOut struct {
+	ctx   context.Context
+	group *errgroup.Group
+	mu    sync.Mutex
+	errs  map[string]error
+}
+
+// NewFanOut creates a FanOut. If failFast is true, context passed to calls will be cancelled once any call failed,
+// otherwise all calls run to completion.
+func NewFanOut(ctx context.Context, failFast bool) *FanOut {
+	f := &FanOut{
+		ctx:  ctx,
+		errs: make(map[string]error),
+	}
+	if failFast {
+		f.group, f.ctx = errgroup.WithContext(ctx)
+	} else {
+		f.group = &errgroup.Group{}
+	}
+	return f
+}
+
+// Go calls fn in a new goroutine. Error returned by fn or panic in fn is recorded as error of backend.
+func (f *FanOut) Go(backend string, fn func(ctx context.Context) error) {
+	f.group.Go(func() (err error) {
+		defer func() {
+			if e := recover(); e != nil {
+				err = errors.Errorf("panic: %v", e)
+			}
+			if err != nil {
+				f.mu.Lock()
+				f.errs[backend] = err
+				f.mu.Unlock()
+			}
+		}()
+		return fn(f.ctx)
+	})
+}
+
+// Wait blocks until all calls returned. It returns *FanOutError if any call failed.
+func (f *FanOut) Wait() error {
+	f.group.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.errs) == 0 {
+		return nil
+	}
+	errs := make(map[string]error, len(f.errs))
+	for backend, err := range f.errs {
+		errs[backend] = err
+	}
+	return &FanOutError{Errors: errs}
+}
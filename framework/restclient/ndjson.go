@@ -0,0 +1,69 @@
+package restclient
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// NDJSONIterator decodes newline-delimited JSON stream value by value, so that large list responses
+// can be consumed with flat memory usage. Send request with resty SetDoNotParseResponse(true) and pass
+// resp.RawBody() to NewNDJSONIterator.
+//
+//	iter := restclient.NewNDJSONIterator(resp.RawBody())
+//	defer iter.Close()
+//	for iter.Next() {
+//		var item dto.User
+//		if err := iter.Decode(&item); err != nil {
+//			return err
+//		}
+//	}
+//	return iter.Err()
+type NDJSONIterator struct {
+	body    io.ReadCloser
+	dec     *json.Decoder
+	current json.RawMessage
+	err     error
+}
+
+// NewNDJSONIterator creates a NDJSONIterator instance reading from body
+func NewNDJSONIterator(body io.ReadCloser) *NDJSONIterator {
+	return &NDJSONIterator{
+		body: body,
+		dec:  json.NewDecoder(body),
+	}
+}
+
+// Next reads next value from stream. It returns false when stream ends or an error occurs.
+func (it *NDJSONIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.current = nil
+	if err := it.dec.Decode(&it.current); err != nil {
+		if err != io.EOF {
+			it.err = errors.Wrap(err, "[go-doudou] failed to read ndjson stream")
+		}
+		return false
+	}
+	return true
+}
+
+// Decode unmarshals current value into v
+func (it *NDJSONIterator) Decode(v interface{}) error {
+	if it.current == nil {
+		return errors.New("[go-doudou] no value to decode, call Next first")
+	}
+	return json.Unmarshal(it.current, v)
+}
+
+// Err returns the first non-EOF error encountered by Next
+func (it *NDJSONIterator) Err() error {
+	return it.err
+}
+
+// Close closes underlying response body
+func (it *NDJSONIterator) Close() error {
+	return it.body.Close()
+}
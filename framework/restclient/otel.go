@@ -0,0 +1,19 @@
+package restclient
+
+import (
+	"github.com/go-resty/resty/v2"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PropagateTraceContext sets W3C traceparent, tracestate and baggage headers of request from OpenTelemetry span in
+// request context, e.g. the server span started by otel tracing middleware when GddTracingProvider is otel.
+// It does nothing if there is no valid span. It is called by generated http clients.
+func PropagateTraceContext(request *resty.Request) {
+	ctx := request.Context()
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	rest.OtelPropagator.Inject(ctx, propagation.HeaderCarrier(request.Header))
+}
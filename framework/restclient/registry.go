@@ -0,0 +1,56 @@
+package restclient
+
+import (
+	"github.com/pkg/errors"
+	"github.com/unionj-cloud/go-doudou/v2/framework/registry"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"reflect"
+)
+
+// ErrNoAvailableServer is returned when there is no available node in the cluster to send request to
+var ErrNoAvailableServer = errors.New("[go-doudou] no available server")
+
+// RegistryProvider wraps a service provider backed by service registry with the name of the service it provides
+type RegistryProvider struct {
+	registry.IServiceProvider
+	serviceName string
+}
+
+// ServiceName returns name of the service
+func (p *RegistryProvider) ServiceName() string {
+	return p.serviceName
+}
+
+// NewRegistryProvider creates new RegistryProvider instance
+func NewRegistryProvider(serviceName string, provider registry.IServiceProvider) *RegistryProvider {
+	return &RegistryProvider{
+		IServiceProvider: provider,
+		serviceName:      serviceName,
+	}
+}
+
+// IsNilProvider reports whether provider is nil, including a nil pointer of concrete type assigned to the interface,
+// e.g. a provider returned as nil *memberlist.MemberlistServiceProvider
+func IsNilProvider(provider registry.IServiceProvider) bool {
+	if provider == nil {
+		return true
+	}
+	v := reflect.ValueOf(provider)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// SelectServer selects base url of a node from provider. It returns ErrNoAvailableServer if provider is empty.
+func SelectServer(provider registry.IServiceProvider) (string, error) {
+	server := provider.SelectServer()
+	if stringutils.IsNotEmpty(server) {
+		return server, nil
+	}
+	if named, ok := provider.(interface{ ServiceName() string }); ok {
+		return "", errors.Wrapf(ErrNoAvailableServer, "service %s", named.ServiceName())
+	}
+	return "", ErrNoAvailableServer
+}
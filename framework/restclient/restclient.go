@@ -10,7 +10,6 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"runtime"
 	"time"
 )
 
@@ -65,32 +64,123 @@ func NewServiceProvider(env string) *ServiceProvider {
 	}
 }
 
-// NewClient creates new resty Client instance
+// PoolConfig tunes connection pool and connection timeouts of http clients.
+// Zero fields fall back to values configured by GddClientXxx configs or their defaults.
+type PoolConfig struct {
+	// MaxIdleConns is max idle connections across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is max idle connections per host, net/http's default 2 causes connection churn under load
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits total connections per host. Zero falls back to GddClientMaxConnsPerHost like other
+	// fields, negative means no limit.
+	MaxConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept in pool
+	IdleConnTimeout time.Duration
+	// DialTimeout is timeout of establishing tcp connections
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout is timeout of tls handshake
+	TLSHandshakeTimeout time.Duration
+}
+
+func durationOrDefault(value, d string) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		duration, _ = time.ParseDuration(d)
+	}
+	return duration
+}
+
+// DefaultPoolConfig returns PoolConfig configured by GddClientXxx configs
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxIdleConns:        cast.ToIntOrDefault(config.GddClientMaxIdleConns.Load(), config.DefaultGddClientMaxIdleConns),
+		MaxIdleConnsPerHost: cast.ToIntOrDefault(config.GddClientMaxIdleConnsPerHost.Load(), config.DefaultGddClientMaxIdleConnsPerHost),
+		MaxConnsPerHost:     cast.ToIntOrDefault(config.GddClientMaxConnsPerHost.Load(), config.DefaultGddClientMaxConnsPerHost),
+		IdleConnTimeout:     durationOrDefault(config.GddClientIdleConnTimeout.Load(), config.DefaultGddClientIdleConnTimeout),
+		DialTimeout:         durationOrDefault(config.GddClientDialTimeout.Load(), config.DefaultGddClientDialTimeout),
+		TLSHandshakeTimeout: durationOrDefault(config.GddClientTLSHandshakeTimeout.Load(), config.DefaultGddClientTLSHandshakeTimeout),
+	}
+}
+
+func (p PoolConfig) withDefaults() PoolConfig {
+	d := DefaultPoolConfig()
+	if p.MaxIdleConns <= 0 {
+		p.MaxIdleConns = d.MaxIdleConns
+	}
+	if p.MaxIdleConnsPerHost <= 0 {
+		p.MaxIdleConnsPerHost = d.MaxIdleConnsPerHost
+	}
+	if p.MaxConnsPerHost == 0 {
+		p.MaxConnsPerHost = d.MaxConnsPerHost
+	}
+	if p.MaxConnsPerHost < 0 {
+		// 0 means no limit for http.Transport
+		p.MaxConnsPerHost = 0
+	}
+	if p.IdleConnTimeout <= 0 {
+		p.IdleConnTimeout = d.IdleConnTimeout
+	}
+	if p.DialTimeout <= 0 {
+		p.DialTimeout = d.DialTimeout
+	}
+	if p.TLSHandshakeTimeout <= 0 {
+		p.TLSHandshakeTimeout = d.TLSHandshakeTimeout
+	}
+	return p
+}
+
+// WithPool replaces http client with a new one created by NewClientWithPool, e.g.
+// client.NewUsersvcClient(restclient.WithPool(restclient.PoolConfig{MaxIdleConnsPerHost: 100})).
+// It should be passed before other options configuring http client like WithClient.
+func WithPool(pool PoolConfig) RestClientOption {
+	return func(c RestClient) {
+		c.SetClient(NewClientWithPool(pool))
+	}
+}
+
+// NewClient creates new resty Client instance with PoolConfig returned by DefaultPoolConfig
 func NewClient() *resty.Client {
+	return NewClientWithPool(DefaultPoolConfig())
+}
+
+// NewClientWithPool creates new resty Client instance with pool
+func NewClientWithPool(pool PoolConfig) *resty.Client {
+	pool = pool.withDefaults()
 	client := resty.New()
 	client.SetTimeout(1 * time.Minute)
 	dialer := &net.Dialer{
-		Timeout:   30 * time.Second,
+		Timeout:   pool.DialTimeout,
 		KeepAlive: 30 * time.Second,
 		DualStack: true,
 	}
-	client.SetTransport(gzhttp.Transport(&nethttp.Transport{
-		RoundTripper: &http.Transport{
-			Proxy:                 http.ProxyFromEnvironment,
-			DialContext:           dialer.DialContext,
-			ForceAttemptHTTP2:     true,
-			MaxIdleConns:          100,
-			IdleConnTimeout:       90 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			MaxIdleConnsPerHost:   runtime.GOMAXPROCS(0) + 1,
-			MaxConnsPerHost:       10000,
-		},
-	}))
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          pool.MaxIdleConns,
+		IdleConnTimeout:       pool.IdleConnTimeout,
+		TLSHandshakeTimeout:   pool.TLSHandshakeTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConnsPerHost:   pool.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       pool.MaxConnsPerHost,
+	}
+	client.SetTransport(&baseTransport{
+		RoundTripper: gzhttp.Transport(&nethttp.Transport{
+			RoundTripper: transport,
+		}),
+		base: transport,
+	})
 	retryCnt := config.DefaultGddRetryCount
 	if cnt, err := cast.ToIntE(config.GddRetryCount.Load()); err == nil {
 		retryCnt = cnt
 	}
 	client.SetRetryCount(retryCnt)
+	maxWait, err := time.ParseDuration(config.GddRetryMaxWait.LoadOrDefault(config.DefaultGddRetryMaxWait))
+	if err != nil {
+		maxWait, _ = time.ParseDuration(config.DefaultGddRetryMaxWait)
+	}
+	client.SetRetryMaxWaitTime(maxWait)
+	client.SetRetryAfter(RetryAfter)
+	client.AddRetryCondition(RetryOnBackpressure)
 	return client
 }
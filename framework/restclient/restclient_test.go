@@ -1,14 +1,27 @@
 package restclient_test
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
 	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/sony/gobreaker"
 	"github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
 	"github.com/unionj-cloud/go-doudou/v2/framework/registry"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
 	"github.com/unionj-cloud/go-doudou/v2/framework/restclient"
 	"github.com/wubin1989/nacos-sdk-go/v2/common/constant"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var clientConfigTest = *constant.NewClientConfig(
@@ -20,9 +33,13 @@ var clientConfigTest = *constant.NewClientConfig(
 var serverConfigTest = *constant.NewServerConfig("console.nacos.io", 80, constant.WithContextPath("/nacos"))
 
 type MockRestClient struct {
-	provider registry.IServiceProvider
-	client   *resty.Client
-	rootPath string
+	provider     registry.IServiceProvider
+	client       *resty.Client
+	rootPath     string
+	retry        *restclient.RetryConfig
+	breaker      *restclient.CircuitBreaker
+	transport    restclient.TransportConfig
+	errorDecoder restclient.ErrorDecoder
 }
 
 func (receiver *MockRestClient) SetRootPath(rootPath string) {
@@ -37,19 +54,42 @@ func (receiver *MockRestClient) SetClient(client *resty.Client) {
 	receiver.client = client
 }
 
+func (receiver *MockRestClient) SetRetry(retry restclient.RetryConfig) {
+	receiver.retry = &retry
+}
+
+func (receiver *MockRestClient) SetBreaker(breaker *restclient.CircuitBreaker) {
+	receiver.breaker = breaker
+}
+
+func (receiver *MockRestClient) SetErrorDecoder(errorDecoder restclient.ErrorDecoder) {
+	receiver.errorDecoder = errorDecoder
+}
+
+func (receiver *MockRestClient) TransportConfig() *restclient.TransportConfig {
+	return &receiver.transport
+}
+
 func NewMockRestClient(opts ...restclient.RestClientOption) *MockRestClient {
 	defaultProvider := restclient.NewServiceProvider("MOCKRESTCLIENT")
 	defaultClient := restclient.NewClient()
 
 	svcClient := &MockRestClient{
-		provider: defaultProvider,
-		client:   defaultClient,
+		provider:     defaultProvider,
+		client:       defaultClient,
+		errorDecoder: restclient.DecodeError,
 	}
 
 	for _, opt := range opts {
 		opt(svcClient)
 	}
 
+	if svcClient.retry != nil {
+		restclient.ApplyRetry(svcClient.client, *svcClient.retry)
+	}
+
+	restclient.ApplyTransport(svcClient.client, svcClient.transport)
+
 	return svcClient
 }
 
@@ -75,6 +115,467 @@ func TestRetryCount(t *testing.T) {
 	})
 }
 
+func TestDefaultPoolConfig(t *testing.T) {
+	Convey("Should load pool config from configs with defaults", t, func() {
+		config.GddClientMaxIdleConnsPerHost.Write("64")
+		config.GddClientIdleConnTimeout.Write("2m")
+		config.GddClientDialTimeout.Write("invalid")
+		defer func() {
+			os.Unsetenv(string(config.GddClientMaxIdleConnsPerHost))
+			os.Unsetenv(string(config.GddClientIdleConnTimeout))
+			os.Unsetenv(string(config.GddClientDialTimeout))
+		}()
+		pool := restclient.DefaultPoolConfig()
+		So(pool.MaxIdleConnsPerHost, ShouldEqual, 64)
+		So(pool.IdleConnTimeout, ShouldEqual, 2*time.Minute)
+		So(pool.DialTimeout, ShouldEqual, 30*time.Second)
+		So(pool.MaxIdleConns, ShouldEqual, config.DefaultGddClientMaxIdleConns)
+		So(pool.MaxIdleConnsPerHost, ShouldBeGreaterThan, http.DefaultMaxIdleConnsPerHost)
+	})
+}
+
+func TestWithPool(t *testing.T) {
+	Convey("Create a RestClient instance with custom connection pool", t, func() {
+		defaultClient := restclient.NewClient()
+		m := NewMockRestClient(restclient.WithClient(defaultClient), restclient.WithPool(restclient.PoolConfig{MaxIdleConnsPerHost: 100}))
+		So(m.client, ShouldNotBeNil)
+		So(m.client, ShouldNotEqual, defaultClient)
+		So(m.client.RetryCount, ShouldEqual, defaultClient.RetryCount)
+	})
+}
+
+func TestDecodeError(t *testing.T) {
+	Convey("Should reconstruct BizError returned from server", t, func() {
+		config.GddErrorDetailsEnable.Write("true")
+		defer os.Unsetenv(string(config.GddErrorDetailsEnable))
+		ts := httptest.NewServer(rest.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/text" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			panic(rest.NewBizError(errors.New("user not found"), rest.WithStatusCode(http.StatusNotFound), rest.WithErrCode(100404)))
+		})))
+		defer ts.Close()
+
+		resp, err := resty.New().R().Get(ts.URL + "/biz")
+		So(err, ShouldBeNil)
+		var bz rest.BizError
+		So(errors.As(restclient.DecodeError(resp), &bz), ShouldBeTrue)
+		So(bz.StatusCode, ShouldEqual, http.StatusNotFound)
+		So(bz.ErrCode, ShouldEqual, 100404)
+		So(bz.Error(), ShouldEqual, "user not found")
+		// GDD_ENV is not set, so it is dev mode
+		So(bz.Details, ShouldNotBeEmpty)
+
+		resp, err = resty.New().R().Get(ts.URL + "/text")
+		So(err, ShouldBeNil)
+		So(errors.As(restclient.DecodeError(resp), &bz), ShouldBeTrue)
+		So(bz.StatusCode, ShouldEqual, http.StatusNotFound)
+		So(bz.Error(), ShouldEqual, "not found")
+	})
+}
+
+func TestWithErrorDecoder(t *testing.T) {
+	Convey("Should decode error response by custom decoder", t, func() {
+		errTeapot := errors.New("teapot")
+		m := NewMockRestClient(restclient.WithErrorDecoder(func(resp *resty.Response) error {
+			return errTeapot
+		}))
+		resp := &resty.Response{RawResponse: &http.Response{StatusCode: http.StatusTeapot}}
+		So(m.errorDecoder(resp), ShouldEqual, errTeapot)
+	})
+
+	Convey("Should keep DecodeError if decoder is nil", t, func() {
+		m := NewMockRestClient(restclient.WithErrorDecoder(nil))
+		So(m.errorDecoder, ShouldNotBeNil)
+	})
+}
+
+func TestBatch(t *testing.T) {
+	Convey("Should dispatch results and errors of batch calls", t, func() {
+		ts := httptest.NewServer(rest.BatchHandler(map[string]rest.BatchMethod{
+			"GetUser": func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				var p struct {
+					UserId int `json:"userId"`
+				}
+				if err := rest.DecodeBatchParams(params, &p); err != nil {
+					return nil, err
+				}
+				if p.UserId == 0 {
+					return nil, rest.NewBizError(errors.New("user not found"), rest.WithStatusCode(http.StatusNotFound), rest.WithErrCode(100404))
+				}
+				return struct {
+					Name string `json:"name"`
+				}{fmt.Sprintf("user%d", p.UserId)}, nil
+			},
+		}))
+		defer ts.Close()
+
+		var batch restclient.Batch
+		var name string
+		found := batch.Add("GetUser", map[string]int{"userId": 1}, func(result json.RawMessage) error {
+			r := struct {
+				Name *string `json:"name"`
+			}{&name}
+			return json.Unmarshal(result, &r)
+		})
+		notFound := batch.Add("GetUser", map[string]int{"userId": 0}, nil)
+		unknown := batch.Add("DeleteUser", nil, nil)
+		So(found.Err(), ShouldNotBeNil)
+		So(batch.Len(), ShouldEqual, 3)
+
+		So(batch.Do(resty.New().R(), ts.URL), ShouldBeNil)
+		So(found.Err(), ShouldBeNil)
+		So(name, ShouldEqual, "user1")
+		var bz rest.BizError
+		So(errors.As(notFound.Err(), &bz), ShouldBeTrue)
+		So(bz.StatusCode, ShouldEqual, http.StatusNotFound)
+		So(bz.ErrCode, ShouldEqual, 100404)
+		So(errors.As(unknown.Err(), &bz), ShouldBeTrue)
+		So(bz.StatusCode, ShouldEqual, http.StatusNotFound)
+	})
+}
+
+type emptyServiceProvider struct{}
+
+func (e emptyServiceProvider) SelectServer() string {
+	return ""
+}
+
+func (e emptyServiceProvider) Close() {
+}
+
+func TestSelectServer(t *testing.T) {
+	Convey("Should return ErrNoAvailableServer if cluster is empty", t, func() {
+		_, err := restclient.SelectServer(restclient.NewRegistryProvider("usersvc", emptyServiceProvider{}))
+		So(errors.Is(err, restclient.ErrNoAvailableServer), ShouldBeTrue)
+		So(err.Error(), ShouldContainSubstring, "usersvc")
+
+		os.Setenv("USERSVC", "http://localhost:6060")
+		defer os.Unsetenv("USERSVC")
+		server, err := restclient.SelectServer(restclient.NewRegistryProvider("usersvc", restclient.NewServiceProvider("USERSVC")))
+		So(err, ShouldBeNil)
+		So(server, ShouldEqual, "http://localhost:6060")
+	})
+}
+
+func TestIsNilProvider(t *testing.T) {
+	Convey("Should detect nil pointer assigned to provider interface", t, func() {
+		var provider *restclient.RegistryProvider
+		So(restclient.IsNilProvider(nil), ShouldBeTrue)
+		So(restclient.IsNilProvider(provider), ShouldBeTrue)
+		So(restclient.IsNilProvider(emptyServiceProvider{}), ShouldBeFalse)
+		So(restclient.IsNilProvider(restclient.NewRegistryProvider("usersvc", emptyServiceProvider{})), ShouldBeFalse)
+	})
+}
+
+func TestFanOut(t *testing.T) {
+	Convey("Should collect errors per backend and keep results of succeeded backends", t, func() {
+		var user, order string
+		f := restclient.NewFanOut(context.Background(), false)
+		f.Go("usersvc", func(ctx context.Context) error {
+			user = "jack"
+			return nil
+		})
+		f.Go("ordersvc", func(ctx context.Context) error {
+			order = "order-1"
+			return nil
+		})
+		f.Go("stocksvc", func(ctx context.Context) error {
+			return errors.New("timeout")
+		})
+		f.Go("paysvc", func(ctx context.Context) error {
+			panic("boom")
+		})
+		err := f.Wait()
+		So(user, ShouldEqual, "jack")
+		So(order, ShouldEqual, "order-1")
+		var fanOutErr *restclient.FanOutError
+		So(errors.As(err, &fanOutErr), ShouldBeTrue)
+		So(fanOutErr.Errors, ShouldHaveLength, 2)
+		So(fanOutErr.Err("usersvc"), ShouldBeNil)
+		So(fanOutErr.Err("stocksvc").Error(), ShouldEqual, "timeout")
+		So(fanOutErr.Err("paysvc").Error(), ShouldContainSubstring, "boom")
+		So(err.Error(), ShouldStartWith, "[go-doudou] fan-out failed: paysvc")
+	})
+
+	Convey("Should cancel other calls if fail fast", t, func() {
+		f := restclient.NewFanOut(context.Background(), true)
+		f.Go("usersvc", func(ctx context.Context) error {
+			return errors.New("unavailable")
+		})
+		f.Go("ordersvc", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		err := f.Wait()
+		So(err, ShouldNotBeNil)
+		So(err.(*restclient.FanOutError).Err("ordersvc"), ShouldEqual, context.Canceled)
+	})
+
+	Convey("Should return nil if all calls succeeded", t, func() {
+		f := restclient.NewFanOut(context.Background(), false)
+		f.Go("usersvc", func(ctx context.Context) error {
+			return nil
+		})
+		So(f.Wait(), ShouldBeNil)
+	})
+}
+
+func TestPropagateTenant(t *testing.T) {
+	Convey("Should propagate tenant id from context", t, func() {
+		var got string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get(rest.TenantHeader)
+		}))
+		defer ts.Close()
+		client := resty.New()
+		client.OnBeforeRequest(func(_ *resty.Client, request *resty.Request) error {
+			restclient.PropagateTenant(request)
+			return nil
+		})
+		_, err := client.R().SetContext(rest.NewTenantContext(context.Background(), "tenant-1")).Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "tenant-1")
+
+		_, err = client.R().SetContext(rest.NewTenantContext(context.Background(), "tenant-1")).
+			SetHeader(rest.TenantHeader, "tenant-2").Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "tenant-2")
+
+		_, err = client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(got, ShouldEqual, "")
+	})
+}
+
+func TestPropagateCorrelation(t *testing.T) {
+	Convey("Should propagate correlation id and id of current hop as causation id", t, func() {
+		var correlationID, causationID string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			correlationID = r.Header.Get(rest.CorrelationIDHeader)
+			causationID = r.Header.Get(rest.CausationIDHeader)
+		}))
+		defer ts.Close()
+		client := resty.New()
+		client.OnBeforeRequest(func(_ *resty.Client, request *resty.Request) error {
+			restclient.PropagateCorrelation(request)
+			return nil
+		})
+		ctx := rest.NewCorrelationContext(context.Background(), rest.CorrelationIDs{
+			CorrelationID: "flow-1",
+			CausationID:   "parent-1",
+			ID:            "rid-1",
+		})
+		_, err := client.R().SetContext(ctx).Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(correlationID, ShouldEqual, "flow-1")
+		So(causationID, ShouldEqual, "rid-1")
+
+		_, err = client.R().SetContext(ctx).SetHeader(rest.CausationIDHeader, "event-1").Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(causationID, ShouldEqual, "event-1")
+
+		_, err = client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(correlationID, ShouldEqual, "")
+		So(causationID, ShouldEqual, "")
+	})
+}
+
+func TestPropagateTraceContext(t *testing.T) {
+	Convey("Should propagate OpenTelemetry span in request context as traceparent header", t, func() {
+		var traceparent string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparent = r.Header.Get("traceparent")
+		}))
+		defer ts.Close()
+		client := resty.New()
+		client.OnBeforeRequest(func(_ *resty.Client, request *resty.Request) error {
+			restclient.PropagateTraceContext(request)
+			return nil
+		})
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}))
+		_, err := client.R().SetContext(ctx).Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(traceparent, ShouldEqual, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		_, err = client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(traceparent, ShouldEqual, "")
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	Convey("Should parse Retry-After in both delta-seconds and HTTP-date forms", t, func() {
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		d, ok := restclient.ParseRetryAfter("3", now)
+		So(ok, ShouldBeTrue)
+		So(d, ShouldEqual, 3*time.Second)
+
+		d, ok = restclient.ParseRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat), now)
+		So(ok, ShouldBeTrue)
+		So(d, ShouldEqual, 5*time.Second)
+
+		d, ok = restclient.ParseRetryAfter(now.Add(-5*time.Second).Format(http.TimeFormat), now)
+		So(ok, ShouldBeTrue)
+		So(d, ShouldEqual, 0)
+
+		_, ok = restclient.ParseRetryAfter("-1", now)
+		So(ok, ShouldBeFalse)
+		_, ok = restclient.ParseRetryAfter("soon", now)
+		So(ok, ShouldBeFalse)
+		_, ok = restclient.ParseRetryAfter("", now)
+		So(ok, ShouldBeFalse)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	Convey("Should wait as Retry-After indicates before retrying, capped by max wait", t, func() {
+		_ = config.GddRetryCount.Write("1")
+		defer os.Unsetenv(string(config.GddRetryCount))
+		_ = config.GddRetryMaxWait.Write("1500ms")
+		defer os.Unsetenv(string(config.GddRetryMaxWait))
+
+		var calls int32
+		var retryAfter string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1)%2 == 1 {
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Write([]byte("OK"))
+		}))
+		defer ts.Close()
+		client := restclient.NewClient()
+
+		retryAfter = "1"
+		start := time.Now()
+		resp, err := client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode(), ShouldEqual, http.StatusOK)
+		So(time.Since(start), ShouldBeGreaterThanOrEqualTo, time.Second)
+
+		retryAfter = "3600"
+		start = time.Now()
+		resp, err = client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode(), ShouldEqual, http.StatusOK)
+		So(time.Since(start), ShouldBeBetween, 1500*time.Millisecond, 3*time.Second)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 4)
+	})
+}
+
+func TestRetryOnBackpressure_NonIdempotent(t *testing.T) {
+	Convey("Should not retry non-idempotent requests on backpressure or transport error unless opted in", t, func() {
+		_ = config.GddRetryCount.Write("2")
+		defer os.Unsetenv(string(config.GddRetryCount))
+		_ = config.GddRetryMaxWait.Write("10ms")
+		defer os.Unsetenv(string(config.GddRetryMaxWait))
+
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer ts.Close()
+		client := restclient.NewClient()
+		resp, err := client.R().Post(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode(), ShouldEqual, http.StatusTooManyRequests)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+
+		atomic.StoreInt32(&calls, 0)
+		_, err = client.R().SetContext(restclient.WithRetryNonIdempotent(context.Background())).Post(ts.URL)
+		So(err, ShouldBeNil)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 3)
+
+		// connections are closed before any response
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		defer ln.Close()
+		var accepts int32
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				atomic.AddInt32(&accepts, 1)
+				conn.Close()
+			}
+		}()
+		_, err = client.R().SetBody(map[string]string{"name": "go-doudou"}).Post("http://" + ln.Addr().String())
+		So(err, ShouldNotBeNil)
+		So(atomic.LoadInt32(&accepts), ShouldEqual, 1)
+
+		atomic.StoreInt32(&accepts, 0)
+		_, err = client.R().Get("http://" + ln.Addr().String())
+		So(err, ShouldNotBeNil)
+		So(atomic.LoadInt32(&accepts), ShouldBeGreaterThanOrEqualTo, 3)
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	Convey("Should retry idempotent requests on 5xx with exponential backoff", t, func() {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1)%3 != 0 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte("OK"))
+		}))
+		defer ts.Close()
+		m := NewMockRestClient(restclient.WithRetry(2, 10*time.Millisecond, 50*time.Millisecond))
+		So(m.client.RetryCount, ShouldEqual, 2)
+		So(m.client.RetryWaitTime, ShouldEqual, 10*time.Millisecond)
+		So(m.client.RetryMaxWaitTime, ShouldEqual, 50*time.Millisecond)
+
+		resp, err := m.client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode(), ShouldEqual, http.StatusOK)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 3)
+
+		atomic.StoreInt32(&calls, 0)
+		resp, err = m.client.R().Post(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode(), ShouldEqual, http.StatusBadGateway)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+
+		atomic.StoreInt32(&calls, 0)
+		resp, err = m.client.R().SetContext(restclient.WithRetryNonIdempotent(context.Background())).Post(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode(), ShouldEqual, http.StatusOK)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 3)
+	})
+
+	Convey("Should stop retrying once context is done", t, func() {
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+		m := NewMockRestClient(restclient.WithRetry(5, time.Second, time.Second))
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := m.client.R().SetContext(ctx).Get(ts.URL)
+		So(err, ShouldNotBeNil)
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+	})
+}
+
 func TestMain(m *testing.M) {
 	setup()
 	m.Run()
@@ -86,3 +587,156 @@ func setup() {
 	_ = config.GddPort.Write("8088")
 	_ = config.GddRouteRootPath.Write("/v1")
 }
+
+func TestSetContext_CancelInFlight(t *testing.T) {
+	Convey("Cancelling context should abort in-flight request", t, func() {
+		release := make(chan struct{})
+		defer close(release)
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer ts.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		start := time.Now()
+		_, err := restclient.NewClient().R().SetContext(ctx).Get(ts.URL)
+		So(err, ShouldNotBeNil)
+		So(errors.Is(err, context.Canceled), ShouldBeTrue)
+		So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+	})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	Convey("Circuit breaker should trip open on 5xx but not on 4xx", t, func() {
+		var status int32 = http.StatusBadRequest
+		var hits int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&hits, 1)
+			w.WriteHeader(int(atomic.LoadInt32(&status)))
+		}))
+		defer ts.Close()
+		var changes []gobreaker.State
+		m := NewMockRestClient(restclient.WithCircuitBreaker(restclient.BreakerConfig{
+			Name:         "mock",
+			FailureRatio: 0.5,
+			MinRequests:  3,
+			Timeout:      time.Minute,
+			OnStateChange: func(name string, from, to gobreaker.State) {
+				changes = append(changes, to)
+			},
+		}))
+		send := func() (*resty.Response, error) {
+			return m.breaker.Execute(func() (*resty.Response, error) {
+				return m.client.R().Get(ts.URL)
+			})
+		}
+		for i := 0; i < 5; i++ {
+			resp, err := send()
+			So(err, ShouldBeNil)
+			So(resp.StatusCode(), ShouldEqual, http.StatusBadRequest)
+		}
+		So(m.breaker.State(), ShouldEqual, gobreaker.StateClosed)
+
+		atomic.StoreInt32(&status, http.StatusInternalServerError)
+		for i := 0; i < 5; i++ {
+			_, _ = send()
+		}
+		So(m.breaker.State(), ShouldEqual, gobreaker.StateOpen)
+		So(changes, ShouldResemble, []gobreaker.State{gobreaker.StateOpen})
+
+		before := atomic.LoadInt32(&hits)
+		resp, err := send()
+		So(resp, ShouldBeNil)
+		So(errors.Is(err, restclient.ErrCircuitOpen), ShouldBeTrue)
+		So(atomic.LoadInt32(&hits), ShouldEqual, before)
+	})
+
+	Convey("Circuit breaker should not trip open on canceled or timed out requests", t, func() {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer ts.Close()
+		m := NewMockRestClient(restclient.WithCircuitBreaker(restclient.BreakerConfig{
+			Name:         "mock",
+			FailureRatio: 0.5,
+			MinRequests:  3,
+			Timeout:      time.Minute,
+		}))
+		for i := 0; i < 6; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			if i%2 == 0 {
+				cancel()
+			}
+			_, err := m.breaker.Execute(func() (*resty.Response, error) {
+				return m.client.R().SetContext(ctx).Get(ts.URL)
+			})
+			cancel()
+			So(err, ShouldNotBeNil)
+			So(errors.Is(err, restclient.ErrCircuitOpen), ShouldBeFalse)
+		}
+		So(m.breaker.State(), ShouldEqual, gobreaker.StateClosed)
+	})
+
+	Convey("Nil circuit breaker should send request directly", t, func() {
+		var cb *restclient.CircuitBreaker
+		_, err := cb.Execute(func() (*resty.Response, error) {
+			return nil, nil
+		})
+		So(err, ShouldBeNil)
+	})
+}
+
+func TestWithProxy(t *testing.T) {
+	Convey("Should send requests through proxy server", t, func() {
+		var host string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host = r.URL.Host
+			w.Write([]byte("OK"))
+		}))
+		defer proxy.Close()
+		m := NewMockRestClient(restclient.WithProxy(proxy.URL))
+		resp, err := m.client.R().Get("http://go-doudou.invalid/hello")
+		So(err, ShouldBeNil)
+		So(resp.String(), ShouldEqual, "OK")
+		So(host, ShouldEqual, "go-doudou.invalid")
+	})
+}
+
+func TestWithTLSClientConfig(t *testing.T) {
+	Convey("Should verify server certificate by tls client config", t, func() {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		}))
+		defer ts.Close()
+		_, err := NewMockRestClient().client.R().Get(ts.URL)
+		So(err, ShouldNotBeNil)
+
+		tlsConfig := ts.Client().Transport.(*http.Transport).TLSClientConfig
+		m := NewMockRestClient(restclient.WithTLSClientConfig(tlsConfig))
+		resp, err := m.client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.String(), ShouldEqual, "OK")
+	})
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	Convey("Should replace http client and ignore proxy and tls client config", t, func() {
+		ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("OK"))
+		}))
+		defer ts.Close()
+		hc := ts.Client()
+		m := NewMockRestClient(restclient.WithHTTPClient(hc), restclient.WithProxy("http://127.0.0.1:1"),
+			restclient.WithTLSClientConfig(&tls.Config{ServerName: "go-doudou.invalid"}))
+		So(m.client.GetClient().Transport, ShouldEqual, hc.Transport)
+		resp, err := m.client.R().Get(ts.URL)
+		So(err, ShouldBeNil)
+		So(resp.String(), ShouldEqual, "OK")
+	})
+}
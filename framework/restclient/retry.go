@@ -0,0 +1,78 @@
+package restclient
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"net/http"
+	"time"
+)
+
+// RetryConfig configures retry with exponential backoff of generated clients
+type RetryConfig struct {
+	// Count is max retry count, 0 means no retry
+	Count int
+	// WaitTime is initial wait time before retry, which doubles on every retry with jitter
+	WaitTime time.Duration
+	// MaxWaitTime caps wait time before retry
+	MaxWaitTime time.Duration
+}
+
+// WithRetry makes generated client retry requests responding 5xx or failed to be sent by count times, waiting from
+// waitTime up to maxWaitTime with exponential backoff between retries. Retries stop once context of the request is done.
+// Only idempotent requests are retried, non-idempotent requests like POST are retried only if sent with context
+// returned by WithRetryNonIdempotent, e.g. by setting RetryNonIdempotent of generated Options.
+func WithRetry(count int, waitTime, maxWaitTime time.Duration) RestClientOption {
+	return func(c RestClient) {
+		if r, ok := c.(interface{ SetRetry(retry RetryConfig) }); ok {
+			r.SetRetry(RetryConfig{
+				Count:       count,
+				WaitTime:    waitTime,
+				MaxWaitTime: maxWaitTime,
+			})
+		}
+	}
+}
+
+// ApplyRetry configures client to retry by retry. It is called by generated constructors after applying options,
+// so that it takes effect on http client set by WithClient or WithPool too.
+func ApplyRetry(client *resty.Client, retry RetryConfig) {
+	client.SetRetryCount(retry.Count)
+	if retry.WaitTime > 0 {
+		client.SetRetryWaitTime(retry.WaitTime)
+	}
+	if retry.MaxWaitTime > 0 {
+		client.SetRetryMaxWaitTime(retry.MaxWaitTime)
+	}
+	client.AddRetryCondition(RetryOnServerError)
+}
+
+type retryNonIdempotentKey struct{}
+
+// WithRetryNonIdempotent returns a copy of ctx opting in retrying non-idempotent requests sent with it
+func WithRetryNonIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryNonIdempotentKey{}, true)
+}
+
+func retryable(req *resty.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	optIn, _ := req.Context().Value(retryNonIdempotentKey{}).(bool)
+	return optIn
+}
+
+// RetryOnServerError is a resty retry condition which retries idempotent requests responding 5xx or failed to be sent,
+// and non-idempotent requests too if they are sent with context returned by WithRetryNonIdempotent.
+func RetryOnServerError(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || !retryable(resp.Request) {
+		return false
+	}
+	if resp.Request.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return resp.RawResponse == nil
+	}
+	return resp.StatusCode() >= http.StatusInternalServerError
+}
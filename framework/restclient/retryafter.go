@@ -0,0 +1,74 @@
+package restclient
+
+import (
+	"github.com/go-resty/resty/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRetryAfter parses value of Retry-After header in either delta-seconds or HTTP-date form,
+// and returns how long to wait from now. It returns false if value is invalid.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+func backpressure(resp *resty.Response) bool {
+	if resp == nil {
+		return false
+	}
+	return resp.StatusCode() == http.StatusTooManyRequests || resp.StatusCode() == http.StatusServiceUnavailable
+}
+
+// RetryOnBackpressure is a resty retry condition which retries 429 and 503 responses carrying Retry-After header.
+// As resty overrides its default condition with custom conditions, it also retries requests failed to be sent,
+// but not those failed in request or response middlewares. Like RetryOnServerError, only idempotent requests and
+// requests sent with context returned by WithRetryNonIdempotent are retried.
+func RetryOnBackpressure(resp *resty.Response, err error) bool {
+	if resp == nil || resp.Request == nil || !retryable(resp.Request) {
+		return false
+	}
+	if resp.Request.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return resp.RawResponse == nil
+	}
+	return backpressure(resp) && resp.Header().Get("Retry-After") != ""
+}
+
+// RetryAfter is a resty retry after function which waits the duration indicated by Retry-After header of 429 and
+// 503 responses, capped by retry max wait time of the client. For other responses, it returns zero to make resty
+// fall back to exponential backoff.
+func RetryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	if !backpressure(resp) {
+		return 0, nil
+	}
+	d, ok := ParseRetryAfter(resp.Header().Get("Retry-After"), time.Now())
+	if !ok {
+		return 0, nil
+	}
+	if d == 0 {
+		// resty takes zero as no preference, wait as short as possible instead
+		return time.Nanosecond, nil
+	}
+	return d, nil
+}
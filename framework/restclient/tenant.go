@@ -0,0 +1,17 @@
+package restclient
+
+import (
+	"github.com/go-resty/resty/v2"
+	"github.com/unionj-cloud/go-doudou/v2/framework/rest"
+)
+
+// PropagateTenant sets X-Tenant-Id header of request from tenant id in request context put by rest.Tenant middleware
+// or rest.NewTenantContext, unless the header has been set explicitly. It is called by generated http clients.
+func PropagateTenant(request *resty.Request) {
+	if request.Header.Get(rest.TenantHeader) != "" {
+		return
+	}
+	if tenantID, ok := rest.TenantFromContext(request.Context()); ok {
+		request.SetHeader(rest.TenantHeader, tenantID)
+	}
+}
@@ -0,0 +1,114 @@
+package restclient
+
+import (
+	"crypto/tls"
+	"github.com/go-resty/resty/v2"
+	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
+	"net/http"
+	"net/url"
+)
+
+// TransportConfig configures transport of generated clients. HTTPClient takes precedence over Proxy and
+// TLSClientConfig: if it is set, the http client of resty client is replaced by it as a whole, and Proxy and
+// TLSClientConfig are ignored. Otherwise Proxy and TLSClientConfig are set on the underlying *http.Transport,
+// keeping connection pool, tracing and gzip of the client.
+type TransportConfig struct {
+	// HTTPClient replaces http client of resty client, including its transport, timeout and cookie jar
+	HTTPClient *http.Client
+	// Proxy is url of proxy server, e.g. http://127.0.0.1:8080, which overrides proxy from environment variables
+	Proxy string
+	// TLSClientConfig is used by tls.Client for https requests
+	TLSClientConfig *tls.Config
+}
+
+// IsZero reports whether conf changes nothing
+func (conf TransportConfig) IsZero() bool {
+	return conf.HTTPClient == nil && conf.Proxy == "" && conf.TLSClientConfig == nil
+}
+
+type transportConfigurer interface {
+	TransportConfig() *TransportConfig
+}
+
+func withTransport(fn func(conf *TransportConfig)) RestClientOption {
+	return func(c RestClient) {
+		if t, ok := c.(transportConfigurer); ok {
+			fn(t.TransportConfig())
+		}
+	}
+}
+
+// WithHTTPClient makes generated client send requests by client. It fully replaces the http client of resty client
+// rather than merging with it, so WithProxy, WithTLSClientConfig and the pool of WithPool take no effect.
+// Timeout of client applies instead of default 1 minute.
+func WithHTTPClient(client *http.Client) RestClientOption {
+	return withTransport(func(conf *TransportConfig) {
+		conf.HTTPClient = client
+	})
+}
+
+// WithProxy makes generated client send requests through proxy server at proxyURL, overriding proxy from
+// environment variables. It is ignored if WithHTTPClient is given.
+func WithProxy(proxyURL string) RestClientOption {
+	return withTransport(func(conf *TransportConfig) {
+		conf.Proxy = proxyURL
+	})
+}
+
+// WithTLSClientConfig makes generated client use tlsConfig for https requests, e.g. for custom root CAs or client
+// certificates. It is ignored if WithHTTPClient is given.
+func WithTLSClientConfig(tlsConfig *tls.Config) RestClientOption {
+	return withTransport(func(conf *TransportConfig) {
+		conf.TLSClientConfig = tlsConfig
+	})
+}
+
+// baseTransport is set by NewClientWithPool to keep the *http.Transport wrapped by tracing and gzip transports
+// reachable, so that ApplyTransport can configure it
+type baseTransport struct {
+	http.RoundTripper
+	base *http.Transport
+}
+
+func transportOf(client *resty.Client) (*http.Transport, bool) {
+	switch t := client.GetClient().Transport.(type) {
+	case *baseTransport:
+		return t.base, true
+	case *http.Transport:
+		return t, true
+	default:
+		return nil, false
+	}
+}
+
+// ApplyTransport configures client by conf. It is called by generated constructors after applying options,
+// so that it takes effect on http client set by WithClient or WithPool too. See TransportConfig for precedence.
+// Invalid proxy url and transports other than *http.Transport are logged and skipped.
+func ApplyTransport(client *resty.Client, conf TransportConfig) {
+	if conf.IsZero() {
+		return
+	}
+	if conf.HTTPClient != nil {
+		if conf.Proxy != "" || conf.TLSClientConfig != nil {
+			logger.Warn().Msg("proxy and tls client config are ignored as http client is set")
+		}
+		*client.GetClient() = *conf.HTTPClient
+		return
+	}
+	transport, ok := transportOf(client)
+	if !ok {
+		logger.Error().Msgf("proxy and tls client config are not applied to transport in type %T", client.GetClient().Transport)
+		return
+	}
+	if conf.Proxy != "" {
+		proxyURL, err := url.Parse(conf.Proxy)
+		if err != nil {
+			logger.Error().Err(err).Msgf("invalid proxy url %s", conf.Proxy)
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+	if conf.TLSClientConfig != nil {
+		transport.TLSClientConfig = conf.TLSClientConfig
+	}
+}
@@ -4,16 +4,60 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
 	"github.com/uber/jaeger-client-go/rpcmetrics"
 	"github.com/uber/jaeger-lib/metrics"
 	jprom "github.com/uber/jaeger-lib/metrics/prometheus"
 	ddconfig "github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/cast"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
 	logger "github.com/unionj-cloud/go-doudou/v2/toolkit/zlogger"
 	"io"
+	"sync/atomic"
 )
 
+// Sampler describes effective sampler of the tracer created by Init
+type Sampler struct {
+	Type  string
+	Param float64
+}
+
+var effectiveSampler atomic.Value
+
+// EffectiveSampler returns sampler of the tracer created by Init, so that operators can verify it
+func EffectiveSampler() Sampler {
+	if s, ok := effectiveSampler.Load().(Sampler); ok {
+		return s
+	}
+	return Sampler{}
+}
+
+// applySampleRatio sets head-based sampler by GddTraceSampleRatio if it is set. Child spans of
+// sampled upstream trace context are always sampled by jaeger tracer regardless of the sampler.
+func applySampleRatio(cfg *config.SamplerConfig) {
+	if stringutils.IsEmpty(ddconfig.GddTraceSampleRatio.Load()) {
+		return
+	}
+	ratio, err := cast.ToFloat64E(ddconfig.GddTraceSampleRatio.Load())
+	if err != nil {
+		logger.Debug().Msgf("Parse %s %s as float64 failed: %s, use default %v instead.\n", string(ddconfig.GddTraceSampleRatio),
+			ddconfig.GddTraceSampleRatio.Load(), err.Error(), ddconfig.DefaultGddTraceSampleRatio)
+		ratio = ddconfig.DefaultGddTraceSampleRatio
+	}
+	switch {
+	case ratio >= 1:
+		cfg.Type = jaeger.SamplerTypeConst
+		cfg.Param = 1
+	case ratio <= 0:
+		cfg.Type = jaeger.SamplerTypeConst
+		cfg.Param = 0
+	default:
+		cfg.Type = jaeger.SamplerTypeProbabilistic
+		cfg.Param = ratio
+	}
+}
+
 // Init returns an instance of Jaeger Tracer.
 func Init() (opentracing.Tracer, io.Closer) {
 	cfg := &config.Configuration{
@@ -32,6 +76,12 @@ func Init() (opentracing.Tracer, io.Closer) {
 	if err != nil {
 		logger.Panic().Err(errors.Wrap(err, "[go-doudou] cannot parse Jaeger env vars")).Msg("")
 	}
+	applySampleRatio(cfg.Sampler)
+	effectiveSampler.Store(Sampler{
+		Type:  cfg.Sampler.Type,
+		Param: cfg.Sampler.Param,
+	})
+	logger.Info().Msgf("Tracing sampler: %s %v", cfg.Sampler.Type, cfg.Sampler.Param)
 	jaegerLogger := jaegerLoggerAdapter{logger: logger.Logger}
 	metricsRoot := ddconfig.DefaultGddTracingMetricsRoot
 	if stringutils.IsNotEmpty(ddconfig.GddTracingMetricsRoot.Load()) {
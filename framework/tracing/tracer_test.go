@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/config"
+	ddconfig "github.com/unionj-cloud/go-doudou/v2/framework/internal/config"
+)
+
+func Test_applySampleRatio(t *testing.T) {
+	tests := []struct {
+		ratio     string
+		wantType  string
+		wantParam float64
+	}{
+		{"", jaeger.SamplerTypeConst, 1},
+		{"0.25", jaeger.SamplerTypeProbabilistic, 0.25},
+		{"0", jaeger.SamplerTypeConst, 0},
+		{"2", jaeger.SamplerTypeConst, 1},
+		{"abc", jaeger.SamplerTypeConst, 1},
+	}
+	defer ddconfig.GddTraceSampleRatio.Write("")
+	for _, tt := range tests {
+		_ = ddconfig.GddTraceSampleRatio.Write(tt.ratio)
+		cfg := &config.SamplerConfig{Type: jaeger.SamplerTypeConst, Param: 1}
+		applySampleRatio(cfg)
+		require.Equal(t, tt.wantType, cfg.Type, tt.ratio)
+		require.Equal(t, tt.wantParam, cfg.Param, tt.ratio)
+	}
+}
+
+func TestInit_EffectiveSampler(t *testing.T) {
+	_ = ddconfig.GddServiceName.Write("tracing")
+	_ = ddconfig.GddTraceSampleRatio.Write("0.1")
+	defer ddconfig.GddTraceSampleRatio.Write("")
+	_, closer := Init()
+	defer closer.Close()
+	require.Equal(t, Sampler{Type: jaeger.SamplerTypeProbabilistic, Param: 0.1}, EffectiveSampler())
+}
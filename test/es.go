@@ -0,0 +1,87 @@
+// Package test provides testcontainers helpers for integration tests depending on external services.
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"time"
+)
+
+const (
+	esImage = "docker.elastic.co/elasticsearch/elasticsearch"
+	// DefaultEs7Tag is the elasticsearch image tag used by SetupEs7Container if tag is empty
+	DefaultEs7Tag = "7.17.9"
+	// DefaultEs8Tag is the elasticsearch image tag used by SetupEs8Container if tag is empty
+	DefaultEs8Tag = "8.6.2"
+	// esStartupTimeout is max waiting time for elasticsearch cluster health api to respond 200
+	esStartupTimeout = 120 * time.Second
+)
+
+// esContainerRequest returns request for a single node elasticsearch container of image tag with xpack security
+// disabled, which is ready once /_cluster/health responds 200, rather than matching a log line varying across versions
+func esContainerRequest(tag string) testcontainers.ContainerRequest {
+	return testcontainers.ContainerRequest{
+		Image:        fmt.Sprintf("%s:%s", esImage, tag),
+		ExposedPorts: []string{"9200/tcp"},
+		Env: map[string]string{
+			"discovery.type":         "single-node",
+			"xpack.security.enabled": "false",
+			"ES_JAVA_OPTS":           "-Xms512m -Xmx512m",
+		},
+		WaitingFor: wait.ForHTTP("/_cluster/health").WithPort("9200/tcp").WithStartupTimeout(esStartupTimeout),
+	}
+}
+
+func setupEsContainer(logger zerolog.Logger, req testcontainers.ContainerRequest) (func() error, string, int, error) {
+	logger.Info().Msgf("setup Elasticsearch Container %s", req.Image)
+	ctx := context.Background()
+	esC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, "", 0, errors.Wrapf(err, "error starting elasticsearch container %s", req.Image)
+	}
+
+	closeContainer := func() error {
+		logger.Info().Msg("terminating container")
+		return errors.Wrap(esC.Terminate(ctx), "error terminating elasticsearch container")
+	}
+
+	host, err := esC.Host(ctx)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting elasticsearch container host")
+	}
+	p, err := esC.MappedPort(ctx, "9200/tcp")
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting elasticsearch container port")
+	}
+	return closeContainer, host, p.Int(), nil
+}
+
+// SetupEs7Container starts a single node elasticsearch 7 container with xpack security disabled and returns a function
+// terminating it, together with host and port of its http api. It returns error rather than panicking if the container
+// fails to start, so that callers can skip tests if docker is not available. tag overrides image tag, DefaultEs7Tag is used if empty.
+func SetupEs7Container(logger zerolog.Logger, tag string) (func() error, string, int, error) {
+	if stringutils.IsEmpty(tag) {
+		tag = DefaultEs7Tag
+	}
+	return setupEsContainer(logger, esContainerRequest(tag))
+}
+
+// SetupEs8Container starts a single node elasticsearch 8 container with xpack security, which is enabled by default
+// since 8, disabled, so that tests can access it by plain http without credentials. It returns the same as
+// SetupEs7Container. tag overrides image tag, DefaultEs8Tag is used if empty.
+func SetupEs8Container(logger zerolog.Logger, tag string) (func() error, string, int, error) {
+	if stringutils.IsEmpty(tag) {
+		tag = DefaultEs8Tag
+	}
+	return setupEsContainer(logger, esContainerRequest(tag))
+}
@@ -0,0 +1,19 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestEsContainerRequest(t *testing.T) {
+	req := esContainerRequest(DefaultEs8Tag)
+	require.Equal(t, "docker.elastic.co/elasticsearch/elasticsearch:8.6.2", req.Image)
+	require.Equal(t, "false", req.Env["xpack.security.enabled"])
+	require.Equal(t, "single-node", req.Env["discovery.type"])
+	strategy, ok := req.WaitingFor.(*wait.HTTPStrategy)
+	require.True(t, ok)
+	require.Equal(t, "/_cluster/health", strategy.Path)
+	require.Equal(t, "9200/tcp", string(strategy.Port))
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultKafkaTag is the apache/kafka image tag used by SetupKafkaContainer if tag is empty
+	DefaultKafkaTag = "3.7.0"
+	// kafkaStartupTimeout is max waiting time for kafka to answer metadata request
+	kafkaStartupTimeout = 120 * time.Second
+	// kafkaPollInterval is the interval of sending metadata request while waiting for kafka
+	kafkaPollInterval = 500 * time.Millisecond
+	// kafkaPort is the container port of the listener for clients, which is mapped to a random port on docker host
+	kafkaPort = "9092/tcp"
+	// kafkaStarterScript is written into the container once the mapped port is known, the container waits for it
+	// before starting kafka
+	kafkaStarterScript = "/tmp/go-doudou-kafka-start.sh"
+)
+
+// waitForKafkaMetadata sends metadata request to addr until the response advertises a broker which is reachable
+// from host, or ctx is done. It returns the advertised broker.
+func waitForKafkaMetadata(ctx context.Context, addr string) (kafka.Broker, error) {
+	ticker := time.NewTicker(kafkaPollInterval)
+	defer ticker.Stop()
+	for {
+		brokers, err := kafkaBrokers(ctx, addr)
+		if err == nil {
+			if len(brokers) == 0 {
+				err = errors.New("no broker advertised")
+			} else {
+				broker := brokers[0]
+				var conn net.Conn
+				if conn, err = net.DialTimeout("tcp", net.JoinHostPort(broker.Host, strconv.Itoa(broker.Port)), time.Second); err == nil {
+					conn.Close()
+					return broker, nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return kafka.Broker{}, errors.Wrapf(ctx.Err(), "kafka is not ready: %s", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func kafkaBrokers(ctx context.Context, addr string) ([]kafka.Broker, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer conn.Close()
+	brokers, err := conn.Brokers()
+	return brokers, errors.WithStack(err)
+}
+
+// kafkaContainerRequest returns request for a single node kafka container of image tag running in KRaft mode without
+// zookeeper. As clients connect to the listener advertised by the broker, which must be the host and mapped port on
+// docker host, kafka is not started until kafkaStarterScript advertising them is written by kafkaStarterCmd.
+func kafkaContainerRequest(tag string) testcontainers.ContainerRequest {
+	return testcontainers.ContainerRequest{
+		Image:        fmt.Sprintf("apache/kafka:%s", tag),
+		ExposedPorts: []string{kafkaPort},
+		Entrypoint:   []string{"sh", "-c"},
+		Cmd: []string{fmt.Sprintf("while [ ! -f %s ]; do sleep 0.1; done; exec sh %s",
+			kafkaStarterScript, kafkaStarterScript)},
+		Env: map[string]string{
+			"KAFKA_NODE_ID":                                  "1",
+			"KAFKA_PROCESS_ROLES":                            "broker,controller",
+			"KAFKA_LISTENERS":                                "PLAINTEXT://:9092,BROKER://:9094,CONTROLLER://:9093",
+			"KAFKA_INTER_BROKER_LISTENER_NAME":               "BROKER",
+			"KAFKA_CONTROLLER_LISTENER_NAMES":                "CONTROLLER",
+			"KAFKA_LISTENER_SECURITY_PROTOCOL_MAP":           "CONTROLLER:PLAINTEXT,PLAINTEXT:PLAINTEXT,BROKER:PLAINTEXT",
+			"KAFKA_CONTROLLER_QUORUM_VOTERS":                 "1@localhost:9093",
+			"KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR":         "1",
+			"KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR": "1",
+			"KAFKA_TRANSACTION_STATE_LOG_MIN_ISR":            "1",
+			"KAFKA_GROUP_INITIAL_REBALANCE_DELAY_MS":         "0",
+		},
+	}
+}
+
+// kafkaStarterCmd returns command writing kafkaStarterScript, which advertises host:port to clients and starts kafka.
+// The script is moved into place at once, so that the container never runs a partially written one.
+func kafkaStarterCmd(host string, port int) []string {
+	script := fmt.Sprintf("export KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://%s,BROKER://localhost:9094\nexec /etc/kafka/docker/run\n",
+		net.JoinHostPort(host, strconv.Itoa(port)))
+	return []string{"sh", "-c", fmt.Sprintf("printf '%%s' '%s' > %s.tmp && mv %s.tmp %s",
+		script, kafkaStarterScript, kafkaStarterScript, kafkaStarterScript)}
+}
+
+// SetupKafkaContainer starts a single node kafka container and returns a function terminating it, together with host
+// and port of the listener advertised by the broker. Rather than matching log lines, it waits until a metadata request
+// succeeds and the advertised listener is reachable. tag overrides image tag of apache/kafka, DefaultKafkaTag is used
+// if empty. It returns error rather than panicking, so that callers can skip tests if docker is not available.
+func SetupKafkaContainer(logger zerolog.Logger, tag string) (func() error, string, int, error) {
+	if stringutils.IsEmpty(tag) {
+		tag = DefaultKafkaTag
+	}
+	req := kafkaContainerRequest(tag)
+	logger.Info().Msgf("setup Kafka Container %s", req.Image)
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaStartupTimeout)
+	defer cancel()
+	kafkaC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, "", 0, errors.Wrapf(err, "error starting kafka container %s", req.Image)
+	}
+
+	closeContainer := func() error {
+		logger.Info().Msg("terminating container")
+		return errors.Wrap(kafkaC.Terminate(context.Background()), "error terminating kafka container")
+	}
+
+	host, err := kafkaC.Host(ctx)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting kafka container host")
+	}
+	p, err := kafkaC.MappedPort(ctx, kafkaPort)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting kafka container port")
+	}
+	code, err := kafkaC.Exec(ctx, kafkaStarterCmd(host, p.Int()))
+	if err == nil && code != 0 {
+		err = errors.Errorf("exit code %d", code)
+	}
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error writing kafka starter script")
+	}
+
+	broker, err := waitForKafkaMetadata(ctx, net.JoinHostPort(host, strconv.Itoa(p.Int())))
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, err
+	}
+	return closeContainer, broker.Host, broker.Port, nil
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitForKafkaMetadata_Timeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err = waitForKafkaMetadata(ctx, addr)
+	require.Error(t, err)
+}
+
+func TestKafkaContainerRequest(t *testing.T) {
+	req := kafkaContainerRequest(DefaultKafkaTag)
+	require.Equal(t, "apache/kafka:3.7.0", req.Image)
+	require.Equal(t, []string{"9092/tcp"}, req.ExposedPorts)
+	require.NotContains(t, req.Env, "KAFKA_ADVERTISED_LISTENERS")
+	require.Contains(t, req.Cmd[0], kafkaStarterScript)
+}
+
+func TestKafkaStarterCmd(t *testing.T) {
+	cmd := kafkaStarterCmd("docker.internal", 32768)
+	require.Equal(t, []string{"sh", "-c"}, cmd[:2])
+	require.True(t, strings.Contains(cmd[2], "KAFKA_ADVERTISED_LISTENERS=PLAINTEXT://docker.internal:32768,BROKER://localhost:9094"))
+	require.True(t, strings.HasSuffix(cmd[2], "mv "+kafkaStarterScript+".tmp "+kafkaStarterScript))
+}
@@ -0,0 +1,180 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"os"
+	"time"
+)
+
+const (
+	// DefaultMySQLTag is the mysql image tag used by SetupMySQLContainer if not overridden by WithMySQLTag
+	DefaultMySQLTag = "8.0.32"
+	// DefaultMySQLRootPassword is the root password used by SetupMySQLContainer if not overridden by WithMySQLRootPassword
+	DefaultMySQLRootPassword = "1234"
+	// DefaultMySQLDatabase is the database created by SetupMySQLContainer if not overridden by WithMySQLDatabase
+	DefaultMySQLDatabase = "test"
+	// mysqlStartupTimeout is max waiting time for mysql to accept connections and respond to SELECT 1
+	mysqlStartupTimeout = 120 * time.Second
+	// mysqlPollInterval is the interval of trying SELECT 1 while waiting for mysql
+	mysqlPollInterval = 500 * time.Millisecond
+)
+
+type mysqlOptions struct {
+	tag          string
+	rootPassword string
+	database     string
+	initScripts  []string
+}
+
+// MySQLOption configures SetupMySQLContainer
+type MySQLOption func(*mysqlOptions)
+
+// WithMySQLTag overrides mysql image tag
+func WithMySQLTag(tag string) MySQLOption {
+	return func(o *mysqlOptions) {
+		o.tag = tag
+	}
+}
+
+// WithMySQLRootPassword overrides password of root user
+func WithMySQLRootPassword(password string) MySQLOption {
+	return func(o *mysqlOptions) {
+		o.rootPassword = password
+	}
+}
+
+// WithMySQLDatabase overrides name of the database created on startup and used by the returned dsn
+func WithMySQLDatabase(database string) MySQLOption {
+	return func(o *mysqlOptions) {
+		o.database = database
+	}
+}
+
+// WithMySQLInitScripts runs sql scripts in order against the database once it is ready, e.g. to seed schema.
+// Each script may contain multiple statements.
+func WithMySQLInitScripts(paths ...string) MySQLOption {
+	return func(o *mysqlOptions) {
+		o.initScripts = append(o.initScripts, paths...)
+	}
+}
+
+func newMySQLOptions(opts ...MySQLOption) mysqlOptions {
+	o := mysqlOptions{
+		tag:          DefaultMySQLTag,
+		rootPassword: DefaultMySQLRootPassword,
+		database:     DefaultMySQLDatabase,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func mysqlDSN(o mysqlOptions, host string, port int) string {
+	return fmt.Sprintf("root:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True", o.rootPassword, host, port, o.database)
+}
+
+// waitForSelectOne pings mysql by SELECT 1 until it succeeds or ctx is done, as mysql may restart once after
+// initializing data directory even if the port has been accepting connections
+func waitForSelectOne(ctx context.Context, db *sql.DB) error {
+	ticker := time.NewTicker(mysqlPollInterval)
+	defer ticker.Stop()
+	for {
+		_, err := db.ExecContext(ctx, "SELECT 1")
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "mysql is not ready: %s", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func runInitScripts(ctx context.Context, dsn string, scripts []string) error {
+	if len(scripts) == 0 {
+		return nil
+	}
+	db, err := sql.Open("mysql", dsn+"&multiStatements=true")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer db.Close()
+	for _, script := range scripts {
+		content, err := os.ReadFile(script)
+		if err != nil {
+			return errors.Wrapf(err, "error reading init script %s", script)
+		}
+		if _, err = db.ExecContext(ctx, string(content)); err != nil {
+			return errors.Wrapf(err, "error running init script %s", script)
+		}
+	}
+	return nil
+}
+
+// SetupMySQLContainer starts a mysql container and returns a function terminating it, together with dsn of the database
+// which is ready to use, i.e. the port is accepting connections, SELECT 1 succeeds and init scripts have been run.
+// It returns error rather than panicking, so that callers can skip tests if docker is not available.
+func SetupMySQLContainer(logger zerolog.Logger, opts ...MySQLOption) (func() error, string, error) {
+	o := newMySQLOptions(opts...)
+	image := fmt.Sprintf("mysql:%s", o.tag)
+	logger.Info().Msgf("setup MySQL Container %s", image)
+	ctx, cancel := context.WithTimeout(context.Background(), mysqlStartupTimeout)
+	defer cancel()
+	mysqlC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{"3306/tcp"},
+			Env: map[string]string{
+				"MYSQL_ROOT_PASSWORD": o.rootPassword,
+				"MYSQL_DATABASE":      o.database,
+			},
+			WaitingFor: wait.ForListeningPort("3306/tcp").WithStartupTimeout(mysqlStartupTimeout),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "error starting mysql container %s", image)
+	}
+
+	closeContainer := func() error {
+		logger.Info().Msg("terminating container")
+		return errors.Wrap(mysqlC.Terminate(context.Background()), "error terminating mysql container")
+	}
+
+	host, err := mysqlC.Host(ctx)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", errors.Wrap(err, "error getting mysql container host")
+	}
+	p, err := mysqlC.MappedPort(ctx, "3306/tcp")
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", errors.Wrap(err, "error getting mysql container port")
+	}
+	dsn := mysqlDSN(o, host, p.Int())
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", errors.WithStack(err)
+	}
+	defer db.Close()
+	if err = waitForSelectOne(ctx, db); err != nil {
+		_ = closeContainer()
+		return nil, "", err
+	}
+	if err = runInitScripts(ctx, dsn, o.initScripts); err != nil {
+		_ = closeContainer()
+		return nil, "", err
+	}
+	return closeContainer, dsn, nil
+}
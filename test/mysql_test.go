@@ -0,0 +1,31 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMySQLOptions(t *testing.T) {
+	o := newMySQLOptions()
+	require.Equal(t, DefaultMySQLTag, o.tag)
+	require.Equal(t, "root:1234@tcp(localhost:3306)/test?charset=utf8mb4&parseTime=True", mysqlDSN(o, "localhost", 3306))
+
+	o = newMySQLOptions(
+		WithMySQLTag("5.7"),
+		WithMySQLRootPassword("secret"),
+		WithMySQLDatabase("users"),
+		WithMySQLInitScripts("schema.sql"),
+		WithMySQLInitScripts("data.sql"),
+	)
+	require.Equal(t, "5.7", o.tag)
+	require.Equal(t, []string{"schema.sql", "data.sql"}, o.initScripts)
+	require.Equal(t, "root:secret@tcp(127.0.0.1:33060)/users?charset=utf8mb4&parseTime=True", mysqlDSN(o, "127.0.0.1", 33060))
+}
+
+func TestRunInitScripts_MissingFile(t *testing.T) {
+	require.NoError(t, runInitScripts(context.Background(), "root:1234@tcp(127.0.0.1:1)/test?charset=utf8mb4", nil))
+	err := runInitScripts(context.Background(), "root:1234@tcp(127.0.0.1:1)/test?charset=utf8mb4", []string{"not_exist.sql"})
+	require.ErrorContains(t, err, "not_exist.sql")
+}
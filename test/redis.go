@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/unionj-cloud/go-doudou/v2/toolkit/stringutils"
+	"time"
+)
+
+const (
+	// DefaultRedisTag is the redis image tag used by SetupRedisContainer if tag is empty
+	DefaultRedisTag = "7.0.11"
+	// redisStartupTimeout is max waiting time for redis to respond to PING
+	redisStartupTimeout = 60 * time.Second
+	// redisPollInterval is the interval of sending PING while waiting for redis
+	redisPollInterval = 200 * time.Millisecond
+)
+
+// waitForPing sends PING until redis responds PONG or ctx is done
+func waitForPing(ctx context.Context, addr string) error {
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	defer client.Close()
+	ticker := time.NewTicker(redisPollInterval)
+	defer ticker.Stop()
+	for {
+		err := client.Ping(ctx).Err()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "redis is not ready: %s", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetupRedisContainer starts a redis container and returns a function terminating it, together with host and port
+// of redis, which has responded to PING. tag overrides image tag, DefaultRedisTag is used if empty.
+// It returns error rather than panicking, so that callers can skip tests if docker is not available.
+func SetupRedisContainer(logger zerolog.Logger, tag string) (func() error, string, int, error) {
+	if stringutils.IsEmpty(tag) {
+		tag = DefaultRedisTag
+	}
+	image := fmt.Sprintf("redis:%s", tag)
+	logger.Info().Msgf("setup Redis Container %s", image)
+	ctx, cancel := context.WithTimeout(context.Background(), redisStartupTimeout)
+	defer cancel()
+	redisC, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        image,
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForListeningPort("6379/tcp").WithStartupTimeout(redisStartupTimeout),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, "", 0, errors.Wrapf(err, "error starting redis container %s", image)
+	}
+
+	closeContainer := func() error {
+		logger.Info().Msg("terminating container")
+		return errors.Wrap(redisC.Terminate(context.Background()), "error terminating redis container")
+	}
+
+	host, err := redisC.Host(ctx)
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting redis container host")
+	}
+	p, err := redisC.MappedPort(ctx, "6379/tcp")
+	if err != nil {
+		_ = closeContainer()
+		return nil, "", 0, errors.Wrap(err, "error getting redis container port")
+	}
+	if err = waitForPing(ctx, fmt.Sprintf("%s:%d", host, p.Int())); err != nil {
+		_ = closeContainer()
+		return nil, "", 0, err
+	}
+	return closeContainer, host, p.Int(), nil
+}
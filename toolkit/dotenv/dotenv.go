@@ -8,14 +8,42 @@ import (
 	"strings"
 )
 
-func Load(env string) {
+// files returns dotenv files in order of precedence
+func files(env string) []string {
 	wd, _ := os.Getwd()
-	_ = godotenv.Load(filepath.Join(wd, ".env."+env+".local"))
+	result := []string{filepath.Join(wd, ".env."+env+".local")}
 	if "test" != env {
-		_ = godotenv.Load(filepath.Join(wd, ".env.local"))
+		result = append(result, filepath.Join(wd, ".env.local"))
+	}
+	return append(result, filepath.Join(wd, ".env."+env), filepath.Join(wd, ".env"))
+}
+
+// Load sets key value pairs in dotenv files under working directory to environment variables, in order of precedence
+// from high to low: .env.{env}.local, .env.local (skipped for test env), .env.{env} and .env. As existing environment
+// variables are never overridden, values in environment specific files override those in .env, and real environment
+// variables override all files. Missing files are skipped silently.
+func Load(env string) {
+	for _, file := range files(env) {
+		_ = godotenv.Load(file)
+	}
+}
+
+// Read reads dotenv files with the same precedence as Load, but returns key value pairs
+// instead of setting them to environment variables
+func Read(env string) map[string]string {
+	result := make(map[string]string)
+	for _, file := range files(env) {
+		envMap, err := godotenv.Read(file)
+		if err != nil {
+			continue
+		}
+		for k, v := range envMap {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
 	}
-	_ = godotenv.Load(filepath.Join(wd, ".env."+env))
-	_ = godotenv.Load() // The Original .env
+	return result
 }
 
 func LoadAsMap(reader io.Reader) (map[string]interface{}, error) {
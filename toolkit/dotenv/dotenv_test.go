@@ -6,6 +6,7 @@ import (
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/dotenv"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -41,3 +42,29 @@ func TestLoad(t *testing.T) {
 	require.Equal(t, "6060", os.Getenv("GDD_PORT"))
 	require.Equal(t, "/api", os.Getenv("GDD_ROUTE_ROOT_PATH"))
 }
+
+func TestLoad_Layering(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("LAYER_BASE=base\nLAYER_ENV=base\nLAYER_REAL=base\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env.prod"), []byte("LAYER_ENV=prod\nLAYER_REAL=prod\n"), 0644))
+	require.NoError(t, os.Chdir(dir))
+	os.Setenv("LAYER_REAL", "real")
+	defer func() {
+		for _, key := range []string{"LAYER_BASE", "LAYER_ENV", "LAYER_REAL"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	dotenv.Load("prod")
+	require.Equal(t, "base", os.Getenv("LAYER_BASE"))
+	require.Equal(t, "prod", os.Getenv("LAYER_ENV"))
+	require.Equal(t, "real", os.Getenv("LAYER_REAL"))
+
+	require.Equal(t, map[string]string{
+		"LAYER_BASE": "base",
+		"LAYER_ENV":  "base",
+		"LAYER_REAL": "base",
+	}, dotenv.Read("staging"))
+}
@@ -1,7 +1,10 @@
 package ip
 
 import (
+	"errors"
 	"net"
+	"strings"
+	"syscall"
 )
 
 // GetOutboundIP return local public net.IP
@@ -11,3 +14,11 @@ func GetOutboundIP() net.IP {
 	localAddr := conn.LocalAddr().(*net.UDPAddr)
 	return localAddr.IP
 }
+
+// IsAddrInUse reports whether err is caused by binding an address already in use
+func IsAddrInUse(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, syscall.EADDRINUSE) || strings.Contains(err.Error(), "address already in use")
+}
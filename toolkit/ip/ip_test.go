@@ -23,3 +23,18 @@ func TestGetOutboundIP(t *testing.T) {
 		})
 	}
 }
+
+func TestIsAddrInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	_, err = net.Listen("tcp", ln.Addr().String())
+	if !IsAddrInUse(err) {
+		t.Errorf("IsAddrInUse(%v) got false", err)
+	}
+	if IsAddrInUse(nil) {
+		t.Errorf("IsAddrInUse(nil) got true")
+	}
+}
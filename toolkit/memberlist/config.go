@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-multierror"
 )
 
@@ -160,6 +161,11 @@ type Config struct {
 	// By default, this is 0, means not enabled
 	WeightInterval time.Duration
 
+	// WeightFunc replaces the formula above to calculate local node weight every WeightInterval if not nil,
+	// e.g. from inflight requests, cpu usage or free memory. Non-positive results are clamped to 1, so that
+	// local node is still selected by remote nodes.
+	WeightFunc func() int
+
 	// GossipVerifyIncoming controls whether to enforce encryption for incoming
 	// gossip. It is used for upshifting from unencrypted to encrypted gossip on
 	// a running cluster.
@@ -175,6 +181,21 @@ type Config struct {
 	// utilization. This is only available starting at protocol version 1.
 	EnableCompression bool
 
+	// CompressUserState is used to control compression of the user state
+	// returned by Delegate.LocalState during push/pull, independent of
+	// EnableCompression. Compressed state is flagged in the push/pull header,
+	// so it is transparent to Delegate.MergeRemoteState of remote nodes.
+	// Nodes of older versions don't know the flag and would pass compressed
+	// state to their delegate as is, so it should only be enabled after all
+	// nodes of the cluster are upgraded. The flag is omitted from the header
+	// if state is not compressed, keeping it compatible with older versions.
+	CompressUserState bool
+
+	// MetricSink receives push/pull byte counters if set, otherwise they are
+	// emitted to the global go-metrics sink like other metrics of memberlist.
+	// It allows exporting the counters without replacing the global sink.
+	MetricSink metrics.MetricSink
+
 	// SecretKey is used to initialize the primary encryption key in a keyring.
 	// The primary encryption key is the only key used to encrypt messages and
 	// the first key used while attempting to decrypt messages. Providing a
@@ -3,6 +3,7 @@ package memberlist_test
 import (
 	"bytes"
 	"fmt"
+	"github.com/armon/go-metrics"
 	"github.com/golang/mock/gomock"
 	"github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist"
 	memmock "github.com/unionj-cloud/go-doudou/v2/toolkit/memberlist/mock"
@@ -1145,6 +1146,57 @@ func TestMemberlist_UserData(t *testing.T) {
 	}
 }
 
+func TestMemberlist_UserData_CompressUserState(t *testing.T) {
+	newConfig := func() (*memberlist.Config, *MockDelegate) {
+		d := &MockDelegate{}
+		c := testConfig(t)
+		c.GossipInterval = 100 * time.Millisecond
+		c.PushPullInterval = 100 * time.Millisecond
+		c.CompressUserState = true
+		c.Delegate = d
+		return c, d
+	}
+
+	state1 := bytes.Repeat([]byte("something"), 100)
+	state2 := bytes.Repeat([]byte("my state"), 100)
+
+	c1, d1 := newConfig()
+	c1.BindPort = 55136
+	c1.AdvertisePort = 55136
+	c1.Name = "m1"
+	sink := metrics.NewInmemSink(time.Minute, time.Minute)
+	c1.MetricSink = sink
+	d1.setState(state1)
+
+	m1, err := memberlist.Create(c1)
+	require.NoError(t, err)
+	defer m1.Shutdown()
+
+	// Second node doesn't compress its state, both should be merged as is
+	c2, d2 := newConfig()
+	c2.BindPort = 55137
+	c2.AdvertisePort = 55137
+	c2.Name = "m2"
+	c2.CompressUserState = false
+	d2.setState(state2)
+
+	m2, err := memberlist.Create(c2)
+	require.NoError(t, err)
+	defer m2.Shutdown()
+
+	joinUrl := fmt.Sprintf("%s/%s:%d", m1.Config().Name, m1.AdvertiseAddr(), m1.AdvertisePort())
+	num, err := m2.Join([]string{joinUrl})
+	require.NoError(t, err)
+	require.Equal(t, 1, num)
+
+	require.Equal(t, state2, d1.getRemoteState())
+	require.Equal(t, state1, d2.getRemoteState())
+
+	counters := sink.Data()[0].Counters
+	require.Contains(t, counters, "memberlist.pushPull.sent")
+	require.Contains(t, counters, "memberlist.pushPull.userState.received")
+}
+
 func TestMemberlist_SendTo(t *testing.T) {
 	newConfig := func() (*memberlist.Config, *MockDelegate, net.IP) {
 		d := &MockDelegate{}
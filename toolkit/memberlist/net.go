@@ -185,6 +185,9 @@ type pushPullHeader struct {
 	Nodes        int
 	UserStateLen int  // Encodes the byte lengh of user state
 	Join         bool // Is this a join request or a anti-entropy run
+	// UserStateCompressed is true if user state is compressed by lzw.
+	// It is omitted if false for compatibility with older versions.
+	UserStateCompressed bool `codec:",omitempty"`
 }
 
 // userMsgHeader is used to encapsulate a userMsg
@@ -1002,6 +1005,16 @@ func (m *Memberlist) sendAndReceiveState(a Address, join bool) ([]pushNodeState,
 	return remoteNodes, userState, err
 }
 
+// incrPushPullCounter emits push/pull byte counters to MetricSink if set,
+// otherwise to the global sink
+func (m *Memberlist) incrPushPullCounter(key []string, val float32) {
+	if m.config.MetricSink != nil {
+		m.config.MetricSink.IncrCounter(key, val)
+		return
+	}
+	metrics.IncrCounter(key, val)
+}
+
 // sendLocalState is invoked to send our local state over a stream connection.
 func (m *Memberlist) sendLocalState(conn net.Conn, join bool) error {
 	// Setup a deadline
@@ -1030,11 +1043,23 @@ func (m *Memberlist) sendLocalState(conn net.Conn, join bool) error {
 		userData = m.config.Delegate.LocalState(join)
 	}
 
+	// Compress the delegate state if enabled
+	var compressed bool
+	if m.config.CompressUserState && len(userData) > 0 {
+		compBuf, err := compressBuffer(userData)
+		if err != nil {
+			m.logger.Printf("[ERR] memberlist: Failed to compress local state: %v", err)
+		} else {
+			userData = compBuf
+			compressed = true
+		}
+	}
+
 	// Create a bytes buffer writer
 	bufConn := bytes.NewBuffer(nil)
 
 	// Send our node state
-	header := pushPullHeader{Nodes: len(localNodes), UserStateLen: len(userData), Join: join}
+	header := pushPullHeader{Nodes: len(localNodes), UserStateLen: len(userData), Join: join, UserStateCompressed: compressed}
 	hd := codec.MsgpackHandle{}
 	enc := codec.NewEncoder(bufConn, &hd)
 
@@ -1059,6 +1084,9 @@ func (m *Memberlist) sendLocalState(conn net.Conn, join bool) error {
 		}
 	}
 
+	m.incrPushPullCounter([]string{"memberlist", "pushPull", "sent"}, float32(bufConn.Len()))
+	m.incrPushPullCounter([]string{"memberlist", "pushPull", "userState", "sent"}, float32(len(userData)))
+
 	// Get the send buffer
 	return m.rawSendMsgStream(conn, bufConn.Bytes())
 }
@@ -1210,6 +1238,14 @@ func (m *Memberlist) readRemoteState(bufConn io.Reader, dec *codec.Decoder) (boo
 		if err != nil {
 			return false, nil, nil, err
 		}
+		m.incrPushPullCounter([]string{"memberlist", "pushPull", "userState", "received"}, float32(header.UserStateLen))
+		if header.UserStateCompressed {
+			decomp, err := decompressBuffer(&compress{Algo: lzwAlgo, Buf: userBuf})
+			if err != nil {
+				return false, nil, nil, fmt.Errorf("Failed to decompress user state: %v", err)
+			}
+			userBuf = decomp
+		}
 	}
 
 	// For proto versions < 2, there is no port provided. Mask old
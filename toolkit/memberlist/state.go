@@ -670,15 +670,22 @@ func (m *Memberlist) gossip() {
 func (m *Memberlist) weight() {
 	defer metrics.MeasureSince([]string{"memberlist", "weight"}, time.Now())
 
-	// Weight = (AwarenessMaxMultiplier - AwarenessScore) * 0.5 + AwarenessMaxMultiplier * CPUIdlePercent * 0.5
-	percent, err := cpu.Percent(0, false)
-	if err != nil {
-		m.logger.Printf("[ERR] memberlist: Failed to get cpu busy percent: %s", err)
-		return
+	var result int
+	if m.config.WeightFunc != nil {
+		if result = m.config.WeightFunc(); result < 1 {
+			result = 1
+		}
+	} else {
+		// Weight = (AwarenessMaxMultiplier - AwarenessScore) * 0.6 + AwarenessMaxMultiplier * CPUIdlePercent * 0.4
+		percent, err := cpu.Percent(0, false)
+		if err != nil {
+			m.logger.Printf("[ERR] memberlist: Failed to get cpu busy percent: %s", err)
+			return
+		}
+		cpuIdlePercent := 100 - percent[0]
+		result = int(math.Round(float64(m.config.AwarenessMaxMultiplier-m.awareness.GetHealthScore())*0.6 +
+			float64(m.config.AwarenessMaxMultiplier)*cpuIdlePercent/100*0.4))
 	}
-	cpuIdlePercent := 100 - percent[0]
-	result := int(math.Round(float64(m.config.AwarenessMaxMultiplier-m.awareness.GetHealthScore())*0.6 +
-		float64(m.config.AwarenessMaxMultiplier)*cpuIdlePercent/100*0.4))
 
 	w := weight{Incarnation: m.incarnation, Node: m.config.Name, From: m.config.Name, Weight: result, WeightAt: time.Now().UTC().UnixNano() / 1000000}
 	m.encodeWeightMsgAndBroadcast(m.config.Name, w)
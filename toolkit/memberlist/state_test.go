@@ -2441,3 +2441,25 @@ func TestMemberList_ProbeNode_FallbackTCP_OldProtocol(t *testing.T) {
 //		t.Fatalf("bad:\nA: %v\nB: %v\nErr: %s", A, B, err)
 //	}
 //}
+
+func TestMemberlist_weight_WeightFunc(t *testing.T) {
+	m := GetMemberlist(t, func(c *Config) {
+		c.WeightFunc = func() int {
+			return -5
+		}
+	})
+	defer m.Shutdown()
+
+	m.weight()
+	msgs := m.broadcasts.GetBroadcasts(0, 1024)
+	if len(msgs) != 1 || messageType(msgs[0][0]) != weightMsg {
+		t.Fatalf("expected a weight message, got %v", msgs)
+	}
+	var w weight
+	if err := decode(msgs[0][1:], &w); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if w.Node != m.config.Name || w.Weight != 1 {
+		t.Fatalf("expected weight of %s clamped to 1, got %+v", m.config.Name, w)
+	}
+}
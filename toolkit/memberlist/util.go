@@ -219,6 +219,21 @@ func decodeCompoundMessage(buf []byte) (trunc int, parts [][]byte, err error) {
 // compressPayload takes an opaque input buffer, compresses it
 // and wraps it in a compress{} message that is encoded.
 func compressPayload(inp []byte) (*bytes.Buffer, error) {
+	buf, err := compressBuffer(inp)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a compressed message
+	c := compress{
+		Algo: lzwAlgo,
+		Buf:  buf,
+	}
+	return encode(compressMsg, &c)
+}
+
+// compressBuffer compresses an opaque input buffer with lzw
+func compressBuffer(inp []byte) ([]byte, error) {
 	var buf bytes.Buffer
 	compressor := lzw.NewWriter(&buf, lzw.LSB, lzwLitWidth)
 
@@ -231,13 +246,7 @@ func compressPayload(inp []byte) (*bytes.Buffer, error) {
 	if err := compressor.Close(); err != nil {
 		return nil, err
 	}
-
-	// Create a compressed message
-	c := compress{
-		Algo: lzwAlgo,
-		Buf:  buf.Bytes(),
-	}
-	return encode(compressMsg, &c)
+	return buf.Bytes(), nil
 }
 
 // decompressPayload is used to unpack an encoded compress{}
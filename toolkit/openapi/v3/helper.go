@@ -271,6 +271,22 @@ func IsStruct(field astutils.FieldMeta) bool {
 	return stringutils.IsNotEmpty(SchemaOf(field).Ref)
 }
 
+// IsFile check whether field is file type, i.e. multipart.FileHeader or v3.FileModel, or slice or pointer of them
+func IsFile(field astutils.FieldMeta) bool {
+	pschema := SchemaOf(field)
+	return pschema == File || (pschema.Type == ArrayT && pschema.Items == File)
+}
+
+// HasFile check whether any parameter of method is file type. Such method is sent as multipart/form-data request.
+func HasFile(method astutils.MethodMeta) bool {
+	for _, param := range method.Params {
+		if IsFile(param) {
+			return true
+		}
+	}
+	return false
+}
+
 // ElementType get element type string from slice
 func ElementType(t string) string {
 	if IsVarargs(t) {
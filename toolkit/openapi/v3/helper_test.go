@@ -233,6 +233,31 @@ func TestIsEnum(t *testing.T) {
 	})
 }
 
+func TestIsFile(t *testing.T) {
+	Convey("Test IsFile and HasFile", t, func() {
+		for _, typ := range []string{"*multipart.FileHeader", "[]*multipart.FileHeader", "v3.FileModel", "*v3.FileModel", "[]v3.FileModel", "...v3.FileModel"} {
+			So(IsFile(astutils.FieldMeta{Name: "file", Type: typ}), ShouldBeTrue)
+		}
+		So(IsFile(astutils.FieldMeta{Name: "data", Type: "[]byte"}), ShouldBeFalse)
+
+		So(HasFile(astutils.MethodMeta{
+			Name: "Upload",
+			Params: []astutils.FieldMeta{
+				{Name: "ctx", Type: "context.Context"},
+				{Name: "desc", Type: "string"},
+				{Name: "file", Type: "v3.FileModel"},
+			},
+		}), ShouldBeTrue)
+		So(HasFile(astutils.MethodMeta{
+			Name: "SignUp",
+			Params: []astutils.FieldMeta{
+				{Name: "ctx", Type: "context.Context"},
+				{Name: "username", Type: "string"},
+			},
+		}), ShouldBeFalse)
+	})
+}
+
 func TestElementType(t *testing.T) {
 	Convey("Test ElementType", t, func() {
 		So(ElementType("[]int"), ShouldEqual, "int")
@@ -11,13 +11,26 @@ import (
 	"strings"
 )
 
-func load(data []byte) error {
+// parse flattens yaml data to environment variable style key value pairs
+func parse(data []byte) (map[string]string, error) {
 	config := make(map[string]interface{})
 	err := yaml.Unmarshal(data, &config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	flat, _ := flatten.Flatten(config, "", flatten.UnderscoreStyle)
+	result := make(map[string]string)
+	for k, v := range flat {
+		result[strings.ToUpper(strings.ReplaceAll(k, "-", ""))] = fmt.Sprint(v)
+	}
+	return result, nil
+}
+
+func load(data []byte) error {
+	flat, err := parse(data)
+	if err != nil {
+		return err
+	}
 	currentEnv := map[string]bool{}
 	rawEnv := os.Environ()
 	for _, rawEnvLine := range rawEnv {
@@ -25,9 +38,8 @@ func load(data []byte) error {
 		currentEnv[key] = true
 	}
 	for k, v := range flat {
-		upperK := strings.ToUpper(strings.ReplaceAll(k, "-", ""))
-		if !currentEnv[upperK] {
-			_ = os.Setenv(upperK, fmt.Sprint(v))
+		if !currentEnv[k] {
+			_ = os.Setenv(k, v)
 		}
 	}
 	return nil
@@ -49,28 +61,50 @@ func loadFile(file string) {
 	}
 }
 
-func Load(env string) {
+// files returns yaml config files in order of precedence
+func files(env string) []string {
 	wd, _ := os.Getwd()
-	matches, _ := filepath.Glob(filepath.Join(wd, fmt.Sprintf("app-%s-local.%s", env, "y*ml")))
-	for _, item := range matches {
-		loadFile(item)
-	}
+	patterns := []string{fmt.Sprintf("app-%s-local.%s", env, "y*ml")}
 	if "test" != env {
-		matches, _ = filepath.Glob(filepath.Join(wd, fmt.Sprintf("app-local.%s", "y*ml")))
-		for _, item := range matches {
-			loadFile(item)
-		}
+		patterns = append(patterns, fmt.Sprintf("app-local.%s", "y*ml"))
 	}
-	matches, _ = filepath.Glob(filepath.Join(wd, fmt.Sprintf("app-%s.%s", env, "y*ml")))
-	for _, item := range matches {
-		loadFile(item)
+	patterns = append(patterns, fmt.Sprintf("app-%s.%s", env, "y*ml"), fmt.Sprintf("app.%s", "y*ml"))
+	var result []string
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(filepath.Join(wd, pattern))
+		result = append(result, matches...)
 	}
-	matches, _ = filepath.Glob(filepath.Join(wd, fmt.Sprintf("app.%s", "y*ml")))
-	for _, item := range matches {
+	return result
+}
+
+func Load(env string) {
+	for _, item := range files(env) {
 		loadFile(item)
 	}
 }
 
+// Read reads yaml config files with the same precedence as Load, but returns environment variable style
+// key value pairs instead of setting them to environment variables
+func Read(env string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range files(env) {
+		data, err := ioutil.ReadFile(item)
+		if err != nil {
+			continue
+		}
+		flat, err := parse(data)
+		if err != nil {
+			continue
+		}
+		for k, v := range flat {
+			if _, ok := result[k]; !ok {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
+
 func LoadReaderAsMap(reader io.Reader) (map[string]interface{}, error) {
 	data, err := ioutil.ReadAll(reader)
 	if err != nil {
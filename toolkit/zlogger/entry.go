@@ -9,9 +9,28 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
 )
 
-var Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+var Logger = zerolog.New(os.Stderr).Hook(levelHook{}).With().Timestamp().Logger()
+
+// minLevel is minimum accepted level of the global Logger. It is checked by levelHook for each event rather than set on
+// Logger, so that SetLevel takes effect without reassigning Logger while other goroutines are logging.
+var minLevel = int32(zerolog.TraceLevel)
+
+// levelHook discards events below minLevel, including those from copies of the global Logger
+type levelHook struct{}
+
+func (levelHook) Run(e *zerolog.Event, l zerolog.Level, msg string) {
+	if l != zerolog.NoLevel && !enabled(l) {
+		e.Discard()
+	}
+}
+
+func enabled(l zerolog.Level) bool {
+	return l >= GetLevel()
+}
 
 type LoggerConfig struct {
 	Dev     bool
@@ -75,7 +94,8 @@ func InitEntry(lc LoggerConfig) {
 		}
 	}
 	zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
-	zeroCtx := zerolog.New(output).Level(lc.Level).With().Timestamp().Stack()
+	atomic.StoreInt32(&minLevel, int32(lc.Level))
+	zeroCtx := zerolog.New(output).Hook(levelHook{}).With().Timestamp().Stack()
 	if lc.Caller {
 		zeroCtx = zeroCtx.Caller()
 	}
@@ -85,6 +105,22 @@ func InitEntry(lc LoggerConfig) {
 	Logger = zeroCtx.Logger()
 }
 
+// SetLevel sets minimum accepted level of the global Logger from level string like debug, info or warn, which takes
+// effect immediately for subsequent logs. It returns error for empty or unknown level string.
+func SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(strings.TrimSpace(level)))
+	if err != nil || strings.TrimSpace(level) == "" {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	atomic.StoreInt32(&minLevel, int32(lvl))
+	return nil
+}
+
+// GetLevel returns minimum accepted level of the global Logger
+func GetLevel() zerolog.Level {
+	return zerolog.Level(atomic.LoadInt32(&minLevel))
+}
+
 // SetOutput duplicates the global logger and sets w as its output,
 // then assign to zlogger package level zerolog.Logger
 func SetOutput(w io.Writer) {
@@ -128,6 +164,9 @@ func Err(err error) *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Trace() *zerolog.Event {
+	if !enabled(zerolog.TraceLevel) {
+		return nil
+	}
 	return Logger.Trace()
 }
 
@@ -135,6 +174,9 @@ func Trace() *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Debug() *zerolog.Event {
+	if !enabled(zerolog.DebugLevel) {
+		return nil
+	}
 	return Logger.Debug()
 }
 
@@ -142,6 +184,9 @@ func Debug() *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Info() *zerolog.Event {
+	if !enabled(zerolog.InfoLevel) {
+		return nil
+	}
 	return Logger.Info()
 }
 
@@ -149,6 +194,9 @@ func Info() *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Warn() *zerolog.Event {
+	if !enabled(zerolog.WarnLevel) {
+		return nil
+	}
 	return Logger.Warn()
 }
 
@@ -156,6 +204,9 @@ func Warn() *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Error() *zerolog.Event {
+	if !enabled(zerolog.ErrorLevel) {
+		return nil
+	}
 	return Logger.Error()
 }
 
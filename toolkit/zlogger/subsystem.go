@@ -0,0 +1,126 @@
+package zlogger
+
+import (
+	"github.com/rs/zerolog"
+	"sort"
+	"sync"
+)
+
+// Subsystem is a named logger whose level can be adjusted at runtime independently of level of the global Logger,
+// e.g. debug logs of one subsystem can be enabled without flooding application logs.
+// A subsystem inherits level of the global Logger until its own level is set.
+type Subsystem struct {
+	name  string
+	lock  sync.RWMutex
+	level *zerolog.Level
+}
+
+var subsystemLock sync.Mutex
+var subsystems = make(map[string]*Subsystem)
+
+// NewSubsystem returns the subsystem named name, creating it if not exists
+func NewSubsystem(name string) *Subsystem {
+	subsystemLock.Lock()
+	defer subsystemLock.Unlock()
+	if s, ok := subsystems[name]; ok {
+		return s
+	}
+	s := &Subsystem{name: name}
+	subsystems[name] = s
+	return s
+}
+
+// GetSubsystem returns the subsystem named name and true if it exists
+func GetSubsystem(name string) (*Subsystem, bool) {
+	subsystemLock.Lock()
+	defer subsystemLock.Unlock()
+	s, ok := subsystems[name]
+	return s, ok
+}
+
+// Subsystems returns all subsystems sorted by name
+func Subsystems() []*Subsystem {
+	subsystemLock.Lock()
+	result := make([]*Subsystem, 0, len(subsystems))
+	for _, s := range subsystems {
+		result = append(result, s)
+	}
+	subsystemLock.Unlock()
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// Name returns name of the subsystem
+func (s *Subsystem) Name() string {
+	return s.name
+}
+
+// Level returns level of the subsystem and false if it is inherited from the global Logger
+func (s *Subsystem) Level() (zerolog.Level, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.level == nil {
+		return GetLevel(), false
+	}
+	return *s.level, true
+}
+
+// SetLevel sets level of the subsystem
+func (s *Subsystem) SetLevel(level zerolog.Level) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.level = &level
+}
+
+// ResetLevel makes the subsystem inherit level of the global Logger again
+func (s *Subsystem) ResetLevel() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.level = nil
+}
+
+// Enabled returns true if events of level will be logged by the subsystem
+func (s *Subsystem) Enabled(level zerolog.Level) bool {
+	current, _ := s.Level()
+	return level >= current && current != zerolog.Disabled
+}
+
+// event starts a new message of level if the subsystem accepts it. As level of the subsystem may be lower than that
+// of the global Logger, the event is started without level and carries level field by itself, so that levelHook
+// doesn't discard it.
+func (s *Subsystem) event(level zerolog.Level) *zerolog.Event {
+	if !s.Enabled(level) {
+		return nil
+	}
+	return Logger.Log().Str(zerolog.LevelFieldName, zerolog.LevelFieldMarshalFunc(level))
+}
+
+// Debug starts a new message with debug level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (s *Subsystem) Debug() *zerolog.Event {
+	return s.event(zerolog.DebugLevel)
+}
+
+// Info starts a new message with info level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (s *Subsystem) Info() *zerolog.Event {
+	return s.event(zerolog.InfoLevel)
+}
+
+// Warn starts a new message with warn level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (s *Subsystem) Warn() *zerolog.Event {
+	return s.event(zerolog.WarnLevel)
+}
+
+// Error starts a new message with error level.
+//
+// You must call Msg on the returned event in order to send the event.
+func (s *Subsystem) Error() *zerolog.Event {
+	return s.event(zerolog.ErrorLevel)
+}